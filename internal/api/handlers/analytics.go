@@ -115,3 +115,25 @@ func (h *Handler) AnalyticsTopSourcesHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, topSources)
 }
+
+func (h *Handler) AnalyticsDemographicsHandler(c *gin.Context) {
+	if h.Config.DBInitErr != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": h.Config.DBInitErr.Error()})
+		return
+	}
+
+	user, loggedIn := h.GetAuthenticatedUser(c)
+	if !loggedIn {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	demographics, err := stats.GetDemographicsStats(h.DB, user.ID)
+	if err != nil {
+		log.Printf("Error getting demographics stats: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, demographics)
+}
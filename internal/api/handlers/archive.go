@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/sources"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var archiveImportNetworks = map[string]bool{
+	"Instagram": true,
+	"TikTok":    true,
+	"Bluesky":   true,
+	"Manual":    true,
+}
+
+func (h *Handler) renderArchiveImport(c *gin.Context, status int, user *database.User, data gin.H) {
+	userSources, err := h.DB.GetUserSources(c.Request.Context(), user.ID)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	var importable []database.Source
+	for _, source := range userSources {
+		if archiveImportNetworks[source.Network] {
+			importable = append(importable, source)
+		}
+	}
+
+	data["username"] = user.Username
+	data["sources"] = importable
+	data["title"] = "Import Archive"
+	c.HTML(status, "import.html", h.CommonData(c, data))
+}
+
+func (h *Handler) ArchiveImportViewHandler(c *gin.Context) {
+	if h.Config.DBInitErr != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
+			"error": h.Config.DBInitErr.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	user, loggedIn := h.GetAuthenticatedUser(c)
+	if !loggedIn {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	h.renderArchiveImport(c, http.StatusOK, user, gin.H{})
+}
+
+func (h *Handler) ArchiveImportHandler(c *gin.Context) {
+	user, loggedIn := h.GetAuthenticatedUser(c)
+	if !loggedIn {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	sourceID, err := uuid.Parse(c.PostForm("source_id"))
+	if err != nil {
+		h.renderArchiveImport(c, http.StatusBadRequest, user, gin.H{"import_error": "Select a source"})
+		return
+	}
+
+	source, err := h.DB.GetSourceById(c.Request.Context(), sourceID)
+	if err != nil || source.UserID != user.ID {
+		h.renderArchiveImport(c, http.StatusNotFound, user, gin.H{"import_error": "Source not found"})
+		return
+	}
+
+	file, err := c.FormFile("archive_file")
+	if err != nil {
+		h.renderArchiveImport(c, http.StatusBadRequest, user, gin.H{"import_error": "No file uploaded"})
+		return
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		h.renderArchiveImport(c, http.StatusInternalServerError, user, gin.H{"import_error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	result, err := sources.ImportArchive(h.DB, sourceID, file.Filename, f, file.Size)
+	if err != nil {
+		h.renderArchiveImport(c, http.StatusBadRequest, user, gin.H{
+			"import_error": fmt.Sprintf("Imported %d posts before failing: %v", result.Created, err),
+		})
+		return
+	}
+
+	h.renderArchiveImport(c, http.StatusOK, user, gin.H{
+		"import_result": result,
+		"import_source": source,
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/fetcher/sources"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -22,8 +23,14 @@ func (h *Handler) FacebookLoginHandler(c *gin.Context) {
 		return
 	}
 
+	source, err := h.DB.GetSourceById(c.Request.Context(), sid)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "source not found"})
+		return
+	}
+
 	pid := c.Query("pid")
-	if pid == "" {
+	if pid == "" && source.Network != "Threads" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "profile_id is required"})
 		return
 	}
@@ -38,7 +45,7 @@ func (h *Handler) FacebookLoginHandler(c *gin.Context) {
 
 	var fbConfig *oauth2.Config
 	if appID != nil && appSecret != nil {
-		fbConfig = authhelp.GenerateFacebookConfig(appID.(string), appSecret.(string), h.Config.BaseURL+"/auth/facebook/callback")
+		fbConfig = authhelp.GenerateMetaConfig(source.Network, appID.(string), appSecret.(string), h.Config.BaseURL+"/auth/facebook/callback")
 	} else {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "App ID and Secret not found in session"})
 		return
@@ -78,6 +85,12 @@ func (h *Handler) FacebookCallbackHandler(c *gin.Context) {
 
 	pid := values[1]
 
+	source, err := h.DB.GetSourceById(c.Request.Context(), sid)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "source not found"})
+		return
+	}
+
 	session := sessions.Default(c)
 	appID := session.Get("app_id_" + sid.String())
 	appSecret := session.Get("app_secret_" + sid.String())
@@ -87,7 +100,7 @@ func (h *Handler) FacebookCallbackHandler(c *gin.Context) {
 		return
 	}
 
-	fbConfig := authhelp.GenerateFacebookConfig(appID.(string), appSecret.(string), h.Config.BaseURL+"/auth/facebook/callback")
+	fbConfig := authhelp.GenerateMetaConfig(source.Network, appID.(string), appSecret.(string), h.Config.BaseURL+"/auth/facebook/callback")
 
 	code := c.Query("code")
 	token, err := fbConfig.Exchange(c, code)
@@ -96,20 +109,28 @@ func (h *Handler) FacebookCallbackHandler(c *gin.Context) {
 		return
 	}
 
-	longLivedToken, err := authhelp.ExchangeLongLivedToken(token.AccessToken, fbConfig)
+	longLivedToken, err := authhelp.ExchangeMetaLongLivedToken(&h.Fetcher.HTTPClient, source.Network, token.AccessToken, fbConfig)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "long-lived token exchange failed", "details": err.Error()})
 		return
 	}
 	token.AccessToken = longLivedToken
 
-	client := fbConfig.Client(c, token)
-	resp, err := client.Get("https://graph.facebook.com/me?fields=id,email")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user info"})
-		return
+	if source.Network == "Threads" {
+		pid, err = sources.GetThreadsUserID(h.Fetcher, longLivedToken)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user info", "details": err.Error()})
+			return
+		}
+	} else {
+		client := fbConfig.Client(c, token)
+		resp, err := client.Get("https://graph.facebook.com/me?fields=id,email")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user info"})
+			return
+		}
+		defer resp.Body.Close()
 	}
-	defer resp.Body.Close()
 
 	tokenStr, err := authhelp.OauthTokenToString(token)
 	if err != nil {
@@ -148,6 +169,12 @@ func (h *Handler) FacebookRefreshTokenHandler(c *gin.Context) {
 		return
 	}
 
+	source, err := h.DB.GetSourceById(context.Background(), sid)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "source not found"})
+		return
+	}
+
 	currentAccessToken, profileID, sourceAppData, _, err := authhelp.GetSourceToken(context.Background(), h.DB, h.Config.TokenEncryptionKey, sid)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve existing token", "details": err.Error()})
@@ -162,11 +189,11 @@ func (h *Handler) FacebookRefreshTokenHandler(c *gin.Context) {
 		return
 	}
 
-	fbConfig := authhelp.GenerateFacebookConfig(appID, appSecret, h.Config.BaseURL+"/auth/facebook/callback")
+	fbConfig := authhelp.GenerateMetaConfig(source.Network, appID, appSecret, h.Config.BaseURL+"/auth/facebook/callback")
 
-	newLongLivedToken, err := authhelp.ExchangeLongLivedToken(currentAccessToken, fbConfig)
+	newLongLivedToken, err := authhelp.RefreshMetaLongLivedToken(&h.Fetcher.HTTPClient, source.Network, currentAccessToken, fbConfig)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to refresh token with Facebook", "details": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to refresh token with " + source.Network, "details": err.Error()})
 		return
 	}
 
@@ -194,3 +221,185 @@ func (h *Handler) FacebookRefreshTokenHandler(c *gin.Context) {
 
 	c.Redirect(http.StatusSeeOther, "/sources")
 }
+
+func (h *Handler) GoogleLoginHandler(c *gin.Context) {
+
+	sid, err := uuid.Parse(c.Query("sid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid source_id"})
+		return
+	}
+
+	payload := base64.URLEncoding.EncodeToString([]byte(sid.String()))
+
+	state := h.Config.OauthEncryptionKey + "|" + payload
+
+	session := sessions.Default(c)
+	clientID := session.Get("client_id_" + sid.String())
+	clientSecret := session.Get("client_secret_" + sid.String())
+
+	if clientID == nil || clientSecret == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Client ID and Secret not found in session"})
+		return
+	}
+
+	googleConfig := authhelp.GenerateGoogleConfig(clientID.(string), clientSecret.(string), h.Config.BaseURL+"/auth/google/callback")
+
+	url := googleConfig.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce)
+	c.Redirect(http.StatusTemporaryRedirect, url)
+
+}
+
+func (h *Handler) GoogleCallbackHandler(c *gin.Context) {
+	rawState := c.Query("state")
+	parts := strings.SplitN(rawState, "|", 2)
+
+	if len(parts) != 2 || parts[0] != h.Config.OauthEncryptionKey {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid oauth state"})
+		return
+	}
+
+	decoded, err := base64.URLEncoding.DecodeString(parts[1])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid state payload"})
+		return
+	}
+
+	sid, err := uuid.Parse(string(decoded))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sid in state"})
+		return
+	}
+
+	session := sessions.Default(c)
+	clientID := session.Get("client_id_" + sid.String())
+	clientSecret := session.Get("client_secret_" + sid.String())
+
+	if clientID == nil || clientSecret == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Client ID and Secret not found in session"})
+		return
+	}
+
+	googleConfig := authhelp.GenerateGoogleConfig(clientID.(string), clientSecret.(string), h.Config.BaseURL+"/auth/google/callback")
+
+	code := c.Query("code")
+	token, err := googleConfig.Exchange(c, code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token exchange failed", "details": err.Error()})
+		return
+	}
+
+	if token.RefreshToken == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Google did not return a refresh token"})
+		return
+	}
+
+	sourceAppData := map[string]any{
+		"auth_mode":     "oauth",
+		"client_id":     clientID.(string),
+		"client_secret": clientSecret.(string),
+	}
+
+	err = authhelp.InsertSourceToken(context.Background(), h.DB, sid, token.RefreshToken, "", sourceAppData, h.Config.TokenEncryptionKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store token", "details": err.Error()})
+		return
+	}
+
+	session.Delete("client_id_" + sid.String())
+	session.Delete("client_secret_" + sid.String())
+	session.Save()
+
+	c.Redirect(http.StatusSeeOther, "/sources")
+}
+
+func (h *Handler) PatreonLoginHandler(c *gin.Context) {
+
+	sid, err := uuid.Parse(c.Query("sid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid source_id"})
+		return
+	}
+
+	payload := base64.URLEncoding.EncodeToString([]byte(sid.String()))
+
+	state := h.Config.OauthEncryptionKey + "|" + payload
+
+	session := sessions.Default(c)
+	clientID := session.Get("client_id_" + sid.String())
+	clientSecret := session.Get("client_secret_" + sid.String())
+
+	if clientID == nil || clientSecret == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Client ID and Secret not found in session"})
+		return
+	}
+
+	patreonConfig := authhelp.GeneratePatreonConfig(clientID.(string), clientSecret.(string), h.Config.BaseURL+"/auth/patreon/callback")
+
+	url := patreonConfig.AuthCodeURL(state)
+	c.Redirect(http.StatusTemporaryRedirect, url)
+
+}
+
+func (h *Handler) PatreonCallbackHandler(c *gin.Context) {
+	rawState := c.Query("state")
+	parts := strings.SplitN(rawState, "|", 2)
+
+	if len(parts) != 2 || parts[0] != h.Config.OauthEncryptionKey {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid oauth state"})
+		return
+	}
+
+	decoded, err := base64.URLEncoding.DecodeString(parts[1])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid state payload"})
+		return
+	}
+
+	sid, err := uuid.Parse(string(decoded))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sid in state"})
+		return
+	}
+
+	session := sessions.Default(c)
+	clientID := session.Get("client_id_" + sid.String())
+	clientSecret := session.Get("client_secret_" + sid.String())
+
+	if clientID == nil || clientSecret == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Client ID and Secret not found in session"})
+		return
+	}
+
+	patreonConfig := authhelp.GeneratePatreonConfig(clientID.(string), clientSecret.(string), h.Config.BaseURL+"/auth/patreon/callback")
+
+	code := c.Query("code")
+	token, err := patreonConfig.Exchange(c, code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token exchange failed", "details": err.Error()})
+		return
+	}
+
+	if token.RefreshToken == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Patreon did not return a refresh token"})
+		return
+	}
+
+	sourceAppData := map[string]any{
+		"auth_mode":     "oauth",
+		"client_id":     clientID.(string),
+		"client_secret": clientSecret.(string),
+	}
+
+	err = authhelp.InsertSourceToken(context.Background(), h.DB, sid, token.RefreshToken, "", sourceAppData, h.Config.TokenEncryptionKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store token", "details": err.Error()})
+		return
+	}
+
+	session.Delete("client_id_" + sid.String())
+	session.Delete("client_secret_" + sid.String())
+	session.Save()
+
+	c.Redirect(http.StatusSeeOther, "/sources")
+}
@@ -8,7 +8,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/fluffyriot/rpsync/internal/database"
 	"github.com/fluffyriot/rpsync/internal/exports"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/objectstore"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -63,7 +65,7 @@ func (h *Handler) ExportDeleteAllHandler(c *gin.Context) {
 				log.Printf("panic in background sync: %v", r)
 			}
 		}()
-		exports.DeleteAllExports(uid, h.DB)
+		exports.DeleteAllExports(uid, h.DB, h.Config.TokenEncryptionKey)
 	}(userId)
 
 	c.Redirect(http.StatusSeeOther, "/")
@@ -142,6 +144,11 @@ func (h *Handler) DownloadExportHandler(c *gin.Context) {
 		return
 	}
 
+	if export.StorageKey.Valid && export.TargetID.Valid {
+		h.redirectToStoredExport(c, export, requestedFilename)
+		return
+	}
+
 	baseDir, err := filepath.Abs("./outputs")
 	if err != nil {
 		c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
@@ -163,3 +170,29 @@ func (h *Handler) DownloadExportHandler(c *gin.Context) {
 
 	c.FileAttachment(fullPath, requestedFilename)
 }
+
+// redirectToStoredExport sends the browser to a short-lived link to the
+// uploaded copy of the export
+func (h *Handler) redirectToStoredExport(c *gin.Context, export database.Export, filename string) {
+	ctx := c.Request.Context()
+
+	store, err := objectstore.OpenExport(ctx, h.DB, h.Config.TokenEncryptionKey, export)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	url, err := store.PresignedUrl(ctx, export.StorageKey.String, filename)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	c.Redirect(http.StatusFound, url.String())
+}
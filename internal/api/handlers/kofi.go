@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/fluffyriot/rpsync/internal/fetcher/sources"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) KofiWebhookHandler(c *gin.Context) {
+	sid, err := uuid.Parse(c.Param("source_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid source_id"})
+		return
+	}
+
+	err = sources.HandleKofiWebhook(h.DB, h.Config.TokenEncryptionKey, sid, c.PostForm("data"))
+	if err != nil {
+		if errors.Is(err, sources.ErrKofiInvalidToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Ko-fi: Failed to process webhook for source %s: %v", sid, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to process webhook"})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/fluffyriot/rpsync/internal/fetcher/sources"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) manualError(c *gin.Context, status int, err error) {
+	c.HTML(status, "error.html", h.CommonData(c, gin.H{
+		"error": err.Error(),
+		"title": "Error",
+	}))
+}
+
+// manualSource returns the source named in the form once it is known to belong
+// to the signed-in user. It has written the response when ok is false.
+func (h *Handler) manualSource(c *gin.Context) (uuid.UUID, bool) {
+	user, loggedIn := h.GetAuthenticatedUser(c)
+	if !loggedIn {
+		c.Redirect(http.StatusFound, "/login")
+		return uuid.Nil, false
+	}
+
+	sourceID, err := uuid.Parse(c.PostForm("source_id"))
+	if err != nil {
+		h.manualError(c, http.StatusBadRequest, err)
+		return uuid.Nil, false
+	}
+
+	source, err := h.DB.GetSourceById(c.Request.Context(), sourceID)
+	if err != nil || source.UserID != user.ID {
+		h.manualError(c, http.StatusNotFound, fmt.Errorf("Source not found"))
+		return uuid.Nil, false
+	}
+
+	return source.ID, true
+}
+
+func (h *Handler) ManualEntryHandler(c *gin.Context) {
+	sourceID, ok := h.manualSource(c)
+	if !ok {
+		return
+	}
+
+	date, err := sources.ParseManualDate(c.PostForm("date"))
+	if err != nil {
+		h.manualError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	counts := make(map[string]sql.NullInt64)
+	for _, name := range []string{"likes", "reposts", "views", "comments", "followers", "following", "posts"} {
+		counts[name], err = sources.ParseManualCount(c.PostForm(name))
+		if err != nil {
+			h.manualError(c, http.StatusBadRequest, fmt.Errorf("%s: %w", name, err))
+			return
+		}
+	}
+
+	switch c.PostForm("kind") {
+	case "post":
+		err = sources.SaveManualPost(h.DB, sourceID, sources.ManualPost{
+			ID:        c.PostForm("post_id"),
+			URL:       c.PostForm("url"),
+			CreatedAt: date,
+			PostType:  c.PostForm("post_type"),
+			Content:   c.PostForm("content"),
+			Likes:     counts["likes"],
+			Reposts:   counts["reposts"],
+			Views:     counts["views"],
+			Comments:  counts["comments"],
+		})
+	case "stats":
+		entry := sources.ManualStats{Date: date}
+		if counts["followers"].Valid {
+			followers := int(counts["followers"].Int64)
+			entry.FollowersCount = &followers
+		}
+		if counts["following"].Valid {
+			following := int(counts["following"].Int64)
+			entry.FollowingCount = &following
+		}
+		if counts["posts"].Valid {
+			posts := int(counts["posts"].Int64)
+			entry.PostsCount = &posts
+		}
+		err = sources.SaveManualStats(h.DB, sourceID, entry)
+	default:
+		err = fmt.Errorf("unknown entry type %q", c.PostForm("kind"))
+	}
+	if err != nil {
+		h.manualError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/sources")
+}
+
+func (h *Handler) ManualImportHandler(c *gin.Context) {
+	sourceID, ok := h.manualSource(c)
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("manual_file")
+	if err != nil {
+		h.manualError(c, http.StatusBadRequest, fmt.Errorf("No file uploaded"))
+		return
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		h.manualError(c, http.StatusInternalServerError, err)
+		return
+	}
+	defer f.Close()
+
+	saved, err := sources.ImportManualCSV(h.DB, sourceID, c.PostForm("kind"), f)
+	if err != nil {
+		h.manualError(c, http.StatusBadRequest, fmt.Errorf("Imported %d rows before failing: %w", saved, err))
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/sources")
+}
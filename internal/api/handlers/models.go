@@ -11,3 +11,19 @@ type TopSourceViewModel struct {
 	FollowersCount    int64
 	ProfileURL        string
 }
+
+type ProfileColumnViewModel struct {
+	Key        string
+	IsDate     bool
+	Included   bool
+	Header     string
+	DateFormat string
+}
+
+type ProfileOutputViewModel struct {
+	Key     string
+	Label   string
+	Enabled bool
+	Custom  bool
+	Columns []ProfileColumnViewModel
+}
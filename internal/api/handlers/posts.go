@@ -2,10 +2,16 @@
 package handlers
 
 import (
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fluffyriot/rpsync/internal/database"
 	"github.com/fluffyriot/rpsync/internal/helpers"
+	"github.com/fluffyriot/rpsync/internal/stats"
 	"github.com/gin-gonic/gin"
 )
 
@@ -35,25 +41,133 @@ func (h *Handler) PostsHandler(c *gin.Context) {
 		return
 	}
 
+	siteVisits := make(map[string]int)
+	visits, err := h.DB.GetPostSiteVisitsForUser(ctx, user.ID)
+	if err != nil {
+		log.Printf("Error fetching site visits for posts: %v", err)
+	}
+	for _, v := range visits {
+		siteVisits[v.SourceID.String()+"/"+v.NetworkInternalID] = v.SiteVisits
+	}
+
+	reactionParts := make(map[string][]string)
+	breakdown, err := h.DB.GetReactionBreakdownForUser(ctx, user.ID)
+	if err != nil {
+		log.Printf("Error fetching reaction breakdown for posts: %v", err)
+	}
+	for _, r := range breakdown {
+		key := r.SourceID.String() + "/" + r.NetworkInternalID
+		reaction := r.Reaction
+		if name, _, ok := strings.Cut(reaction, ":"); ok && name != "custom" {
+			reaction = ":" + name + ":"
+		}
+		reactionParts[key] = append(reactionParts[key], fmt.Sprintf("%s %d", reaction, r.Count))
+	}
+
+	replies := make(map[string]int64)
+	comments, err := h.DB.GetLatestPostCommentsForUser(ctx, user.ID)
+	if err != nil {
+		log.Printf("Error fetching reply counts for posts: %v", err)
+	}
+	for _, cm := range comments {
+		replies[cm.SourceID.String()+"/"+cm.NetworkInternalID] = cm.Comments.Int64
+	}
+
+	streams := make(map[string]database.GetPostStreamStatsForUserRow)
+	streamStats, err := h.DB.GetPostStreamStatsForUser(ctx, user.ID)
+	if err != nil {
+		log.Printf("Error fetching stream stats for posts: %v", err)
+	}
+	for _, st := range streamStats {
+		streams[st.SourceID.String()+"/"+st.NetworkInternalID] = st
+	}
+
+	siteURL := ""
+	gaSource, err := h.DB.GetUserActiveSourceByName(ctx, database.GetUserActiveSourceByNameParams{
+		UserID:  user.ID,
+		Network: "Google Analytics",
+	})
+	if err == nil {
+		siteURL = gaSource.UserName
+	}
+
+	referrals, err := stats.GetReferralStats(h.DB, user.ID)
+	if err != nil {
+		log.Printf("Error fetching referral stats: %v", err)
+	}
+
 	type PostWithURL struct {
-		Post database.GetRecentPostsForUserRow
-		URL  string
+		Post        database.GetRecentPostsForUserRow
+		URL         string
+		UTMURL      string
+		SiteVisits  int
+		Reactions   string
+		Replies     string
+		Duration    string
+		PeakViewers string
+		AvgViewers  string
+		UpvoteRatio string
+		Quotes      string
+		Bookmarks   string
 	}
 
 	postsWithURL := make([]PostWithURL, 0, len(posts))
 	for _, post := range posts {
 		url := ""
+		utmURL := ""
 		if post.Network.Valid && post.Author != "" {
 			url, _ = helpers.ConvPostToURL(post.Network.String, post.Author, post.NetworkInternalID)
 		}
+		if post.Network.Valid && siteURL != "" {
+			utmURL, _ = helpers.BuildUTMURL(siteURL, post.Network.String, post.NetworkInternalID)
+		}
+		key := post.SourceID.String() + "/" + post.NetworkInternalID
+		replyCount := ""
+		if n, ok := replies[key]; ok {
+			replyCount = strconv.FormatInt(n, 10)
+		}
+		upvoteRatio := ""
+		if post.UpvoteRatio.Valid {
+			upvoteRatio = strconv.FormatFloat(post.UpvoteRatio.Float64*100, 'f', 0, 64) + "%"
+		}
+		quotes, bookmarks := "", ""
+		if post.Quotes.Valid {
+			quotes = strconv.FormatInt(post.Quotes.Int64, 10)
+		}
+		if post.Bookmarks.Valid {
+			bookmarks = strconv.FormatInt(post.Bookmarks.Int64, 10)
+		}
+		duration, peakViewers, avgViewers := "", "", ""
+		if st, ok := streams[key]; ok {
+			if st.DurationSeconds.Valid {
+				duration = (time.Duration(st.DurationSeconds.Int64) * time.Second).String()
+			}
+			if st.PeakViewers.Valid {
+				peakViewers = strconv.FormatInt(st.PeakViewers.Int64, 10)
+			}
+			if st.AverageViewers.Valid {
+				avgViewers = strconv.FormatFloat(st.AverageViewers.Float64, 'f', 1, 64)
+			}
+		}
 		postsWithURL = append(postsWithURL, PostWithURL{
-			Post: post,
-			URL:  url,
+			Post:        post,
+			URL:         url,
+			UTMURL:      utmURL,
+			SiteVisits:  siteVisits[key],
+			Reactions:   strings.Join(reactionParts[key], ", "),
+			Replies:     replyCount,
+			Duration:    duration,
+			PeakViewers: peakViewers,
+			AvgViewers:  avgViewers,
+			UpvoteRatio: upvoteRatio,
+			Quotes:      quotes,
+			Bookmarks:   bookmarks,
 		})
 	}
 
 	c.HTML(http.StatusOK, "posts.html", h.CommonData(c, gin.H{
-		"posts": postsWithURL,
-		"title": "Posts",
+		"posts":     postsWithURL,
+		"referrals": referrals,
+		"title":     "Posts",
 	}))
 }
@@ -42,11 +42,31 @@ func (h *Handler) SourcesHandler(c *gin.Context) {
 		return
 	}
 
+	renames, err := h.DB.GetUserSourceRenames(ctx, user.ID)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	previousNames := make(map[uuid.UUID]string)
+	for _, rename := range renames {
+		if names, ok := previousNames[rename.SourceID]; ok {
+			previousNames[rename.SourceID] = names + ", " + rename.OldUserName
+		} else {
+			previousNames[rename.SourceID] = rename.OldUserName
+		}
+	}
+
 	c.HTML(http.StatusOK, "sources.html", h.CommonData(c, gin.H{
 		"username":          user.Username,
 		"user_id":           user.ID,
 		"sources":           sources,
+		"previous_names":    previousNames,
 		"available_sources": helpers.AvailableSources,
+		"base_url":          h.Config.BaseURL,
 		"title":             "Sources",
 	}))
 }
@@ -83,13 +103,27 @@ func (h *Handler) SourcesSetupHandler(c *gin.Context) {
 	tgChannelId := c.PostForm("telegram_channel_id")
 	tgAppId := c.PostForm("telegram_app_id")
 	tgAppHash := c.PostForm("telegram_app_hash")
+	tgAuthMode := c.PostForm("telegram_auth_mode")
+	tgPhone := c.PostForm("telegram_phone")
 	googlePropertyId := c.PostForm("google_analytics_property_id")
 	googleKey := c.PostForm("google_service_account_key")
 	discordBotToken := c.PostForm("discord_bot_token")
 	discordServerId := c.PostForm("discord_server_id")
 	discordChannelIds := c.PostForm("discord_channel_ids")
+	deviantartClientId := c.PostForm("deviantart_client_id")
+	deviantartClientSecret := c.PostForm("deviantart_client_secret")
+	twitchClientId := c.PostForm("twitch_client_id")
+	twitchClientSecret := c.PostForm("twitch_client_secret")
+	kofiVerificationToken := c.PostForm("kofi_verification_token")
+	blueskyAppPassword := c.PostForm("bluesky_app_password")
+	blueskyIncludeReplies := c.PostForm("bluesky_include_replies") == "on"
+	patreonClientID := c.PostForm("patreon_client_id")
+	patreonClientSecret := c.PostForm("patreon_client_secret")
 	appID := c.PostForm("app_id")
 	appSecret := c.PostForm("app_secret")
+	youtubeAuthMode := c.PostForm("youtube_auth_mode")
+	googleClientID := c.PostForm("google_client_id")
+	googleClientSecret := c.PostForm("google_client_secret")
 
 	if userID == "" || network == "" || username == "" {
 		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
@@ -99,22 +133,46 @@ func (h *Handler) SourcesSetupHandler(c *gin.Context) {
 		return
 	}
 
-	sid, _, err := config.CreateSourceFromForm(
-		h.DB,
-		userID,
-		network,
-		username,
-		tgBotToken,
-		tgChannelId,
-		tgAppId,
-		tgAppHash,
-		googleKey,
-		googlePropertyId,
-		discordBotToken,
-		discordServerId,
-		discordChannelIds,
-		h.Config.TokenEncryptionKey,
-	)
+	if network == "YouTube" && youtubeAuthMode == "oauth" && (googleClientID == "" || googleClientSecret == "") {
+		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+			"error": "Client ID and Client Secret are required for YouTube Analytics",
+			"title": "Error",
+		}))
+		return
+	}
+
+	if network == "Patreon" && (patreonClientID == "" || patreonClientSecret == "") {
+		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+			"error": "Client ID and Client Secret are required for Patreon",
+			"title": "Error",
+		}))
+		return
+	}
+
+	sid, _, err := config.CreateSourceFromForm(h.DB, config.SourceForm{
+		UserID:                 userID,
+		Network:                network,
+		Username:               username,
+		TelegramBotToken:       tgBotToken,
+		TelegramChannelId:      tgChannelId,
+		TelegramAppId:          tgAppId,
+		TelegramAppHash:        tgAppHash,
+		TelegramAuthMode:       tgAuthMode,
+		TelegramPhone:          tgPhone,
+		GoogleKey:              googleKey,
+		GooglePropertyId:       googlePropertyId,
+		YoutubeAuthMode:        youtubeAuthMode,
+		DiscordBotToken:        discordBotToken,
+		DiscordServerId:        discordServerId,
+		DiscordChannelIds:      discordChannelIds,
+		DeviantartClientId:     deviantartClientId,
+		DeviantartClientSecret: deviantartClientSecret,
+		TwitchClientId:         twitchClientId,
+		TwitchClientSecret:     twitchClientSecret,
+		KofiVerificationToken:  kofiVerificationToken,
+		BlueskyAppPassword:     blueskyAppPassword,
+		BlueskyIncludeReplies:  blueskyIncludeReplies,
+	}, h.Config.TokenEncryptionKey)
 	if err != nil {
 		c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
 			"error": err.Error(),
@@ -123,7 +181,7 @@ func (h *Handler) SourcesSetupHandler(c *gin.Context) {
 		return
 	}
 
-	if network == "Instagram" {
+	if network == "Instagram" || network == "Facebook" || network == "Threads" {
 		session := sessions.Default(c)
 		session.Set("app_id_"+sid, appID)
 		session.Set("app_secret_"+sid, appSecret)
@@ -133,6 +191,31 @@ func (h *Handler) SourcesSetupHandler(c *gin.Context) {
 		return
 	}
 
+	if network == "YouTube" && youtubeAuthMode == "oauth" {
+		session := sessions.Default(c)
+		session.Set("client_id_"+sid, googleClientID)
+		session.Set("client_secret_"+sid, googleClientSecret)
+		session.Save()
+
+		c.Redirect(http.StatusSeeOther, "/auth/google/login?sid="+sid)
+		return
+	}
+
+	if network == "Patreon" {
+		session := sessions.Default(c)
+		session.Set("client_id_"+sid, patreonClientID)
+		session.Set("client_secret_"+sid, patreonClientSecret)
+		session.Save()
+
+		c.Redirect(http.StatusSeeOther, "/auth/patreon/login?sid="+sid)
+		return
+	}
+
+	if network == "Telegram" && tgAuthMode == "user" {
+		c.Redirect(http.StatusSeeOther, "/auth/telegram/login?sid="+sid)
+		return
+	}
+
 	if network == "TikTok" {
 		c.Redirect(http.StatusSeeOther, "/auth/tiktok/login?username="+username)
 		return
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fluffyriot/rpsync/internal/pusher/targets"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) TargetProfileHandler(c *gin.Context) {
+	target, ok := h.userTarget(c, "CSV", c.Query("target_id"))
+	if !ok {
+		return
+	}
+
+	settings, _ := targets.ParseSettings(target)
+
+	var outputs []ProfileOutputViewModel
+	for _, output := range targets.CsvOutputs {
+		profile, err := targets.LoadProfile(c.Request.Context(), h.DB, target, output)
+		if err != nil {
+			c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
+				"error": err.Error(),
+				"title": "Error",
+			}))
+			return
+		}
+
+		view := ProfileOutputViewModel{
+			Key:     output.Key,
+			Label:   output.Label,
+			Enabled: settings.CsvEnabled(output.Key),
+			Custom:  profile.Custom,
+		}
+		for _, col := range output.Columns {
+			colView := ProfileColumnViewModel{Key: col.Key, IsDate: col.IsDate(), Header: col.Key, DateFormat: "iso"}
+			if pc, ok := profile.Column(col.Key); ok {
+				colView.Included = true
+				colView.Header = pc.Header
+				colView.DateFormat = pc.DateFormat
+			}
+			view.Columns = append(view.Columns, colView)
+		}
+		outputs = append(outputs, view)
+	}
+
+	c.HTML(http.StatusOK, "target-profile.html", h.CommonData(c, gin.H{
+		"target":       target,
+		"outputs":      outputs,
+		"date_formats": targets.DateFormats,
+		"title":        "Export Profile",
+	}))
+}
+
+func (h *Handler) UpdateTargetProfileHandler(c *gin.Context) {
+	target, ok := h.userTarget(c, "CSV", c.PostForm("target_id"))
+	if !ok {
+		return
+	}
+
+	reset := c.PostForm("reset") != ""
+
+	changes := make([]targets.ProfileChange, len(targets.CsvOutputs))
+	for i, output := range targets.CsvOutputs {
+		if reset {
+			changes[i] = targets.ProfileChange{Output: output, Reset: true}
+		} else {
+			changes[i] = profileFromForm(c, output)
+		}
+	}
+
+	if err := targets.SaveProfiles(c.Request.Context(), h.DBConn, h.DB, target, changes); err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/targets/profile?target_id="+target.ID.String())
+}
+
+// profileFromForm reads the columns ticked for output. A profile equal to the
+// default becomes a reset instead, so the output keeps following new columns.
+func profileFromForm(c *gin.Context, output targets.CsvOutput) targets.ProfileChange {
+	included := c.PostFormArray("include_" + output.Key)
+
+	change := targets.ProfileChange{Output: output}
+	isDefault := len(included) == len(output.Columns)
+	for _, col := range output.Columns {
+		picked := false
+		for _, key := range included {
+			if key == col.Key {
+				picked = true
+			}
+		}
+		if !picked {
+			continue
+		}
+
+		input := targets.ProfileColumnInput{
+			Key:    col.Key,
+			Header: c.PostForm("header_" + output.Key + "_" + col.Key),
+		}
+		if col.IsDate() {
+			input.DateFormat = c.DefaultPostForm("format_"+output.Key+"_"+col.Key, "iso")
+		}
+		if (input.Header != "" && input.Header != col.Key) || (input.DateFormat != "" && input.DateFormat != "iso") {
+			isDefault = false
+		}
+		change.Columns = append(change.Columns, input)
+	}
+
+	change.Reset = isDefault
+	return change
+}
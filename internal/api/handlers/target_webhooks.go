@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) WebhookDeliveriesHandler(c *gin.Context) {
+	target, ok := h.userTarget(c, "Webhook", c.Query("target_id"))
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.DB.GetWebhookDeliveriesByTarget(c.Request.Context(), target.ID)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	secret, _, _, err := authhelp.GetTargetToken(c.Request.Context(), h.DB, h.Config.TokenEncryptionKey, target.ID)
+	if err != nil {
+		log.Printf("Webhook target %s: reading secret: %v", target.ID, err)
+	}
+
+	c.HTML(http.StatusOK, "target-webhook.html", h.CommonData(c, gin.H{
+		"target":     target,
+		"deliveries": deliveries,
+		"secret":     secret,
+		"title":      "Webhook Deliveries",
+	}))
+}
+
+func (h *Handler) SendWebhookTestHandler(c *gin.Context) {
+	target, ok := h.userTarget(c, "Webhook", c.PostForm("target_id"))
+	if !ok {
+		return
+	}
+
+	if err := webhook.EmitPing(c.Request.Context(), h.DB, target); err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	h.deliverWebhooksInBackground(target)
+
+	c.Redirect(http.StatusSeeOther, "/targets/webhook?target_id="+target.ID.String())
+}
+
+func (h *Handler) RetryWebhookDeliveryHandler(c *gin.Context) {
+	target, ok := h.userTarget(c, "Webhook", c.PostForm("target_id"))
+	if !ok {
+		return
+	}
+
+	deliveryID, err := uuid.Parse(c.PostForm("delivery_id"))
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	delivery, err := h.DB.GetWebhookDeliveryById(c.Request.Context(), deliveryID)
+	if err != nil || delivery.TargetID != target.ID {
+		c.HTML(http.StatusNotFound, "error.html", h.CommonData(c, gin.H{
+			"error": "Delivery not found",
+			"title": "Error",
+		}))
+		return
+	}
+
+	err = h.DB.RequeueWebhookDelivery(c.Request.Context(), database.RequeueWebhookDeliveryParams{
+		ID:            delivery.ID,
+		NextAttemptAt: time.Now(),
+	})
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	h.deliverWebhooksInBackground(target)
+
+	c.Redirect(http.StatusSeeOther, "/targets/webhook?target_id="+target.ID.String())
+}
+
+// deliverWebhooksInBackground sends what was just queued instead of waiting for
+// the worker's next run
+func (h *Handler) deliverWebhooksInBackground(target database.Target) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic in background webhook delivery: %v", r)
+			}
+		}()
+		if err := webhook.DeliverDue(context.Background(), h.DB, h.Puller, h.Config.TokenEncryptionKey, target); err != nil {
+			log.Printf("Webhook target %s: %v", target.ID, err)
+		}
+	}()
+}
@@ -4,13 +4,18 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 
 	"github.com/fluffyriot/rpsync/internal/config"
 	"github.com/fluffyriot/rpsync/internal/database"
 	"github.com/fluffyriot/rpsync/internal/helpers"
 	"github.com/fluffyriot/rpsync/internal/pusher"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/objectstore"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -33,7 +38,7 @@ func (h *Handler) TargetsHandler(c *gin.Context) {
 		return
 	}
 
-	targets, err := h.DB.GetUserTargets(ctx, user.ID)
+	userTargets, err := h.DB.GetUserTargets(ctx, user.ID)
 	if err != nil {
 		c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
 			"error": err.Error(),
@@ -41,11 +46,40 @@ func (h *Handler) TargetsHandler(c *gin.Context) {
 		}))
 		return
 	}
+
+	targetSettings := make(map[string]targets.Settings)
+	s3AccessKeys := make(map[string]string)
+	for _, t := range userTargets {
+		if t.TargetType != "CSV" && t.TargetType != "SQLite" && t.TargetType != "Webhook" {
+			continue
+		}
+		settings, err := targets.ParseSettings(t)
+		if err != nil {
+			log.Printf("Target %s: %v", t.ID, err)
+		}
+		targetSettings[t.ID.String()] = settings
+
+		// The access key is shown so it can be kept; the secret never is
+		if settings.S3Enabled() {
+			token, err := h.DB.GetTokenByTarget(ctx, uuid.NullUUID{UUID: t.ID, Valid: true})
+			if err == nil {
+				s3AccessKeys[t.ID.String()] = token.ProfileID.String
+			}
+		}
+	}
+
 	c.HTML(http.StatusOK, "targets.html", h.CommonData(c, gin.H{
 		"username":          user.Username,
 		"user_id":           user.ID,
-		"targets":           targets,
+		"targets":           userTargets,
 		"available_targets": helpers.AvailableTargets,
+		"csv_outputs":       targets.CsvOutputs,
+		"csv_modes":         targets.CsvModes,
+		"export_formats":    targets.ExportFormats,
+		"csv_delimiters":    targets.CsvDelimiters,
+		"webhook_events":    targets.WebhookEvents,
+		"target_settings":   targetSettings,
+		"s3_access_keys":    s3AccessKeys,
 		"title":             "Targets",
 	}))
 }
@@ -56,8 +90,37 @@ func (h *Handler) TargetsSetupHandler(c *gin.Context) {
 	dbId := c.PostForm("db_id")
 	token := c.PostForm("api_token")
 	hostUrl := c.PostForm("host_url")
+	accountEmail := c.PostForm("account_email")
 	period := "PT30M"
 
+	var settings targets.Settings
+	if err := settingsFromForm(c, target, &settings); err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	if target == "Google Sheets" {
+		token = c.PostForm("google_service_account_key")
+	}
+
+	if target == "CSV" || target == "SQLite" {
+		token, accountEmail = "", ""
+		if settings.S3Enabled() {
+			token = c.PostForm("s3_secret_key")
+			accountEmail = c.PostForm("s3_access_key")
+			if err := checkBucket(c.Request.Context(), settings, accountEmail, token); err != nil {
+				c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+					"error": err.Error(),
+					"title": "Error",
+				}))
+				return
+			}
+		}
+	}
+
 	if userID == "" || target == "" || period == "" {
 		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
 			"error": "All fields are required",
@@ -74,6 +137,8 @@ func (h *Handler) TargetsSetupHandler(c *gin.Context) {
 		period,
 		token,
 		hostUrl,
+		accountEmail,
+		settings,
 		h.Config.TokenEncryptionKey,
 	)
 	if err != nil {
@@ -87,6 +152,180 @@ func (h *Handler) TargetsSetupHandler(c *gin.Context) {
 	c.Redirect(http.StatusSeeOther, "/targets")
 }
 
+func (h *Handler) UpdateTargetSettingsHandler(c *gin.Context) {
+	user, loggedIn := h.GetAuthenticatedUser(c)
+	if !loggedIn {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	targetID, err := uuid.Parse(c.PostForm("target_id"))
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	target, err := h.DB.GetTargetById(c.Request.Context(), targetID)
+	if err != nil || target.UserID != user.ID {
+		c.HTML(http.StatusNotFound, "error.html", h.CommonData(c, gin.H{
+			"error": "Target not found",
+			"title": "Error",
+		}))
+		return
+	}
+
+	settings, err := targets.ParseSettings(target)
+	if err != nil {
+		log.Printf("Target %s: %v, resetting settings", target.ID, err)
+	}
+
+	if err := settingsFromForm(c, target.TargetType, &settings); err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	usesBucket := (target.TargetType == "CSV" || target.TargetType == "SQLite") && settings.S3Enabled()
+	accessKey := c.PostForm("s3_access_key")
+	secretKey := c.PostForm("s3_secret_key")
+	if usesBucket {
+		// The secret is never shown again, so an empty one keeps the stored secret
+		bucketSecret := secretKey
+		if bucketSecret == "" {
+			bucketSecret, err = objectstore.StoredSecret(c.Request.Context(), h.DB, h.Config.TokenEncryptionKey, target.ID, accessKey)
+		}
+		if err == nil {
+			err = checkBucket(c.Request.Context(), settings, accessKey, bucketSecret)
+		}
+		if err != nil {
+			c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+				"error": err.Error(),
+				"title": "Error",
+			}))
+			return
+		}
+	}
+
+	settingsJson, err := settings.Encode()
+	if err == nil {
+		err = h.DB.UpdateTargetSettings(c.Request.Context(), database.UpdateTargetSettingsParams{
+			ID:       target.ID,
+			Settings: settingsJson,
+		})
+	}
+	if err == nil && usesBucket && secretKey != "" {
+		err = objectstore.SaveCredentials(c.Request.Context(), h.DB, h.Config.TokenEncryptionKey, target.ID, accessKey, secretKey)
+	}
+	if err == nil && (target.TargetType == "CSV" || target.TargetType == "SQLite") && !settings.S3Enabled() {
+		// Exports already uploaded still need the credentials to be downloaded and removed
+		var stored int64
+		stored, err = h.DB.CountStoredExportsByTarget(c.Request.Context(), uuid.NullUUID{UUID: target.ID, Valid: true})
+		if err == nil && stored == 0 {
+			err = objectstore.RemoveCredentials(c.Request.Context(), h.DB, target.ID)
+		}
+	}
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/targets")
+}
+
+// userTarget loads the target of targetType named by the request, making sure
+// it belongs to the signed in user. It renders the error page when it does not.
+func (h *Handler) userTarget(c *gin.Context, targetType, id string) (database.Target, bool) {
+	user, loggedIn := h.GetAuthenticatedUser(c)
+	if !loggedIn {
+		c.Redirect(http.StatusFound, "/login")
+		return database.Target{}, false
+	}
+
+	targetID, err := uuid.Parse(id)
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return database.Target{}, false
+	}
+
+	target, err := h.DB.GetTargetById(c.Request.Context(), targetID)
+	if err != nil || target.UserID != user.ID || target.TargetType != targetType {
+		c.HTML(http.StatusNotFound, "error.html", h.CommonData(c, gin.H{
+			"error": "Target not found",
+			"title": "Error",
+		}))
+		return database.Target{}, false
+	}
+
+	return target, true
+}
+
+// settingsFromForm reads the options of the target types that have any
+func settingsFromForm(c *gin.Context, targetType string, settings *targets.Settings) error {
+	switch targetType {
+	case "CSV":
+		if err := csvSettingsFromForm(c, settings); err != nil {
+			return err
+		}
+		return s3SettingsFromForm(c, settings)
+	case "SQLite":
+		return s3SettingsFromForm(c, settings)
+	case "Webhook":
+		return settings.SetWebhookEvents(c.PostFormArray("webhook_events"))
+	}
+	return nil
+}
+
+func csvSettingsFromForm(c *gin.Context, settings *targets.Settings) error {
+	retentionDays := 0
+	if v := c.PostForm("csv_retention_days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("retention must be a number of days")
+		}
+		retentionDays = days
+	}
+
+	if err := settings.SetFileFormat(c.DefaultPostForm("export_format", targets.FormatCsv), c.DefaultPostForm("csv_delimiter", ",")); err != nil {
+		return err
+	}
+
+	return settings.SetCsvOptions(c.PostFormArray("csv_outputs"), c.DefaultPostForm("csv_mode", targets.CsvModeBundle), retentionDays)
+}
+
+func s3SettingsFromForm(c *gin.Context, settings *targets.Settings) error {
+	return settings.SetS3Options(
+		c.PostForm("s3_endpoint"),
+		c.PostForm("s3_region"),
+		c.PostForm("s3_bucket"),
+		c.PostForm("s3_prefix"),
+		c.PostForm("s3_keep_local") == "on",
+	)
+}
+
+// checkBucket makes sure exports can be uploaded before the bucket is saved
+func checkBucket(ctx context.Context, settings targets.Settings, accessKey, secretKey string) error {
+	if accessKey == "" || secretKey == "" {
+		return fmt.Errorf("access key and secret key are required to upload exports to S3")
+	}
+
+	store, err := objectstore.New(settings, accessKey, secretKey)
+	if err != nil {
+		return err
+	}
+	return store.Check(ctx)
+}
+
 func (h *Handler) ActivateTargetHandler(c *gin.Context) {
 	targetID, err := uuid.Parse(c.PostForm("target_id"))
 	if err != nil {
@@ -157,6 +396,15 @@ func (h *Handler) DeleteTargetHandler(c *gin.Context) {
 		return
 	}
 
+	target, err := h.DB.GetTargetById(context.Background(), targetID)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
 	err = h.DB.DeleteTarget(context.Background(), targetID)
 	if err != nil {
 		c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
@@ -166,6 +414,12 @@ func (h *Handler) DeleteTargetHandler(c *gin.Context) {
 		return
 	}
 
+	if target.TargetType == "CSV" {
+		if err := os.RemoveAll(targets.CsvStableDir(target)); err != nil {
+			log.Printf("Failed to remove stable CSV files of target %s: %v", target.ID, err)
+		}
+	}
+
 	c.Redirect(http.StatusSeeOther, "/targets")
 }
 
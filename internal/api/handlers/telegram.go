@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/fluffyriot/rpsync/internal/fetcher/sources"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gotd/td/telegram/auth"
+	"github.com/gotd/td/tg"
+)
+
+func (h *Handler) TelegramLoginHandler(c *gin.Context) {
+	sid, err := uuid.Parse(c.Query("sid"))
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+			"error": "invalid source_id",
+			"title": "Error",
+		}))
+		return
+	}
+
+	client, phone, err := sources.GetTelegramClient(h.DB, h.Config.TokenEncryptionKey, sid)
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	var codeHash string
+	err = client.Run(context.Background(), func(ctx context.Context) error {
+		sent, err := client.Auth().SendCode(ctx, phone, auth.SendCodeOptions{})
+		if err != nil {
+			return err
+		}
+
+		code, ok := sent.(*tg.AuthSentCode)
+		if !ok {
+			return fmt.Errorf("unexpected response to code request: %T", sent)
+		}
+		codeHash = code.PhoneCodeHash
+		return nil
+	})
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", h.CommonData(c, gin.H{
+			"error": "Failed to send Telegram login code: " + err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	session := sessions.Default(c)
+	session.Set("tg_code_hash_"+sid.String(), codeHash)
+	session.Save()
+
+	c.HTML(http.StatusOK, "telegram_login.html", h.CommonData(c, gin.H{
+		"SourceID": sid.String(),
+		"title":    "Telegram Login",
+	}))
+}
+
+func (h *Handler) TelegramVerifyHandler(c *gin.Context) {
+	sid, err := uuid.Parse(c.PostForm("sid"))
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+			"error": "invalid source_id",
+			"title": "Error",
+		}))
+		return
+	}
+
+	code := c.PostForm("code")
+	password := c.PostForm("password")
+
+	session := sessions.Default(c)
+	codeHash, ok := session.Get("tg_code_hash_" + sid.String()).(string)
+	if !ok || codeHash == "" {
+		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+			"error": "Telegram login code expired, please start the login again",
+			"title": "Error",
+		}))
+		return
+	}
+
+	client, phone, err := sources.GetTelegramClient(h.DB, h.Config.TokenEncryptionKey, sid)
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", h.CommonData(c, gin.H{
+			"error": err.Error(),
+			"title": "Error",
+		}))
+		return
+	}
+
+	err = client.Run(context.Background(), func(ctx context.Context) error {
+		_, err := client.Auth().SignIn(ctx, phone, code, codeHash)
+		if errors.Is(err, auth.ErrPasswordAuthNeeded) {
+			if password == "" {
+				return fmt.Errorf("two-step verification password is required")
+			}
+			_, err = client.Auth().Password(ctx, password)
+		}
+		return err
+	})
+	if err != nil {
+		c.HTML(http.StatusOK, "telegram_login.html", h.CommonData(c, gin.H{
+			"SourceID": sid.String(),
+			"error":    err.Error(),
+			"title":    "Telegram Login",
+		}))
+		return
+	}
+
+	session.Delete("tg_code_hash_" + sid.String())
+	session.Save()
+
+	c.Redirect(http.StatusSeeOther, "/sources")
+}
@@ -22,6 +22,44 @@ func GenerateFacebookConfig(appID, appSecret, callbackURL string) *oauth2.Config
 	return facebookOAuthConfig
 }
 
+func GenerateFacebookPageConfig(appID, appSecret, callbackURL string) *oauth2.Config {
+	facebookOAuthConfig := &oauth2.Config{
+		ClientID:     appID,
+		ClientSecret: appSecret,
+		RedirectURL:  callbackURL,
+		Scopes:       []string{"pages_show_list", "pages_read_engagement", "pages_read_user_content", "read_insights"},
+		Endpoint:     facebook.Endpoint,
+	}
+	return facebookOAuthConfig
+}
+
+// GenerateMetaConfig picks the OAuth config for the Meta product behind a source,
+// so Instagram, Facebook Pages and Threads can share the /auth/facebook flow
+func GenerateMetaConfig(network, appID, appSecret, callbackURL string) *oauth2.Config {
+	switch network {
+	case "Threads":
+		return GenerateThreadsConfig(appID, appSecret, callbackURL)
+	case "Facebook":
+		return GenerateFacebookPageConfig(appID, appSecret, callbackURL)
+	default:
+		return GenerateFacebookConfig(appID, appSecret, callbackURL)
+	}
+}
+
+func ExchangeMetaLongLivedToken(client *http.Client, network, shortLivedToken string, config *oauth2.Config) (string, error) {
+	if network == "Threads" {
+		return ExchangeThreadsLongLivedToken(client, shortLivedToken, config)
+	}
+	return ExchangeLongLivedToken(shortLivedToken, config)
+}
+
+func RefreshMetaLongLivedToken(client *http.Client, network, longLivedToken string, config *oauth2.Config) (string, error) {
+	if network == "Threads" {
+		return RefreshThreadsLongLivedToken(client, longLivedToken)
+	}
+	return ExchangeLongLivedToken(longLivedToken, config)
+}
+
 func OauthTokenToString(token *oauth2.Token) (string, error) {
 	tokenM, err := json.Marshal(token)
 	if err != nil {
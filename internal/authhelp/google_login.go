@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package authhelp
+
+import (
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/youtube/v3"
+	"google.golang.org/api/youtubeanalytics/v2"
+)
+
+func GenerateGoogleConfig(clientID, clientSecret, callbackURL string) *oauth2.Config {
+	googleOAuthConfig := &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  callbackURL,
+		Scopes:       []string{youtube.YoutubeReadonlyScope, youtubeanalytics.YtAnalyticsReadonlyScope},
+		Endpoint:     google.Endpoint,
+	}
+	return googleOAuthConfig
+}
@@ -0,0 +1,22 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package authhelp
+
+import (
+	"golang.org/x/oauth2"
+)
+
+var PatreonEndpoint = oauth2.Endpoint{
+	AuthURL:  "https://www.patreon.com/oauth2/authorize",
+	TokenURL: "https://www.patreon.com/api/oauth2/token",
+}
+
+func GeneratePatreonConfig(clientID, clientSecret, callbackURL string) *oauth2.Config {
+	patreonOAuthConfig := &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  callbackURL,
+		Scopes:       []string{"identity", "campaigns", "campaigns.members", "campaigns.posts"},
+		Endpoint:     PatreonEndpoint,
+	}
+	return patreonOAuthConfig
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package authhelp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+	"github.com/gotd/td/session"
+)
+
+type TelegramSessionStorage struct {
+	DB            *database.Queries
+	EncryptionKey []byte
+	SourceID      uuid.UUID
+}
+
+func (s *TelegramSessionStorage) LoadSession(ctx context.Context) ([]byte, error) {
+	dbSession, err := s.DB.GetTelegramSessionBySource(ctx, s.SourceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, session.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return decrypt(dbSession.EncryptedSession, dbSession.Nonce, s.EncryptionKey)
+}
+
+func (s *TelegramSessionStorage) StoreSession(ctx context.Context, data []byte) error {
+	ciphertext, nonce, err := encrypt(data, s.EncryptionKey)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.UpsertTelegramSession(ctx, database.UpsertTelegramSessionParams{
+		ID:               uuid.New(),
+		SourceID:         s.SourceID,
+		EncryptedSession: ciphertext,
+		Nonce:            nonce,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	})
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package authhelp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2"
+)
+
+var ThreadsEndpoint = oauth2.Endpoint{
+	AuthURL:   "https://threads.net/oauth/authorize",
+	TokenURL:  "https://graph.threads.net/oauth/access_token",
+	AuthStyle: oauth2.AuthStyleInParams,
+}
+
+func GenerateThreadsConfig(appID, appSecret, callbackURL string) *oauth2.Config {
+	threadsOAuthConfig := &oauth2.Config{
+		ClientID:     appID,
+		ClientSecret: appSecret,
+		RedirectURL:  callbackURL,
+		Scopes:       []string{"threads_basic", "threads_manage_insights", "threads_read_replies"},
+		Endpoint:     ThreadsEndpoint,
+	}
+	return threadsOAuthConfig
+}
+
+func requestThreadsToken(client *http.Client, endpoint string, params url.Values) (string, error) {
+	req, err := http.NewRequest("GET", endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	var res struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+		Error       struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(bodyBytes, &res); err != nil {
+		return "", err
+	}
+
+	if res.AccessToken == "" {
+		return "", fmt.Errorf("Threads did not return an access token: %s", res.Error.Message)
+	}
+
+	return res.AccessToken, nil
+}
+
+func ExchangeThreadsLongLivedToken(client *http.Client, shortLivedToken string, config *oauth2.Config) (string, error) {
+	params := url.Values{}
+	params.Add("grant_type", "th_exchange_token")
+	params.Add("client_secret", config.ClientSecret)
+	params.Add("access_token", shortLivedToken)
+
+	return requestThreadsToken(client, "https://graph.threads.net/access_token", params)
+}
+
+func RefreshThreadsLongLivedToken(client *http.Client, longLivedToken string) (string, error) {
+	params := url.Values{}
+	params.Add("grant_type", "th_refresh_token")
+	params.Add("access_token", longLivedToken)
+
+	return requestThreadsToken(client, "https://graph.threads.net/refresh_access_token", params)
+}
@@ -223,6 +223,36 @@ func UpdateSourceProfile(
 	})
 }
 
+func UpdateSourceAppData(
+	ctx context.Context,
+	db *database.Queries,
+	encryptionKey []byte,
+	sid uuid.UUID,
+	key string,
+	value any,
+) error {
+	_, _, sourceAppData, tokenID, err := GetSourceToken(ctx, db, encryptionKey, sid)
+	if err != nil {
+		return err
+	}
+
+	if sourceAppData == nil {
+		sourceAppData = make(map[string]any)
+	}
+	sourceAppData[key] = value
+
+	metaBytes, err := json.Marshal(sourceAppData)
+	if err != nil {
+		return err
+	}
+
+	return db.UpdateTokenAppData(ctx, database.UpdateTokenAppDataParams{
+		ID:            tokenID,
+		SourceAppData: json.RawMessage(metaBytes),
+		UpdatedAt:     time.Now(),
+	})
+}
+
 func normalizeAccessTokenPayload(input string) ([]byte, error) {
 	if input == "" {
 		return nil, errors.New("access token is empty")
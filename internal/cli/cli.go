@@ -6,10 +6,13 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"os"
 	"syscall"
 
 	"github.com/fluffyriot/rpsync/internal/authhelp"
 	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/sources"
+	"github.com/google/uuid"
 	"golang.org/x/term"
 )
 
@@ -78,3 +81,34 @@ func HandleReset2FA(dbQueries *database.Queries, username string) {
 
 	fmt.Printf("2FA successfully disabled for user '%s'\n", username)
 }
+
+func HandleImportArchive(dbQueries *database.Queries, sourceID, path string) {
+	if sourceID == "" || path == "" {
+		log.Fatal("--source-id and --import-archive are required")
+	}
+
+	sid, err := uuid.Parse(sourceID)
+	if err != nil {
+		log.Fatalf("Invalid source ID '%s': %v", sourceID, err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open archive: %v", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		log.Fatalf("Failed to read archive: %v", err)
+	}
+
+	fmt.Printf("Importing %s into source %s...\n", path, sid)
+
+	result, err := sources.ImportArchive(dbQueries, sid, path, f, info.Size())
+	if err != nil {
+		log.Fatalf("Import failed after %d new posts: %v", result.Created, err)
+	}
+
+	fmt.Printf("Imported %d new posts, %d were already present.\n", result.Created, result.Existing)
+}
@@ -12,6 +12,9 @@ import (
 
 	"github.com/fluffyriot/rpsync/internal/authhelp"
 	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/warehouse"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/webhook"
 	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/google/uuid"
 	"github.com/pressly/goose/v3"
@@ -193,35 +196,78 @@ func CreateUserFromForm(dbQueries *database.Queries, userName string) (name, id
 
 }
 
-func CreateSourceFromForm(dbQueries *database.Queries, uid, network, username, tgBotToken, tgChannelId, tgAppId, tgAppHash, googleKey, googlePropertyId, discordBotToken, discordServerId, discordChannelIds string, encryptionKey []byte) (id, networkName string, e error) {
+// SourceForm holds what the add source form submits. Only the fields of the
+// chosen network are used.
+type SourceForm struct {
+	UserID                 string
+	Network                string
+	Username               string
+	TelegramBotToken       string
+	TelegramChannelId      string
+	TelegramAppId          string
+	TelegramAppHash        string
+	TelegramAuthMode       string
+	TelegramPhone          string
+	GoogleKey              string
+	GooglePropertyId       string
+	YoutubeAuthMode        string
+	DiscordBotToken        string
+	DiscordServerId        string
+	DiscordChannelIds      string
+	DeviantartClientId     string
+	DeviantartClientSecret string
+	TwitchClientId         string
+	TwitchClientSecret     string
+	KofiVerificationToken  string
+	BlueskyAppPassword     string
+	BlueskyIncludeReplies  bool
+}
 
-	uidParse, err := uuid.Parse(uid)
+func CreateSourceFromForm(dbQueries *database.Queries, form SourceForm, encryptionKey []byte) (id, networkName string, e error) {
+
+	uidParse, err := uuid.Parse(form.UserID)
 	if err != nil {
 		return "", "", fmt.Errorf("Failed to parse UUID. Error: %v", err)
 	}
 
-	if network == "Telegram" && (tgBotToken == "" || tgChannelId == "" || tgAppId == "" || tgAppHash == "") {
+	if form.Network == "Telegram" && form.TelegramAuthMode != "user" && (form.TelegramBotToken == "" || form.TelegramChannelId == "" || form.TelegramAppId == "" || form.TelegramAppHash == "") {
 		return "", "", fmt.Errorf("Channel Id, Bot Token, App Id and App Hash are required for Telegram")
 	}
 
-	if network == "Google Analytics" && (googleKey == "" || googlePropertyId == "") {
+	if form.Network == "Telegram" && form.TelegramAuthMode == "user" && (form.TelegramPhone == "" || form.TelegramChannelId == "" || form.TelegramAppId == "" || form.TelegramAppHash == "") {
+		return "", "", fmt.Errorf("Channel Id, Phone Number, App Id and App Hash are required for Telegram user sessions")
+	}
+
+	if form.Network == "Google Analytics" && (form.GoogleKey == "" || form.GooglePropertyId == "") {
 		return "", "", fmt.Errorf("Property ID and Service Account Key are required for Google Analytics")
 	}
 
-	if network == "YouTube" && googleKey == "" {
+	if form.Network == "YouTube" && form.YoutubeAuthMode != "oauth" && form.GoogleKey == "" {
 		return "", "", fmt.Errorf("Service Account Key is required for YouTube")
 	}
 
-	if network == "Discord" && (discordBotToken == "" || discordServerId == "" || discordChannelIds == "") {
+	if form.Network == "Discord" && (form.DiscordBotToken == "" || form.DiscordServerId == "" || form.DiscordChannelIds == "") {
 		return "", "", fmt.Errorf("Bot Token, Server ID, and Channel ID(s) are required for Discord")
 	}
 
+	if form.Network == "DeviantArt" && (form.DeviantartClientId == "" || form.DeviantartClientSecret == "") {
+		return "", "", fmt.Errorf("Client ID and Client Secret are required for DeviantArt")
+	}
+
+	if form.Network == "Twitch" && (form.TwitchClientId == "" || form.TwitchClientSecret == "") {
+		return "", "", fmt.Errorf("Client ID and Client Secret are required for Twitch")
+	}
+
+	if form.Network == "Ko-fi" && form.KofiVerificationToken == "" {
+		return "", "", fmt.Errorf("Verification Token is required for Ko-fi")
+	}
+
 	s, err := dbQueries.CreateSource(context.Background(), database.CreateSourceParams{
 		ID:           uuid.New(),
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
-		Network:      network,
-		UserName:     username,
+		Network:      form.Network,
+		UserName:     form.Username,
 		UserID:       uidParse,
 		IsActive:     true,
 		SyncStatus:   "Initialized",
@@ -232,34 +278,39 @@ func CreateSourceFromForm(dbQueries *database.Queries, uid, network, username, t
 		return "", "", fmt.Errorf("Failed to create source. Error: %v", err)
 	}
 
-	if network == "Telegram" {
-		tokenFormatted := tgBotToken + ":::" + tgAppId + ":::" + tgAppHash
-		err = authhelp.InsertSourceToken(context.Background(), dbQueries, s.ID, tokenFormatted, tgChannelId, nil, encryptionKey)
+	if form.Network == "Telegram" {
+		tokenFormatted := form.TelegramBotToken + ":::" + form.TelegramAppId + ":::" + form.TelegramAppHash
+		var sourceAppData map[string]any
+		if form.TelegramAuthMode == "user" {
+			tokenFormatted = form.TelegramPhone + ":::" + form.TelegramAppId + ":::" + form.TelegramAppHash
+			sourceAppData = map[string]any{"auth_mode": "user"}
+		}
+		err = authhelp.InsertSourceToken(context.Background(), dbQueries, s.ID, tokenFormatted, form.TelegramChannelId, sourceAppData, encryptionKey)
 		if err != nil {
 			dbQueries.DeleteSource(context.Background(), s.ID)
 			return "", "", fmt.Errorf("Failed to create source with auth key. Error: %v", err)
 		}
 	}
 
-	if network == "Google Analytics" {
-		err = authhelp.InsertSourceToken(context.Background(), dbQueries, s.ID, googleKey, googlePropertyId, nil, encryptionKey)
+	if form.Network == "Google Analytics" {
+		err = authhelp.InsertSourceToken(context.Background(), dbQueries, s.ID, form.GoogleKey, form.GooglePropertyId, nil, encryptionKey)
 		if err != nil {
 			dbQueries.DeleteSource(context.Background(), s.ID)
 			return "", "", fmt.Errorf("Failed to create source with auth key. Error: %v", err)
 		}
 	}
 
-	if network == "YouTube" {
-		err = authhelp.InsertSourceToken(context.Background(), dbQueries, s.ID, googleKey, "", nil, encryptionKey)
+	if form.Network == "YouTube" && form.YoutubeAuthMode != "oauth" {
+		err = authhelp.InsertSourceToken(context.Background(), dbQueries, s.ID, form.GoogleKey, "", nil, encryptionKey)
 		if err != nil {
 			dbQueries.DeleteSource(context.Background(), s.ID)
 			return "", "", fmt.Errorf("Failed to create source with auth key. Error: %v", err)
 		}
 	}
 
-	if network == "Discord" {
-		tokenFormatted := discordBotToken
-		profileFormatted := discordServerId + ":::" + discordChannelIds
+	if form.Network == "Discord" {
+		tokenFormatted := form.DiscordBotToken
+		profileFormatted := form.DiscordServerId + ":::" + form.DiscordChannelIds
 		err = authhelp.InsertSourceToken(context.Background(), dbQueries, s.ID, tokenFormatted, profileFormatted, nil, encryptionKey)
 		if err != nil {
 			dbQueries.DeleteSource(context.Background(), s.ID)
@@ -267,17 +318,98 @@ func CreateSourceFromForm(dbQueries *database.Queries, uid, network, username, t
 		}
 	}
 
+	if form.Network == "DeviantArt" {
+		tokenFormatted := form.DeviantartClientId + ":::" + form.DeviantartClientSecret
+		err = authhelp.InsertSourceToken(context.Background(), dbQueries, s.ID, tokenFormatted, "", nil, encryptionKey)
+		if err != nil {
+			dbQueries.DeleteSource(context.Background(), s.ID)
+			return "", "", fmt.Errorf("Failed to create source with auth key. Error: %v", err)
+		}
+	}
+
+	if form.Network == "Twitch" {
+		tokenFormatted := form.TwitchClientId + ":::" + form.TwitchClientSecret
+		err = authhelp.InsertSourceToken(context.Background(), dbQueries, s.ID, tokenFormatted, "", nil, encryptionKey)
+		if err != nil {
+			dbQueries.DeleteSource(context.Background(), s.ID)
+			return "", "", fmt.Errorf("Failed to create source with auth key. Error: %v", err)
+		}
+	}
+
+	if form.Network == "Ko-fi" {
+		err = authhelp.InsertSourceToken(context.Background(), dbQueries, s.ID, form.KofiVerificationToken, "", nil, encryptionKey)
+		if err != nil {
+			dbQueries.DeleteSource(context.Background(), s.ID)
+			return "", "", fmt.Errorf("Failed to create source with auth key. Error: %v", err)
+		}
+	}
+
+	if form.Network == "Bluesky" && form.BlueskyAppPassword != "" {
+		sourceAppData := map[string]any{"include_replies": form.BlueskyIncludeReplies}
+		err = authhelp.InsertSourceToken(context.Background(), dbQueries, s.ID, form.BlueskyAppPassword, "", sourceAppData, encryptionKey)
+		if err != nil {
+			dbQueries.DeleteSource(context.Background(), s.ID)
+			return "", "", fmt.Errorf("Failed to create source with auth key. Error: %v", err)
+		}
+	}
+
 	return s.ID.String(), s.Network, nil
 
 }
 
-func CreateTargetFromForm(dbQueries *database.Queries, uid, target, dbId, period, token, hostUrl string, encryptionKey []byte) (id, targetName string, e error) {
+func CreateTargetFromForm(dbQueries *database.Queries, uid, target, dbId, period, token, hostUrl, accountEmail string, settings targets.Settings, encryptionKey []byte) (id, targetName string, e error) {
 
 	uidParse, err := uuid.Parse(uid)
 	if err != nil {
 		return "", "", fmt.Errorf("Failed to parse UUID. Error: %v", err)
 	}
 
+	if target == "Google Sheets" && (token == "" || dbId == "") {
+		return "", "", fmt.Errorf("Spreadsheet ID and Service Account Key are required for Google Sheets")
+	}
+
+	if target == "Baserow" && (accountEmail == "" || token == "") {
+		return "", "", fmt.Errorf("Account email and password are required for Baserow")
+	}
+
+	if target == "PostgreSQL" || target == "MySQL" {
+		if token == "" {
+			return "", "", fmt.Errorf("Connection string is required for %s", target)
+		}
+		if !warehouse.ValidSchemaName(dbId) {
+			return "", "", fmt.Errorf("Schema must start with a letter or underscore and contain only letters, digits and underscores")
+		}
+	}
+
+	if target == "Webhook" {
+		if err := webhook.ValidateUrl(hostUrl); err != nil {
+			return "", "", err
+		}
+		// The secret signs every delivery, so one is generated when left empty
+		if token == "" {
+			token, err = webhook.NewSecret()
+			if err != nil {
+				return "", "", fmt.Errorf("Failed to generate webhook secret. Error: %v", err)
+			}
+		}
+	}
+
+	if (target == "CSV" || target == "SQLite") && settings.S3Enabled() && (accountEmail == "" || token == "") {
+		return "", "", fmt.Errorf("Access key and secret key are required to upload exports to S3")
+	}
+
+	settingsJson, err := settings.Encode()
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to encode target settings. Error: %v", err)
+	}
+
+	// Baserow signs in with email and password, and export buckets with an access
+	// key and secret, so the email or key is kept alongside the token
+	profileId := dbId
+	if target == "Baserow" || target == "CSV" || target == "SQLite" {
+		profileId = accountEmail
+	}
+
 	t, err := dbQueries.CreateTarget(context.Background(), database.CreateTargetParams{
 		ID:            uuid.New(),
 		CreatedAt:     time.Now(),
@@ -289,6 +421,7 @@ func CreateTargetFromForm(dbQueries *database.Queries, uid, target, dbId, period
 		SyncStatus:    "Initialized",
 		SyncFrequency: period,
 		HostUrl:       sql.NullString{String: hostUrl, Valid: true},
+		Settings:      settingsJson,
 	})
 
 	if err != nil {
@@ -297,7 +430,7 @@ func CreateTargetFromForm(dbQueries *database.Queries, uid, target, dbId, period
 
 	if token != "" {
 
-		err = authhelp.InsertTargetToken(context.Background(), dbQueries, t.ID, token, dbId, encryptionKey)
+		err = authhelp.InsertTargetToken(context.Background(), dbQueries, t.ID, token, profileId, encryptionKey)
 
 		if err != nil {
 			return "", "", fmt.Errorf("Failed to store token. Error: %v", err)
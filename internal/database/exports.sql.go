@@ -23,7 +23,7 @@ SET
 WHERE
     id = $1
 RETURNING
-    id, created_at, completed_at, export_status, status_message, user_id, download_url, export_method, target_id
+    id, created_at, completed_at, export_status, status_message, user_id, download_url, export_method, target_id, storage_key, storage_endpoint, storage_region, storage_bucket
 `
 
 type ChangeExportStatusByIdParams struct {
@@ -53,10 +53,29 @@ func (q *Queries) ChangeExportStatusById(ctx context.Context, arg ChangeExportSt
 		&i.DownloadUrl,
 		&i.ExportMethod,
 		&i.TargetID,
+		&i.StorageKey,
+		&i.StorageEndpoint,
+		&i.StorageRegion,
+		&i.StorageBucket,
 	)
 	return i, err
 }
 
+const countStoredExportsByTarget = `-- name: CountStoredExportsByTarget :one
+SELECT COUNT(*)
+FROM exports
+WHERE
+    target_id = $1
+    AND storage_key IS NOT NULL
+`
+
+func (q *Queries) CountStoredExportsByTarget(ctx context.Context, targetID uuid.NullUUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countStoredExportsByTarget, targetID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createExport = `-- name: CreateExport :one
 INSERT INTO
     exports (
@@ -82,7 +101,7 @@ VALUES (
         $9
     )
 RETURNING
-    id, created_at, completed_at, export_status, status_message, user_id, download_url, export_method, target_id
+    id, created_at, completed_at, export_status, status_message, user_id, download_url, export_method, target_id, storage_key, storage_endpoint, storage_region, storage_bucket
 `
 
 type CreateExportParams struct {
@@ -120,6 +139,10 @@ func (q *Queries) CreateExport(ctx context.Context, arg CreateExportParams) (Exp
 		&i.DownloadUrl,
 		&i.ExportMethod,
 		&i.TargetID,
+		&i.StorageKey,
+		&i.StorageEndpoint,
+		&i.StorageRegion,
+		&i.StorageBucket,
 	)
 	return i, err
 }
@@ -133,8 +156,17 @@ func (q *Queries) DeleteAllExportsByUserId(ctx context.Context, userID uuid.UUID
 	return err
 }
 
+const deleteExportById = `-- name: DeleteExportById :exec
+DELETE FROM exports WHERE id = $1
+`
+
+func (q *Queries) DeleteExportById(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteExportById, id)
+	return err
+}
+
 const getAllExportsByUserId = `-- name: GetAllExportsByUserId :many
-SELECT id, created_at, completed_at, export_status, status_message, user_id, download_url, export_method, target_id FROM exports where user_id = $1 ORDER BY created_at DESC
+SELECT id, created_at, completed_at, export_status, status_message, user_id, download_url, export_method, target_id, storage_key, storage_endpoint, storage_region, storage_bucket FROM exports where user_id = $1 ORDER BY created_at DESC
 `
 
 func (q *Queries) GetAllExportsByUserId(ctx context.Context, userID uuid.UUID) ([]Export, error) {
@@ -156,6 +188,10 @@ func (q *Queries) GetAllExportsByUserId(ctx context.Context, userID uuid.UUID) (
 			&i.DownloadUrl,
 			&i.ExportMethod,
 			&i.TargetID,
+			&i.StorageKey,
+			&i.StorageEndpoint,
+			&i.StorageRegion,
+			&i.StorageBucket,
 		); err != nil {
 			return nil, err
 		}
@@ -171,7 +207,7 @@ func (q *Queries) GetAllExportsByUserId(ctx context.Context, userID uuid.UUID) (
 }
 
 const getExportById = `-- name: GetExportById :one
-SELECT id, created_at, completed_at, export_status, status_message, user_id, download_url, export_method, target_id FROM exports WHERE id = $1
+SELECT id, created_at, completed_at, export_status, status_message, user_id, download_url, export_method, target_id, storage_key, storage_endpoint, storage_region, storage_bucket FROM exports WHERE id = $1
 `
 
 func (q *Queries) GetExportById(ctx context.Context, id uuid.UUID) (Export, error) {
@@ -187,12 +223,16 @@ func (q *Queries) GetExportById(ctx context.Context, id uuid.UUID) (Export, erro
 		&i.DownloadUrl,
 		&i.ExportMethod,
 		&i.TargetID,
+		&i.StorageKey,
+		&i.StorageEndpoint,
+		&i.StorageRegion,
+		&i.StorageBucket,
 	)
 	return i, err
 }
 
 const getLast20ExportsByUserId = `-- name: GetLast20ExportsByUserId :many
-SELECT id, created_at, completed_at, export_status, status_message, user_id, download_url, export_method, target_id
+SELECT id, created_at, completed_at, export_status, status_message, user_id, download_url, export_method, target_id, storage_key, storage_endpoint, storage_region, storage_bucket
 FROM exports
 where
     user_id = $1
@@ -219,6 +259,10 @@ func (q *Queries) GetLast20ExportsByUserId(ctx context.Context, userID uuid.UUID
 			&i.DownloadUrl,
 			&i.ExportMethod,
 			&i.TargetID,
+			&i.StorageKey,
+			&i.StorageEndpoint,
+			&i.StorageRegion,
+			&i.StorageBucket,
 		); err != nil {
 			return nil, err
 		}
@@ -232,3 +276,115 @@ func (q *Queries) GetLast20ExportsByUserId(ctx context.Context, userID uuid.UUID
 	}
 	return items, nil
 }
+
+const getLastCompletedExportForTarget = `-- name: GetLastCompletedExportForTarget :one
+SELECT id, created_at, completed_at, export_status, status_message, user_id, download_url, export_method, target_id, storage_key, storage_endpoint, storage_region, storage_bucket
+FROM exports
+WHERE
+    target_id = $1
+    AND export_status = 'Completed'
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLastCompletedExportForTarget(ctx context.Context, targetID uuid.NullUUID) (Export, error) {
+	row := q.db.QueryRowContext(ctx, getLastCompletedExportForTarget, targetID)
+	var i Export
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ExportStatus,
+		&i.StatusMessage,
+		&i.UserID,
+		&i.DownloadUrl,
+		&i.ExportMethod,
+		&i.TargetID,
+		&i.StorageKey,
+		&i.StorageEndpoint,
+		&i.StorageRegion,
+		&i.StorageBucket,
+	)
+	return i, err
+}
+
+const getTargetExportsCreatedBefore = `-- name: GetTargetExportsCreatedBefore :many
+SELECT id, created_at, completed_at, export_status, status_message, user_id, download_url, export_method, target_id, storage_key, storage_endpoint, storage_region, storage_bucket
+FROM exports
+WHERE
+    target_id = $1
+    AND created_at < $2
+ORDER BY created_at
+`
+
+type GetTargetExportsCreatedBeforeParams struct {
+	TargetID  uuid.NullUUID
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetTargetExportsCreatedBefore(ctx context.Context, arg GetTargetExportsCreatedBeforeParams) ([]Export, error) {
+	rows, err := q.db.QueryContext(ctx, getTargetExportsCreatedBefore, arg.TargetID, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Export
+	for rows.Next() {
+		var i Export
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.CompletedAt,
+			&i.ExportStatus,
+			&i.StatusMessage,
+			&i.UserID,
+			&i.DownloadUrl,
+			&i.ExportMethod,
+			&i.TargetID,
+			&i.StorageKey,
+			&i.StorageEndpoint,
+			&i.StorageRegion,
+			&i.StorageBucket,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setExportStorage = `-- name: SetExportStorage :exec
+UPDATE exports
+SET
+    storage_key = $2,
+    storage_endpoint = $3,
+    storage_region = $4,
+    storage_bucket = $5
+WHERE
+    id = $1
+`
+
+type SetExportStorageParams struct {
+	ID              uuid.UUID
+	StorageKey      sql.NullString
+	StorageEndpoint sql.NullString
+	StorageRegion   sql.NullString
+	StorageBucket   sql.NullString
+}
+
+func (q *Queries) SetExportStorage(ctx context.Context, arg SetExportStorageParams) error {
+	_, err := q.db.ExecContext(ctx, setExportStorage,
+		arg.ID,
+		arg.StorageKey,
+		arg.StorageEndpoint,
+		arg.StorageRegion,
+		arg.StorageBucket,
+	)
+	return err
+}
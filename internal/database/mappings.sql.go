@@ -99,6 +99,21 @@ func (q *Queries) CreateMappingForTable(ctx context.Context, arg CreateMappingFo
 	return i, err
 }
 
+const deleteTableMappingByTargetAndName = `-- name: DeleteTableMappingByTargetAndName :exec
+DELETE FROM table_mappings
+WHERE target_id = $1 AND target_table_name = $2
+`
+
+type DeleteTableMappingByTargetAndNameParams struct {
+	TargetID        uuid.UUID
+	TargetTableName string
+}
+
+func (q *Queries) DeleteTableMappingByTargetAndName(ctx context.Context, arg DeleteTableMappingByTargetAndNameParams) error {
+	_, err := q.db.ExecContext(ctx, deleteTableMappingByTargetAndName, arg.TargetID, arg.TargetTableName)
+	return err
+}
+
 const getColumnMappingsByTable = `-- name: GetColumnMappingsByTable :many
 SELECT id, created_at, table_mapping_id, source_column_name, target_column_name, target_column_code FROM column_mappings
 where table_mapping_id = $1
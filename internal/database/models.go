@@ -44,11 +44,38 @@ type AnalyticsSiteStatsOnTarget struct {
 	TargetRecordID string
 }
 
+type AnalyticsTrafficSource struct {
+	ID                  uuid.UUID
+	Date                time.Time
+	SessionSource       string
+	SessionMedium       string
+	SessionCampaignName string
+	Sessions            int
+	SourceID            uuid.UUID
+}
+
 type AppConfig struct {
 	Key   string
 	Value string
 }
 
+type AudienceDemographic struct {
+	ID        uuid.UUID
+	Date      time.Time
+	SourceID  uuid.UUID
+	Dimension string
+	Bucket    string
+	Value     float64
+}
+
+type AudienceDemographicsOnTarget struct {
+	ID             uuid.UUID
+	SyncedAt       time.Time
+	DemographicID  uuid.UUID
+	TargetID       uuid.UUID
+	TargetRecordID string
+}
+
 type ColumnMapping struct {
 	ID               uuid.UUID
 	CreatedAt        time.Time
@@ -66,15 +93,19 @@ type Exclusion struct {
 }
 
 type Export struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	CompletedAt   time.Time
-	ExportStatus  string
-	StatusMessage sql.NullString
-	UserID        uuid.UUID
-	DownloadUrl   sql.NullString
-	ExportMethod  string
-	TargetID      uuid.NullUUID
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	CompletedAt     time.Time
+	ExportStatus    string
+	StatusMessage   sql.NullString
+	UserID          uuid.UUID
+	DownloadUrl     sql.NullString
+	ExportMethod    string
+	TargetID        uuid.NullUUID
+	StorageKey      sql.NullString
+	StorageEndpoint sql.NullString
+	StorageRegion   sql.NullString
+	StorageBucket   sql.NullString
 }
 
 type Log struct {
@@ -96,6 +127,24 @@ type Post struct {
 	PostType          string
 	Author            string
 	Content           sql.NullString
+	IsImported        bool
+}
+
+type PostsAnalyticsHistory struct {
+	ID                  uuid.UUID
+	Date                time.Time
+	PostID              uuid.UUID
+	Views               sql.NullInt64
+	WatchTimeMinutes    sql.NullFloat64
+	AverageViewDuration sql.NullFloat64
+	Impressions         sql.NullInt64
+	ImpressionsCtr      sql.NullFloat64
+	SubscribersGained   sql.NullInt64
+	Reach               sql.NullInt64
+	Saves               sql.NullInt64
+	Shares              sql.NullInt64
+	ProfileVisits       sql.NullInt64
+	Comments            sql.NullInt64
 }
 
 type PostsOnTarget struct {
@@ -106,13 +155,35 @@ type PostsOnTarget struct {
 	TargetPostID  string
 }
 
-type PostsReactionsHistory struct {
+type PostsReactionsBreakdown struct {
 	ID       uuid.UUID
 	SyncedAt time.Time
 	PostID   uuid.UUID
-	Likes    sql.NullInt64
-	Reposts  sql.NullInt64
-	Views    sql.NullInt64
+	Reaction string
+	Count    int
+}
+
+type PostsReactionsHistory struct {
+	ID          uuid.UUID
+	SyncedAt    time.Time
+	PostID      uuid.UUID
+	Likes       sql.NullInt64
+	Reposts     sql.NullInt64
+	Views       sql.NullInt64
+	UpvoteRatio sql.NullFloat64
+	Quotes      sql.NullInt64
+	Bookmarks   sql.NullInt64
+	RecordedAt  time.Time
+}
+
+type PostsStreamStat struct {
+	ID              uuid.UUID
+	PostID          uuid.UUID
+	StreamID        sql.NullString
+	DurationSeconds sql.NullInt64
+	PeakViewers     sql.NullInt64
+	AverageViewers  sql.NullFloat64
+	UpdatedAt       time.Time
 }
 
 type Redirect struct {
@@ -134,6 +205,28 @@ type Source struct {
 	SyncStatus   string
 	StatusReason sql.NullString
 	LastSynced   sql.NullTime
+	AccountID    sql.NullString
+}
+
+type SourceUsernameHistory struct {
+	ID          uuid.UUID
+	SourceID    uuid.UUID
+	OldUserName string
+	NewUserName string
+	ChangedAt   time.Time
+}
+
+type SourcesAnalyticsHistory struct {
+	ID                  uuid.UUID
+	Date                time.Time
+	SourceID            uuid.UUID
+	Views               sql.NullInt64
+	WatchTimeMinutes    sql.NullFloat64
+	AverageViewDuration sql.NullFloat64
+	Impressions         sql.NullInt64
+	ImpressionsCtr      sql.NullFloat64
+	SubscribersGained   sql.NullInt64
+	SubscribersLost     sql.NullInt64
 }
 
 type SourcesOnTarget struct {
@@ -144,15 +237,20 @@ type SourcesOnTarget struct {
 }
 
 type SourcesStat struct {
-	ID             uuid.UUID
-	Date           time.Time
-	SourceID       uuid.UUID
-	FollowersCount sql.NullInt64
-	FollowingCount sql.NullInt64
-	PostsCount     sql.NullInt64
-	AverageLikes   sql.NullFloat64
-	AverageReposts sql.NullFloat64
-	AverageViews   sql.NullFloat64
+	ID                      uuid.UUID
+	Date                    time.Time
+	SourceID                uuid.UUID
+	FollowersCount          sql.NullInt64
+	FollowingCount          sql.NullInt64
+	PostsCount              sql.NullInt64
+	AverageLikes            sql.NullFloat64
+	AverageReposts          sql.NullFloat64
+	AverageViews            sql.NullFloat64
+	NotificationsEnabledPct sql.NullFloat64
+	OnlineCount             sql.NullInt64
+	SupportersCount         sql.NullInt64
+	Karma                   sql.NullInt64
+	CommunitySubscribers    sql.NullInt64
 }
 
 type SourcesStatsOnTarget struct {
@@ -163,6 +261,29 @@ type SourcesStatsOnTarget struct {
 	TargetRecordID string
 }
 
+type StreamViewerSample struct {
+	ID        uuid.UUID
+	SourceID  uuid.UUID
+	StreamID  string
+	SampledAt time.Time
+	Viewers   int
+}
+
+type SupporterEvent struct {
+	ID             uuid.UUID
+	SourceID       uuid.UUID
+	ExternalID     string
+	ReceivedAt     time.Time
+	EventType      string
+	SupporterKey   string
+	SupporterName  sql.NullString
+	Amount         sql.NullFloat64
+	Currency       sql.NullString
+	TierName       sql.NullString
+	IsSubscription bool
+	Message        sql.NullString
+}
+
 type TableMapping struct {
 	ID              uuid.UUID
 	CreatedAt       time.Time
@@ -185,6 +306,16 @@ type Target struct {
 	StatusReason  sql.NullString
 	LastSynced    sql.NullTime
 	HostUrl       sql.NullString
+	Settings      json.RawMessage
+}
+
+type TelegramSession struct {
+	ID               uuid.UUID
+	SourceID         uuid.UUID
+	EncryptedSession []byte
+	Nonce            []byte
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
 }
 
 type Token struct {
@@ -224,3 +355,17 @@ type WebauthnCredential struct {
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	TargetID       uuid.UUID
+	Event          string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastAttemptAt  sql.NullTime
+	ResponseStatus sql.NullInt32
+	LastError      sql.NullString
+}
@@ -20,6 +20,7 @@ SET
 WHERE
     source_id = $1
     AND last_synced_at < $2
+    AND NOT is_imported
 `
 
 type ArchiveUnsyncedPostsParams struct {
@@ -47,6 +48,49 @@ func (q *Queries) CheckCountOfPostsForUser(ctx context.Context, userID uuid.UUID
 	return count, err
 }
 
+const claimImportedPost = `-- name: ClaimImportedPost :one
+UPDATE posts
+SET
+    network_internal_id = $1
+WHERE
+    id = (
+        SELECT p.id
+        FROM posts p
+        WHERE
+            p.source_id = $2
+            AND p.created_at = $3
+            AND p.is_imported
+            AND p.network_internal_id LIKE 'archive/%'
+        LIMIT 1
+    )
+RETURNING
+    id, created_at, last_synced_at, source_id, is_archived, network_internal_id, post_type, author, content, is_imported
+`
+
+type ClaimImportedPostParams struct {
+	NetworkInternalID string
+	SourceID          uuid.UUID
+	CreatedAt         time.Time
+}
+
+func (q *Queries) ClaimImportedPost(ctx context.Context, arg ClaimImportedPostParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, claimImportedPost, arg.NetworkInternalID, arg.SourceID, arg.CreatedAt)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.LastSyncedAt,
+		&i.SourceID,
+		&i.IsArchived,
+		&i.NetworkInternalID,
+		&i.PostType,
+		&i.Author,
+		&i.Content,
+		&i.IsImported,
+	)
+	return i, err
+}
+
 const createPost = `-- name: CreatePost :one
 INSERT INTO
     posts (
@@ -72,7 +116,7 @@ VALUES (
         $9
     )
 RETURNING
-    id, created_at, last_synced_at, source_id, is_archived, network_internal_id, post_type, author, content
+    id, created_at, last_synced_at, source_id, is_archived, network_internal_id, post_type, author, content, is_imported
 `
 
 type CreatePostParams struct {
@@ -110,6 +154,7 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.PostType,
 		&i.Author,
 		&i.Content,
+		&i.IsImported,
 	)
 	return i, err
 }
@@ -135,6 +180,7 @@ const getAllPostsWithTheLatestInfoForUser = `-- name: GetAllPostsWithTheLatestIn
 SELECT
     p.id,
     p.created_at,
+    p.last_synced_at,
     p.source_id,
     p.is_archived,
     p.network_internal_id,
@@ -145,6 +191,7 @@ SELECT
     u.username AS current_user_name,
     s.user_name AS source_user_name,
     r.synced_at AS reactions_synced_at,
+    r.recorded_at AS reactions_recorded_at,
     r.likes,
     r.reposts,
     r.views
@@ -164,21 +211,23 @@ WHERE
 `
 
 type GetAllPostsWithTheLatestInfoForUserRow struct {
-	ID                uuid.UUID
-	CreatedAt         time.Time
-	SourceID          uuid.UUID
-	IsArchived        bool
-	NetworkInternalID string
-	Content           sql.NullString
-	PostType          string
-	Author            string
-	Network           sql.NullString
-	CurrentUserName   sql.NullString
-	SourceUserName    sql.NullString
-	ReactionsSyncedAt sql.NullTime
-	Likes             sql.NullInt64
-	Reposts           sql.NullInt64
-	Views             sql.NullInt64
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	LastSyncedAt        time.Time
+	SourceID            uuid.UUID
+	IsArchived          bool
+	NetworkInternalID   string
+	Content             sql.NullString
+	PostType            string
+	Author              string
+	Network             sql.NullString
+	CurrentUserName     sql.NullString
+	SourceUserName      sql.NullString
+	ReactionsSyncedAt   sql.NullTime
+	ReactionsRecordedAt sql.NullTime
+	Likes               sql.NullInt64
+	Reposts             sql.NullInt64
+	Views               sql.NullInt64
 }
 
 func (q *Queries) GetAllPostsWithTheLatestInfoForUser(ctx context.Context, userID uuid.UUID) ([]GetAllPostsWithTheLatestInfoForUserRow, error) {
@@ -193,6 +242,7 @@ func (q *Queries) GetAllPostsWithTheLatestInfoForUser(ctx context.Context, userI
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
+			&i.LastSyncedAt,
 			&i.SourceID,
 			&i.IsArchived,
 			&i.NetworkInternalID,
@@ -203,6 +253,7 @@ func (q *Queries) GetAllPostsWithTheLatestInfoForUser(ctx context.Context, userI
 			&i.CurrentUserName,
 			&i.SourceUserName,
 			&i.ReactionsSyncedAt,
+			&i.ReactionsRecordedAt,
 			&i.Likes,
 			&i.Reposts,
 			&i.Views,
@@ -221,7 +272,7 @@ func (q *Queries) GetAllPostsWithTheLatestInfoForUser(ctx context.Context, userI
 }
 
 const getPostByNetworkAndId = `-- name: GetPostByNetworkAndId :one
-SELECT posts.id, posts.created_at, posts.last_synced_at, posts.source_id, posts.is_archived, posts.network_internal_id, posts.post_type, posts.author, posts.content
+SELECT posts.id, posts.created_at, posts.last_synced_at, posts.source_id, posts.is_archived, posts.network_internal_id, posts.post_type, posts.author, posts.content, posts.is_imported
 FROM posts
     join sources on posts.source_id = sources.id
 where
@@ -247,6 +298,39 @@ func (q *Queries) GetPostByNetworkAndId(ctx context.Context, arg GetPostByNetwor
 		&i.PostType,
 		&i.Author,
 		&i.Content,
+		&i.IsImported,
+	)
+	return i, err
+}
+
+const getPostBySourceAndCreatedAt = `-- name: GetPostBySourceAndCreatedAt :one
+SELECT id, created_at, last_synced_at, source_id, is_archived, network_internal_id, post_type, author, content, is_imported
+FROM posts
+WHERE
+    source_id = $1
+    AND created_at = $2
+LIMIT 1
+`
+
+type GetPostBySourceAndCreatedAtParams struct {
+	SourceID  uuid.UUID
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetPostBySourceAndCreatedAt(ctx context.Context, arg GetPostBySourceAndCreatedAtParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostBySourceAndCreatedAt, arg.SourceID, arg.CreatedAt)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.LastSyncedAt,
+		&i.SourceID,
+		&i.IsArchived,
+		&i.NetworkInternalID,
+		&i.PostType,
+		&i.Author,
+		&i.Content,
+		&i.IsImported,
 	)
 	return i, err
 }
@@ -266,7 +350,10 @@ SELECT
     (
         COALESCE(r.likes, 0) + COALESCE(r.reposts, 0)
     )::bigint AS interactions,
-    r.views
+    r.views,
+    r.upvote_ratio,
+    r.quotes,
+    r.bookmarks
 FROM
     posts p
     left join sources s ON p.source_id = s.id
@@ -296,6 +383,9 @@ type GetRecentPostsForUserRow struct {
 	Reposts           sql.NullInt64
 	Interactions      int
 	Views             sql.NullInt64
+	UpvoteRatio       sql.NullFloat64
+	Quotes            sql.NullInt64
+	Bookmarks         sql.NullInt64
 }
 
 func (q *Queries) GetRecentPostsForUser(ctx context.Context, userID uuid.UUID) ([]GetRecentPostsForUserRow, error) {
@@ -320,6 +410,9 @@ func (q *Queries) GetRecentPostsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.Reposts,
 			&i.Interactions,
 			&i.Views,
+			&i.UpvoteRatio,
+			&i.Quotes,
+			&i.Bookmarks,
 		); err != nil {
 			return nil, err
 		}
@@ -334,6 +427,15 @@ func (q *Queries) GetRecentPostsForUser(ctx context.Context, userID uuid.UUID) (
 	return items, nil
 }
 
+const markPostImported = `-- name: MarkPostImported :exec
+UPDATE posts SET is_imported = true WHERE id = $1
+`
+
+func (q *Queries) MarkPostImported(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, markPostImported, id)
+	return err
+}
+
 const updatePost = `-- name: UpdatePost :one
 UPDATE posts
 SET
@@ -341,11 +443,12 @@ SET
     is_archived = $3,
     content = $4,
     post_type = $5,
-    author = $6
+    author = $6,
+    is_imported = false
 WHERE
     id = $1
 RETURNING
-    id, created_at, last_synced_at, source_id, is_archived, network_internal_id, post_type, author, content
+    id, created_at, last_synced_at, source_id, is_archived, network_internal_id, post_type, author, content, is_imported
 `
 
 type UpdatePostParams struct {
@@ -377,6 +480,22 @@ func (q *Queries) UpdatePost(ctx context.Context, arg UpdatePostParams) (Post, e
 		&i.PostType,
 		&i.Author,
 		&i.Content,
+		&i.IsImported,
 	)
 	return i, err
 }
+
+const updatePostsAuthorForSource = `-- name: UpdatePostsAuthorForSource :exec
+UPDATE posts SET author = $3 WHERE source_id = $1 AND author = $2
+`
+
+type UpdatePostsAuthorForSourceParams struct {
+	SourceID uuid.UUID
+	Author   string
+	Author_2 string
+}
+
+func (q *Queries) UpdatePostsAuthorForSource(ctx context.Context, arg UpdatePostsAuthorForSourceParams) error {
+	_, err := q.db.ExecContext(ctx, updatePostsAuthorForSource, arg.SourceID, arg.Author, arg.Author_2)
+	return err
+}
@@ -14,9 +14,13 @@ import (
 )
 
 const deleteOldStats = `-- name: DeleteOldStats :exec
-DELETE from posts_reactions_history
+DELETE from posts_reactions_history prh
+USING posts p
 where
-    synced_at < now() - INTERVAL '365 days'
+    prh.post_id = p.id
+    AND prh.synced_at < now() - INTERVAL '365 days'
+    -- Posts only known from an archive have no newer history to replace it
+    AND NOT p.is_imported
 `
 
 func (q *Queries) DeleteOldStats(ctx context.Context) error {
@@ -94,6 +98,155 @@ func (q *Queries) GetDailyEngagementStats(ctx context.Context, userID uuid.UUID)
 	return items, nil
 }
 
+const getReactionsHistoryForUserRecordedAfter = `-- name: GetReactionsHistoryForUserRecordedAfter :many
+SELECT
+    prh.post_id,
+    p.source_id,
+    prh.synced_at,
+    prh.recorded_at,
+    prh.likes,
+    prh.reposts,
+    prh.views,
+    pah.comments,
+    prh.quotes,
+    prh.bookmarks
+FROM
+    posts_reactions_history prh
+    JOIN posts p ON prh.post_id = p.id
+    JOIN sources s ON p.source_id = s.id
+    LEFT JOIN posts_analytics_history pah ON pah.post_id = prh.post_id
+    AND pah.date = date_trunc('day', prh.synced_at)
+WHERE
+    s.user_id = $1
+    AND prh.recorded_at > $2
+ORDER BY prh.recorded_at ASC
+`
+
+type GetReactionsHistoryForUserRecordedAfterParams struct {
+	UserID     uuid.UUID
+	RecordedAt time.Time
+}
+
+type GetReactionsHistoryForUserRecordedAfterRow struct {
+	PostID     uuid.UUID
+	SourceID   uuid.UUID
+	SyncedAt   time.Time
+	RecordedAt time.Time
+	Likes      sql.NullInt64
+	Reposts    sql.NullInt64
+	Views      sql.NullInt64
+	Comments   sql.NullInt64
+	Quotes     sql.NullInt64
+	Bookmarks  sql.NullInt64
+}
+
+func (q *Queries) GetReactionsHistoryForUserRecordedAfter(ctx context.Context, arg GetReactionsHistoryForUserRecordedAfterParams) ([]GetReactionsHistoryForUserRecordedAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReactionsHistoryForUserRecordedAfter, arg.UserID, arg.RecordedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReactionsHistoryForUserRecordedAfterRow
+	for rows.Next() {
+		var i GetReactionsHistoryForUserRecordedAfterRow
+		if err := rows.Scan(
+			&i.PostID,
+			&i.SourceID,
+			&i.SyncedAt,
+			&i.RecordedAt,
+			&i.Likes,
+			&i.Reposts,
+			&i.Views,
+			&i.Comments,
+			&i.Quotes,
+			&i.Bookmarks,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReactionsHistoryForUserSince = `-- name: GetReactionsHistoryForUserSince :many
+SELECT
+    prh.post_id,
+    p.source_id,
+    prh.synced_at,
+    prh.likes,
+    prh.reposts,
+    prh.views,
+    pah.comments,
+    prh.quotes,
+    prh.bookmarks
+FROM
+    posts_reactions_history prh
+    JOIN posts p ON prh.post_id = p.id
+    JOIN sources s ON p.source_id = s.id
+    LEFT JOIN posts_analytics_history pah ON pah.post_id = prh.post_id
+    AND pah.date = date_trunc('day', prh.synced_at)
+WHERE
+    s.user_id = $1
+    AND prh.synced_at >= $2
+ORDER BY prh.synced_at ASC
+`
+
+type GetReactionsHistoryForUserSinceParams struct {
+	UserID   uuid.UUID
+	SyncedAt time.Time
+}
+
+type GetReactionsHistoryForUserSinceRow struct {
+	PostID    uuid.UUID
+	SourceID  uuid.UUID
+	SyncedAt  time.Time
+	Likes     sql.NullInt64
+	Reposts   sql.NullInt64
+	Views     sql.NullInt64
+	Comments  sql.NullInt64
+	Quotes    sql.NullInt64
+	Bookmarks sql.NullInt64
+}
+
+func (q *Queries) GetReactionsHistoryForUserSince(ctx context.Context, arg GetReactionsHistoryForUserSinceParams) ([]GetReactionsHistoryForUserSinceRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReactionsHistoryForUserSince, arg.UserID, arg.SyncedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReactionsHistoryForUserSinceRow
+	for rows.Next() {
+		var i GetReactionsHistoryForUserSinceRow
+		if err := rows.Scan(
+			&i.PostID,
+			&i.SourceID,
+			&i.SyncedAt,
+			&i.Likes,
+			&i.Reposts,
+			&i.Views,
+			&i.Comments,
+			&i.Quotes,
+			&i.Bookmarks,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const syncReactions = `-- name: SyncReactions :one
 INSERT INTO
     posts_reactions_history (
@@ -114,9 +267,10 @@ SET
     likes = EXCLUDED.likes,
     reposts = EXCLUDED.reposts,
     views = EXCLUDED.views,
-    synced_at = EXCLUDED.synced_at
+    synced_at = EXCLUDED.synced_at,
+    recorded_at = NOW()
 RETURNING
-    id, synced_at, post_id, likes, reposts, views
+    id, synced_at, post_id, likes, reposts, views, upvote_ratio, quotes, bookmarks, recorded_at
 `
 
 type SyncReactionsParams struct {
@@ -145,6 +299,50 @@ func (q *Queries) SyncReactions(ctx context.Context, arg SyncReactionsParams) (P
 		&i.Likes,
 		&i.Reposts,
 		&i.Views,
+		&i.UpvoteRatio,
+		&i.Quotes,
+		&i.Bookmarks,
+		&i.RecordedAt,
 	)
 	return i, err
 }
+
+const updateReactionCommunityStats = `-- name: UpdateReactionCommunityStats :exec
+UPDATE posts_reactions_history
+SET
+    upvote_ratio = $2,
+    recorded_at = NOW()
+WHERE
+    id = $1
+`
+
+type UpdateReactionCommunityStatsParams struct {
+	ID          uuid.UUID
+	UpvoteRatio sql.NullFloat64
+}
+
+func (q *Queries) UpdateReactionCommunityStats(ctx context.Context, arg UpdateReactionCommunityStatsParams) error {
+	_, err := q.db.ExecContext(ctx, updateReactionCommunityStats, arg.ID, arg.UpvoteRatio)
+	return err
+}
+
+const updateReactionEngagementStats = `-- name: UpdateReactionEngagementStats :exec
+UPDATE posts_reactions_history
+SET
+    quotes = $2,
+    bookmarks = $3,
+    recorded_at = NOW()
+WHERE
+    id = $1
+`
+
+type UpdateReactionEngagementStatsParams struct {
+	ID        uuid.UUID
+	Quotes    sql.NullInt64
+	Bookmarks sql.NullInt64
+}
+
+func (q *Queries) UpdateReactionEngagementStats(ctx context.Context, arg UpdateReactionEngagementStatsParams) error {
+	_, err := q.db.ExecContext(ctx, updateReactionEngagementStats, arg.ID, arg.Quotes, arg.Bookmarks)
+	return err
+}
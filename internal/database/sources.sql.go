@@ -17,7 +17,7 @@ const changeSourceStatusById = `-- name: ChangeSourceStatusById :one
 UPDATE sources
 SET is_active = $2, sync_status = $3, status_reason = $4, updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced
+RETURNING id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced, account_id
 `
 
 type ChangeSourceStatusByIdParams struct {
@@ -46,6 +46,7 @@ func (q *Queries) ChangeSourceStatusById(ctx context.Context, arg ChangeSourceSt
 		&i.SyncStatus,
 		&i.StatusReason,
 		&i.LastSynced,
+		&i.AccountID,
 	)
 	return i, err
 }
@@ -64,7 +65,7 @@ VALUES (
     $9,
     $10
 )
-RETURNING id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced
+RETURNING id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced, account_id
 `
 
 type CreateSourceParams struct {
@@ -105,6 +106,7 @@ func (q *Queries) CreateSource(ctx context.Context, arg CreateSourceParams) (Sou
 		&i.SyncStatus,
 		&i.StatusReason,
 		&i.LastSynced,
+		&i.AccountID,
 	)
 	return i, err
 }
@@ -119,8 +121,48 @@ func (q *Queries) DeleteSource(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const getActiveSourcesByNetwork = `-- name: GetActiveSourcesByNetwork :many
+SELECT id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced, account_id FROM sources
+where network = $1 and is_active = TRUE
+`
+
+func (q *Queries) GetActiveSourcesByNetwork(ctx context.Context, network string) ([]Source, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveSourcesByNetwork, network)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Source
+	for rows.Next() {
+		var i Source
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Network,
+			&i.UserName,
+			&i.UserID,
+			&i.IsActive,
+			&i.SyncStatus,
+			&i.StatusReason,
+			&i.LastSynced,
+			&i.AccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSourceById = `-- name: GetSourceById :one
-SELECT id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced FROM sources
+SELECT id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced, account_id FROM sources
 where id = $1
 `
 
@@ -138,12 +180,13 @@ func (q *Queries) GetSourceById(ctx context.Context, id uuid.UUID) (Source, erro
 		&i.SyncStatus,
 		&i.StatusReason,
 		&i.LastSynced,
+		&i.AccountID,
 	)
 	return i, err
 }
 
 const getUserActiveSourceByName = `-- name: GetUserActiveSourceByName :one
-SELECT id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced FROM sources
+SELECT id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced, account_id FROM sources
 where user_id = $1 and network = $2 and is_active = TRUE
 LIMIT 1
 `
@@ -167,12 +210,13 @@ func (q *Queries) GetUserActiveSourceByName(ctx context.Context, arg GetUserActi
 		&i.SyncStatus,
 		&i.StatusReason,
 		&i.LastSynced,
+		&i.AccountID,
 	)
 	return i, err
 }
 
 const getUserActiveSources = `-- name: GetUserActiveSources :many
-SELECT id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced FROM sources
+SELECT id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced, account_id FROM sources
 where user_id = $1 and is_active = TRUE
 `
 
@@ -196,6 +240,7 @@ func (q *Queries) GetUserActiveSources(ctx context.Context, userID uuid.UUID) ([
 			&i.SyncStatus,
 			&i.StatusReason,
 			&i.LastSynced,
+			&i.AccountID,
 		); err != nil {
 			return nil, err
 		}
@@ -211,7 +256,7 @@ func (q *Queries) GetUserActiveSources(ctx context.Context, userID uuid.UUID) ([
 }
 
 const getUserSources = `-- name: GetUserSources :many
-SELECT id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced FROM sources
+SELECT id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced, account_id FROM sources
 where user_id = $1
 ORDER BY
   CASE sync_status
@@ -243,6 +288,7 @@ func (q *Queries) GetUserSources(ctx context.Context, userID uuid.UUID) ([]Sourc
 			&i.SyncStatus,
 			&i.StatusReason,
 			&i.LastSynced,
+			&i.AccountID,
 		); err != nil {
 			return nil, err
 		}
@@ -257,11 +303,43 @@ func (q *Queries) GetUserSources(ctx context.Context, userID uuid.UUID) ([]Sourc
 	return items, nil
 }
 
+const renameSource = `-- name: RenameSource :exec
+UPDATE sources
+SET user_name = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type RenameSourceParams struct {
+	ID       uuid.UUID
+	UserName string
+}
+
+func (q *Queries) RenameSource(ctx context.Context, arg RenameSourceParams) error {
+	_, err := q.db.ExecContext(ctx, renameSource, arg.ID, arg.UserName)
+	return err
+}
+
+const updateSourceAccountID = `-- name: UpdateSourceAccountID :exec
+UPDATE sources
+SET account_id = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateSourceAccountIDParams struct {
+	ID        uuid.UUID
+	AccountID sql.NullString
+}
+
+func (q *Queries) UpdateSourceAccountID(ctx context.Context, arg UpdateSourceAccountIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateSourceAccountID, arg.ID, arg.AccountID)
+	return err
+}
+
 const updateSourceSyncStatusById = `-- name: UpdateSourceSyncStatusById :one
 UPDATE sources
 SET sync_status = $2, status_reason = $3, last_synced = $4
 WHERE id = $1
-RETURNING id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced
+RETURNING id, created_at, updated_at, network, user_name, user_id, is_active, sync_status, status_reason, last_synced, account_id
 `
 
 type UpdateSourceSyncStatusByIdParams struct {
@@ -290,6 +368,7 @@ func (q *Queries) UpdateSourceSyncStatusById(ctx context.Context, arg UpdateSour
 		&i.SyncStatus,
 		&i.StatusReason,
 		&i.LastSynced,
+		&i.AccountID,
 	)
 	return i, err
 }
@@ -13,6 +13,41 @@ import (
 	"github.com/google/uuid"
 )
 
+const backfillSourceFollowers = `-- name: BackfillSourceFollowers :exec
+INSERT INTO
+    sources_stats (
+        id,
+        date,
+        source_id,
+        followers_count
+    )
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (source_id, date) DO
+UPDATE
+SET
+    followers_count = COALESCE(
+        sources_stats.followers_count,
+        EXCLUDED.followers_count
+    )
+`
+
+type BackfillSourceFollowersParams struct {
+	ID             uuid.UUID
+	Date           time.Time
+	SourceID       uuid.UUID
+	FollowersCount sql.NullInt64
+}
+
+func (q *Queries) BackfillSourceFollowers(ctx context.Context, arg BackfillSourceFollowersParams) error {
+	_, err := q.db.ExecContext(ctx, backfillSourceFollowers,
+		arg.ID,
+		arg.Date,
+		arg.SourceID,
+		arg.FollowersCount,
+	)
+	return err
+}
+
 const createSourceStat = `-- name: CreateSourceStat :one
 INSERT INTO
     sources_stats (
@@ -38,7 +73,7 @@ VALUES (
         $9
     )
 RETURNING
-    id, date, source_id, followers_count, following_count, posts_count, average_likes, average_reposts, average_views
+    id, date, source_id, followers_count, following_count, posts_count, average_likes, average_reposts, average_views, notifications_enabled_pct, online_count, supporters_count, karma, community_subscribers
 `
 
 type CreateSourceStatParams struct {
@@ -76,12 +111,34 @@ func (q *Queries) CreateSourceStat(ctx context.Context, arg CreateSourceStatPara
 		&i.AverageLikes,
 		&i.AverageReposts,
 		&i.AverageViews,
+		&i.NotificationsEnabledPct,
+		&i.OnlineCount,
+		&i.SupportersCount,
+		&i.Karma,
+		&i.CommunitySubscribers,
 	)
 	return i, err
 }
 
+const getLatestSourceFollowers = `-- name: GetLatestSourceFollowers :one
+SELECT followers_count
+FROM sources_stats
+WHERE
+    source_id = $1
+    AND followers_count IS NOT NULL
+ORDER BY date DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestSourceFollowers(ctx context.Context, sourceID uuid.UUID) (sql.NullInt64, error) {
+	row := q.db.QueryRowContext(ctx, getLatestSourceFollowers, sourceID)
+	var followers_count sql.NullInt64
+	err := row.Scan(&followers_count)
+	return followers_count, err
+}
+
 const getSourceStatsByDate = `-- name: GetSourceStatsByDate :one
-SELECT id, date, source_id, followers_count, following_count, posts_count, average_likes, average_reposts, average_views
+SELECT id, date, source_id, followers_count, following_count, posts_count, average_likes, average_reposts, average_views, notifications_enabled_pct, online_count, supporters_count, karma, community_subscribers
 FROM sources_stats
 WHERE
     source_id = $1
@@ -107,6 +164,11 @@ func (q *Queries) GetSourceStatsByDate(ctx context.Context, arg GetSourceStatsBy
 		&i.AverageLikes,
 		&i.AverageReposts,
 		&i.AverageViews,
+		&i.NotificationsEnabledPct,
+		&i.OnlineCount,
+		&i.SupportersCount,
+		&i.Karma,
+		&i.CommunitySubscribers,
 	)
 	return i, err
 }
@@ -147,6 +209,33 @@ func (q *Queries) GetSourceTotals(ctx context.Context, sourceID uuid.UUID) (GetS
 	return i, err
 }
 
+const updateSourceCommunityStats = `-- name: UpdateSourceCommunityStats :exec
+UPDATE sources_stats
+SET
+    karma = $1,
+    community_subscribers = $2
+WHERE
+    source_id = $3
+    AND date = $4
+`
+
+type UpdateSourceCommunityStatsParams struct {
+	Karma                sql.NullInt64
+	CommunitySubscribers sql.NullInt64
+	SourceID             uuid.UUID
+	Date                 time.Time
+}
+
+func (q *Queries) UpdateSourceCommunityStats(ctx context.Context, arg UpdateSourceCommunityStatsParams) error {
+	_, err := q.db.ExecContext(ctx, updateSourceCommunityStats,
+		arg.Karma,
+		arg.CommunitySubscribers,
+		arg.SourceID,
+		arg.Date,
+	)
+	return err
+}
+
 const updateSourceDayStats = `-- name: UpdateSourceDayStats :one
 UPDATE sources_stats
 SET
@@ -160,7 +249,7 @@ WHERE
     source_id = $7
     AND date = $8
 RETURNING
-    id, date, source_id, followers_count, following_count, posts_count, average_likes, average_reposts, average_views
+    id, date, source_id, followers_count, following_count, posts_count, average_likes, average_reposts, average_views, notifications_enabled_pct, online_count, supporters_count, karma, community_subscribers
 `
 
 type UpdateSourceDayStatsParams struct {
@@ -196,6 +285,71 @@ func (q *Queries) UpdateSourceDayStats(ctx context.Context, arg UpdateSourceDayS
 		&i.AverageLikes,
 		&i.AverageReposts,
 		&i.AverageViews,
+		&i.NotificationsEnabledPct,
+		&i.OnlineCount,
+		&i.SupportersCount,
+		&i.Karma,
+		&i.CommunitySubscribers,
 	)
 	return i, err
 }
+
+const updateSourceNotificationsEnabled = `-- name: UpdateSourceNotificationsEnabled :exec
+UPDATE sources_stats
+SET
+    notifications_enabled_pct = $1
+WHERE
+    source_id = $2
+    AND date = $3
+`
+
+type UpdateSourceNotificationsEnabledParams struct {
+	NotificationsEnabledPct sql.NullFloat64
+	SourceID                uuid.UUID
+	Date                    time.Time
+}
+
+func (q *Queries) UpdateSourceNotificationsEnabled(ctx context.Context, arg UpdateSourceNotificationsEnabledParams) error {
+	_, err := q.db.ExecContext(ctx, updateSourceNotificationsEnabled, arg.NotificationsEnabledPct, arg.SourceID, arg.Date)
+	return err
+}
+
+const updateSourceOnlineCount = `-- name: UpdateSourceOnlineCount :exec
+UPDATE sources_stats
+SET
+    online_count = $1
+WHERE
+    source_id = $2
+    AND date = $3
+`
+
+type UpdateSourceOnlineCountParams struct {
+	OnlineCount sql.NullInt64
+	SourceID    uuid.UUID
+	Date        time.Time
+}
+
+func (q *Queries) UpdateSourceOnlineCount(ctx context.Context, arg UpdateSourceOnlineCountParams) error {
+	_, err := q.db.ExecContext(ctx, updateSourceOnlineCount, arg.OnlineCount, arg.SourceID, arg.Date)
+	return err
+}
+
+const updateSourceSupporters = `-- name: UpdateSourceSupporters :exec
+UPDATE sources_stats
+SET
+    supporters_count = $1
+WHERE
+    source_id = $2
+    AND date = $3
+`
+
+type UpdateSourceSupportersParams struct {
+	SupportersCount sql.NullInt64
+	SourceID        uuid.UUID
+	Date            time.Time
+}
+
+func (q *Queries) UpdateSourceSupporters(ctx context.Context, arg UpdateSourceSupportersParams) error {
+	_, err := q.db.ExecContext(ctx, updateSourceSupporters, arg.SupportersCount, arg.SourceID, arg.Date)
+	return err
+}
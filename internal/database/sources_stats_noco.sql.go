@@ -60,7 +60,7 @@ func (q *Queries) AddSourcesStatToTarget(ctx context.Context, arg AddSourcesStat
 }
 
 const getAllSourcesStatsForUser = `-- name: GetAllSourcesStatsForUser :many
-SELECT ss.id, ss.date, ss.source_id, ss.followers_count, ss.following_count, ss.posts_count, ss.average_likes, ss.average_reposts, ss.average_views
+SELECT ss.id, ss.date, ss.source_id, ss.followers_count, ss.following_count, ss.posts_count, ss.average_likes, ss.average_reposts, ss.average_views, ss.notifications_enabled_pct, ss.online_count, ss.supporters_count, ss.karma, ss.community_subscribers
 FROM sources_stats ss
     LEFT JOIN sources s ON ss.source_id = s.id
 WHERE
@@ -87,6 +87,11 @@ func (q *Queries) GetAllSourcesStatsForUser(ctx context.Context, userID uuid.UUI
 			&i.AverageLikes,
 			&i.AverageReposts,
 			&i.AverageViews,
+			&i.NotificationsEnabledPct,
+			&i.OnlineCount,
+			&i.SupportersCount,
+			&i.Karma,
+			&i.CommunitySubscribers,
 		); err != nil {
 			return nil, err
 		}
@@ -102,7 +107,7 @@ func (q *Queries) GetAllSourcesStatsForUser(ctx context.Context, userID uuid.UUI
 }
 
 const getAllSourcesStatsWithTargetInfo = `-- name: GetAllSourcesStatsWithTargetInfo :many
-SELECT ss.id, ss.date, ss.source_id, ss.followers_count, ss.following_count, ss.posts_count, ss.average_likes, ss.average_reposts, ss.average_views, map.target_record_id
+SELECT ss.id, ss.date, ss.source_id, ss.followers_count, ss.following_count, ss.posts_count, ss.average_likes, ss.average_reposts, ss.average_views, ss.notifications_enabled_pct, ss.online_count, ss.supporters_count, ss.karma, ss.community_subscribers, map.target_record_id
 FROM
     sources_stats ss
     LEFT JOIN sources_stats_on_target map ON ss.id = map.stat_id
@@ -117,16 +122,21 @@ type GetAllSourcesStatsWithTargetInfoParams struct {
 }
 
 type GetAllSourcesStatsWithTargetInfoRow struct {
-	ID             uuid.UUID
-	Date           time.Time
-	SourceID       uuid.UUID
-	FollowersCount sql.NullInt64
-	FollowingCount sql.NullInt64
-	PostsCount     sql.NullInt64
-	AverageLikes   sql.NullFloat64
-	AverageReposts sql.NullFloat64
-	AverageViews   sql.NullFloat64
-	TargetRecordID sql.NullString
+	ID                      uuid.UUID
+	Date                    time.Time
+	SourceID                uuid.UUID
+	FollowersCount          sql.NullInt64
+	FollowingCount          sql.NullInt64
+	PostsCount              sql.NullInt64
+	AverageLikes            sql.NullFloat64
+	AverageReposts          sql.NullFloat64
+	AverageViews            sql.NullFloat64
+	NotificationsEnabledPct sql.NullFloat64
+	OnlineCount             sql.NullInt64
+	SupportersCount         sql.NullInt64
+	Karma                   sql.NullInt64
+	CommunitySubscribers    sql.NullInt64
+	TargetRecordID          sql.NullString
 }
 
 func (q *Queries) GetAllSourcesStatsWithTargetInfo(ctx context.Context, arg GetAllSourcesStatsWithTargetInfoParams) ([]GetAllSourcesStatsWithTargetInfoRow, error) {
@@ -148,6 +158,11 @@ func (q *Queries) GetAllSourcesStatsWithTargetInfo(ctx context.Context, arg GetA
 			&i.AverageLikes,
 			&i.AverageReposts,
 			&i.AverageViews,
+			&i.NotificationsEnabledPct,
+			&i.OnlineCount,
+			&i.SupportersCount,
+			&i.Karma,
+			&i.CommunitySubscribers,
 			&i.TargetRecordID,
 		); err != nil {
 			return nil, err
@@ -164,7 +179,7 @@ func (q *Queries) GetAllSourcesStatsWithTargetInfo(ctx context.Context, arg GetA
 }
 
 const getSyncedSourcesStatsForUpdate = `-- name: GetSyncedSourcesStatsForUpdate :many
-SELECT ss.id, ss.date, ss.source_id, ss.followers_count, ss.following_count, ss.posts_count, ss.average_likes, ss.average_reposts, ss.average_views, map.target_record_id
+SELECT ss.id, ss.date, ss.source_id, ss.followers_count, ss.following_count, ss.posts_count, ss.average_likes, ss.average_reposts, ss.average_views, ss.notifications_enabled_pct, ss.online_count, ss.supporters_count, ss.karma, ss.community_subscribers, map.target_record_id
 FROM
     sources_stats ss
     JOIN sources_stats_on_target map ON ss.id = map.stat_id
@@ -181,16 +196,21 @@ type GetSyncedSourcesStatsForUpdateParams struct {
 }
 
 type GetSyncedSourcesStatsForUpdateRow struct {
-	ID             uuid.UUID
-	Date           time.Time
-	SourceID       uuid.UUID
-	FollowersCount sql.NullInt64
-	FollowingCount sql.NullInt64
-	PostsCount     sql.NullInt64
-	AverageLikes   sql.NullFloat64
-	AverageReposts sql.NullFloat64
-	AverageViews   sql.NullFloat64
-	TargetRecordID string
+	ID                      uuid.UUID
+	Date                    time.Time
+	SourceID                uuid.UUID
+	FollowersCount          sql.NullInt64
+	FollowingCount          sql.NullInt64
+	PostsCount              sql.NullInt64
+	AverageLikes            sql.NullFloat64
+	AverageReposts          sql.NullFloat64
+	AverageViews            sql.NullFloat64
+	NotificationsEnabledPct sql.NullFloat64
+	OnlineCount             sql.NullInt64
+	SupportersCount         sql.NullInt64
+	Karma                   sql.NullInt64
+	CommunitySubscribers    sql.NullInt64
+	TargetRecordID          string
 }
 
 func (q *Queries) GetSyncedSourcesStatsForUpdate(ctx context.Context, arg GetSyncedSourcesStatsForUpdateParams) ([]GetSyncedSourcesStatsForUpdateRow, error) {
@@ -212,6 +232,11 @@ func (q *Queries) GetSyncedSourcesStatsForUpdate(ctx context.Context, arg GetSyn
 			&i.AverageLikes,
 			&i.AverageReposts,
 			&i.AverageViews,
+			&i.NotificationsEnabledPct,
+			&i.OnlineCount,
+			&i.SupportersCount,
+			&i.Karma,
+			&i.CommunitySubscribers,
 			&i.TargetRecordID,
 		); err != nil {
 			return nil, err
@@ -228,7 +253,7 @@ func (q *Queries) GetSyncedSourcesStatsForUpdate(ctx context.Context, arg GetSyn
 }
 
 const getUnsyncedSourcesStatsForTarget = `-- name: GetUnsyncedSourcesStatsForTarget :many
-SELECT ss.id, ss.date, ss.source_id, ss.followers_count, ss.following_count, ss.posts_count, ss.average_likes, ss.average_reposts, ss.average_views
+SELECT ss.id, ss.date, ss.source_id, ss.followers_count, ss.following_count, ss.posts_count, ss.average_likes, ss.average_reposts, ss.average_views, ss.notifications_enabled_pct, ss.online_count, ss.supporters_count, ss.karma, ss.community_subscribers
 FROM sources_stats ss
 WHERE
     ss.source_id = $1
@@ -265,6 +290,11 @@ func (q *Queries) GetUnsyncedSourcesStatsForTarget(ctx context.Context, arg GetU
 			&i.AverageLikes,
 			&i.AverageReposts,
 			&i.AverageViews,
+			&i.NotificationsEnabledPct,
+			&i.OnlineCount,
+			&i.SupportersCount,
+			&i.Karma,
+			&i.CommunitySubscribers,
 		); err != nil {
 			return nil, err
 		}
@@ -8,6 +8,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,7 +18,7 @@ const changeTargetStatusById = `-- name: ChangeTargetStatusById :one
 UPDATE targets
 SET is_active = $2, sync_status = $3, status_reason = $4, updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, status_reason, last_synced, host_url
+RETURNING id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, status_reason, last_synced, host_url, settings
 `
 
 type ChangeTargetStatusByIdParams struct {
@@ -48,12 +49,13 @@ func (q *Queries) ChangeTargetStatusById(ctx context.Context, arg ChangeTargetSt
 		&i.StatusReason,
 		&i.LastSynced,
 		&i.HostUrl,
+		&i.Settings,
 	)
 	return i, err
 }
 
 const createTarget = `-- name: CreateTarget :one
-INSERT INTO targets (id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, host_url)
+INSERT INTO targets (id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, host_url, settings)
 VALUES (
     $1,
     $2,
@@ -64,9 +66,10 @@ VALUES (
     $7,
     $8,
     $9,
-    $10
+    $10,
+    $11
 )
-RETURNING id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, status_reason, last_synced, host_url
+RETURNING id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, status_reason, last_synced, host_url, settings
 `
 
 type CreateTargetParams struct {
@@ -80,6 +83,7 @@ type CreateTargetParams struct {
 	SyncFrequency string
 	SyncStatus    string
 	HostUrl       sql.NullString
+	Settings      json.RawMessage
 }
 
 func (q *Queries) CreateTarget(ctx context.Context, arg CreateTargetParams) (Target, error) {
@@ -94,6 +98,7 @@ func (q *Queries) CreateTarget(ctx context.Context, arg CreateTargetParams) (Tar
 		arg.SyncFrequency,
 		arg.SyncStatus,
 		arg.HostUrl,
+		arg.Settings,
 	)
 	var i Target
 	err := row.Scan(
@@ -109,6 +114,7 @@ func (q *Queries) CreateTarget(ctx context.Context, arg CreateTargetParams) (Tar
 		&i.StatusReason,
 		&i.LastSynced,
 		&i.HostUrl,
+		&i.Settings,
 	)
 	return i, err
 }
@@ -123,8 +129,50 @@ func (q *Queries) DeleteTarget(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const getActiveTargetsByType = `-- name: GetActiveTargetsByType :many
+SELECT id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, status_reason, last_synced, host_url, settings FROM targets
+where target_type = $1 and is_active = TRUE
+`
+
+func (q *Queries) GetActiveTargetsByType(ctx context.Context, targetType string) ([]Target, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveTargetsByType, targetType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Target
+	for rows.Next() {
+		var i Target
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TargetType,
+			&i.UserID,
+			&i.DbID,
+			&i.IsActive,
+			&i.SyncFrequency,
+			&i.SyncStatus,
+			&i.StatusReason,
+			&i.LastSynced,
+			&i.HostUrl,
+			&i.Settings,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTargetById = `-- name: GetTargetById :one
-SELECT id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, status_reason, last_synced, host_url FROM targets
+SELECT id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, status_reason, last_synced, host_url, settings FROM targets
 where id = $1
 `
 
@@ -144,12 +192,13 @@ func (q *Queries) GetTargetById(ctx context.Context, id uuid.UUID) (Target, erro
 		&i.StatusReason,
 		&i.LastSynced,
 		&i.HostUrl,
+		&i.Settings,
 	)
 	return i, err
 }
 
 const getUserActiveTargets = `-- name: GetUserActiveTargets :many
-SELECT id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, status_reason, last_synced, host_url FROM targets
+SELECT id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, status_reason, last_synced, host_url, settings FROM targets
 where is_active = TRUE and user_id = $1
 `
 
@@ -175,6 +224,7 @@ func (q *Queries) GetUserActiveTargets(ctx context.Context, userID uuid.UUID) ([
 			&i.StatusReason,
 			&i.LastSynced,
 			&i.HostUrl,
+			&i.Settings,
 		); err != nil {
 			return nil, err
 		}
@@ -190,7 +240,7 @@ func (q *Queries) GetUserActiveTargets(ctx context.Context, userID uuid.UUID) ([
 }
 
 const getUserTargets = `-- name: GetUserTargets :many
-SELECT id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, status_reason, last_synced, host_url FROM targets
+SELECT id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, status_reason, last_synced, host_url, settings FROM targets
 where user_id = $1
 `
 
@@ -216,6 +266,7 @@ func (q *Queries) GetUserTargets(ctx context.Context, userID uuid.UUID) ([]Targe
 			&i.StatusReason,
 			&i.LastSynced,
 			&i.HostUrl,
+			&i.Settings,
 		); err != nil {
 			return nil, err
 		}
@@ -230,11 +281,27 @@ func (q *Queries) GetUserTargets(ctx context.Context, userID uuid.UUID) ([]Targe
 	return items, nil
 }
 
+const updateTargetSettings = `-- name: UpdateTargetSettings :exec
+UPDATE targets
+SET settings = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateTargetSettingsParams struct {
+	ID       uuid.UUID
+	Settings json.RawMessage
+}
+
+func (q *Queries) UpdateTargetSettings(ctx context.Context, arg UpdateTargetSettingsParams) error {
+	_, err := q.db.ExecContext(ctx, updateTargetSettings, arg.ID, arg.Settings)
+	return err
+}
+
 const updateTargetSyncStatusById = `-- name: UpdateTargetSyncStatusById :one
 UPDATE targets
 SET sync_status = $2, status_reason = $3, last_synced = $4
 WHERE id = $1
-RETURNING id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, status_reason, last_synced, host_url
+RETURNING id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, status_reason, last_synced, host_url, settings
 `
 
 type UpdateTargetSyncStatusByIdParams struct {
@@ -265,6 +332,7 @@ func (q *Queries) UpdateTargetSyncStatusById(ctx context.Context, arg UpdateTarg
 		&i.StatusReason,
 		&i.LastSynced,
 		&i.HostUrl,
+		&i.Settings,
 	)
 	return i, err
 }
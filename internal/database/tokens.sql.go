@@ -132,6 +132,26 @@ func (q *Queries) GetTokenByTarget(ctx context.Context, targetID uuid.NullUUID)
 	return i, err
 }
 
+const updateTokenAppData = `-- name: UpdateTokenAppData :exec
+UPDATE tokens
+SET
+    source_app_data = $2,
+    updated_at = $3
+WHERE
+    id = $1
+`
+
+type UpdateTokenAppDataParams struct {
+	ID            uuid.UUID
+	SourceAppData json.RawMessage
+	UpdatedAt     time.Time
+}
+
+func (q *Queries) UpdateTokenAppData(ctx context.Context, arg UpdateTokenAppDataParams) error {
+	_, err := q.db.ExecContext(ctx, updateTokenAppData, arg.ID, arg.SourceAppData, arg.UpdatedAt)
+	return err
+}
+
 const updateTokenProfile = `-- name: UpdateTokenProfile :exec
 UPDATE tokens
 SET
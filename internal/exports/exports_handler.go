@@ -9,22 +9,22 @@ import (
 	"time"
 
 	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/objectstore"
 	"github.com/google/uuid"
 )
 
-func DeleteAllExports(userID uuid.UUID, dbQueries *database.Queries) error {
+func DeleteAllExports(userID uuid.UUID, dbQueries *database.Queries, encryptionKey []byte) error {
 
 	exports, err := dbQueries.GetAllExportsByUserId(context.Background(), userID)
 	if err != nil {
 		log.Printf("Error getting all exports records: %v", err)
 		return err
 	}
+
+	stores := newTargetStores(dbQueries, encryptionKey)
 	for _, exp := range exports {
-		if exp.DownloadUrl.Valid {
-			err := os.Remove(exp.DownloadUrl.String)
-			if err != nil {
-				log.Printf("Error deleting export file %s: %v", exp.DownloadUrl.String, err)
-			}
+		if err := removeExportFiles(exp, stores); err != nil {
+			log.Printf("Error deleting export %s: %v", exp.ID, err)
 		}
 	}
 
@@ -92,3 +92,93 @@ func UpdateLogAutoExport(export database.Export, dbQueries *database.Queries, st
 
 	return err
 }
+
+// PruneTargetExports removes the target's exports created before cutoff along
+// with their files, so retention keeps outputs/ and the target's bucket from
+// growing without bound.
+func PruneTargetExports(targetID uuid.UUID, dbQueries *database.Queries, encryptionKey []byte, cutoff time.Time) error {
+
+	exports, err := dbQueries.GetTargetExportsCreatedBefore(context.Background(), database.GetTargetExportsCreatedBeforeParams{
+		TargetID:  uuid.NullUUID{UUID: targetID, Valid: true},
+		CreatedAt: cutoff,
+	})
+	if err != nil {
+		return err
+	}
+
+	stores := newTargetStores(dbQueries, encryptionKey)
+	for _, exp := range exports {
+		if err := removeExportFiles(exp, stores); err != nil {
+			log.Printf("Error deleting export %s: %v", exp.ID, err)
+			continue
+		}
+
+		err = dbQueries.DeleteExportById(context.Background(), exp.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// targetStores opens each bucket exports were uploaded to once while they are
+// removed
+type targetStores struct {
+	dbQueries     *database.Queries
+	encryptionKey []byte
+	opened        map[storeKey]*objectstore.Store
+}
+
+// storeKey tells buckets apart: the same one may be reached with the
+// credentials of different targets
+type storeKey struct {
+	targetID uuid.UUID
+	loc      objectstore.Location
+}
+
+func newTargetStores(dbQueries *database.Queries, encryptionKey []byte) *targetStores {
+	return &targetStores{
+		dbQueries:     dbQueries,
+		encryptionKey: encryptionKey,
+		opened:        make(map[storeKey]*objectstore.Store),
+	}
+}
+
+func (t *targetStores) get(exp database.Export) (*objectstore.Store, error) {
+	key := storeKey{targetID: exp.TargetID.UUID, loc: objectstore.ExportLocation(exp)}
+	if store, ok := t.opened[key]; ok {
+		return store, nil
+	}
+
+	store, err := objectstore.OpenExport(context.Background(), t.dbQueries, t.encryptionKey, exp)
+	if err != nil {
+		return nil, err
+	}
+
+	t.opened[key] = store
+	return store, nil
+}
+
+// removeExportFiles deletes the export's local file and its uploaded copy.
+// Either may already be gone.
+func removeExportFiles(exp database.Export, stores *targetStores) error {
+	if exp.StorageKey.Valid && exp.TargetID.Valid {
+		store, err := stores.get(exp)
+		if err != nil {
+			return err
+		}
+		if err := store.Remove(context.Background(), exp.StorageKey.String); err != nil {
+			return err
+		}
+	}
+
+	if exp.DownloadUrl.Valid {
+		err := os.Remove(exp.DownloadUrl.String)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
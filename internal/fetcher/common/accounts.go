@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package common
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+// sameAccountName compares names the way networks do, ignoring case, spacing
+// and the leading @ some users type
+func sameAccountName(a, b string) bool {
+	normalize := func(name string) string {
+		return strings.TrimPrefix(strings.TrimSpace(name), "@")
+	}
+	return strings.EqualFold(normalize(a), normalize(b))
+}
+
+// SyncSourceAccount stores the network's stable account ID for a source and, when the
+// account now goes by another name, keeps the old one in the history and moves posts
+// over so their URLs keep working. It returns true when a rename was recorded.
+func SyncSourceAccount(ctx context.Context, dbQueries *database.Queries, source database.Source, accountID, oldName, newName string) (bool, error) {
+	if accountID != "" && source.AccountID.String != accountID {
+		if source.AccountID.Valid && source.AccountID.String != "" {
+			return false, fmt.Errorf("%s now belongs to a different account (%s, expected %s)", oldName, accountID, source.AccountID.String)
+		}
+
+		err := dbQueries.UpdateSourceAccountID(ctx, database.UpdateSourceAccountIDParams{
+			ID:        source.ID,
+			AccountID: sql.NullString{String: accountID, Valid: true},
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if newName == "" || sameAccountName(oldName, newName) {
+		return false, nil
+	}
+
+	_, err := dbQueries.CreateSourceRename(ctx, database.CreateSourceRenameParams{
+		ID:          uuid.New(),
+		SourceID:    source.ID,
+		OldUserName: oldName,
+		NewUserName: newName,
+		ChangedAt:   time.Now(),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	err = dbQueries.UpdatePostsAuthorForSource(ctx, database.UpdatePostsAuthorForSourceParams{
+		SourceID: source.ID,
+		Author:   oldName,
+		Author_2: newName,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if sameAccountName(source.UserName, oldName) {
+		err = dbQueries.RenameSource(ctx, database.RenameSourceParams{
+			ID:       source.ID,
+			UserName: newName,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	message := fmt.Sprintf("%s account renamed from %s to %s", source.Network, oldName, newName)
+	log.Printf("%s: %s for source %s", source.Network, message, source.ID)
+
+	_, _ = dbQueries.CreateLog(ctx, database.CreateLogParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		SourceID:  uuid.NullUUID{UUID: source.ID, Valid: true},
+		Message:   message,
+	})
+
+	return true, nil
+}
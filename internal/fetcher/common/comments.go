@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package common
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+func SavePostComments(ctx context.Context, dbQueries *database.Queries, postID uuid.UUID, comments int) error {
+	return dbQueries.SyncPostAnalytics(ctx, database.SyncPostAnalyticsParams{
+		ID:       uuid.New(),
+		Date:     time.Now().UTC().Truncate(24 * time.Hour),
+		PostID:   postID,
+		Comments: sql.NullInt64{Int64: int64(comments), Valid: true},
+	})
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package common
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+func ProcessCommunityPost(
+	ctx context.Context,
+	dbQueries *database.Queries,
+	sourceID uuid.UUID,
+	networkInternalID string,
+	network string,
+	createdAt time.Time,
+	postType string,
+	author string,
+	content string,
+	score int,
+	comments int,
+	upvoteRatio sql.NullFloat64,
+) error {
+	postID, err := CreateOrUpdatePost(
+		ctx,
+		dbQueries,
+		sourceID,
+		networkInternalID,
+		network,
+		createdAt,
+		postType,
+		author,
+		content,
+	)
+	if err != nil {
+		return err
+	}
+
+	reaction, err := dbQueries.SyncReactions(ctx, database.SyncReactionsParams{
+		ID:       uuid.New(),
+		SyncedAt: time.Now(),
+		PostID:   postID,
+		Likes:    sql.NullInt64{Int64: int64(score), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := dbQueries.UpdateReactionCommunityStats(ctx, database.UpdateReactionCommunityStatsParams{
+		ID:          reaction.ID,
+		UpvoteRatio: upvoteRatio,
+	}); err != nil {
+		return err
+	}
+
+	return SavePostComments(ctx, dbQueries, postID, comments)
+}
+
+func SaveCommunityStats(ctx context.Context, dbQueries *database.Queries, sourceID uuid.UUID, karma, communitySubscribers *int, updateFn func(*ProfileStats)) error {
+	if err := UpdateSourceStats(ctx, dbQueries, sourceID, updateFn); err != nil {
+		return err
+	}
+
+	params := database.UpdateSourceCommunityStatsParams{
+		SourceID: sourceID,
+		Date:     time.Now().UTC().Truncate(24 * time.Hour),
+	}
+	if karma != nil {
+		params.Karma = sql.NullInt64{Int64: int64(*karma), Valid: true}
+	}
+	if communitySubscribers != nil {
+		params.CommunitySubscribers = sql.NullInt64{Int64: int64(*communitySubscribers), Valid: true}
+	}
+
+	return dbQueries.UpdateSourceCommunityStats(ctx, params)
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package common
+
+import (
+	"context"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+func snapshotDate() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func HasDemographicsSnapshotToday(ctx context.Context, dbQueries *database.Queries, sourceID uuid.UUID) bool {
+	count, err := dbQueries.CheckCountOfAudienceDemographicsForSourceOnDate(ctx, database.CheckCountOfAudienceDemographicsForSourceOnDateParams{
+		SourceID: sourceID,
+		Date:     snapshotDate(),
+	})
+	return err == nil && count > 0
+}
+
+func SaveDemographicsSnapshot(ctx context.Context, dbQueries *database.Queries, sourceID uuid.UUID, entries []DemographicEntry) error {
+	date := snapshotDate()
+
+	for _, entry := range entries {
+		if entry.Bucket == "" {
+			continue
+		}
+		err := dbQueries.CreateAudienceDemographic(ctx, database.CreateAudienceDemographicParams{
+			ID:        uuid.New(),
+			Date:      date,
+			SourceID:  sourceID,
+			Dimension: entry.Dimension,
+			Bucket:    entry.Bucket,
+			Value:     entry.Value,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -44,8 +44,12 @@ func StripHTMLToText(input string) string {
 }
 
 func SaveOrUpdateSourceStats(ctx context.Context, dbQueries *database.Queries, sourceID uuid.UUID, stats *ProfileStats) error {
+	return SaveSourceStatsForDate(ctx, dbQueries, sourceID, time.Now(), stats)
+}
+
+func SaveSourceStatsForDate(ctx context.Context, dbQueries *database.Queries, sourceID uuid.UUID, date time.Time, stats *ProfileStats) error {
 
-	today := time.Now().UTC().Truncate(24 * time.Hour)
+	today := date.UTC().Truncate(24 * time.Hour)
 
 	existing, err := dbQueries.GetSourceStatsByDate(ctx, database.GetSourceStatsByDateParams{
 		SourceID: sourceID,
@@ -167,6 +171,7 @@ func CreateOrUpdatePost(
 		if err != nil {
 			return uuid.Nil, err
 		}
+		recordNewPost(newPost)
 		return newPost.ID, nil
 	}
 
@@ -199,7 +204,7 @@ func ProcessScrapedPost(
 	author string,
 	content string,
 	likes, reposts, views sql.NullInt64,
-) error {
+) (uuid.UUID, error) {
 	postID, err := CreateOrUpdatePost(
 		ctx,
 		dbQueries,
@@ -212,7 +217,7 @@ func ProcessScrapedPost(
 		content,
 	)
 	if err != nil {
-		return err
+		return uuid.Nil, err
 	}
 
 	_, err = dbQueries.SyncReactions(ctx, database.SyncReactionsParams{
@@ -223,7 +228,10 @@ func ProcessScrapedPost(
 		Likes:    likes,
 		Reposts:  reposts,
 	})
-	return err
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return postID, nil
 }
 
 func UpdateSourceStats(
@@ -41,3 +41,9 @@ type ProfileStats struct {
 	AverageReposts *float64
 	AverageViews   *float64
 }
+
+type DemographicEntry struct {
+	Dimension string  `json:"dimension"`
+	Bucket    string  `json:"bucket"`
+	Value     float64 `json:"value"`
+}
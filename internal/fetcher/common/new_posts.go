@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package common
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+// NewPostsRun collects the posts created while one sync of a source runs, so
+// they can be announced once the sync succeeds. A manual and a scheduled sync
+// of the same source may overlap; each run sees every new post, and the first
+// one to succeed takes them.
+type NewPostsRun struct {
+	sourceID uuid.UUID
+	posts    []database.Post
+}
+
+// newPostRuns holds the runs in progress per source. Sources without one are
+// ignored.
+var newPostRuns = struct {
+	sync.Mutex
+	bySource map[uuid.UUID][]*NewPostsRun
+}{bySource: make(map[uuid.UUID][]*NewPostsRun)}
+
+func TrackNewPosts(sourceID uuid.UUID) *NewPostsRun {
+	newPostRuns.Lock()
+	defer newPostRuns.Unlock()
+	run := &NewPostsRun{sourceID: sourceID}
+	newPostRuns.bySource[sourceID] = append(newPostRuns.bySource[sourceID], run)
+	return run
+}
+
+// Take stops the run and returns the posts created since it started. Other
+// runs of the source forget them, so each post is announced once.
+func (r *NewPostsRun) Take() []database.Post {
+	newPostRuns.Lock()
+	defer newPostRuns.Unlock()
+	r.stop()
+	for _, other := range newPostRuns.bySource[r.sourceID] {
+		other.posts = slices.DeleteFunc(other.posts, func(p database.Post) bool {
+			return slices.ContainsFunc(r.posts, func(taken database.Post) bool { return taken.ID == p.ID })
+		})
+	}
+	return r.posts
+}
+
+// Stop ends a run whose sync failed, leaving its posts to the others
+func (r *NewPostsRun) Stop() {
+	newPostRuns.Lock()
+	defer newPostRuns.Unlock()
+	r.stop()
+}
+
+func (r *NewPostsRun) stop() {
+	runs := slices.DeleteFunc(newPostRuns.bySource[r.sourceID], func(run *NewPostsRun) bool { return run == r })
+	if len(runs) == 0 {
+		delete(newPostRuns.bySource, r.sourceID)
+		return
+	}
+	newPostRuns.bySource[r.sourceID] = runs
+}
+
+func recordNewPost(post database.Post) {
+	newPostRuns.Lock()
+	defer newPostRuns.Unlock()
+	for _, run := range newPostRuns.bySource[post.SourceID] {
+		run.posts = append(run.posts, post)
+	}
+}
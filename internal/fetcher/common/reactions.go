@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package common
+
+import (
+	"context"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+func SaveReactionBreakdown(ctx context.Context, dbQueries *database.Queries, postID uuid.UUID, reactions map[string]int) error {
+	for reaction, count := range reactions {
+		if reaction == "" {
+			continue
+		}
+		err := dbQueries.SyncReactionBreakdown(ctx, database.SyncReactionBreakdownParams{
+			ID:       uuid.New(),
+			SyncedAt: time.Now(),
+			PostID:   postID,
+			Reaction: reaction,
+			Count:    count,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package common
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+func SaveStreamViewerSample(ctx context.Context, dbQueries *database.Queries, sourceID uuid.UUID, streamID string, viewers int) error {
+	return dbQueries.CreateStreamViewerSample(ctx, database.CreateStreamViewerSampleParams{
+		ID:        uuid.New(),
+		SourceID:  sourceID,
+		StreamID:  streamID,
+		SampledAt: time.Now(),
+		Viewers:   viewers,
+	})
+}
+
+func SaveStreamStats(
+	ctx context.Context,
+	dbQueries *database.Queries,
+	sourceID uuid.UUID,
+	network string,
+	networkInternalID string,
+	streamID string,
+	duration time.Duration,
+) error {
+	post, err := dbQueries.GetPostByNetworkAndId(ctx, database.GetPostByNetworkAndIdParams{
+		NetworkInternalID: networkInternalID,
+		Network:           network,
+	})
+	if err != nil {
+		return err
+	}
+
+	params := database.SyncPostStreamStatsParams{
+		ID:        uuid.New(),
+		PostID:    post.ID,
+		StreamID:  sql.NullString{String: streamID, Valid: streamID != ""},
+		UpdatedAt: time.Now(),
+	}
+
+	if duration > 0 {
+		params.DurationSeconds = sql.NullInt64{Int64: int64(duration.Seconds()), Valid: true}
+	}
+
+	if streamID != "" {
+		summary, err := dbQueries.GetStreamViewerSummary(ctx, database.GetStreamViewerSummaryParams{
+			SourceID: sourceID,
+			StreamID: streamID,
+		})
+		if err != nil {
+			return err
+		}
+		if summary.SampleCount > 0 {
+			params.PeakViewers = sql.NullInt64{Int64: int64(summary.PeakViewers), Valid: true}
+			params.AverageViewers = sql.NullFloat64{Float64: summary.AverageViewers, Valid: true}
+		}
+	}
+
+	return dbQueries.SyncPostStreamStats(ctx, params)
+}
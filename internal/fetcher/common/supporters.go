@@ -0,0 +1,23 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package common
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+func SaveSupporterStats(ctx context.Context, dbQueries *database.Queries, sourceID uuid.UUID, supporters int, updateFn func(*ProfileStats)) error {
+	if err := UpdateSourceStats(ctx, dbQueries, sourceID, updateFn); err != nil {
+		return err
+	}
+
+	return dbQueries.UpdateSourceSupporters(ctx, database.UpdateSourceSupportersParams{
+		SupportersCount: sql.NullInt64{Int64: int64(supporters), Valid: true},
+		SourceID:        sourceID,
+		Date:            time.Now().UTC().Truncate(24 * time.Hour),
+	})
+}
@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+type ArchiveImportResult struct {
+	Created  int
+	Existing int
+}
+
+type archivedPost struct {
+	NetworkInternalID string
+	CreatedAt         time.Time
+	PostType          string
+	Content           string
+	Likes             sql.NullInt64
+	Reposts           sql.NullInt64
+	Views             sql.NullInt64
+}
+
+// ImportArchive reads a platform data export and creates the posts that are not
+// in the database yet, using the same network IDs as the live fetchers.
+func ImportArchive(dbQueries *database.Queries, sourceId uuid.UUID, filename string, r io.ReaderAt, size int64) (ArchiveImportResult, error) {
+	ctx := context.Background()
+
+	source, err := dbQueries.GetSourceById(ctx, sourceId)
+	if err != nil {
+		return ArchiveImportResult{}, fmt.Errorf("failed to get source: %w", err)
+	}
+
+	var posts []archivedPost
+	switch source.Network {
+	case "Instagram":
+		posts, err = parseInstagramArchive(r, size, filename)
+	case "TikTok":
+		posts, err = parseTikTokArchive(r, size, filename)
+	case "Bluesky":
+		var includeReplies bool
+		includeReplies, err = blueskyIncludesReplies(ctx, dbQueries, source.ID)
+		if err != nil {
+			return ArchiveImportResult{}, fmt.Errorf("Bluesky: failed to read source options: %w", err)
+		}
+		posts, err = parseBlueskyArchive(io.NewSectionReader(r, 0, size), includeReplies)
+	case "Manual":
+		// There is no X fetcher, so tweets are kept on a manual source keyed by their URL
+		return importTwitterArchive(ctx, dbQueries, source, r, size, filename)
+	default:
+		return ArchiveImportResult{}, fmt.Errorf("archive import is not supported for %s sources", source.Network)
+	}
+	if err != nil {
+		return ArchiveImportResult{}, fmt.Errorf("%s: failed to read archive: %w", source.Network, err)
+	}
+
+	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
+	if err != nil {
+		return ArchiveImportResult{}, err
+	}
+
+	var result ArchiveImportResult
+	for _, post := range posts {
+		if exclusionMap[post.NetworkInternalID] {
+			continue
+		}
+
+		created, err := saveArchivedPost(ctx, dbQueries, source, post)
+		if err != nil {
+			return result, fmt.Errorf("%s: failed to save post %s: %w", source.Network, post.NetworkInternalID, err)
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Existing++
+		}
+	}
+
+	return result, nil
+}
+
+func saveArchivedPost(ctx context.Context, dbQueries *database.Queries, source database.Source, post archivedPost) (bool, error) {
+	_, err := dbQueries.GetPostByNetworkAndId(ctx, database.GetPostByNetworkAndIdParams{
+		NetworkInternalID: post.NetworkInternalID,
+		Network:           source.Network,
+	})
+	if err == nil {
+		return false, nil
+	}
+
+	// Placeholder IDs can't be matched by ID, so look for a live post made at the same moment
+	if strings.HasPrefix(post.NetworkInternalID, "archive/") {
+		_, err := dbQueries.GetPostBySourceAndCreatedAt(ctx, database.GetPostBySourceAndCreatedAtParams{
+			SourceID:  source.ID,
+			CreatedAt: post.CreatedAt,
+		})
+		if err == nil {
+			return false, nil
+		}
+	}
+
+	postID, err := common.CreateOrUpdatePost(
+		ctx,
+		dbQueries,
+		source.ID,
+		post.NetworkInternalID,
+		source.Network,
+		post.CreatedAt,
+		post.PostType,
+		source.UserName,
+		post.Content,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	if err := dbQueries.MarkPostImported(ctx, postID); err != nil {
+		return false, err
+	}
+
+	if post.Likes.Valid || post.Reposts.Valid || post.Views.Valid {
+		_, err = dbQueries.SyncReactions(ctx, database.SyncReactionsParams{
+			ID:       uuid.New(),
+			SyncedAt: time.Now(),
+			PostID:   postID,
+			Likes:    post.Likes,
+			Reposts:  post.Reposts,
+			Views:    post.Views,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func isZipArchive(filename string) bool {
+	return strings.EqualFold(path.Ext(filename), ".zip")
+}
+
+// readArchiveFiles returns the contents of every file in the export whose base name
+// matches, reading the upload itself when it is not a zip.
+func readArchiveFiles(r io.ReaderAt, size int64, filename string, match func(name string) bool) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	if !isZipArchive(filename) {
+		data, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return nil, err
+		}
+		files[path.Base(filename)] = data
+		return files, nil
+	}
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !match(path.Base(f.Name)) {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[f.Name] = data
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no matching files found in %s", filename)
+	}
+
+	return files, nil
+}
@@ -2,6 +2,7 @@
 package sources
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
@@ -10,9 +11,11 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
+	"github.com/fluffyriot/rpsync/internal/authhelp"
 	"github.com/fluffyriot/rpsync/internal/database"
 	"github.com/fluffyriot/rpsync/internal/fetcher/common"
 	"github.com/google/uuid"
@@ -36,6 +39,11 @@ type bskyFeed struct {
 						Type string `json:"$type"`
 					} `json:"media"`
 				} `json:"embed"`
+				Reply *struct {
+					Parent struct {
+						URI string `json:"uri"`
+					} `json:"parent"`
+				} `json:"reply"`
 				Text string `json:"text"`
 			} `json:"record"`
 			BookmarkCount int `json:"bookmarkCount"`
@@ -55,47 +63,128 @@ type bskyFeed struct {
 }
 
 type bskyProfile struct {
-	FollowersCount int `json:"followersCount"`
-	FollowsCount   int `json:"followsCount"`
-	PostsCount     int `json:"postsCount"`
+	Did            string `json:"did"`
+	Handle         string `json:"handle"`
+	FollowersCount int    `json:"followersCount"`
+	FollowsCount   int    `json:"followsCount"`
+	PostsCount     int    `json:"postsCount"`
 }
 
-func getBskyApiString(dbQueries *database.Queries, uid uuid.UUID, cursor string) (string, string, error) {
+type bskyDidDoc struct {
+	Service []struct {
+		ID              string `json:"id"`
+		Type            string `json:"type"`
+		ServiceEndpoint string `json:"serviceEndpoint"`
+	} `json:"service"`
+}
+
+type bskySession struct {
+	AccessJwt string `json:"accessJwt"`
+	Did       string `json:"did"`
+}
 
-	username, err := dbQueries.GetUserActiveSourceByName(
-		context.Background(),
-		database.GetUserActiveSourceByNameParams{
-			UserID:  uid,
-			Network: "Bluesky",
-		},
-	)
+// bskyClient reads the public AppView, or the user's PDS when an app password is set
+type bskyClient struct {
+	client      *common.Client
+	host        string
+	accessToken string
+}
 
+func (b *bskyClient) get(method string, params url.Values, target any) error {
+	req, err := http.NewRequest("GET", b.host+"/xrpc/"+method+"?"+params.Encode(), nil)
 	if err != nil {
-		return "", "", err
+		return err
+	}
+
+	if b.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+b.accessToken)
+		req.Header.Set("atproto-proxy", "did:web:api.bsky.app#bsky_appview")
 	}
 
-	apiString := fmt.Sprintf(
-		"https://public.api.bsky.app/xrpc/app.bsky.feed.getAuthorFeed?actor=%s&limit=100&filter=posts_no_replies",
-		username.UserName,
-	)
+	resp, err := b.client.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 
-	if cursor != "" {
-		apiString += "&cursor=" + cursor
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("Failed to get a successfull response. %v: %v", resp.StatusCode, resp.Status)
 	}
 
-	return apiString, username.UserName, nil
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
 
+	return json.Unmarshal(data, target)
 }
 
-func fetchBlueskyProfile(username string, c *common.Client) (*bskyProfile, error) {
+func resolveBlueskyDid(c *common.Client, handle string) (string, error) {
+	public := &bskyClient{client: c, host: "https://public.api.bsky.app"}
+
+	var resolved struct {
+		Did string `json:"did"`
+	}
+	if err := public.get("com.atproto.identity.resolveHandle", url.Values{"handle": {handle}}, &resolved); err != nil {
+		return "", err
+	}
+	if resolved.Did == "" {
+		return "", fmt.Errorf("no DID returned for %s", handle)
+	}
+
+	return resolved.Did, nil
+}
 
-	url := fmt.Sprintf("https://public.api.bsky.app/xrpc/app.bsky.actor.getProfile?actor=%s", username)
+func resolveBlueskyPds(c *common.Client, did string) (string, error) {
+	var docURL string
+	switch {
+	case strings.HasPrefix(did, "did:plc:"):
+		docURL = "https://plc.directory/" + did
+	case strings.HasPrefix(did, "did:web:"):
+		docURL = "https://" + strings.TrimPrefix(did, "did:web:") + "/.well-known/did.json"
+	default:
+		return "", fmt.Errorf("unsupported DID method: %s", did)
+	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	resp, err := c.HTTPClient.Get(docURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("Failed to get a successfull response. %v: %v", resp.StatusCode, resp.Status)
+	}
+
+	var doc bskyDidDoc
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return "", err
+	}
+
+	for _, service := range doc.Service {
+		if service.ID == "#atproto_pds" || service.Type == "AtprotoPersonalDataServer" {
+			return strings.TrimSuffix(service.ServiceEndpoint, "/"), nil
+		}
+	}
+
+	return "", fmt.Errorf("no PDS listed for %s", did)
+}
+
+func createBlueskySession(c *common.Client, pds, did, appPassword string) (*bskySession, error) {
+	body, err := json.Marshal(map[string]string{
+		"identifier": did,
+		"password":   appPassword,
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	req, err := http.NewRequest("POST", pds+"/xrpc/com.atproto.server.createSession", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, err
@@ -103,23 +192,95 @@ func fetchBlueskyProfile(username string, c *common.Client) (*bskyProfile, error
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to get profile: %v %v", resp.StatusCode, resp.Status)
+		return nil, fmt.Errorf("Failed to create session. %v: %v", resp.StatusCode, resp.Status)
 	}
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
+	var session bskySession
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
 		return nil, err
 	}
 
-	var profile bskyProfile
-	if err := json.Unmarshal(data, &profile); err != nil {
-		return nil, err
+	return &session, nil
+}
+
+// getBlueskyClient resolves the source's DID, storing it so the source survives handle
+// changes, and logs in with the app password when one was saved.
+func getBlueskyClient(ctx context.Context, dbQueries *database.Queries, c *common.Client, encryptionKey []byte, source database.Source) (*bskyClient, string, bool, error) {
+	did := source.AccountID.String
+	if !source.AccountID.Valid || did == "" {
+		resolved, err := resolveBlueskyDid(c, source.UserName)
+		if err != nil {
+			return nil, "", false, fmt.Errorf("Bluesky: failed to resolve handle %s: %w", source.UserName, err)
+		}
+		did = resolved
+
+		err = dbQueries.UpdateSourceAccountID(ctx, database.UpdateSourceAccountIDParams{
+			ID:        source.ID,
+			AccountID: sql.NullString{String: did, Valid: true},
+		})
+		if err != nil {
+			return nil, "", false, err
+		}
 	}
 
-	return &profile, nil
+	client := &bskyClient{client: c, host: "https://public.api.bsky.app"}
+
+	appPassword, _, sourceAppData, _, err := authhelp.GetSourceToken(ctx, dbQueries, encryptionKey, source.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return client, did, false, nil
+	}
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	includeReplies, _ := sourceAppData["include_replies"].(bool)
+
+	pds, err := resolveBlueskyPds(c, did)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("Bluesky: failed to resolve PDS: %w", err)
+	}
+
+	session, err := createBlueskySession(c, pds, did, appPassword)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("Bluesky: failed to log in: %w", err)
+	}
+
+	client.host = pds
+	client.accessToken = session.AccessJwt
+
+	return client, did, includeReplies, nil
 }
 
-func FetchBlueskyPosts(dbQueries *database.Queries, c *common.Client, uid uuid.UUID, sourceId uuid.UUID) error {
+func FetchBlueskyPosts(dbQueries *database.Queries, c *common.Client, encryptionKey []byte, sourceId uuid.UUID) error {
+
+	ctx := context.Background()
+
+	source, err := dbQueries.GetSourceById(ctx, sourceId)
+	if err != nil {
+		return err
+	}
+
+	client, did, includeReplies, err := getBlueskyClient(ctx, dbQueries, c, encryptionKey, source)
+	if err != nil {
+		return err
+	}
+
+	source.AccountID = sql.NullString{String: did, Valid: true}
+
+	var profile bskyProfile
+	profileErr := client.get("app.bsky.actor.getProfile", url.Values{"actor": {did}}, &profile)
+	if profileErr != nil {
+		log.Printf("Bluesky: Failed to fetch profile for source %s: %v", sourceId, profileErr)
+	} else if profile.Handle != "handle.invalid" {
+		if _, err := common.SyncSourceAccount(ctx, dbQueries, source, did, source.UserName, profile.Handle); err != nil {
+			return err
+		}
+	}
+
+	filter := "posts_no_replies"
+	if includeReplies {
+		filter = "posts_with_replies"
+	}
 
 	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
 	if err != nil {
@@ -131,38 +292,20 @@ func FetchBlueskyPosts(dbQueries *database.Queries, c *common.Client, uid uuid.U
 	const maxPages = 500
 
 	var cursor string
-	var username string
-	var url string
 
 	for page := 0; page < maxPages; page++ {
 
-		url, username, err = getBskyApiString(dbQueries, uid, cursor)
-		if err != nil {
-			return err
-		}
-
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return err
-		}
-
-		resp, err := c.HTTPClient.Do(req)
-		if err != nil {
-			return err
+		params := url.Values{
+			"actor":  {did},
+			"limit":  {"100"},
+			"filter": {filter},
 		}
-
-		if resp.StatusCode != 200 {
-			return fmt.Errorf("Failed to get a successfull response. %v: %v", resp.StatusCode, resp.Status)
-		}
-
-		data, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		if err != nil {
-			return err
+		if cursor != "" {
+			params.Set("cursor", cursor)
 		}
 
 		var feed bskyFeed
-		if err := json.Unmarshal(data, &feed); err != nil {
+		if err := client.get("app.bsky.feed.getAuthorFeed", params, &feed); err != nil {
 			return err
 		}
 
@@ -183,6 +326,10 @@ func FetchBlueskyPosts(dbQueries *database.Queries, c *common.Client, uid uuid.U
 
 			post_type := "post"
 
+			if item.Post.Record.Reply != nil {
+				post_type = "reply"
+			}
+
 			if item.Post.Record.Embed.Type == "app.bsky.embed.record" || item.Post.Record.Embed.Type == "app.bsky.embed.recordWithMedia" {
 				post_type = "quote"
 			}
@@ -192,7 +339,7 @@ func FetchBlueskyPosts(dbQueries *database.Queries, c *common.Client, uid uuid.U
 			}
 
 			postID, err := common.CreateOrUpdatePost(
-				context.Background(),
+				ctx,
 				dbQueries,
 				sourceId,
 				interNetId,
@@ -206,7 +353,7 @@ func FetchBlueskyPosts(dbQueries *database.Queries, c *common.Client, uid uuid.U
 				return err
 			}
 
-			_, err = dbQueries.SyncReactions(context.Background(), database.SyncReactionsParams{
+			reaction, err := dbQueries.SyncReactions(ctx, database.SyncReactionsParams{
 				ID:       uuid.New(),
 				SyncedAt: time.Now(),
 				PostID:   postID,
@@ -222,6 +369,23 @@ func FetchBlueskyPosts(dbQueries *database.Queries, c *common.Client, uid uuid.U
 					Valid: false,
 				},
 			})
+			if err != nil {
+				log.Printf("Bluesky: Failed to sync reactions for post %s: %v", interNetId, err)
+				continue
+			}
+
+			err = dbQueries.UpdateReactionEngagementStats(ctx, database.UpdateReactionEngagementStatsParams{
+				ID:        reaction.ID,
+				Quotes:    sql.NullInt64{Int64: int64(item.Post.QuoteCount), Valid: true},
+				Bookmarks: sql.NullInt64{Int64: int64(item.Post.BookmarkCount), Valid: true},
+			})
+			if err != nil {
+				log.Printf("Bluesky: Failed to save engagement stats for post %s: %v", interNetId, err)
+			}
+
+			if err := common.SavePostComments(ctx, dbQueries, reaction.PostID, item.Post.ReplyCount); err != nil {
+				log.Printf("Bluesky: Failed to save reply count for post %s: %v", interNetId, err)
+			}
 		}
 
 		if feed.Cursor == "" {
@@ -235,20 +399,17 @@ func FetchBlueskyPosts(dbQueries *database.Queries, c *common.Client, uid uuid.U
 		return errors.New("No content found")
 	}
 
-	stats, err := common.CalculateAverageStats(context.Background(), dbQueries, sourceId)
+	stats, err := common.CalculateAverageStats(ctx, dbQueries, sourceId)
 	if err != nil {
 		log.Printf("Bluesky: Failed to calculate stats for source %s: %v", sourceId, err)
 	} else {
 
-		profile, err := fetchBlueskyProfile(username, c)
-		if err != nil {
-			log.Printf("Bluesky: Failed to fetch profile for source %s: %v", sourceId, err)
-		} else {
+		if profileErr == nil {
 			stats.FollowersCount = &profile.FollowersCount
 			stats.FollowingCount = &profile.FollowsCount
 		}
 
-		if err := common.SaveOrUpdateSourceStats(context.Background(), dbQueries, sourceId, stats); err != nil {
+		if err := common.SaveOrUpdateSourceStats(ctx, dbQueries, sourceId, stats); err != nil {
 			log.Printf("Bluesky: Failed to save stats for source %s: %v", sourceId, err)
 		}
 	}
@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/google/uuid"
+)
+
+type blueskyArchiveEntry struct {
+	P int      `cbor:"p"`
+	K []byte   `cbor:"k"`
+	V cbor.Tag `cbor:"v"`
+}
+
+// blueskyArchiveBlock covers both MST nodes (e) and post records; other blocks decode empty.
+type blueskyArchiveBlock struct {
+	Type      string          `cbor:"$type"`
+	Text      string          `cbor:"text"`
+	CreatedAt string          `cbor:"createdAt"`
+	Reply     cbor.RawMessage `cbor:"reply"`
+	Embed     *struct {
+		Type string `cbor:"$type"`
+	} `cbor:"embed"`
+	Entries []blueskyArchiveEntry `cbor:"e"`
+}
+
+// readCARCID returns the raw bytes of the CID at the start of a CAR section.
+func readCARCID(section []byte) ([]byte, error) {
+	// CIDv0 is a bare sha2-256 multihash
+	if len(section) >= 34 && section[0] == 0x12 && section[1] == 0x20 {
+		return section[:34], nil
+	}
+
+	r := bytes.NewReader(section)
+	for i := 0; i < 3; i++ {
+		if _, err := binary.ReadUvarint(r); err != nil {
+			return nil, err
+		}
+	}
+	digestLen, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	end := len(section) - r.Len() + int(digestLen)
+	if end > len(section) {
+		return nil, fmt.Errorf("truncated CID")
+	}
+	return section[:end], nil
+}
+
+// blueskyIncludesReplies reads the source's reply option. It is kept in the
+// token's app data, which is not encrypted, so no key is needed.
+func blueskyIncludesReplies(ctx context.Context, dbQueries *database.Queries, sourceId uuid.UUID) (bool, error) {
+	token, err := dbQueries.GetTokenBySource(ctx, uuid.NullUUID{UUID: sourceId, Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var sourceAppData map[string]any
+	if len(token.SourceAppData) > 0 {
+		if err := json.Unmarshal(token.SourceAppData, &sourceAppData); err != nil {
+			return false, err
+		}
+	}
+
+	includeReplies, _ := sourceAppData["include_replies"].(bool)
+	return includeReplies, nil
+}
+
+func parseBlueskyArchive(r io.Reader, includeReplies bool) ([]archivedPost, error) {
+	br := bufio.NewReader(r)
+
+	headerLen, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("not a CAR file: %w", err)
+	}
+	if _, err := br.Discard(int(headerLen)); err != nil {
+		return nil, fmt.Errorf("not a CAR file: %w", err)
+	}
+
+	records := make(map[string]blueskyArchiveBlock)
+	keys := make(map[string]string)
+
+	for {
+		sectionLen, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		section := make([]byte, sectionLen)
+		if _, err := io.ReadFull(br, section); err != nil {
+			return nil, err
+		}
+
+		cid, err := readCARCID(section)
+		if err != nil {
+			return nil, err
+		}
+
+		var block blueskyArchiveBlock
+		if err := cbor.Unmarshal(section[len(cid):], &block); err != nil {
+			continue
+		}
+
+		if block.Type == "app.bsky.feed.post" {
+			records[string(cid)] = block
+			continue
+		}
+
+		// MST entries store each key as a suffix of the previous key in the node
+		var prev []byte
+		for _, entry := range block.Entries {
+			if entry.P > len(prev) {
+				break
+			}
+			key := append(append([]byte{}, prev[:entry.P]...), entry.K...)
+			prev = key
+
+			link, ok := entry.V.Content.([]byte)
+			if !ok || len(link) < 2 {
+				continue
+			}
+			// Links carry a leading 0x00 multibase prefix
+			keys[string(link[1:])] = string(key)
+		}
+	}
+
+	var posts []archivedPost
+	for cid, record := range records {
+		rkey, ok := strings.CutPrefix(keys[cid], "app.bsky.feed.post/")
+		if !ok {
+			continue
+		}
+
+		// Replies are kept only when the live fetcher reads them too
+		if len(record.Reply) > 0 && !includeReplies {
+			continue
+		}
+
+		createdAt, err := time.Parse(time.RFC3339Nano, record.CreatedAt)
+		if err != nil {
+			continue
+		}
+
+		postType := "post"
+		if len(record.Reply) > 0 {
+			postType = "reply"
+		}
+		if record.Embed != nil && (record.Embed.Type == "app.bsky.embed.record" || record.Embed.Type == "app.bsky.embed.recordWithMedia") {
+			postType = "quote"
+		}
+
+		posts = append(posts, archivedPost{
+			NetworkInternalID: rkey,
+			CreatedAt:         createdAt,
+			PostType:          postType,
+			Content:           record.Text,
+		})
+	}
+
+	return posts, nil
+}
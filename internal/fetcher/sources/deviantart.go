@@ -0,0 +1,281 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+const deviantArtMetadataBatchSize = 50
+
+type deviantArtTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+type deviantArtDeviation struct {
+	DeviationID   string `json:"deviationid"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	PublishedTime string `json:"published_time"`
+	IsDeleted     bool   `json:"is_deleted"`
+	Author        struct {
+		Username string `json:"username"`
+	} `json:"author"`
+	Stats struct {
+		Comments   int `json:"comments"`
+		Favourites int `json:"favourites"`
+	} `json:"stats"`
+}
+
+type deviantArtGalleryResponse struct {
+	HasMore    bool                  `json:"has_more"`
+	NextOffset *int                  `json:"next_offset"`
+	Results    []deviantArtDeviation `json:"results"`
+}
+
+type deviantArtMetadataResponse struct {
+	Metadata []struct {
+		DeviationID string `json:"deviationid"`
+		Description string `json:"description"`
+		Stats       struct {
+			Views      int `json:"views"`
+			Favourites int `json:"favourites"`
+			Comments   int `json:"comments"`
+		} `json:"stats"`
+	} `json:"metadata"`
+}
+
+type deviantArtProfileResponse struct {
+	User struct {
+		Username string `json:"username"`
+		Stats    struct {
+			Watchers int `json:"watchers"`
+			Friends  int `json:"friends"`
+		} `json:"stats"`
+	} `json:"user"`
+}
+
+func getDeviantArtToken(ctx context.Context, dbQueries *database.Queries, c *common.Client, encryptionKey []byte, sid uuid.UUID) (string, error) {
+	credentials, _, _, _, err := authhelp.GetSourceToken(ctx, dbQueries, encryptionKey, sid)
+	if err != nil {
+		return "", err
+	}
+
+	parts := strings.Split(credentials, ":::")
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid DeviantArt credentials format")
+	}
+
+	resp, err := c.HTTPClient.PostForm("https://www.deviantart.com/oauth2/token", url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {parts[0]},
+		"client_secret": {parts[1]},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var token deviantArtTokenResponse
+	if err := json.Unmarshal(data, &token); err != nil {
+		return "", err
+	}
+
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("failed to obtain access token: %s %s", token.Error, token.Description)
+	}
+
+	return token.AccessToken, nil
+}
+
+func fetchDeviantArtJSON(c *common.Client, accessToken, endpoint string, params url.Values, out any) error {
+	params.Set("access_token", accessToken)
+
+	req, err := http.NewRequest("GET", "https://www.deviantart.com/api/v1/oauth2/"+endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed to get a successfull response. %v: %v", resp.StatusCode, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+func deviantArtNetworkID(deviationURL string) string {
+	idx := strings.LastIndex(deviationURL, "-")
+	if idx == -1 {
+		return ""
+	}
+	return deviationURL[idx+1:]
+}
+
+func FetchDeviantArtPosts(dbQueries *database.Queries, c *common.Client, encryptionKey []byte, sourceId uuid.UUID) error {
+
+	ctx := context.Background()
+
+	source, err := dbQueries.GetSourceById(ctx, sourceId)
+	if err != nil {
+		return fmt.Errorf("failed to get source: %w", err)
+	}
+
+	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
+	if err != nil {
+		return err
+	}
+
+	accessToken, err := getDeviantArtToken(ctx, dbQueries, c, encryptionKey, sourceId)
+	if err != nil {
+		return fmt.Errorf("DeviantArt: %w", err)
+	}
+
+	var profile deviantArtProfileResponse
+	err = fetchDeviantArtJSON(c, accessToken, "user/profile/"+url.PathEscape(source.UserName), url.Values{
+		"expand": {"user.stats"},
+	}, &profile)
+	if err != nil {
+		return fmt.Errorf("DeviantArt: failed to get profile: %w", err)
+	}
+
+	var deviations []deviantArtDeviation
+	offset := 0
+	for {
+		var gallery deviantArtGalleryResponse
+		err := fetchDeviantArtJSON(c, accessToken, "gallery/all", url.Values{
+			"username":       {source.UserName},
+			"offset":         {strconv.Itoa(offset)},
+			"limit":          {"24"},
+			"mature_content": {"true"},
+		}, &gallery)
+		if err != nil {
+			return fmt.Errorf("DeviantArt: failed to get gallery: %w", err)
+		}
+
+		for _, d := range gallery.Results {
+			if !d.IsDeleted {
+				deviations = append(deviations, d)
+			}
+		}
+
+		if !gallery.HasMore || gallery.NextOffset == nil {
+			break
+		}
+		offset = *gallery.NextOffset
+	}
+
+	if len(deviations) == 0 {
+		return errors.New("No content found")
+	}
+
+	for start := 0; start < len(deviations); start += deviantArtMetadataBatchSize {
+		batch := deviations[start:min(start+deviantArtMetadataBatchSize, len(deviations))]
+
+		params := url.Values{
+			"ext_stats":      {"true"},
+			"mature_content": {"true"},
+		}
+		for _, d := range batch {
+			params.Add("deviationids[]", d.DeviationID)
+		}
+
+		var metadata deviantArtMetadataResponse
+		if err := fetchDeviantArtJSON(c, accessToken, "deviation/metadata", params, &metadata); err != nil {
+			log.Printf("DeviantArt: Failed to get metadata for source %s: %v", sourceId, err)
+		}
+
+		type deviationMeta struct {
+			description string
+			views       sql.NullInt64
+		}
+		metaByID := make(map[string]deviationMeta)
+		for _, m := range metadata.Metadata {
+			metaByID[m.DeviationID] = deviationMeta{
+				description: m.Description,
+				views:       sql.NullInt64{Int64: int64(m.Stats.Views), Valid: true},
+			}
+		}
+
+		for _, d := range batch {
+			networkID := deviantArtNetworkID(d.URL)
+			if networkID == "" || exclusionMap[networkID] {
+				continue
+			}
+
+			createdAt := time.Now()
+			if ts, err := strconv.ParseInt(d.PublishedTime, 10, 64); err == nil {
+				createdAt = time.Unix(ts, 0)
+			}
+
+			meta := metaByID[d.DeviationID]
+
+			postID, err := common.ProcessScrapedPost(
+				ctx,
+				dbQueries,
+				sourceId,
+				networkID,
+				"DeviantArt",
+				createdAt,
+				"image",
+				d.Author.Username,
+				fmt.Sprintf("%s\n\n%s", d.Title, common.StripHTMLToText(meta.description)),
+				sql.NullInt64{Int64: int64(d.Stats.Favourites), Valid: true},
+				sql.NullInt64{},
+				meta.views,
+			)
+			if err != nil {
+				log.Printf("DeviantArt: Failed to save deviation %s: %v", networkID, err)
+				continue
+			}
+
+			if err := common.SavePostComments(ctx, dbQueries, postID, d.Stats.Comments); err != nil {
+				log.Printf("DeviantArt: Failed to save comment count for deviation %s: %v", networkID, err)
+			}
+		}
+	}
+
+	watchers := profile.User.Stats.Watchers
+	friends := profile.User.Stats.Friends
+	err = common.UpdateSourceStats(ctx, dbQueries, sourceId, func(stats *common.ProfileStats) {
+		stats.FollowersCount = &watchers
+		stats.FollowingCount = &friends
+	})
+	if err != nil {
+		log.Printf("DeviantArt: Failed to save stats for source %s: %v", sourceId, err)
+	}
+
+	return nil
+}
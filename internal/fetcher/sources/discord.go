@@ -112,12 +112,14 @@ func FetchDiscordPosts(dbQueries *database.Queries, encryptionKey []byte, source
 	}
 
 	guild, err := session.GuildWithCounts(serverID)
-	var memberCount *int
+	var memberCount, onlineCount *int
 	if err != nil {
 		log.Printf("Discord: Failed to get server info: %v", err)
 	} else {
 		count := guild.ApproximateMemberCount
 		memberCount = &count
+		online := guild.ApproximatePresenceCount
+		onlineCount = &online
 	}
 
 	processedMessages := make(map[string]struct{})
@@ -194,10 +196,7 @@ func FetchDiscordPosts(dbQueries *database.Queries, encryptionKey []byte, source
 					continue
 				}
 
-				totalReactions := 0
-				for _, reaction := range msg.Reactions {
-					totalReactions += reaction.Count
-				}
+				breakdown, totalReactions := discordReactionBreakdown(msg.Reactions)
 
 				postID, err := common.CreateOrUpdatePost(
 					ctx,
@@ -215,6 +214,10 @@ func FetchDiscordPosts(dbQueries *database.Queries, encryptionKey []byte, source
 					continue
 				}
 
+				if err := common.SaveReactionBreakdown(ctx, dbQueries, postID, breakdown); err != nil {
+					log.Printf("Discord: Failed to sync reaction breakdown for message %s: %v", msgID, err)
+				}
+
 				_, err = dbQueries.SyncReactions(ctx, database.SyncReactionsParams{
 					ID:       uuid.New(),
 					PostID:   postID,
@@ -236,10 +239,6 @@ func FetchDiscordPosts(dbQueries *database.Queries, encryptionKey []byte, source
 
 	}
 
-	if len(processedMessages) == 0 {
-		return errors.New("No messages found in any configured channels")
-	}
-
 	stats, err := common.CalculateAverageStats(context.Background(), dbQueries, sourceId)
 	if err != nil {
 		log.Printf("Discord: Failed to calculate stats: %v", err)
@@ -248,12 +247,37 @@ func FetchDiscordPosts(dbQueries *database.Queries, encryptionKey []byte, source
 
 		if err := common.SaveOrUpdateSourceStats(context.Background(), dbQueries, sourceId, stats); err != nil {
 			log.Printf("Discord: Failed to save stats: %v", err)
+		} else if onlineCount != nil {
+			err := dbQueries.UpdateSourceOnlineCount(ctx, database.UpdateSourceOnlineCountParams{
+				OnlineCount: sql.NullInt64{Int64: int64(*onlineCount), Valid: true},
+				SourceID:    sourceId,
+				Date:        time.Now().UTC().Truncate(24 * time.Hour),
+			})
+			if err != nil {
+				log.Printf("Discord: Failed to save online member count: %v", err)
+			}
 		}
 	}
 
+	if len(processedMessages) == 0 {
+		return errors.New("No messages found in any configured channels")
+	}
+
 	return nil
 }
 
+func discordReactionBreakdown(reactions []*discordgo.MessageReactions) (map[string]int, int) {
+	breakdown := make(map[string]int, len(reactions))
+	total := 0
+	for _, reaction := range reactions {
+		total += reaction.Count
+		if reaction.Emoji != nil {
+			breakdown[reaction.Emoji.APIName()] += reaction.Count
+		}
+	}
+	return breakdown, total
+}
+
 func processForumThread(
 	ctx context.Context,
 	dbQueries *database.Queries,
@@ -311,12 +335,23 @@ func processForumThread(
 	if err != nil {
 		log.Printf("Discord: Failed to fetch first message for thread %s: %v", threadID, err)
 	} else if len(messages) > 0 {
-		firstMsg := messages[0]
-		for _, reaction := range firstMsg.Reactions {
-			totalReactions += reaction.Count
+		var breakdown map[string]int
+		breakdown, totalReactions = discordReactionBreakdown(messages[0].Reactions)
+		if err := common.SaveReactionBreakdown(ctx, dbQueries, postID, breakdown); err != nil {
+			log.Printf("Discord: Failed to sync reaction breakdown for thread %s: %v", threadID, err)
 		}
 	}
 
+	err = dbQueries.SyncPostAnalytics(ctx, database.SyncPostAnalyticsParams{
+		ID:       uuid.New(),
+		Date:     time.Now().UTC().Truncate(24 * time.Hour),
+		PostID:   postID,
+		Comments: sql.NullInt64{Int64: int64(thread.MessageCount), Valid: true},
+	})
+	if err != nil {
+		log.Printf("Discord: Failed to sync reply count for thread %s: %v", threadID, err)
+	}
+
 	_, err = dbQueries.SyncReactions(ctx, database.SyncReactionsParams{
 		ID:       uuid.New(),
 		PostID:   postID,
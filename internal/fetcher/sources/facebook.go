@@ -0,0 +1,249 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+const facebookPageInsightMetrics = "page_impressions,page_views_total,page_daily_follows_unique,page_daily_unfollows_unique"
+
+type facebookPage struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	AccessToken    string `json:"access_token"`
+	FollowersCount int    `json:"followers_count"`
+	FanCount       int    `json:"fan_count"`
+}
+
+type facebookSummary struct {
+	Summary struct {
+		TotalCount int64 `json:"total_count"`
+	} `json:"summary"`
+}
+
+type facebookPostsFeed struct {
+	Data []struct {
+		ID          string          `json:"id"`
+		Message     string          `json:"message"`
+		CreatedTime string          `json:"created_time"`
+		StatusType  string          `json:"status_type"`
+		Reactions   facebookSummary `json:"reactions"`
+		Comments    facebookSummary `json:"comments"`
+		Shares      struct {
+			Count int64 `json:"count"`
+		} `json:"shares"`
+		Insights instagramInsightsResponse `json:"insights"`
+	} `json:"data"`
+	Paging struct {
+		Next string `json:"next,omitempty"`
+	} `json:"paging"`
+}
+
+type facebookPageInsights struct {
+	Data []struct {
+		Name   string `json:"name"`
+		Values []struct {
+			Value   int64  `json:"value"`
+			EndTime string `json:"end_time"`
+		} `json:"values"`
+	} `json:"data"`
+}
+
+func facebookPostType(statusType string) string {
+	switch statusType {
+	case "added_photos":
+		return "image"
+	case "added_video":
+		return "video"
+	case "shared_story":
+		return "link"
+	default:
+		return "text"
+	}
+}
+
+func fetchFacebookPageInsights(c *common.Client, pageToken, pageID, version string) (map[time.Time]map[string]int64, error) {
+	now := time.Now()
+	params := url.Values{
+		"metric":       {facebookPageInsightMetrics},
+		"period":       {"day"},
+		"since":        {strconv.FormatInt(now.AddDate(0, 0, -28).Unix(), 10)},
+		"until":        {strconv.FormatInt(now.Unix(), 10)},
+		"access_token": {pageToken},
+	}
+
+	var insights facebookPageInsights
+	if err := fetchMetaJSON(c, fmt.Sprintf("https://graph.facebook.com/%s/%s/insights?%s", version, pageID, params.Encode()), &insights); err != nil {
+		return nil, err
+	}
+
+	days := make(map[time.Time]map[string]int64)
+	for _, metric := range insights.Data {
+		for _, value := range metric.Values {
+			endTime, err := time.Parse("2006-01-02T15:04:05-0700", value.EndTime)
+			if err != nil {
+				continue
+			}
+			// Values are reported at the end of the day they cover
+			date := endTime.UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour)
+			if days[date] == nil {
+				days[date] = make(map[string]int64)
+			}
+			days[date][metric.Name] = value.Value
+		}
+	}
+
+	return days, nil
+}
+
+func FetchFacebookPagePosts(dbQueries *database.Queries, c *common.Client, sourceId uuid.UUID, version string, encryptionKey []byte) error {
+
+	ctx := context.Background()
+
+	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
+	if err != nil {
+		return err
+	}
+
+	userToken, pageID, _, _, err := authhelp.GetSourceToken(ctx, dbQueries, encryptionKey, sourceId)
+	if err != nil {
+		return fmt.Errorf("Facebook: %w", err)
+	}
+
+	var page facebookPage
+	pageParams := url.Values{
+		"fields":       {"id,name,access_token,followers_count,fan_count"},
+		"access_token": {userToken},
+	}
+	if err := fetchMetaJSON(c, fmt.Sprintf("https://graph.facebook.com/%s/%s?%s", version, pageID, pageParams.Encode()), &page); err != nil {
+		return fmt.Errorf("Facebook: failed to get page: %w", err)
+	}
+
+	if page.AccessToken == "" {
+		return fmt.Errorf("Facebook: no page access token returned, check that the app has access to page %s", pageID)
+	}
+
+	postParams := url.Values{
+		"fields":       {"id,message,created_time,status_type,reactions.summary(total_count).limit(0),comments.summary(total_count).limit(0),shares,insights.metric(post_impressions,post_impressions_unique)"},
+		"limit":        {"50"},
+		"access_token": {page.AccessToken},
+	}
+
+	const maxPages = 200
+
+	next := fmt.Sprintf("https://graph.facebook.com/%s/%s/posts?%s", version, pageID, postParams.Encode())
+	for p := 0; p < maxPages && next != ""; p++ {
+		var feed facebookPostsFeed
+		if err := fetchMetaJSON(c, next, &feed); err != nil {
+			return fmt.Errorf("Facebook: failed to get posts: %w", err)
+		}
+
+		for _, item := range feed.Data {
+			if exclusionMap[item.ID] {
+				continue
+			}
+
+			createdAt, _ := time.Parse("2006-01-02T15:04:05-0700", item.CreatedTime)
+
+			insights := make(map[string]int64)
+			for _, metric := range item.Insights.Data {
+				if len(metric.Values) != 0 {
+					insights[metric.Name] = metric.Values[0].Value
+				}
+			}
+
+			var views sql.NullInt64
+			if impressions, ok := insights["post_impressions"]; ok {
+				views = sql.NullInt64{Int64: impressions, Valid: true}
+			}
+
+			postID, err := common.ProcessScrapedPost(
+				ctx,
+				dbQueries,
+				sourceId,
+				item.ID,
+				"Facebook",
+				createdAt,
+				facebookPostType(item.StatusType),
+				page.Name,
+				item.Message,
+				sql.NullInt64{Int64: item.Reactions.Summary.TotalCount, Valid: true},
+				sql.NullInt64{Int64: item.Shares.Count, Valid: true},
+				views,
+			)
+			if err != nil {
+				log.Printf("Facebook: Failed to save post %s: %v", item.ID, err)
+				continue
+			}
+
+			metric := func(name string) sql.NullInt64 {
+				v, ok := insights[name]
+				return sql.NullInt64{Int64: v, Valid: ok}
+			}
+
+			err = dbQueries.SyncPostAnalytics(ctx, database.SyncPostAnalyticsParams{
+				ID:          uuid.New(),
+				Date:        time.Now().UTC().Truncate(24 * time.Hour),
+				PostID:      postID,
+				Views:       views,
+				Impressions: metric("post_impressions"),
+				Reach:       metric("post_impressions_unique"),
+				Shares:      sql.NullInt64{Int64: item.Shares.Count, Valid: true},
+				Comments:    sql.NullInt64{Int64: item.Comments.Summary.TotalCount, Valid: true},
+			})
+			if err != nil {
+				log.Printf("Facebook: Failed to save insights for post %s: %v", item.ID, err)
+			}
+		}
+
+		next = feed.Paging.Next
+	}
+
+	days, err := fetchFacebookPageInsights(c, page.AccessToken, pageID, version)
+	if err != nil {
+		log.Printf("Facebook: Failed to get page insights for source %s: %v", sourceId, err)
+	}
+	for date, values := range days {
+		metric := func(name string) sql.NullInt64 {
+			v, ok := values[name]
+			return sql.NullInt64{Int64: v, Valid: ok}
+		}
+
+		err = dbQueries.SyncSourceAnalytics(ctx, database.SyncSourceAnalyticsParams{
+			ID:                uuid.New(),
+			Date:              date,
+			SourceID:          sourceId,
+			Views:             metric("page_views_total"),
+			Impressions:       metric("page_impressions"),
+			SubscribersGained: metric("page_daily_follows_unique"),
+			SubscribersLost:   metric("page_daily_unfollows_unique"),
+		})
+		if err != nil {
+			log.Printf("Facebook: Failed to save page insights for %s: %v", date.Format("2006-01-02"), err)
+		}
+	}
+
+	followers := page.FollowersCount
+	if followers == 0 {
+		followers = page.FanCount
+	}
+	err = common.UpdateSourceStats(ctx, dbQueries, sourceId, func(stats *common.ProfileStats) {
+		stats.FollowersCount = &followers
+	})
+	if err != nil {
+		log.Printf("Facebook: Failed to save stats for source %s: %v", sourceId, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+const furAffinityMaxGalleryPages = 50
+
+func fetchFurAffinityDoc(c *common.Client, pageURL string) (*goquery.Document, error) {
+	req, err := http.NewRequest("GET", pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set(
+		"User-Agent",
+		"Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)",
+	)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Failed to get a successfull response. %v: %v", resp.StatusCode, resp.Status)
+	}
+
+	return goquery.NewDocumentFromReader(resp.Body)
+}
+
+func FetchFurAffinityPosts(dbQueries *database.Queries, c *common.Client, uid uuid.UUID, sourceId uuid.UUID) error {
+
+	source, err := dbQueries.GetSourceById(context.Background(), sourceId)
+	if err != nil {
+		return fmt.Errorf("failed to get source: %w", err)
+	}
+
+	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
+	if err != nil {
+		return err
+	}
+
+	username := strings.ToLower(source.UserName)
+
+	profileDoc, err := fetchFurAffinityDoc(c, fmt.Sprintf("https://www.furaffinity.net/user/%s/", username))
+	if err != nil {
+		return fmt.Errorf("FurAffinity: failed to load profile: %w", err)
+	}
+
+	profileText := profileDoc.Text()
+	if strings.Contains(profileText, "This user cannot be found") {
+		return fmt.Errorf("FurAffinity: user %s not found", username)
+	}
+
+	var watchersCount, watchingCount *int
+	if count, err := extractMurrNumber(profileText, `Watched by\s*\(?([\d,]+)`); err == nil {
+		watchersCount = &count
+	}
+	if count, err := extractMurrNumber(profileText, `Watching\s*\(?([\d,]+)`); err == nil {
+		watchingCount = &count
+	}
+
+	processed := make(map[string]struct{})
+
+	for page := 1; page <= furAffinityMaxGalleryPages; page++ {
+		galleryDoc, err := fetchFurAffinityDoc(c, fmt.Sprintf("https://www.furaffinity.net/gallery/%s/%d/", username, page))
+		if err != nil {
+			return fmt.Errorf("FurAffinity: failed to load gallery page %d: %w", page, err)
+		}
+
+		var ids []string
+		galleryDoc.Find(`figure[id^="sid-"]`).Each(func(_ int, s *goquery.Selection) {
+			id, _ := s.Attr("id")
+			id = strings.TrimPrefix(id, "sid-")
+			if _, exists := processed[id]; exists || id == "" {
+				return
+			}
+			processed[id] = struct{}{}
+			ids = append(ids, id)
+		})
+
+		if len(ids) == 0 {
+			break
+		}
+
+		for _, id := range ids {
+			if exclusionMap[id] {
+				continue
+			}
+
+			if err := syncFurAffinitySubmission(dbQueries, c, sourceId, username, id); err != nil {
+				log.Printf("FurAffinity: Failed to sync submission %s: %v", id, err)
+			}
+
+			time.Sleep(300 * time.Millisecond)
+		}
+
+		time.Sleep(300 * time.Millisecond)
+	}
+
+	if len(processed) == 0 {
+		return errors.New("No content found")
+	}
+
+	err = common.UpdateSourceStats(context.Background(), dbQueries, sourceId, func(stats *common.ProfileStats) {
+		stats.FollowersCount = watchersCount
+		stats.FollowingCount = watchingCount
+	})
+	if err != nil {
+		log.Printf("FurAffinity: Failed to save stats for source %s: %v", sourceId, err)
+	}
+
+	return nil
+}
+
+func syncFurAffinitySubmission(dbQueries *database.Queries, c *common.Client, sourceId uuid.UUID, username, id string) error {
+	doc, err := fetchFurAffinityDoc(c, fmt.Sprintf("https://www.furaffinity.net/view/%s/", id))
+	if err != nil {
+		return err
+	}
+
+	title := strings.TrimSpace(doc.Find(".submission-title p").First().Text())
+	if title == "" {
+		title, _ = doc.Find(`meta[property="og:title"]`).Attr("content")
+	}
+	description := strings.TrimSpace(doc.Find(".submission-description").First().Text())
+
+	createdAt := time.Now()
+	dateSpan := doc.Find("span.popup_date").First()
+	for _, raw := range []string{dateSpan.AttrOr("title", ""), strings.TrimSpace(dateSpan.Text())} {
+		if t, err := time.Parse("Jan 2, 2006 03:04 PM", raw); err == nil {
+			createdAt = t
+			break
+		}
+	}
+
+	views := furAffinityStat(doc, "views")
+	faves := furAffinityStat(doc, "favorites")
+	comments := furAffinityStat(doc, "comments")
+
+	postID, err := common.ProcessScrapedPost(
+		context.Background(),
+		dbQueries,
+		sourceId,
+		id,
+		"FurAffinity",
+		createdAt,
+		"image",
+		username,
+		strings.TrimSpace(fmt.Sprintf("%s\n\n%s", title, description)),
+		faves,
+		sql.NullInt64{},
+		views,
+	)
+	if err != nil {
+		return err
+	}
+
+	if comments.Valid {
+		if err := common.SavePostComments(context.Background(), dbQueries, postID, int(comments.Int64)); err != nil {
+			log.Printf("FurAffinity: Failed to save comment count for submission %s: %v", id, err)
+		}
+	}
+
+	return nil
+}
+
+func furAffinityStat(doc *goquery.Document, class string) sql.NullInt64 {
+	raw := strings.TrimSpace(doc.Find(".stats-container ." + class + " .font-large").First().Text())
+	value, err := strconv.Atoi(strings.ReplaceAll(raw, ",", ""))
+	if err != nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(value), Valid: true}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/fluffyriot/rpsync/internal/authhelp"
@@ -67,6 +68,10 @@ func FetchGoogleAnalyticsStatsWithRange(dbQueries *database.Queries, sourceID uu
 		return fmt.Errorf("failed to fetch page stats: %w", err)
 	}
 
+	if err := fetchAndSaveTrafficSources(ctx, client, dbQueries, sourceID, propertyID, startDate, endDate); err != nil {
+		return fmt.Errorf("failed to fetch traffic sources: %w", err)
+	}
+
 	return nil
 }
 
@@ -200,3 +205,67 @@ func fetchAndSavePageStats(ctx context.Context, svc *analyticsdata.Service, db *
 	}
 	return nil
 }
+
+func fetchAndSaveTrafficSources(ctx context.Context, svc *analyticsdata.Service, db *database.Queries, sourceID uuid.UUID, propertyID, startDate, endDate string) error {
+	req := &analyticsdata.RunReportRequest{
+		Property: "properties/" + propertyID,
+		DateRanges: []*analyticsdata.DateRange{
+			{StartDate: startDate, EndDate: endDate},
+		},
+		Dimensions: []*analyticsdata.Dimension{
+			{Name: "date"},
+			{Name: "sessionSource"},
+			{Name: "sessionMedium"},
+			{Name: "sessionCampaignName"},
+		},
+		Metrics: []*analyticsdata.Metric{
+			{Name: "sessions"},
+		},
+		DimensionFilter: &analyticsdata.FilterExpression{
+			NotExpression: &analyticsdata.FilterExpression{
+				Filter: &analyticsdata.Filter{
+					FieldName: "sessionMedium",
+					InListFilter: &analyticsdata.InListFilter{
+						Values: []string{"(none)", "organic"},
+					},
+				},
+			},
+		},
+	}
+
+	resp, err := svc.Properties.RunReport(req.Property, req).Do()
+	if err != nil {
+		return err
+	}
+
+	for _, row := range resp.Rows {
+		if len(row.DimensionValues) < 4 || len(row.MetricValues) < 1 {
+			continue
+		}
+
+		dateStr := row.DimensionValues[0].Value
+
+		parsedDate, err := time.Parse("20060102", dateStr)
+		if err != nil {
+			log.Printf("Error parsing date %s: %v", dateStr, err)
+			continue
+		}
+
+		var sessionsInt int
+		fmt.Sscanf(row.MetricValues[0].Value, "%d", &sessionsInt)
+
+		_, err = db.CreateAnalyticsTrafficSource(ctx, database.CreateAnalyticsTrafficSourceParams{
+			ID:                  uuid.New(),
+			Date:                parsedDate,
+			SessionSource:       strings.ToLower(row.DimensionValues[1].Value),
+			SessionMedium:       strings.ToLower(row.DimensionValues[2].Value),
+			SessionCampaignName: row.DimensionValues[3].Value,
+			Sessions:            sessionsInt,
+			SourceID:            sourceID,
+		})
+		if err != nil {
+			log.Printf("Error saving traffic source for %s: %v", dateStr, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+const inkbunnyBatchSize = 100
+
+type inkbunnyInt int
+
+func (n *inkbunnyInt) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(string(bytes.TrimSpace(data)), `"`)
+	if raw == "" || raw == "null" {
+		*n = 0
+		return nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return err
+	}
+	*n = inkbunnyInt(value)
+	return nil
+}
+
+type inkbunnyResponse struct {
+	Error     inkbunnyInt `json:"error_code"`
+	ErrorText string      `json:"error_message"`
+}
+
+type inkbunnyLoginResponse struct {
+	inkbunnyResponse
+	SID string `json:"sid"`
+}
+
+type inkbunnySearchResponse struct {
+	inkbunnyResponse
+	SID         string      `json:"sid"`
+	RID         string      `json:"rid"`
+	PagesCount  inkbunnyInt `json:"pages_count"`
+	Submissions []struct {
+		SubmissionID string `json:"submission_id"`
+		UserID       string `json:"user_id"`
+	} `json:"submissions"`
+}
+
+type inkbunnySubmissionsResponse struct {
+	inkbunnyResponse
+	Submissions []struct {
+		SubmissionID   string      `json:"submission_id"`
+		Title          string      `json:"title"`
+		Description    string      `json:"description"`
+		Username       string      `json:"username"`
+		CreateDatetime string      `json:"create_datetime"`
+		TypeName       string      `json:"type_name"`
+		Views          inkbunnyInt `json:"views"`
+		FavoritesCount inkbunnyInt `json:"favorites_count"`
+		CommentsCount  inkbunnyInt `json:"comments_count"`
+	} `json:"submissions"`
+}
+
+type inkbunnyWatchlistResponse struct {
+	inkbunnyResponse
+	Watches []struct {
+		UserID string `json:"user_id"`
+	} `json:"watches"`
+}
+
+func fetchInkbunnyJSON(c *common.Client, endpoint string, params url.Values, out any) error {
+	req, err := http.NewRequest("GET", "https://inkbunny.net/"+endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed to get a successfull response. %v: %v", resp.StatusCode, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var apiErr inkbunnyResponse
+	if err := json.Unmarshal(data, &apiErr); err == nil && apiErr.ErrorText != "" {
+		return fmt.Errorf("inkbunny error %d: %s", apiErr.Error, apiErr.ErrorText)
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+func FetchInkbunnyPosts(dbQueries *database.Queries, c *common.Client, uid uuid.UUID, sourceId uuid.UUID) error {
+
+	source, err := dbQueries.GetSourceById(context.Background(), sourceId)
+	if err != nil {
+		return fmt.Errorf("failed to get source: %w", err)
+	}
+
+	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
+	if err != nil {
+		return err
+	}
+
+	var login inkbunnyLoginResponse
+	if err := fetchInkbunnyJSON(c, "api_login.php", url.Values{"username": {"guest"}}, &login); err != nil {
+		return fmt.Errorf("Inkbunny: failed to start guest session: %w", err)
+	}
+
+	var submissionIDs []string
+	var inkbunnyUserID string
+	rid := ""
+	for page := 1; ; page++ {
+		params := url.Values{
+			"sid":                  {login.SID},
+			"submissions_per_page": {strconv.Itoa(inkbunnyBatchSize)},
+			"page":                 {strconv.Itoa(page)},
+		}
+		if rid != "" {
+			params.Set("rid", rid)
+		} else {
+			params.Set("username", source.UserName)
+			params.Set("get_rid", "yes")
+		}
+
+		var search inkbunnySearchResponse
+		if err := fetchInkbunnyJSON(c, "api_search.php", params, &search); err != nil {
+			return fmt.Errorf("Inkbunny: failed to search submissions: %w", err)
+		}
+		rid = search.RID
+
+		for _, s := range search.Submissions {
+			submissionIDs = append(submissionIDs, s.SubmissionID)
+			inkbunnyUserID = s.UserID
+		}
+
+		if page >= int(search.PagesCount) || len(search.Submissions) == 0 || rid == "" {
+			break
+		}
+	}
+
+	if len(submissionIDs) == 0 {
+		return errors.New("No content found")
+	}
+
+	for start := 0; start < len(submissionIDs); start += inkbunnyBatchSize {
+		end := min(start+inkbunnyBatchSize, len(submissionIDs))
+
+		var details inkbunnySubmissionsResponse
+		err := fetchInkbunnyJSON(c, "api_submissions.php", url.Values{
+			"sid":              {login.SID},
+			"submission_ids":   {strings.Join(submissionIDs[start:end], ",")},
+			"show_description": {"yes"},
+		}, &details)
+		if err != nil {
+			log.Printf("Inkbunny: Failed to get submission details: %v", err)
+			continue
+		}
+
+		for _, sub := range details.Submissions {
+			if exclusionMap[sub.SubmissionID] {
+				continue
+			}
+
+			createdAt, err := time.Parse("2006-01-02 15:04:05.999999-07", sub.CreateDatetime)
+			if err != nil {
+				createdAt = time.Now()
+			}
+
+			postType := strings.ToLower(sub.TypeName)
+			if postType == "" || strings.Contains(postType, "picture") {
+				postType = "image"
+			}
+
+			postID, err := common.ProcessScrapedPost(
+				context.Background(),
+				dbQueries,
+				sourceId,
+				sub.SubmissionID,
+				"Inkbunny",
+				createdAt,
+				postType,
+				sub.Username,
+				fmt.Sprintf("%s\n\n%s", sub.Title, sub.Description),
+				sql.NullInt64{Int64: int64(sub.FavoritesCount), Valid: true},
+				sql.NullInt64{},
+				sql.NullInt64{Int64: int64(sub.Views), Valid: true},
+			)
+			if err != nil {
+				log.Printf("Inkbunny: Failed to save submission %s: %v", sub.SubmissionID, err)
+				continue
+			}
+
+			if err := common.SavePostComments(context.Background(), dbQueries, postID, int(sub.CommentsCount)); err != nil {
+				log.Printf("Inkbunny: Failed to save comment count for submission %s: %v", sub.SubmissionID, err)
+			}
+		}
+	}
+
+	var followingCount *int
+	if inkbunnyUserID != "" {
+		var watchlist inkbunnyWatchlistResponse
+		err := fetchInkbunnyJSON(c, "api_watchlist.php", url.Values{
+			"sid":     {login.SID},
+			"user_id": {inkbunnyUserID},
+		}, &watchlist)
+		if err != nil {
+			log.Printf("Inkbunny: Failed to get watchlist for source %s: %v", sourceId, err)
+		} else {
+			count := len(watchlist.Watches)
+			followingCount = &count
+		}
+	}
+
+	err = common.UpdateSourceStats(context.Background(), dbQueries, sourceId, func(stats *common.ProfileStats) {
+		stats.FollowingCount = followingCount
+	})
+	if err != nil {
+		log.Printf("Inkbunny: Failed to save stats for source %s: %v", sourceId, err)
+	}
+
+	return nil
+}
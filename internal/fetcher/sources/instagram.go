@@ -23,14 +23,15 @@ import (
 
 type instagramFeed struct {
 	Data []struct {
-		ID        string `json:"id"`
-		Caption   string `json:"caption"`
-		Shortcode string `json:"shortcode"`
-		LikeCount int    `json:"like_count"`
-		Timestamp string `json:"timestamp"`
-		MediaType string `json:"media_type"`
-		Username  string `json:"username"`
-		Insights  struct {
+		ID               string `json:"id"`
+		Caption          string `json:"caption"`
+		Shortcode        string `json:"shortcode"`
+		LikeCount        int    `json:"like_count"`
+		Timestamp        string `json:"timestamp"`
+		MediaType        string `json:"media_type"`
+		MediaProductType string `json:"media_product_type"`
+		Username         string `json:"username"`
+		Insights         struct {
 			Data []struct {
 				Values []struct {
 					Value int `json:"value"`
@@ -59,8 +60,9 @@ type instagramTagsFeed struct {
 }
 
 type instagramProfile struct {
-	FollowsCount   int `json:"follows_count"`
-	FollowersCount int `json:"followers_count"`
+	Username       string `json:"username"`
+	FollowsCount   int    `json:"follows_count"`
+	FollowersCount int    `json:"followers_count"`
 }
 
 func getInstagramApiString(dbQueries *database.Queries, sid uuid.UUID, next string, version string, encryptionKey []byte) (string, string, string, string, error) {
@@ -70,7 +72,7 @@ func getInstagramApiString(dbQueries *database.Queries, sid uuid.UUID, next stri
 		return "", "", "", "", err
 	}
 
-	apiString := fmt.Sprintf("https://graph.facebook.com/%v/%v/media?fields=id,caption,shortcode,like_count,timestamp,media_type,username,insights.metric(views)&access_token=%v&limit=25", version, pid, token)
+	apiString := fmt.Sprintf("https://graph.facebook.com/%v/%v/media?fields=id,caption,shortcode,like_count,timestamp,media_type,media_product_type,username,insights.metric(views)&access_token=%v&limit=25", version, pid, token)
 
 	if next != "" {
 		apiString = next
@@ -98,7 +100,7 @@ func getInstagramTagstring(dbQueries *database.Queries, sid uuid.UUID, next stri
 }
 
 func fetchInstagramProfile(token, pid, version string, c *common.Client) (*instagramProfile, error) {
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s?fields=follows_count,followers_count&access_token=%s", version, pid, token)
+	url := fmt.Sprintf("https://graph.facebook.com/%s/%s?fields=username,follows_count,followers_count&access_token=%s", version, pid, token)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -128,6 +130,24 @@ func fetchInstagramProfile(token, pid, version string, c *common.Client) (*insta
 	return &profile, nil
 }
 
+// findInstagramPost returns the stored post with the shortcode. Posts imported
+// from an archive have no shortcode yet, so one made at the same moment is
+// claimed instead.
+func findInstagramPost(ctx context.Context, dbQueries *database.Queries, sourceId uuid.UUID, shortcode string, createdAt time.Time) (database.Post, error) {
+	post, err := dbQueries.GetPostByNetworkAndId(ctx, database.GetPostByNetworkAndIdParams{
+		NetworkInternalID: shortcode,
+		Network:           "Instagram",
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return dbQueries.ClaimImportedPost(ctx, database.ClaimImportedPostParams{
+			NetworkInternalID: shortcode,
+			SourceID:          sourceId,
+			CreatedAt:         createdAt,
+		})
+	}
+	return post, err
+}
+
 func FetchInstagramPosts(dbQueries *database.Queries, c *common.Client, sourceId uuid.UUID, version string, encryptionKey []byte) error {
 
 	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
@@ -192,11 +212,7 @@ func FetchInstagramPosts(dbQueries *database.Queries, c *common.Client, sourceId
 			}
 
 			timeParse, _ := time.Parse("2006-01-02T15:04:05-0700", item.Timestamp)
-			post_type := strings.ToLower(item.MediaType)
-
-			if item.MediaType == "CAROUSEL_ALBUM" {
-				post_type = "image"
-			}
+			post_type := instagramPostType(item.MediaType, item.MediaProductType)
 
 			views := 0
 			if len(item.Insights.Data) != 0 {
@@ -206,8 +222,16 @@ func FetchInstagramPosts(dbQueries *database.Queries, c *common.Client, sourceId
 				}
 			}
 
-			err = common.ProcessScrapedPost(
-				context.Background(), dbQueries, sourceId, item.Shortcode, "Instagram", timeParse, post_type, item.Username, item.Caption,
+			storedType := post_type
+			stored, err := findInstagramPost(context.Background(), dbQueries, sourceId, item.Shortcode, timeParse)
+			if err == nil {
+				storedType = instagramStoredPostType(stored.PostType, post_type)
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			postID, err := common.ProcessScrapedPost(
+				context.Background(), dbQueries, sourceId, item.Shortcode, "Instagram", timeParse, storedType, item.Username, item.Caption,
 				sql.NullInt64{Int64: int64(item.LikeCount), Valid: true},
 				sql.NullInt64{Valid: false},
 				sql.NullInt64{Int64: int64(views), Valid: true},
@@ -215,6 +239,17 @@ func FetchInstagramPosts(dbQueries *database.Queries, c *common.Client, sourceId
 			if err != nil {
 				return err
 			}
+
+			if metrics, ok := instagramInsightMetrics[post_type]; ok {
+				insights, err := fetchInstagramMediaInsights(token, ver, item.ID, metrics, c)
+				if err != nil {
+					log.Printf("Instagram: Failed to fetch insights for %s: %v", item.Shortcode, err)
+				} else if err := saveInstagramInsights(context.Background(), dbQueries, postID, insights); err != nil {
+					log.Printf("Instagram: Failed to save insights for %s: %v", item.Shortcode, err)
+				}
+
+				time.Sleep(300 * time.Millisecond)
+			}
 		}
 
 		if feed.Paging.Next == "" {
@@ -230,11 +265,21 @@ func FetchInstagramPosts(dbQueries *database.Queries, c *common.Client, sourceId
 		return errors.New("No content found")
 	}
 
-	if err := common.UpdateSourceStats(context.Background(), dbQueries, sourceId, func(s *common.ProfileStats) {
-		profile, err := fetchInstagramProfile(token, pid, ver, c)
+	profile, profileErr := fetchInstagramProfile(token, pid, ver, c)
+	if profileErr != nil {
+		log.Printf("Instagram: Failed to fetch profile for source %s: %v", sourceId, profileErr)
+	} else {
+		source, err := dbQueries.GetSourceById(context.Background(), sourceId)
 		if err != nil {
-			log.Printf("Instagram: Failed to fetch profile for source %s: %v", sourceId, err)
-		} else {
+			return err
+		}
+		if _, err := common.SyncSourceAccount(context.Background(), dbQueries, source, pid, source.UserName, profile.Username); err != nil {
+			return err
+		}
+	}
+
+	if err := common.UpdateSourceStats(context.Background(), dbQueries, sourceId, func(s *common.ProfileStats) {
+		if profileErr == nil {
 			s.FollowersCount = &profile.FollowersCount
 			s.FollowingCount = &profile.FollowsCount
 		}
@@ -242,6 +287,15 @@ func FetchInstagramPosts(dbQueries *database.Queries, c *common.Client, sourceId
 		log.Printf("Instagram: Failed to update stats for source %s: %v", sourceId, err)
 	}
 
+	if !common.HasDemographicsSnapshotToday(context.Background(), dbQueries, sourceId) {
+		demographics, err := fetchInstagramDemographics(token, pid, ver, c)
+		if err != nil {
+			log.Printf("Instagram: Failed to fetch demographics for source %s: %v", sourceId, err)
+		} else if err := common.SaveDemographicsSnapshot(context.Background(), dbQueries, sourceId, demographics); err != nil {
+			log.Printf("Instagram: Failed to save demographics for source %s: %v", sourceId, err)
+		}
+	}
+
 	return nil
 
 }
@@ -312,7 +366,7 @@ func FetchInstagramTags(dbQueries *database.Queries, c *common.Client, sourceId
 
 			timeParse, _ := time.Parse("2006-01-02T15:04:05-0700", item.Timestamp)
 
-			err = common.ProcessScrapedPost(
+			_, err = common.ProcessScrapedPost(
 				context.Background(), dbQueries, sourceId, shortcode, "Instagram", timeParse, "tag", item.Username, item.Caption,
 				sql.NullInt64{Int64: int64(item.LikeCount), Valid: true},
 				sql.NullInt64{Valid: false},
@@ -0,0 +1,276 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+var instagramInsightMetrics = map[string]string{
+	"reel":     "reach,saved,shares,views",
+	"carousel": "reach,saved,shares,views,profile_visits",
+	"story":    "reach,shares,views,profile_visits",
+}
+
+type instagramStoriesFeed struct {
+	Data []struct {
+		ID               string `json:"id"`
+		Caption          string `json:"caption"`
+		Timestamp        string `json:"timestamp"`
+		MediaType        string `json:"media_type"`
+		MediaProductType string `json:"media_product_type"`
+		Username         string `json:"username"`
+	} `json:"data"`
+}
+
+type instagramInsightsResponse struct {
+	Data []struct {
+		Name   string `json:"name"`
+		Values []struct {
+			Value int64 `json:"value"`
+		} `json:"values"`
+		TotalValue struct {
+			Value int64 `json:"value"`
+		} `json:"total_value"`
+	} `json:"data"`
+}
+
+func instagramPostType(mediaType, mediaProductType string) string {
+	switch {
+	case mediaProductType == "STORY":
+		return "story"
+	case mediaProductType == "REELS":
+		return "reel"
+	case mediaType == "CAROUSEL_ALBUM":
+		return "carousel"
+	default:
+		return strings.ToLower(mediaType)
+	}
+}
+
+// instagramStoredPostType keeps the type a post was stored with. Only the
+// "image" and "video" types written before reels and carousels were told apart
+// are replaced, once, by the finer one.
+func instagramStoredPostType(stored, postType string) string {
+	if stored == "image" || stored == "video" {
+		return postType
+	}
+	return stored
+}
+
+func fetchInstagramMediaInsights(token, version, mediaID, metrics string, c *common.Client) (map[string]int64, error) {
+	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/insights?metric=%s&access_token=%s", version, mediaID, metrics, token)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to get insights: %v %v", resp.StatusCode, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var insights instagramInsightsResponse
+	if err := json.Unmarshal(data, &insights); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int64)
+	for _, metric := range insights.Data {
+		if len(metric.Values) != 0 {
+			result[metric.Name] = metric.Values[0].Value
+		} else {
+			result[metric.Name] = metric.TotalValue.Value
+		}
+	}
+
+	return result, nil
+}
+
+func saveInstagramInsights(ctx context.Context, dbQueries *database.Queries, postID uuid.UUID, insights map[string]int64) error {
+	metric := func(name string) sql.NullInt64 {
+		v, ok := insights[name]
+		return sql.NullInt64{Int64: v, Valid: ok}
+	}
+
+	now := time.Now().UTC()
+
+	return dbQueries.SyncPostAnalytics(ctx, database.SyncPostAnalyticsParams{
+		ID:            uuid.New(),
+		Date:          time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		PostID:        postID,
+		Views:         metric("views"),
+		Reach:         metric("reach"),
+		Saves:         metric("saved"),
+		Shares:        metric("shares"),
+		ProfileVisits: metric("profile_visits"),
+	})
+}
+
+func FetchInstagramStories(dbQueries *database.Queries, c *common.Client, sourceId uuid.UUID, version string, encryptionKey []byte) error {
+	ctx := context.Background()
+
+	token, pid, _, _, err := authhelp.GetSourceToken(ctx, dbQueries, encryptionKey, sourceId)
+	if err != nil {
+		return err
+	}
+
+	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("https://graph.facebook.com/%v/%v/stories?fields=id,caption,timestamp,media_type,media_product_type,username&access_token=%v", version, pid, token)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != 200 {
+		resp.Body.Close()
+		return fmt.Errorf("Failed to get stories. Code: %v. Status: %v", resp.StatusCode, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	var feed instagramStoriesFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return err
+	}
+
+	for _, item := range feed.Data {
+		networkID := "stories/" + item.ID
+
+		if exclusionMap[networkID] {
+			continue
+		}
+
+		timeParse, _ := time.Parse("2006-01-02T15:04:05-0700", item.Timestamp)
+
+		postID, err := common.CreateOrUpdatePost(ctx, dbQueries, sourceId, networkID, "Instagram", timeParse, "story", item.Username, item.Caption)
+		if err != nil {
+			return err
+		}
+
+		insights, err := fetchInstagramMediaInsights(token, version, item.ID, instagramInsightMetrics["story"], c)
+		if err != nil {
+			log.Printf("Instagram: Failed to fetch story insights for %s: %v", item.ID, err)
+			continue
+		}
+
+		_, err = dbQueries.SyncReactions(ctx, database.SyncReactionsParams{
+			ID:       uuid.New(),
+			SyncedAt: time.Now(),
+			PostID:   postID,
+			Views:    sql.NullInt64{Int64: insights["views"], Valid: true},
+		})
+		if err != nil {
+			log.Printf("Instagram: Failed to sync story reactions for %s: %v", item.ID, err)
+		}
+
+		if err := saveInstagramInsights(ctx, dbQueries, postID, insights); err != nil {
+			log.Printf("Instagram: Failed to save story insights for %s: %v", item.ID, err)
+		}
+
+		time.Sleep(300 * time.Millisecond)
+	}
+
+	return nil
+}
+
+type instagramDemographicsResponse struct {
+	Data []struct {
+		TotalValue struct {
+			Breakdowns []struct {
+				Results []struct {
+					DimensionValues []string `json:"dimension_values"`
+					Value           float64  `json:"value"`
+				} `json:"results"`
+			} `json:"breakdowns"`
+		} `json:"total_value"`
+	} `json:"data"`
+}
+
+func fetchInstagramDemographics(token, pid, version string, c *common.Client) ([]common.DemographicEntry, error) {
+	var entries []common.DemographicEntry
+
+	for _, breakdown := range []string{"age", "gender", "country", "city"} {
+		url := fmt.Sprintf("https://graph.facebook.com/%s/%s/insights?metric=follower_demographics&period=lifetime&metric_type=total_value&breakdown=%s&access_token=%s", version, pid, breakdown, token)
+
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != 200 {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to get %s demographics: %v %v", breakdown, resp.StatusCode, resp.Status)
+		}
+
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		var demographics instagramDemographicsResponse
+		if err := json.Unmarshal(data, &demographics); err != nil {
+			return nil, err
+		}
+
+		for _, metric := range demographics.Data {
+			for _, b := range metric.TotalValue.Breakdowns {
+				for _, r := range b.Results {
+					if len(r.DimensionValues) == 0 {
+						continue
+					}
+					entries = append(entries, common.DemographicEntry{
+						Dimension: breakdown,
+						Bucket:    r.DimensionValues[0],
+						Value:     r.Value,
+					})
+				}
+			}
+		}
+	}
+
+	return entries, nil
+}
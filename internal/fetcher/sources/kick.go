@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+type kickChannelResponse struct {
+	ID             int    `json:"id"`
+	Slug           string `json:"slug"`
+	FollowersCount int    `json:"followers_count"`
+	Livestream     *struct {
+		ID          int `json:"id"`
+		ViewerCount int `json:"viewer_count"`
+	} `json:"livestream"`
+}
+
+type kickVideo struct {
+	ID           int    `json:"id"`
+	SessionTitle string `json:"session_title"`
+	CreatedAt    string `json:"created_at"`
+	Duration     int64  `json:"duration"`
+	Views        int    `json:"views"`
+	Video        struct {
+		UUID  string `json:"uuid"`
+		Views int    `json:"views"`
+	} `json:"video"`
+}
+
+func fetchKickJSON(c *common.Client, endpoint string, out any) error {
+	req, err := http.NewRequest("GET", "https://kick.com/api/v2/"+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed to get a successfull response. %v: %v", resp.StatusCode, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+func FetchKickPosts(dbQueries *database.Queries, c *common.Client, uid uuid.UUID, sourceId uuid.UUID) error {
+
+	ctx := context.Background()
+
+	source, err := dbQueries.GetSourceById(ctx, sourceId)
+	if err != nil {
+		return fmt.Errorf("failed to get source: %w", err)
+	}
+
+	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
+	if err != nil {
+		return err
+	}
+
+	slug := url.PathEscape(source.UserName)
+
+	var channel kickChannelResponse
+	if err := fetchKickJSON(c, "channels/"+slug, &channel); err != nil {
+		return fmt.Errorf("Kick: failed to get channel: %w", err)
+	}
+
+	var videos []kickVideo
+	if err := fetchKickJSON(c, "channels/"+slug+"/videos", &videos); err != nil {
+		return fmt.Errorf("Kick: failed to get videos: %w", err)
+	}
+
+	for _, video := range videos {
+		networkID := video.Video.UUID
+		if networkID == "" || exclusionMap[networkID] {
+			continue
+		}
+
+		createdAt, err := time.Parse("2006-01-02 15:04:05", video.CreatedAt)
+		if err != nil {
+			createdAt = time.Now()
+		}
+
+		views := video.Video.Views
+		if views == 0 {
+			views = video.Views
+		}
+
+		_, err = common.ProcessScrapedPost(
+			ctx,
+			dbQueries,
+			sourceId,
+			networkID,
+			"Kick",
+			createdAt,
+			"stream",
+			channel.Slug,
+			video.SessionTitle,
+			sql.NullInt64{},
+			sql.NullInt64{},
+			sql.NullInt64{Int64: int64(views), Valid: true},
+		)
+		if err != nil {
+			log.Printf("Kick: Failed to save video %s: %v", networkID, err)
+			continue
+		}
+
+		streamID := strconv.Itoa(video.ID)
+		duration := time.Duration(video.Duration) * time.Millisecond
+
+		if err := common.SaveStreamStats(ctx, dbQueries, sourceId, "Kick", networkID, streamID, duration); err != nil {
+			log.Printf("Kick: Failed to save stream stats for video %s: %v", networkID, err)
+		}
+	}
+
+	followers := channel.FollowersCount
+	err = common.UpdateSourceStats(ctx, dbQueries, sourceId, func(stats *common.ProfileStats) {
+		stats.FollowersCount = &followers
+	})
+	if err != nil {
+		log.Printf("Kick: Failed to save stats for source %s: %v", sourceId, err)
+	}
+
+	return nil
+}
+
+func SampleKickViewers(dbQueries *database.Queries, c *common.Client, sourceId uuid.UUID) error {
+
+	source, err := dbQueries.GetSourceById(context.Background(), sourceId)
+	if err != nil {
+		return fmt.Errorf("failed to get source: %w", err)
+	}
+
+	var channel kickChannelResponse
+	if err := fetchKickJSON(c, "channels/"+url.PathEscape(source.UserName), &channel); err != nil {
+		return fmt.Errorf("Kick: failed to get channel: %w", err)
+	}
+
+	if channel.Livestream == nil {
+		return nil
+	}
+
+	return common.SaveStreamViewerSample(
+		context.Background(),
+		dbQueries,
+		sourceId,
+		strconv.Itoa(channel.Livestream.ID),
+		channel.Livestream.ViewerCount,
+	)
+}
@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+// Ko-fi memberships renew monthly, so a subscriber without a payment in this window has lapsed
+const kofiSubscriptionWindow = 31 * 24 * time.Hour
+
+var ErrKofiInvalidToken = errors.New("invalid Ko-fi verification token")
+
+type kofiWebhookPayload struct {
+	VerificationToken     string `json:"verification_token"`
+	MessageID             string `json:"message_id"`
+	Timestamp             string `json:"timestamp"`
+	Type                  string `json:"type"`
+	FromName              string `json:"from_name"`
+	Message               string `json:"message"`
+	Amount                string `json:"amount"`
+	Email                 string `json:"email"`
+	Currency              string `json:"currency"`
+	IsSubscriptionPayment bool   `json:"is_subscription_payment"`
+	TierName              string `json:"tier_name"`
+	KofiTransactionID     string `json:"kofi_transaction_id"`
+}
+
+func kofiNullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func HandleKofiWebhook(dbQueries *database.Queries, encryptionKey []byte, sourceId uuid.UUID, data string) error {
+
+	ctx := context.Background()
+
+	source, err := dbQueries.GetSourceById(ctx, sourceId)
+	if err != nil {
+		return fmt.Errorf("failed to get source: %w", err)
+	}
+
+	if source.Network != "Ko-fi" {
+		return fmt.Errorf("source %s is not a Ko-fi source", sourceId)
+	}
+
+	var payload kofiWebhookPayload
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		return fmt.Errorf("Ko-fi: failed to parse webhook payload: %w", err)
+	}
+
+	verificationToken, _, _, _, err := authhelp.GetSourceToken(ctx, dbQueries, encryptionKey, sourceId)
+	if err != nil {
+		return fmt.Errorf("Ko-fi: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(payload.VerificationToken), []byte(verificationToken)) != 1 {
+		return ErrKofiInvalidToken
+	}
+
+	event, err := kofiSupporterEvent(sourceId, payload)
+	if err != nil {
+		return err
+	}
+
+	return dbQueries.CreateSupporterEvent(ctx, event)
+}
+
+// kofiSupporterEvent turns a verified webhook payload into the event stored for it
+func kofiSupporterEvent(sourceId uuid.UUID, payload kofiWebhookPayload) (database.CreateSupporterEventParams, error) {
+	externalID := payload.KofiTransactionID
+	if externalID == "" {
+		externalID = payload.MessageID
+	}
+	if externalID == "" {
+		return database.CreateSupporterEventParams{}, fmt.Errorf("Ko-fi: webhook payload has no transaction id")
+	}
+
+	receivedAt, err := time.Parse(time.RFC3339, payload.Timestamp)
+	if err != nil {
+		receivedAt = time.Now()
+	}
+
+	// Supporters are counted by a hash of their email so that raw addresses never reach the database.
+	// Anonymous payments count as a supporter each.
+	identity := strings.ToLower(strings.TrimSpace(payload.Email))
+	if identity == "" {
+		identity = strings.ToLower(strings.TrimSpace(payload.FromName))
+	}
+	if identity == "" {
+		identity = "transaction:" + externalID
+	}
+	sum := sha256.Sum256([]byte(identity))
+
+	var amount sql.NullFloat64
+	if value, err := strconv.ParseFloat(payload.Amount, 64); err == nil {
+		amount = sql.NullFloat64{Float64: value, Valid: true}
+	}
+
+	return database.CreateSupporterEventParams{
+		ID:             uuid.New(),
+		SourceID:       sourceId,
+		ExternalID:     externalID,
+		ReceivedAt:     receivedAt,
+		EventType:      payload.Type,
+		SupporterKey:   hex.EncodeToString(sum[:]),
+		SupporterName:  kofiNullString(payload.FromName),
+		Amount:         amount,
+		Currency:       kofiNullString(payload.Currency),
+		TierName:       kofiNullString(payload.TierName),
+		IsSubscription: payload.IsSubscriptionPayment,
+		Message:        kofiNullString(payload.Message),
+	}, nil
+}
+
+func FetchKofiStats(dbQueries *database.Queries, sourceId uuid.UUID) error {
+
+	ctx := context.Background()
+	since := time.Now().Add(-kofiSubscriptionWindow)
+
+	supporters, err := dbQueries.CountActiveSubscribers(ctx, database.CountActiveSubscribersParams{
+		SourceID:   sourceId,
+		ReceivedAt: since,
+	})
+	if err != nil {
+		return fmt.Errorf("Ko-fi: failed to count supporters: %w", err)
+	}
+
+	if err := common.SaveSupporterStats(ctx, dbQueries, sourceId, int(supporters), nil); err != nil {
+		log.Printf("Ko-fi: Failed to save stats for source %s: %v", sourceId, err)
+	}
+
+	tierRows, err := dbQueries.GetActiveSubscriberTiers(ctx, database.GetActiveSubscriberTiersParams{
+		SourceID:   sourceId,
+		ReceivedAt: since,
+	})
+	if err != nil {
+		log.Printf("Ko-fi: Failed to get tier breakdown for source %s: %v", sourceId, err)
+		return nil
+	}
+
+	var tiers []common.DemographicEntry
+	for _, row := range tierRows {
+		bucket := row.TierName
+		if bucket == "" {
+			bucket = "Membership"
+		}
+		tiers = append(tiers, common.DemographicEntry{
+			Dimension: "tier",
+			Bucket:    bucket,
+			Value:     float64(row.Supporters),
+		})
+	}
+
+	if err := common.SaveDemographicsSnapshot(ctx, dbQueries, sourceId, tiers); err != nil {
+		log.Printf("Ko-fi: Failed to save tier breakdown for source %s: %v", sourceId, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A subscription payment shaped like the ones Ko-fi sends, minus the fields RPSync ignores
+const kofiSamplePayload = `{
+	"verification_token": "8a8cbf9e-7d5c-4d8b-9f3a-0c1b2d3e4f5a",
+	"message_id": "3a1fac0c-f960-4506-a60e-824979a74e74",
+	"timestamp": "2026-06-01T09:30:00Z",
+	"type": "Subscription",
+	"from_name": "Ko-fi Supporter",
+	"message": "Good luck with the integration!",
+	"amount": "3.00",
+	"email": "Someone@Example.com ",
+	"currency": "USD",
+	"is_subscription_payment": true,
+	"tier_name": "Bronze",
+	"kofi_transaction_id": "00000000-1111-2222-3333-444444444444"
+}`
+
+func TestKofiSupporterEvent(t *testing.T) {
+	var payload kofiWebhookPayload
+	if err := json.Unmarshal([]byte(kofiSamplePayload), &payload); err != nil {
+		t.Fatalf("parsing payload: %v", err)
+	}
+
+	sourceID := uuid.New()
+	event, err := kofiSupporterEvent(sourceID, payload)
+	if err != nil {
+		t.Fatalf("building event: %v", err)
+	}
+
+	if event.SourceID != sourceID {
+		t.Errorf("source ID is %s, want %s", event.SourceID, sourceID)
+	}
+	if event.ExternalID != payload.KofiTransactionID {
+		t.Errorf("external ID is %q, want the transaction ID", event.ExternalID)
+	}
+	if want := time.Date(2026, 6, 1, 9, 30, 0, 0, time.UTC); !event.ReceivedAt.Equal(want) {
+		t.Errorf("received at %v, want %v", event.ReceivedAt, want)
+	}
+	if !event.Amount.Valid || event.Amount.Float64 != 3 {
+		t.Errorf("amount is %+v, want 3", event.Amount)
+	}
+	if !event.IsSubscription || event.TierName.String != "Bronze" {
+		t.Errorf("subscription is %v in tier %q, want a Bronze subscription", event.IsSubscription, event.TierName.String)
+	}
+
+	// The same address in another case is the same supporter
+	payload.Email = "someone@example.com"
+	payload.KofiTransactionID = "55555555-6666-7777-8888-999999999999"
+	again, err := kofiSupporterEvent(sourceID, payload)
+	if err != nil {
+		t.Fatalf("building second event: %v", err)
+	}
+	if again.SupporterKey != event.SupporterKey {
+		t.Errorf("supporter key changed with the email's case")
+	}
+}
+
+func TestKofiSupporterEventAnonymous(t *testing.T) {
+	first, err := kofiSupporterEvent(uuid.New(), kofiWebhookPayload{KofiTransactionID: "first", Amount: "5"})
+	if err != nil {
+		t.Fatalf("building first event: %v", err)
+	}
+	second, err := kofiSupporterEvent(uuid.New(), kofiWebhookPayload{KofiTransactionID: "second", Amount: "5"})
+	if err != nil {
+		t.Fatalf("building second event: %v", err)
+	}
+
+	if first.SupporterKey == second.SupporterKey {
+		t.Errorf("anonymous payments share the supporter key %s", first.SupporterKey)
+	}
+}
+
+func TestKofiSupporterEventFallbacks(t *testing.T) {
+	event, err := kofiSupporterEvent(uuid.New(), kofiWebhookPayload{MessageID: "message", Timestamp: "not a time", Amount: "free"})
+	if err != nil {
+		t.Fatalf("building event: %v", err)
+	}
+	if event.ExternalID != "message" {
+		t.Errorf("external ID is %q, want the message ID", event.ExternalID)
+	}
+	if time.Since(event.ReceivedAt) > time.Minute {
+		t.Errorf("received at %v, want the time of receipt", event.ReceivedAt)
+	}
+	if event.Amount.Valid {
+		t.Errorf("amount is %v, want none", event.Amount.Float64)
+	}
+
+	if _, err := kofiSupporterEvent(uuid.New(), kofiWebhookPayload{}); err == nil {
+		t.Error("a payload without transaction or message ID was accepted")
+	}
+}
@@ -0,0 +1,215 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+type lemmyPersonDetails struct {
+	PersonView struct {
+		Person struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"person"`
+		Counts struct {
+			PostCount    int  `json:"post_count"`
+			PostScore    *int `json:"post_score"`
+			CommentScore *int `json:"comment_score"`
+		} `json:"counts"`
+	} `json:"person_view"`
+	Posts []struct {
+		Post struct {
+			ID        int    `json:"id"`
+			Name      string `json:"name"`
+			Body      string `json:"body"`
+			URL       string `json:"url"`
+			Published string `json:"published"`
+		} `json:"post"`
+		Community struct {
+			Name string `json:"name"`
+		} `json:"community"`
+		Counts struct {
+			Score     int `json:"score"`
+			Upvotes   int `json:"upvotes"`
+			Downvotes int `json:"downvotes"`
+			Comments  int `json:"comments"`
+		} `json:"counts"`
+	} `json:"posts"`
+	Moderates []struct {
+		Community struct {
+			ID int `json:"id"`
+		} `json:"community"`
+	} `json:"moderates"`
+}
+
+type lemmyCommunityResponse struct {
+	CommunityView struct {
+		Counts struct {
+			Subscribers int `json:"subscribers"`
+		} `json:"counts"`
+	} `json:"community_view"`
+}
+
+func fetchLemmyJSON(c *common.Client, domain, endpoint string, params url.Values, out any) error {
+	req, err := http.NewRequest("GET", "https://"+domain+"/api/v3/"+endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "rpsync")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed to get a successfull response. %v: %v", resp.StatusCode, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+func parseLemmyTime(value string) time.Time {
+	// Lemmy before 0.19 returns timestamps without a zone, which are UTC
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Now()
+}
+
+func FetchLemmyPosts(dbQueries *database.Queries, c *common.Client, uid uuid.UUID, sourceId uuid.UUID) error {
+
+	ctx := context.Background()
+
+	source, err := dbQueries.GetSourceById(ctx, sourceId)
+	if err != nil {
+		return fmt.Errorf("failed to get source: %w", err)
+	}
+
+	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
+	if err != nil {
+		return err
+	}
+
+	splits := strings.SplitN(source.UserName, "@", 2)
+	if len(splits) != 2 {
+		return fmt.Errorf("Lemmy: username must be in the form user@instance")
+	}
+	user := splits[0]
+	domain := splits[1]
+
+	var details lemmyPersonDetails
+	for page := 1; ; page++ {
+		var pageDetails lemmyPersonDetails
+		params := url.Values{
+			"username": {user},
+			"sort":     {"New"},
+			"limit":    {"50"},
+			"page":     {strconv.Itoa(page)},
+		}
+		if err := fetchLemmyJSON(c, domain, "user", params, &pageDetails); err != nil {
+			return fmt.Errorf("Lemmy: failed to get user %s: %w", source.UserName, err)
+		}
+
+		if page == 1 {
+			details = pageDetails
+		}
+
+		for _, item := range pageDetails.Posts {
+			networkID := strconv.Itoa(item.Post.ID)
+			if exclusionMap[networkID] {
+				continue
+			}
+
+			postType := "text"
+			if item.Post.URL != "" {
+				postType = "link"
+			}
+
+			var upvoteRatio sql.NullFloat64
+			if votes := item.Counts.Upvotes + item.Counts.Downvotes; votes > 0 {
+				upvoteRatio = sql.NullFloat64{Float64: float64(item.Counts.Upvotes) / float64(votes), Valid: true}
+			}
+
+			err = common.ProcessCommunityPost(
+				ctx,
+				dbQueries,
+				sourceId,
+				networkID,
+				"Lemmy",
+				parseLemmyTime(item.Post.Published),
+				postType,
+				source.UserName,
+				strings.TrimSpace(fmt.Sprintf("!%s: %s\n\n%s", item.Community.Name, item.Post.Name, item.Post.Body)),
+				item.Counts.Score,
+				item.Counts.Comments,
+				upvoteRatio,
+			)
+			if err != nil {
+				log.Printf("Lemmy: Failed to save post %s: %v", networkID, err)
+			}
+		}
+
+		if len(pageDetails.Posts) == 0 {
+			break
+		}
+	}
+
+	var karma *int
+	counts := details.PersonView.Counts
+	if counts.PostScore != nil || counts.CommentScore != nil {
+		total := 0
+		if counts.PostScore != nil {
+			total += *counts.PostScore
+		}
+		if counts.CommentScore != nil {
+			total += *counts.CommentScore
+		}
+		karma = &total
+	}
+
+	var communitySubscribers *int
+	if len(details.Moderates) > 0 {
+		total := 0
+		for _, mod := range details.Moderates {
+			var community lemmyCommunityResponse
+			err := fetchLemmyJSON(c, domain, "community", url.Values{"id": {strconv.Itoa(mod.Community.ID)}}, &community)
+			if err != nil {
+				log.Printf("Lemmy: Failed to get community %d for source %s: %v", mod.Community.ID, sourceId, err)
+				continue
+			}
+			total += community.CommunityView.Counts.Subscribers
+		}
+		communitySubscribers = &total
+	}
+
+	err = common.SaveCommunityStats(ctx, dbQueries, sourceId, karma, communitySubscribers, nil)
+	if err != nil {
+		log.Printf("Lemmy: Failed to save stats for source %s: %v", sourceId, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,325 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+var ErrNotManualSource = errors.New("source is not a manual source")
+
+type ManualPost struct {
+	ID         string
+	URL        string
+	CreatedAt  time.Time
+	RecordedAt time.Time
+	PostType   string
+	Content    string
+	Likes      sql.NullInt64
+	Reposts    sql.NullInt64
+	Views      sql.NullInt64
+	Comments   sql.NullInt64
+}
+
+type ManualStats struct {
+	Date           time.Time
+	FollowersCount *int
+	FollowingCount *int
+	PostsCount     *int
+}
+
+func ParseManualDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+}
+
+func ParseManualCount(value string) (sql.NullInt64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return sql.NullInt64{}, nil
+	}
+	n, err := strconv.ParseInt(strings.ReplaceAll(value, ",", ""), 10, 64)
+	if err != nil || n < 0 {
+		return sql.NullInt64{}, fmt.Errorf("invalid count %q", value)
+	}
+	return sql.NullInt64{Int64: n, Valid: true}, nil
+}
+
+func manualPostID(sourceId uuid.UUID, post ManualPost) string {
+	if post.URL != "" {
+		return post.URL
+	}
+
+	id := post.ID
+	if id == "" {
+		sum := sha256.Sum256([]byte(post.CreatedAt.UTC().Format(time.RFC3339) + "\n" + post.Content))
+		id = hex.EncodeToString(sum[:8])
+	}
+
+	// IDs typed by hand are only unique within one source
+	return sourceId.String()[:8] + "-" + id
+}
+
+func getManualSource(ctx context.Context, dbQueries *database.Queries, sourceId uuid.UUID) (database.Source, error) {
+	source, err := dbQueries.GetSourceById(ctx, sourceId)
+	if err != nil {
+		return database.Source{}, fmt.Errorf("failed to get source: %w", err)
+	}
+	if source.Network != "Manual" {
+		return database.Source{}, ErrNotManualSource
+	}
+	return source, nil
+}
+
+func saveManualPost(ctx context.Context, dbQueries *database.Queries, source database.Source, post ManualPost) error {
+	if post.PostType == "" {
+		post.PostType = "post"
+	}
+
+	postID, err := common.CreateOrUpdatePost(
+		ctx,
+		dbQueries,
+		source.ID,
+		manualPostID(source.ID, post),
+		"Manual",
+		post.CreatedAt,
+		post.PostType,
+		source.UserName,
+		post.Content,
+	)
+	if err != nil {
+		return err
+	}
+
+	recordedAt := post.RecordedAt
+	if recordedAt.IsZero() {
+		recordedAt = time.Now()
+	}
+
+	_, err = dbQueries.SyncReactions(ctx, database.SyncReactionsParams{
+		ID:       uuid.New(),
+		SyncedAt: recordedAt,
+		PostID:   postID,
+		Likes:    post.Likes,
+		Reposts:  post.Reposts,
+		Views:    post.Views,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !post.Comments.Valid {
+		return nil
+	}
+
+	return dbQueries.SyncPostAnalytics(ctx, database.SyncPostAnalyticsParams{
+		ID:       uuid.New(),
+		Date:     recordedAt.UTC().Truncate(24 * time.Hour),
+		PostID:   postID,
+		Comments: post.Comments,
+	})
+}
+
+// saveManualStats only changes the counts that were entered; whatever the day's
+// row already holds is kept.
+func saveManualStats(ctx context.Context, dbQueries *database.Queries, sourceId uuid.UUID, entry ManualStats) error {
+	day := entry.Date.UTC().Truncate(24 * time.Hour)
+
+	stats := &common.ProfileStats{}
+	existing, err := dbQueries.GetSourceStatsByDate(ctx, database.GetSourceStatsByDateParams{
+		SourceID: sourceId,
+		Date:     day,
+	})
+	switch {
+	case err == nil:
+		stats = &common.ProfileStats{
+			FollowersCount: nullCountToInt(existing.FollowersCount),
+			FollowingCount: nullCountToInt(existing.FollowingCount),
+			PostsCount:     nullCountToInt(existing.PostsCount),
+			AverageLikes:   nullAverage(existing.AverageLikes),
+			AverageReposts: nullAverage(existing.AverageReposts),
+			AverageViews:   nullAverage(existing.AverageViews),
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	// Averages describe the posts as they are now, so they only belong on today's row
+	if day.Equal(time.Now().UTC().Truncate(24 * time.Hour)) {
+		current, err := common.CalculateAverageStats(ctx, dbQueries, sourceId)
+		if err != nil {
+			return err
+		}
+		stats.AverageLikes = current.AverageLikes
+		stats.AverageReposts = current.AverageReposts
+		stats.AverageViews = current.AverageViews
+		if stats.PostsCount == nil {
+			stats.PostsCount = current.PostsCount
+		}
+	}
+
+	if entry.FollowersCount != nil {
+		stats.FollowersCount = entry.FollowersCount
+	}
+	if entry.FollowingCount != nil {
+		stats.FollowingCount = entry.FollowingCount
+	}
+	if entry.PostsCount != nil {
+		stats.PostsCount = entry.PostsCount
+	}
+
+	return common.SaveSourceStatsForDate(ctx, dbQueries, sourceId, entry.Date, stats)
+}
+
+func SaveManualPost(dbQueries *database.Queries, sourceId uuid.UUID, post ManualPost) error {
+	ctx := context.Background()
+
+	source, err := getManualSource(ctx, dbQueries, sourceId)
+	if err != nil {
+		return err
+	}
+
+	return saveManualPost(ctx, dbQueries, source, post)
+}
+
+func SaveManualStats(dbQueries *database.Queries, sourceId uuid.UUID, entry ManualStats) error {
+	ctx := context.Background()
+
+	if _, err := getManualSource(ctx, dbQueries, sourceId); err != nil {
+		return err
+	}
+
+	return saveManualStats(ctx, dbQueries, sourceId, entry)
+}
+
+func nullCountToInt(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	n := int(value.Int64)
+	return &n
+}
+
+func nullAverage(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
+
+// ImportManualCSV reads either a posts file (date, content, likes, ...) or a
+// stats file (date, followers, following, posts) and returns the number of rows saved.
+func ImportManualCSV(dbQueries *database.Queries, sourceId uuid.UUID, kind string, r io.Reader) (int, error) {
+	ctx := context.Background()
+
+	source, err := getManualSource(ctx, dbQueries, sourceId)
+	if err != nil {
+		return 0, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("Manual: failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["date"]; !ok {
+		return 0, fmt.Errorf("Manual: CSV must have a date column")
+	}
+
+	saved := 0
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return saved, fmt.Errorf("Manual: line %d: %w", line, err)
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		date, err := ParseManualDate(field("date"))
+		if err != nil {
+			return saved, fmt.Errorf("Manual: line %d: %w", line, err)
+		}
+
+		counts := make(map[string]sql.NullInt64)
+		for _, name := range []string{"likes", "reposts", "views", "comments", "followers", "following", "posts"} {
+			counts[name], err = ParseManualCount(field(name))
+			if err != nil {
+				return saved, fmt.Errorf("Manual: line %d: %s: %w", line, name, err)
+			}
+		}
+
+		switch kind {
+		case "posts":
+			post := ManualPost{
+				ID:        field("id"),
+				URL:       field("url"),
+				CreatedAt: date,
+				PostType:  field("type"),
+				Content:   field("content"),
+				Likes:     counts["likes"],
+				Reposts:   counts["reposts"],
+				Views:     counts["views"],
+				Comments:  counts["comments"],
+			}
+			if recorded := field("recorded_at"); recorded != "" {
+				post.RecordedAt, err = ParseManualDate(recorded)
+				if err != nil {
+					return saved, fmt.Errorf("Manual: line %d: %w", line, err)
+				}
+			}
+			err = saveManualPost(ctx, dbQueries, source, post)
+
+		case "stats":
+			err = saveManualStats(ctx, dbQueries, source.ID, ManualStats{
+				Date:           date,
+				FollowersCount: nullCountToInt(counts["followers"]),
+				FollowingCount: nullCountToInt(counts["following"]),
+				PostsCount:     nullCountToInt(counts["posts"]),
+			})
+
+		default:
+			return saved, fmt.Errorf("Manual: unknown import type %q", kind)
+		}
+		if err != nil {
+			return saved, fmt.Errorf("Manual: line %d: %w", line, err)
+		}
+		saved++
+	}
+
+	return saved, nil
+}
@@ -0,0 +1,268 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+type patreonCampaignsResponse struct {
+	Data []struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			PatronCount int    `json:"patron_count"`
+			Vanity      string `json:"vanity"`
+			URL         string `json:"url"`
+		} `json:"attributes"`
+	} `json:"data"`
+	Included []struct {
+		ID         string `json:"id"`
+		Type       string `json:"type"`
+		Attributes struct {
+			Title       string `json:"title"`
+			PatronCount int    `json:"patron_count"`
+		} `json:"attributes"`
+	} `json:"included"`
+}
+
+type patreonPostsResponse struct {
+	Data []struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			Title        string    `json:"title"`
+			Content      string    `json:"content"`
+			PublishedAt  time.Time `json:"published_at"`
+			LikeCount    *int      `json:"like_count"`
+			CommentCount *int      `json:"comment_count"`
+		} `json:"attributes"`
+	} `json:"data"`
+	Links struct {
+		Next string `json:"next"`
+	} `json:"links"`
+}
+
+type patreonPostCounts struct {
+	Likes    *int
+	Comments *int
+}
+
+func getPatreonAccessToken(ctx context.Context, dbQueries *database.Queries, c *common.Client, encryptionKey []byte, sid uuid.UUID) (string, error) {
+	refreshToken, profileID, sourceAppData, tokenID, err := authhelp.GetSourceToken(ctx, dbQueries, encryptionKey, sid)
+	if err != nil {
+		return "", err
+	}
+
+	clientID, _ := sourceAppData["client_id"].(string)
+	clientSecret, _ := sourceAppData["client_secret"].(string)
+
+	patreonConfig := authhelp.GeneratePatreonConfig(clientID, clientSecret, "")
+	tokenCtx := context.WithValue(ctx, oauth2.HTTPClient, &c.HTTPClient)
+
+	token, err := patreonConfig.TokenSource(tokenCtx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh access token: %w", err)
+	}
+
+	if token.RefreshToken != "" && token.RefreshToken != refreshToken {
+		_ = dbQueries.DeleteTokenById(ctx, tokenID)
+		err = authhelp.InsertSourceToken(ctx, dbQueries, sid, token.RefreshToken, profileID, sourceAppData, encryptionKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to store rotated refresh token: %w", err)
+		}
+	}
+
+	return token.AccessToken, nil
+}
+
+func fetchPatreonJSON(c *common.Client, accessToken, fullURL string, out any) error {
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("User-Agent", "rpsync")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed to get a successfull response. %v: %v", resp.StatusCode, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+// fetchPatreonPostCounts reads likes and comments of the campaign's posts. The
+// API documents no engagement fields, so they are asked for separately: when
+// Patreon refuses them, posts are still synced without touching their stored
+// counts.
+func fetchPatreonPostCounts(c *common.Client, accessToken, campaignID string) (map[string]patreonPostCounts, error) {
+	params := url.Values{
+		"fields[post]": {"like_count,comment_count"},
+	}
+
+	counts := make(map[string]patreonPostCounts)
+	next := "https://www.patreon.com/api/oauth2/v2/campaigns/" + campaignID + "/posts?" + params.Encode()
+	for next != "" {
+		var page patreonPostsResponse
+		if err := fetchPatreonJSON(c, accessToken, next, &page); err != nil {
+			return counts, err
+		}
+		for _, p := range page.Data {
+			counts[p.ID] = patreonPostCounts{Likes: p.Attributes.LikeCount, Comments: p.Attributes.CommentCount}
+		}
+		next = page.Links.Next
+	}
+
+	return counts, nil
+}
+
+func FetchPatreonPosts(dbQueries *database.Queries, c *common.Client, encryptionKey []byte, sourceId uuid.UUID) error {
+
+	ctx := context.Background()
+
+	source, err := dbQueries.GetSourceById(ctx, sourceId)
+	if err != nil {
+		return fmt.Errorf("failed to get source: %w", err)
+	}
+
+	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
+	if err != nil {
+		return err
+	}
+
+	accessToken, err := getPatreonAccessToken(ctx, dbQueries, c, encryptionKey, sourceId)
+	if err != nil {
+		return fmt.Errorf("Patreon: %w", err)
+	}
+
+	campaignParams := url.Values{
+		"include":          {"tiers"},
+		"fields[campaign]": {"patron_count,vanity,url"},
+		"fields[tier]":     {"title,patron_count"},
+	}
+
+	var campaigns patreonCampaignsResponse
+	err = fetchPatreonJSON(c, accessToken, "https://www.patreon.com/api/oauth2/v2/campaigns?"+campaignParams.Encode(), &campaigns)
+	if err != nil {
+		return fmt.Errorf("Patreon: failed to get campaign: %w", err)
+	}
+
+	if len(campaigns.Data) == 0 {
+		return fmt.Errorf("Patreon: no campaign found for this account")
+	}
+
+	campaign := campaigns.Data[0]
+
+	var tiers []common.DemographicEntry
+	for _, inc := range campaigns.Included {
+		if inc.Type != "tier" {
+			continue
+		}
+		tiers = append(tiers, common.DemographicEntry{
+			Dimension: "tier",
+			Bucket:    inc.Attributes.Title,
+			Value:     float64(inc.Attributes.PatronCount),
+		})
+	}
+
+	postCounts, err := fetchPatreonPostCounts(c, accessToken, campaign.ID)
+	countsFetched := err == nil
+	if err != nil {
+		log.Printf("Patreon: Failed to get post likes and comments for source %s, syncing posts without them: %v", sourceId, err)
+	}
+
+	postParams := url.Values{
+		"fields[post]": {"title,content,published_at"},
+	}
+	next := "https://www.patreon.com/api/oauth2/v2/campaigns/" + campaign.ID + "/posts?" + postParams.Encode()
+	for next != "" {
+		var page patreonPostsResponse
+		if err := fetchPatreonJSON(c, accessToken, next, &page); err != nil {
+			return fmt.Errorf("Patreon: failed to get posts: %w", err)
+		}
+
+		for _, post := range page.Data {
+			if exclusionMap[post.ID] {
+				continue
+			}
+
+			content := fmt.Sprintf("%s\n\n%s", post.Attributes.Title, common.StripHTMLToText(post.Attributes.Content))
+
+			if !countsFetched {
+				_, err := common.CreateOrUpdatePost(ctx, dbQueries, sourceId, post.ID, "Patreon", post.Attributes.PublishedAt, "post", source.UserName, content)
+				if err != nil {
+					log.Printf("Patreon: Failed to save post %s: %v", post.ID, err)
+				}
+				continue
+			}
+
+			var likes sql.NullInt64
+			var comments *int
+			if counts, ok := postCounts[post.ID]; ok {
+				if counts.Likes != nil {
+					likes = sql.NullInt64{Int64: int64(*counts.Likes), Valid: true}
+				}
+				comments = counts.Comments
+			}
+
+			postID, err := common.ProcessScrapedPost(
+				ctx,
+				dbQueries,
+				sourceId,
+				post.ID,
+				"Patreon",
+				post.Attributes.PublishedAt,
+				"post",
+				source.UserName,
+				content,
+				likes,
+				sql.NullInt64{},
+				sql.NullInt64{},
+			)
+			if err != nil {
+				log.Printf("Patreon: Failed to save post %s: %v", post.ID, err)
+				continue
+			}
+
+			if comments != nil {
+				if err := common.SavePostComments(ctx, dbQueries, postID, *comments); err != nil {
+					log.Printf("Patreon: Failed to save comment count for post %s: %v", post.ID, err)
+				}
+			}
+		}
+
+		next = page.Links.Next
+	}
+
+	if err := common.SaveSupporterStats(ctx, dbQueries, sourceId, campaign.Attributes.PatronCount, nil); err != nil {
+		log.Printf("Patreon: Failed to save stats for source %s: %v", sourceId, err)
+	}
+
+	if err := common.SaveDemographicsSnapshot(ctx, dbQueries, sourceId, tiers); err != nil {
+		log.Printf("Patreon: Failed to save tier breakdown for source %s: %v", sourceId, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+type pixivResponse struct {
+	Error   bool            `json:"error"`
+	Message string          `json:"message"`
+	Body    json.RawMessage `json:"body"`
+}
+
+type pixivProfileAll struct {
+	Illusts json.RawMessage `json:"illusts"`
+	Manga   json.RawMessage `json:"manga"`
+}
+
+type pixivUser struct {
+	UserID    string `json:"userId"`
+	Name      string `json:"name"`
+	Following int    `json:"following"`
+}
+
+type pixivIllust struct {
+	IllustID      string    `json:"illustId"`
+	IllustTitle   string    `json:"illustTitle"`
+	IllustComment string    `json:"illustComment"`
+	IllustType    int       `json:"illustType"`
+	CreateDate    time.Time `json:"createDate"`
+	BookmarkCount int       `json:"bookmarkCount"`
+	LikeCount     int       `json:"likeCount"`
+	CommentCount  int       `json:"commentCount"`
+	ViewCount     int       `json:"viewCount"`
+}
+
+func fetchPixivJSON(c *common.Client, endpoint string, out any) error {
+	req, err := http.NewRequest("GET", "https://www.pixiv.net/ajax/"+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Referer", "https://www.pixiv.net/")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var parsed pixivResponse
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return fmt.Errorf("%v: %w", resp.Status, err)
+	}
+
+	if parsed.Error {
+		return fmt.Errorf("pixiv error: %s", parsed.Message)
+	}
+
+	return json.Unmarshal(parsed.Body, out)
+}
+
+func pixivWorkIDs(raw json.RawMessage) []string {
+	// Pixiv returns an empty array instead of an object when there are no works
+	if !bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+		return nil
+	}
+
+	var works map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &works); err != nil {
+		return nil
+	}
+
+	ids := make([]string, 0, len(works))
+	for id := range works {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func FetchPixivPosts(dbQueries *database.Queries, c *common.Client, uid uuid.UUID, sourceId uuid.UUID) error {
+
+	source, err := dbQueries.GetSourceById(context.Background(), sourceId)
+	if err != nil {
+		return fmt.Errorf("failed to get source: %w", err)
+	}
+
+	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
+	if err != nil {
+		return err
+	}
+
+	userID := source.UserName
+
+	var user pixivUser
+	if err := fetchPixivJSON(c, "user/"+userID+"?full=1&lang=en", &user); err != nil {
+		return fmt.Errorf("Pixiv: failed to get user %s: %w", userID, err)
+	}
+
+	var works pixivProfileAll
+	if err := fetchPixivJSON(c, "user/"+userID+"/profile/all?lang=en", &works); err != nil {
+		return fmt.Errorf("Pixiv: failed to get works: %w", err)
+	}
+
+	ids := append(pixivWorkIDs(works.Illusts), pixivWorkIDs(works.Manga)...)
+	if len(ids) == 0 {
+		return errors.New("No content found")
+	}
+
+	for _, id := range ids {
+		if exclusionMap[id] {
+			continue
+		}
+
+		var illust pixivIllust
+		if err := fetchPixivJSON(c, "illust/"+id+"?lang=en", &illust); err != nil {
+			log.Printf("Pixiv: Failed to get work %s: %v", id, err)
+			continue
+		}
+
+		postType := "image"
+		switch illust.IllustType {
+		case 1:
+			postType = "manga"
+		case 2:
+			postType = "animation"
+		}
+
+		postID, err := common.ProcessScrapedPost(
+			context.Background(),
+			dbQueries,
+			sourceId,
+			id,
+			"Pixiv",
+			illust.CreateDate,
+			postType,
+			userID,
+			fmt.Sprintf("%s\n\n%s", illust.IllustTitle, common.StripHTMLToText(illust.IllustComment)),
+			sql.NullInt64{Int64: int64(illust.BookmarkCount), Valid: true},
+			sql.NullInt64{},
+			sql.NullInt64{Int64: int64(illust.ViewCount), Valid: true},
+		)
+		if err != nil {
+			log.Printf("Pixiv: Failed to save work %s: %v", id, err)
+			continue
+		}
+
+		if err := common.SavePostComments(context.Background(), dbQueries, postID, illust.CommentCount); err != nil {
+			log.Printf("Pixiv: Failed to save comment count for work %s: %v", id, err)
+		}
+	}
+
+	following := user.Following
+	err = common.UpdateSourceStats(context.Background(), dbQueries, sourceId, func(stats *common.ProfileStats) {
+		stats.FollowingCount = &following
+	})
+	if err != nil {
+		log.Printf("Pixiv: Failed to save stats for source %s: %v", sourceId, err)
+	}
+
+	return nil
+}
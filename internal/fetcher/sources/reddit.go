@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+type redditListing struct {
+	Data struct {
+		After    string `json:"after"`
+		Children []struct {
+			Data redditSubmission `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+type redditSubmission struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Selftext    string  `json:"selftext"`
+	Author      string  `json:"author"`
+	Subreddit   string  `json:"subreddit"`
+	CreatedUTC  float64 `json:"created_utc"`
+	Score       int     `json:"score"`
+	UpvoteRatio float64 `json:"upvote_ratio"`
+	NumComments int     `json:"num_comments"`
+	IsSelf      bool    `json:"is_self"`
+	IsVideo     bool    `json:"is_video"`
+	IsGallery   bool    `json:"is_gallery"`
+	PostHint    string  `json:"post_hint"`
+}
+
+type redditAbout struct {
+	Data struct {
+		TotalKarma int `json:"total_karma"`
+		Subreddit  *struct {
+			Subscribers int `json:"subscribers"`
+		} `json:"subreddit"`
+	} `json:"data"`
+}
+
+type redditModerated struct {
+	Data []struct {
+		Sr          string `json:"sr"`
+		Subscribers int    `json:"subscribers"`
+	} `json:"data"`
+}
+
+// Reddit asks API clients to name themselves, and allows unauthenticated ones
+// about ten requests a minute
+const (
+	redditUserAgent       = "server:rpsync:v1 (self-hosted social media statistics sync)"
+	redditRequestInterval = 6 * time.Second
+)
+
+// redditThrottle spaces requests across all Reddit sources syncing at once
+var redditThrottle struct {
+	sync.Mutex
+	last time.Time
+}
+
+func waitForReddit() {
+	redditThrottle.Lock()
+	defer redditThrottle.Unlock()
+
+	if wait := time.Until(redditThrottle.last.Add(redditRequestInterval)); wait > 0 {
+		time.Sleep(wait)
+	}
+	redditThrottle.last = time.Now()
+}
+
+func fetchRedditJSON(c *common.Client, endpoint string, out any) error {
+	req, err := http.NewRequest("GET", "https://www.reddit.com/"+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", redditUserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	waitForReddit()
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed to get a successfull response. %v: %v", resp.StatusCode, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+func redditPostType(s redditSubmission) string {
+	switch {
+	case s.IsVideo:
+		return "video"
+	case s.IsGallery:
+		return "gallery"
+	case s.PostHint == "image":
+		return "image"
+	case s.IsSelf:
+		return "text"
+	default:
+		return "link"
+	}
+}
+
+func FetchRedditPosts(dbQueries *database.Queries, c *common.Client, uid uuid.UUID, sourceId uuid.UUID) error {
+
+	ctx := context.Background()
+
+	source, err := dbQueries.GetSourceById(ctx, sourceId)
+	if err != nil {
+		return fmt.Errorf("failed to get source: %w", err)
+	}
+
+	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
+	if err != nil {
+		return err
+	}
+
+	user := url.PathEscape(strings.TrimPrefix(source.UserName, "u/"))
+
+	var about redditAbout
+	if err := fetchRedditJSON(c, "user/"+user+"/about.json", &about); err != nil {
+		return fmt.Errorf("Reddit: failed to get user %s: %w", source.UserName, err)
+	}
+
+	after := ""
+	for {
+		params := url.Values{
+			"limit":    {"100"},
+			"sort":     {"new"},
+			"raw_json": {"1"},
+		}
+		if after != "" {
+			params.Set("after", after)
+		}
+
+		var listing redditListing
+		if err := fetchRedditJSON(c, "user/"+user+"/submitted.json?"+params.Encode(), &listing); err != nil {
+			return fmt.Errorf("Reddit: failed to get submissions: %w", err)
+		}
+
+		for _, child := range listing.Data.Children {
+			submission := child.Data
+			if exclusionMap[submission.ID] {
+				continue
+			}
+
+			err = common.ProcessCommunityPost(
+				ctx,
+				dbQueries,
+				sourceId,
+				submission.ID,
+				"Reddit",
+				time.Unix(int64(submission.CreatedUTC), 0),
+				redditPostType(submission),
+				submission.Author,
+				strings.TrimSpace(fmt.Sprintf("r/%s: %s\n\n%s", submission.Subreddit, submission.Title, submission.Selftext)),
+				submission.Score,
+				submission.NumComments,
+				sql.NullFloat64{Float64: submission.UpvoteRatio, Valid: true},
+			)
+			if err != nil {
+				log.Printf("Reddit: Failed to save submission %s: %v", submission.ID, err)
+			}
+		}
+
+		if listing.Data.After == "" || len(listing.Data.Children) == 0 {
+			break
+		}
+		after = listing.Data.After
+	}
+
+	var communitySubscribers *int
+	var moderated redditModerated
+	if err := fetchRedditJSON(c, "user/"+user+"/moderated_subreddits.json", &moderated); err != nil {
+		log.Printf("Reddit: Failed to get moderated communities for source %s: %v", sourceId, err)
+	} else if len(moderated.Data) > 0 {
+		total := 0
+		for _, sr := range moderated.Data {
+			total += sr.Subscribers
+		}
+		communitySubscribers = &total
+	}
+
+	karma := about.Data.TotalKarma
+	err = common.SaveCommunityStats(ctx, dbQueries, sourceId, &karma, communitySubscribers, func(stats *common.ProfileStats) {
+		if about.Data.Subreddit != nil {
+			stats.FollowersCount = &about.Data.Subreddit.Subscribers
+		}
+	})
+	if err != nil {
+		log.Printf("Reddit: Failed to save stats for source %s: %v", sourceId, err)
+	}
+
+	return nil
+}
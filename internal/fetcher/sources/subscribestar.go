@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+var subscribeStarCountRegex = regexp.MustCompile(`(?i)([\d][\d,.\s]*)\s*(?:</[^>]+>\s*)*(?:<[^>]+>\s*)*subscribers?\b`)
+
+func fetchSubscribeStarPage(c *common.Client, host, slug string) (string, error) {
+	req, err := http.NewRequest("GET", "https://"+host+"/"+url.PathEscape(slug), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.AddCookie(&http.Cookie{Name: "18_plus_agreement_generic", Value: "true"})
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Failed to get a successfull response. %v: %v", resp.StatusCode, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func FetchSubscribeStarStats(dbQueries *database.Queries, c *common.Client, sourceId uuid.UUID) error {
+
+	ctx := context.Background()
+
+	source, err := dbQueries.GetSourceById(ctx, sourceId)
+	if err != nil {
+		return fmt.Errorf("failed to get source: %w", err)
+	}
+
+	page, err := fetchSubscribeStarPage(c, "www.subscribestar.com", source.UserName)
+	if err != nil {
+		// Adult creators are only served from the .adult domain
+		page, err = fetchSubscribeStarPage(c, "subscribestar.adult", source.UserName)
+		if err != nil {
+			return fmt.Errorf("SubscribeStar: failed to get profile: %w", err)
+		}
+	}
+
+	match := subscribeStarCountRegex.FindStringSubmatch(page)
+	if match == nil {
+		return fmt.Errorf("SubscribeStar: subscriber count is not public for %s", source.UserName)
+	}
+
+	digits := strings.NewReplacer(",", "", ".", "", " ", "").Replace(strings.TrimSpace(match[1]))
+	supporters, err := strconv.Atoi(digits)
+	if err != nil {
+		return fmt.Errorf("SubscribeStar: failed to parse subscriber count %q: %w", match[1], err)
+	}
+
+	if err := common.SaveSupporterStats(ctx, dbQueries, sourceId, supporters, nil); err != nil {
+		log.Printf("SubscribeStar: Failed to save stats for source %s: %v", sourceId, err)
+	}
+
+	return nil
+}
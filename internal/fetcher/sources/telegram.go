@@ -23,23 +23,75 @@ import (
 	"github.com/gotd/td/tg"
 )
 
-func getTgDetails(ctx context.Context, dbQueries *database.Queries, encryptionKey []byte, sid uuid.UUID) (string, string, int, string, error) {
-	botToken, channelUsername, _, _, err := authhelp.GetSourceToken(ctx, dbQueries, encryptionKey, sid)
+func getTgDetails(ctx context.Context, dbQueries *database.Queries, encryptionKey []byte, sid uuid.UUID) (string, string, int, string, string, error) {
+	botToken, channelUsername, sourceAppData, _, err := authhelp.GetSourceToken(ctx, dbQueries, encryptionKey, sid)
 	if err != nil {
-		return "", "", 0, "", err
+		return "", "", 0, "", "", err
 	}
 
 	parts := strings.Split(botToken, ":::")
 	if len(parts) != 3 {
-		return "", "", 0, "", fmt.Errorf("invalid bot token format")
+		return "", "", 0, "", "", fmt.Errorf("invalid bot token format")
 	}
 
 	appID, err := strconv.Atoi(parts[1])
 	if err != nil {
-		return "", "", 0, "", err
+		return "", "", 0, "", "", err
 	}
 
-	return parts[0], channelUsername, appID, parts[2], nil
+	authMode, _ := sourceAppData["auth_mode"].(string)
+
+	return parts[0], channelUsername, appID, parts[2], authMode, nil
+}
+
+func TelegramSessionStorage(dbQueries *database.Queries, encryptionKey []byte, sid uuid.UUID, authMode string) session.Storage {
+	if authMode == "user" {
+		return &authhelp.TelegramSessionStorage{
+			DB:            dbQueries,
+			EncryptionKey: encryptionKey,
+			SourceID:      sid,
+		}
+	}
+
+	sessionFile := fmt.Sprintf("outputs/telegram_session_%s.json", sid.String())
+
+	f, err := os.OpenFile(sessionFile, os.O_RDWR|os.O_CREATE, 0600)
+	if err == nil {
+		f.Close()
+		if err := os.Chmod(sessionFile, 0600); err != nil {
+			log.Printf("Warning: failed to chmod telegram session file: %v", err)
+		}
+	}
+
+	return &session.FileStorage{Path: sessionFile}
+}
+
+func GetTelegramClient(dbQueries *database.Queries, encryptionKey []byte, sid uuid.UUID) (*telegram.Client, string, error) {
+	phone, _, appID, appHash, authMode, err := getTgDetails(context.Background(), dbQueries, encryptionKey, sid)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if authMode != "user" {
+		return nil, "", fmt.Errorf("source is not configured for user session authorization")
+	}
+
+	client := telegram.NewClient(appID, appHash, telegram.Options{
+		SessionStorage: TelegramSessionStorage(dbQueries, encryptionKey, sid, authMode),
+	})
+
+	return client, phone, nil
+}
+
+func userAuth(ctx context.Context, client *telegram.Client) error {
+	status, err := client.Auth().Status(ctx)
+	if err != nil {
+		return err
+	}
+	if !status.Authorized {
+		return fmt.Errorf("telegram user session is not authorized, please log in again")
+	}
+	return nil
 }
 
 func botAuth(ctx context.Context, client *telegram.Client, botToken string, maxRetries int) error {
@@ -70,27 +122,83 @@ func botAuth(ctx context.Context, client *telegram.Client, botToken string, maxR
 	return fmt.Errorf("bot auth failed after %d retries", maxRetries)
 }
 
-func FetchTelegramPosts(dbQueries *database.Queries, encryptionKey []byte, sourceId uuid.UUID, c *common.Client) error {
-	ctx := context.Background()
+// resolveTelegramChannel looks the channel up by username, falling back to the stored
+// channel ID once the username is gone, and records any rename on the source.
+func resolveTelegramChannel(ctx context.Context, api *tg.Client, dbQueries *database.Queries, encryptionKey []byte, sid uuid.UUID, channelUsername string) (*tg.Channel, error) {
+	source, err := dbQueries.GetSourceById(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
 
-	botToken, channelUsername, appID, appHash, err := getTgDetails(ctx, dbQueries, encryptionKey, sourceId)
+	_, _, sourceAppData, _, err := authhelp.GetSourceToken(ctx, dbQueries, encryptionKey, sid)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	storedHash, _ := sourceAppData["access_hash"].(string)
 
-	sessionFile := fmt.Sprintf("outputs/telegram_session_%s.json", sourceId.String())
-	sess := &session.FileStorage{Path: sessionFile}
+	var channel *tg.Channel
+	var chats []tg.ChatClass
 
-	f, err := os.OpenFile(sessionFile, os.O_RDWR|os.O_CREATE, 0600)
-	if err == nil {
-		f.Close()
-		if err := os.Chmod(sessionFile, 0600); err != nil {
-			log.Printf("Warning: failed to chmod telegram session file: %v", err)
+	res, resolveErr := api.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{
+		Username: channelUsername,
+	})
+	if resolveErr == nil {
+		chats = res.Chats
+	} else if source.AccountID.Valid && storedHash != "" {
+		channelID, _ := strconv.ParseInt(source.AccountID.String, 10, 64)
+		accessHash, _ := strconv.ParseInt(storedHash, 10, 64)
+
+		found, err := api.ChannelsGetChannels(ctx, []tg.InputChannelClass{
+			&tg.InputChannel{ChannelID: channelID, AccessHash: accessHash},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve channel: %w", resolveErr)
 		}
+		chats = found.GetChats()
+	} else {
+		return nil, fmt.Errorf("failed to resolve channel: %w", resolveErr)
+	}
+
+	for _, c := range chats {
+		if ch, ok := c.(*tg.Channel); ok {
+			channel = ch
+			break
+		}
+	}
+	if channel == nil {
+		return nil, fmt.Errorf("resolved chat is not a channel")
+	}
+
+	accessHash := strconv.FormatInt(channel.AccessHash, 10)
+	if accessHash != storedHash {
+		if err := authhelp.UpdateSourceAppData(ctx, dbQueries, encryptionKey, sid, "access_hash", accessHash); err != nil {
+			log.Printf("Telegram: Failed to store access hash for source %s: %v", sid, err)
+		}
+	}
+
+	renamed, err := common.SyncSourceAccount(ctx, dbQueries, source, strconv.FormatInt(channel.ID, 10), channelUsername, channel.Username)
+	if err != nil {
+		return nil, err
+	}
+	if renamed {
+		if err := authhelp.UpdateSourceProfile(ctx, dbQueries, encryptionKey, sid, channel.Username); err != nil {
+			return nil, err
+		}
+	}
+
+	return channel, nil
+}
+
+func FetchTelegramPosts(dbQueries *database.Queries, encryptionKey []byte, sourceId uuid.UUID, c *common.Client) error {
+	ctx := context.Background()
+
+	botToken, channelUsername, appID, appHash, authMode, err := getTgDetails(ctx, dbQueries, encryptionKey, sourceId)
+	if err != nil {
+		return err
 	}
 
 	client := telegram.NewClient(appID, appHash, telegram.Options{
-		SessionStorage: sess,
+		SessionStorage: TelegramSessionStorage(dbQueries, encryptionKey, sourceId, authMode),
 	})
 
 	return client.Run(ctx, func(ctx context.Context) error {
@@ -100,26 +208,20 @@ func FetchTelegramPosts(dbQueries *database.Queries, encryptionKey []byte, sourc
 			return err
 		}
 
-		if err := botAuth(ctx, client, botToken, 5); err != nil {
+		if authMode == "user" {
+			if err := userAuth(ctx, client); err != nil {
+				return err
+			}
+		} else if err := botAuth(ctx, client, botToken, 5); err != nil {
 			return err
 		}
 
-		res, err := client.API().ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{
-			Username: channelUsername,
-		})
+		channel, err := resolveTelegramChannel(ctx, client.API(), dbQueries, encryptionKey, sourceId, channelUsername)
 		if err != nil {
-			return fmt.Errorf("failed to resolve channel: %w", err)
-		}
-
-		var channel *tg.Channel
-		for _, c := range res.Chats {
-			if ch, ok := c.(*tg.Channel); ok {
-				channel = ch
-				break
-			}
+			return err
 		}
-		if channel == nil {
-			return fmt.Errorf("resolved chat is not a channel")
+		if channel.Username != "" {
+			channelUsername = channel.Username
 		}
 
 		input := &tg.InputChannel{
@@ -128,6 +230,8 @@ func FetchTelegramPosts(dbQueries *database.Queries, encryptionKey []byte, sourc
 		}
 
 		var participantCount *int
+		var statsDC int
+		canViewStats := false
 		fullChan, err := client.API().ChannelsGetFullChannel(ctx, input)
 		if err != nil {
 			log.Printf("Telegram: Failed to get full channel info: %v", err)
@@ -137,6 +241,8 @@ func FetchTelegramPosts(dbQueries *database.Queries, encryptionKey []byte, sourc
 					count := int(channelFull.ParticipantsCount)
 					participantCount = &count
 				}
+				statsDC, _ = channelFull.GetStatsDC()
+				canViewStats = channelFull.CanViewStats
 			}
 		}
 
@@ -148,6 +254,7 @@ func FetchTelegramPosts(dbQueries *database.Queries, encryptionKey []byte, sourc
 		)
 
 		processedLinks := make(map[int]struct{})
+		postIDs := make(map[int]uuid.UUID)
 
 		for emptyHits < maxEmptyAttempts {
 			ids := make([]tg.InputMessageClass, 0, batchSize)
@@ -200,7 +307,16 @@ func FetchTelegramPosts(dbQueries *database.Queries, encryptionKey []byte, sourc
 						continue
 					}
 
-					likes, _ := FetchTelegramWebStats(channelUsername, msg.ID, c)
+					breakdown, hasReactions := telegramReactionBreakdown(msg)
+
+					likes := 0
+					if hasReactions {
+						for _, count := range breakdown {
+							likes += count
+						}
+					} else {
+						likes, _ = FetchTelegramWebStats(channelUsername, msg.ID, c)
+					}
 
 					msgTime := time.Unix(int64(msg.Date), 0).UTC()
 
@@ -218,6 +334,13 @@ func FetchTelegramPosts(dbQueries *database.Queries, encryptionKey []byte, sourc
 					if err != nil {
 						continue
 					}
+					postIDs[msg.ID] = postID
+
+					if hasReactions {
+						if err := common.SaveReactionBreakdown(ctx, dbQueries, postID, breakdown); err != nil {
+							log.Printf("[WARN] Failed to sync reaction breakdown for post ID=%d: %v", msg.ID, err)
+						}
+					}
 
 					_, err = dbQueries.SyncReactions(ctx, database.SyncReactionsParams{
 						ID:       uuid.New(),
@@ -255,14 +378,42 @@ func FetchTelegramPosts(dbQueries *database.Queries, encryptionKey []byte, sourc
 			return fmt.Errorf("no new messages found")
 		}
 
+		var broadcastStats *tg.StatsBroadcastStats
+		if authMode == "user" && canViewStats {
+			broadcastStats, err = fetchTelegramBroadcastStats(ctx, client, dbQueries, sourceId, input, statsDC, postIDs)
+			if err != nil {
+				log.Printf("Telegram: Failed to fetch broadcast stats for source %s: %v", sourceId, err)
+			}
+		}
+
 		stats, err := common.CalculateAverageStats(context.Background(), dbQueries, sourceId)
 		if err != nil {
 			log.Printf("Telegram: Failed to calculate stats for source %s: %v", sourceId, err)
 		} else {
 			stats.FollowersCount = participantCount
 
+			if broadcastStats != nil {
+				followers := int(broadcastStats.Followers.Current)
+				stats.FollowersCount = &followers
+				stats.AverageViews = &broadcastStats.ViewsPerPost.Current
+				stats.AverageReposts = &broadcastStats.SharesPerPost.Current
+				stats.AverageLikes = &broadcastStats.ReactionsPerPost.Current
+			}
+
 			if err := common.SaveOrUpdateSourceStats(context.Background(), dbQueries, sourceId, stats); err != nil {
 				log.Printf("Telegram: Failed to save stats for source %s: %v", sourceId, err)
+			} else if broadcastStats != nil && broadcastStats.EnabledNotifications.Total > 0 {
+				err := dbQueries.UpdateSourceNotificationsEnabled(ctx, database.UpdateSourceNotificationsEnabledParams{
+					NotificationsEnabledPct: sql.NullFloat64{
+						Float64: broadcastStats.EnabledNotifications.Part / broadcastStats.EnabledNotifications.Total * 100,
+						Valid:   true,
+					},
+					SourceID: sourceId,
+					Date:     time.Now().UTC().Truncate(24 * time.Hour),
+				})
+				if err != nil {
+					log.Printf("Telegram: Failed to save notifications share for source %s: %v", sourceId, err)
+				}
 			}
 		}
 
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+)
+
+type telegramGraphData struct {
+	Columns [][]any `json:"columns"`
+}
+
+func telegramReactionKey(reaction tg.ReactionClass) string {
+	switch r := reaction.(type) {
+	case *tg.ReactionEmoji:
+		return r.Emoticon
+	case *tg.ReactionCustomEmoji:
+		return fmt.Sprintf("custom:%d", r.DocumentID)
+	case *tg.ReactionPaid:
+		return "paid"
+	}
+	return ""
+}
+
+func telegramReactionBreakdown(msg *tg.Message) (map[string]int, bool) {
+	reactions, ok := msg.GetReactions()
+	if !ok {
+		return nil, false
+	}
+
+	breakdown := make(map[string]int, len(reactions.Results))
+	for _, r := range reactions.Results {
+		breakdown[telegramReactionKey(r.Reaction)] += r.Count
+	}
+	return breakdown, true
+}
+
+func loadTelegramGraph(ctx context.Context, api *tg.Client, graph tg.StatsGraphClass) (*telegramGraphData, error) {
+	if async, ok := graph.(*tg.StatsGraphAsync); ok {
+		loaded, err := api.StatsLoadAsyncGraph(ctx, &tg.StatsLoadAsyncGraphRequest{Token: async.Token})
+		if err != nil {
+			return nil, err
+		}
+		graph = loaded
+	}
+
+	switch g := graph.(type) {
+	case *tg.StatsGraph:
+		var data telegramGraphData
+		if err := json.Unmarshal([]byte(g.JSON.Data), &data); err != nil {
+			return nil, err
+		}
+		return &data, nil
+	case *tg.StatsGraphError:
+		return nil, fmt.Errorf("graph unavailable: %s", g.Error)
+	}
+
+	return nil, fmt.Errorf("unexpected graph type %T", graph)
+}
+
+func (g *telegramGraphData) firstSeries() map[time.Time]float64 {
+	var xs, ys []any
+	for _, col := range g.Columns {
+		if len(col) == 0 {
+			continue
+		}
+		if label, _ := col[0].(string); label == "x" {
+			xs = col[1:]
+		} else if ys == nil {
+			ys = col[1:]
+		}
+	}
+
+	points := make(map[time.Time]float64)
+	for i := 0; i < len(xs) && i < len(ys); i++ {
+		ts, ok := xs[i].(float64)
+		if !ok {
+			continue
+		}
+		v, ok := ys[i].(float64)
+		if !ok {
+			continue
+		}
+		points[time.UnixMilli(int64(ts)).UTC().Truncate(24*time.Hour)] = v
+	}
+	return points
+}
+
+func fetchTelegramBroadcastStats(ctx context.Context, client *telegram.Client, dbQueries *database.Queries, sourceID uuid.UUID, channel *tg.InputChannel, statsDC int, postIDs map[int]uuid.UUID) (*tg.StatsBroadcastStats, error) {
+	api := client.API()
+	if statsDC != 0 && statsDC != client.Config().ThisDC {
+		invoker, err := client.DC(ctx, statsDC, 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to stats DC %d: %w", statsDC, err)
+		}
+		defer invoker.Close()
+		api = tg.NewClient(invoker)
+	}
+
+	stats, err := api.StatsGetBroadcastStats(ctx, &tg.StatsGetBroadcastStatsRequest{Channel: channel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get broadcast stats: %w", err)
+	}
+
+	growth, err := loadTelegramGraph(ctx, api, stats.GrowthGraph)
+	if err != nil {
+		log.Printf("Telegram: Failed to load growth graph: %v", err)
+	} else {
+		for date, followers := range growth.firstSeries() {
+			err := dbQueries.BackfillSourceFollowers(ctx, database.BackfillSourceFollowersParams{
+				ID:             uuid.New(),
+				Date:           date,
+				SourceID:       sourceID,
+				FollowersCount: sql.NullInt64{Int64: int64(followers), Valid: true},
+			})
+			if err != nil {
+				log.Printf("Telegram: Failed to backfill followers for %s: %v", date.Format("2006-01-02"), err)
+			}
+		}
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	for _, counters := range stats.RecentPostsInteractions {
+		msg, ok := counters.(*tg.PostInteractionCountersMessage)
+		if !ok {
+			continue
+		}
+		postID, ok := postIDs[msg.MsgID]
+		if !ok {
+			continue
+		}
+
+		err := dbQueries.SyncPostAnalytics(ctx, database.SyncPostAnalyticsParams{
+			ID:     uuid.New(),
+			Date:   today,
+			PostID: postID,
+			Views:  sql.NullInt64{Int64: int64(msg.Views), Valid: true},
+			Shares: sql.NullInt64{Int64: int64(msg.Forwards), Valid: true},
+		})
+		if err != nil {
+			log.Printf("Telegram: Failed to save post metrics for message %d: %v", msg.MsgID, err)
+		}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+const threadsInsightMetrics = "views,likes,replies,reposts,quotes"
+
+type threadsUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+type threadsFeed struct {
+	Data []struct {
+		ID               string `json:"id"`
+		MediaProductType string `json:"media_product_type"`
+		MediaType        string `json:"media_type"`
+		Text             string `json:"text"`
+		Timestamp        string `json:"timestamp"`
+		Shortcode        string `json:"shortcode"`
+		Username         string `json:"username"`
+		IsQuotePost      bool   `json:"is_quote_post"`
+	} `json:"data"`
+	Paging struct {
+		Next string `json:"next,omitempty"`
+	} `json:"paging"`
+}
+
+func fetchMetaJSON(c *common.Client, fullURL string, out any) error {
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed to get a successfull response. %v: %v", resp.StatusCode, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+func GetThreadsUserID(c *common.Client, token string) (string, error) {
+	var user threadsUser
+	params := url.Values{
+		"fields":       {"id,username"},
+		"access_token": {token},
+	}
+	if err := fetchMetaJSON(c, "https://graph.threads.net/v1.0/me?"+params.Encode(), &user); err != nil {
+		return "", err
+	}
+	return user.ID, nil
+}
+
+func fetchThreadsInsights(c *common.Client, token, endpoint, metrics string) (map[string]int64, error) {
+	params := url.Values{
+		"metric":       {metrics},
+		"access_token": {token},
+	}
+
+	var insights instagramInsightsResponse
+	if err := fetchMetaJSON(c, "https://graph.threads.net/v1.0/"+endpoint+"?"+params.Encode(), &insights); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int64)
+	for _, metric := range insights.Data {
+		if len(metric.Values) != 0 {
+			result[metric.Name] = metric.Values[0].Value
+		} else {
+			result[metric.Name] = metric.TotalValue.Value
+		}
+	}
+
+	return result, nil
+}
+
+func threadsPostType(mediaType string, isQuote bool) string {
+	switch {
+	case isQuote:
+		return "quote"
+	case mediaType == "TEXT_POST":
+		return "text"
+	case mediaType == "CAROUSEL_ALBUM":
+		return "carousel"
+	case mediaType == "REPOST_FACADE":
+		return "repost"
+	default:
+		return strings.ToLower(mediaType)
+	}
+}
+
+func FetchThreadsPosts(dbQueries *database.Queries, c *common.Client, encryptionKey []byte, sourceId uuid.UUID) error {
+
+	ctx := context.Background()
+
+	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
+	if err != nil {
+		return err
+	}
+
+	token, pid, _, _, err := authhelp.GetSourceToken(ctx, dbQueries, encryptionKey, sourceId)
+	if err != nil {
+		return fmt.Errorf("Threads: %w", err)
+	}
+
+	params := url.Values{
+		"fields":       {"id,media_product_type,media_type,text,timestamp,shortcode,username,is_quote_post"},
+		"limit":        {"50"},
+		"access_token": {token},
+	}
+
+	const maxPages = 200
+
+	next := "https://graph.threads.net/v1.0/" + pid + "/threads?" + params.Encode()
+	for page := 0; page < maxPages && next != ""; page++ {
+		var feed threadsFeed
+		if err := fetchMetaJSON(c, next, &feed); err != nil {
+			return fmt.Errorf("Threads: failed to get posts: %w", err)
+		}
+
+		for _, item := range feed.Data {
+			if item.Shortcode == "" || exclusionMap[item.Shortcode] {
+				continue
+			}
+
+			postType := threadsPostType(item.MediaType, item.IsQuotePost)
+
+			var likes, reposts, views sql.NullInt64
+			var replies *int64
+			if postType != "repost" {
+				insights, err := fetchThreadsInsights(c, token, item.ID+"/insights", threadsInsightMetrics)
+				if err != nil {
+					log.Printf("Threads: Failed to get insights for post %s: %v", item.Shortcode, err)
+				} else {
+					likes = sql.NullInt64{Int64: insights["likes"], Valid: true}
+					reposts = sql.NullInt64{Int64: insights["reposts"] + insights["quotes"], Valid: true}
+					views = sql.NullInt64{Int64: insights["views"], Valid: true}
+					r := insights["replies"]
+					replies = &r
+				}
+
+				time.Sleep(300 * time.Millisecond)
+			}
+
+			timeParse, _ := time.Parse("2006-01-02T15:04:05-0700", item.Timestamp)
+
+			postID, err := common.ProcessScrapedPost(
+				ctx,
+				dbQueries,
+				sourceId,
+				item.Shortcode,
+				"Threads",
+				timeParse,
+				postType,
+				item.Username,
+				item.Text,
+				likes,
+				reposts,
+				views,
+			)
+			if err != nil {
+				log.Printf("Threads: Failed to save post %s: %v", item.Shortcode, err)
+				continue
+			}
+
+			if replies != nil {
+				if err := common.SavePostComments(ctx, dbQueries, postID, int(*replies)); err != nil {
+					log.Printf("Threads: Failed to save reply count for post %s: %v", item.Shortcode, err)
+				}
+			}
+		}
+
+		next = feed.Paging.Next
+	}
+
+	var followersCount *int
+	profile, err := fetchThreadsInsights(c, token, pid+"/threads_insights", "followers_count")
+	if err != nil {
+		log.Printf("Threads: Failed to get followers for source %s: %v", sourceId, err)
+	} else if followers, ok := profile["followers_count"]; ok {
+		count := int(followers)
+		followersCount = &count
+	}
+
+	err = common.UpdateSourceStats(ctx, dbQueries, sourceId, func(stats *common.ProfileStats) {
+		stats.FollowersCount = followersCount
+	})
+	if err != nil {
+		log.Printf("Threads: Failed to save stats for source %s: %v", sourceId, err)
+	}
+
+	return nil
+}
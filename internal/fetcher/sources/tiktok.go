@@ -234,7 +234,7 @@ func FetchTikTokPosts(dbQueries *database.Queries, c *common.Client, uid uuid.UU
 		viewsCount := parseCount(item.Views)
 		likesCount := parseCount(item.Likes)
 
-		err = common.ProcessScrapedPost(
+		_, err = common.ProcessScrapedPost(
 			context.Background(), dbQueries, sourceId, item.ID, "TikTok", createdAt, postType, username, content,
 			sql.NullInt64{Int64: int64(likesCount), Valid: likesCount >= 0},
 			sql.NullInt64{Int64: 0, Valid: false},
@@ -247,6 +247,7 @@ func FetchTikTokPosts(dbQueries *database.Queries, c *common.Client, uid uuid.UU
 	}
 
 	var followersCount *int
+	var demographics []common.DemographicEntry
 	err = chromedp.Run(ctx,
 		chromedp.Navigate("https://www.tiktok.com/tiktokstudio/analytics/followers"),
 		chromedp.Sleep(3*time.Second),
@@ -272,6 +273,30 @@ func FetchTikTokPosts(dbQueries *database.Queries, c *common.Client, uid uuid.UU
 				count := parseCount(followerText)
 				followersCount = &count
 			}
+
+			if err := chromedp.Evaluate(`
+				(function() {
+					const dimensions = { 'Gender': 'gender', 'Age': 'age', 'Locations': 'country' };
+					const entries = [];
+					const cards = document.querySelectorAll('div[data-tt="components_AnalyticsCard_CardWrapper"]');
+					for (const card of cards) {
+						const lines = card.innerText.split('\n').map(l => l.trim()).filter(l => l !== '');
+						if (lines.length === 0 || !(lines[0] in dimensions)) {
+							continue;
+						}
+						for (let i = 1; i < lines.length - 1; i++) {
+							const match = lines[i + 1].match(/^([0-9.]+)%$/);
+							if (match && !lines[i].endsWith('%')) {
+								entries.push({ dimension: dimensions[lines[0]], bucket: lines[i], value: parseFloat(match[1]) });
+								i++;
+							}
+						}
+					}
+					return entries;
+				})()
+			`, &demographics).Do(ctx); err != nil {
+				log.Printf("TikTok: Failed to scrape demographics: %v", err)
+			}
 			return nil
 		}),
 	)
@@ -289,6 +314,12 @@ func FetchTikTokPosts(dbQueries *database.Queries, c *common.Client, uid uuid.UU
 		log.Printf("TikTok: Failed to update stats for source %s: %v", sourceId, err)
 	}
 
+	if len(demographics) > 0 && !common.HasDemographicsSnapshotToday(context.Background(), dbQueries, sourceId) {
+		if err := common.SaveDemographicsSnapshot(context.Background(), dbQueries, sourceId, demographics); err != nil {
+			log.Printf("TikTok: Failed to save demographics for source %s: %v", sourceId, err)
+		}
+	}
+
 	return nil
 }
 
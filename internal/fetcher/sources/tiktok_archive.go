@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var tiktokArchiveLink = regexp.MustCompile(`/(video|photo)/(\d+)`)
+
+type tiktokArchiveVideo struct {
+	Date  string `json:"Date"`
+	Link  string `json:"Link"`
+	Likes string `json:"Likes"`
+	Title string `json:"Title"`
+	Desc  string `json:"Desc"`
+}
+
+type tiktokArchiveVideoList struct {
+	VideoList []tiktokArchiveVideo `json:"VideoList"`
+}
+
+type tiktokArchive struct {
+	// Older exports put uploads under "Video", newer ones under "Post"
+	Video struct {
+		Videos tiktokArchiveVideoList `json:"Videos"`
+	} `json:"Video"`
+	Post struct {
+		Posts tiktokArchiveVideoList `json:"Posts"`
+	} `json:"Post"`
+}
+
+func parseTikTokArchive(r io.ReaderAt, size int64, filename string) ([]archivedPost, error) {
+	files, err := readArchiveFiles(r, size, filename, func(name string) bool {
+		return strings.HasPrefix(name, "user_data") && strings.HasSuffix(name, ".json")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []archivedPost
+	for _, data := range files {
+		var archive tiktokArchive
+		if err := json.Unmarshal(data, &archive); err != nil {
+			return nil, err
+		}
+
+		videos := append(archive.Video.Videos.VideoList, archive.Post.Posts.VideoList...)
+		for _, video := range videos {
+			match := tiktokArchiveLink.FindStringSubmatch(video.Link)
+			if match == nil {
+				continue
+			}
+
+			createdAt, err := time.Parse("2006-01-02 15:04:05", video.Date)
+			if err != nil {
+				createdAt = extractTimestampFromID(match[2])
+			}
+
+			content := video.Desc
+			if content == "" {
+				content = video.Title
+			}
+
+			var likes sql.NullInt64
+			if n, err := strconv.ParseInt(strings.TrimSpace(video.Likes), 10, 64); err == nil {
+				likes = sql.NullInt64{Int64: n, Valid: true}
+			}
+
+			posts = append(posts, archivedPost{
+				NetworkInternalID: match[2],
+				CreatedAt:         createdAt,
+				PostType:          match[1],
+				Content:           content,
+				Likes:             likes,
+			})
+		}
+	}
+
+	if len(posts) == 0 {
+		return nil, fmt.Errorf("no posts found, expected the JSON data export")
+	}
+
+	return posts, nil
+}
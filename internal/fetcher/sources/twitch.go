@@ -0,0 +1,304 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+type twitchTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	Message     string `json:"message"`
+}
+
+type twitchCachedToken struct {
+	accessToken string
+	expiresAt   time.Time
+}
+
+var (
+	twitchTokenCache   = make(map[string]twitchCachedToken)
+	twitchTokenCacheMu sync.Mutex
+)
+
+type twitchUsersResponse struct {
+	Data []struct {
+		ID          string `json:"id"`
+		Login       string `json:"login"`
+		DisplayName string `json:"display_name"`
+	} `json:"data"`
+}
+
+type twitchFollowersResponse struct {
+	Total int `json:"total"`
+}
+
+type twitchVideosResponse struct {
+	Data []struct {
+		ID          string    `json:"id"`
+		StreamID    *string   `json:"stream_id"`
+		UserLogin   string    `json:"user_login"`
+		Title       string    `json:"title"`
+		Description string    `json:"description"`
+		CreatedAt   time.Time `json:"created_at"`
+		ViewCount   int       `json:"view_count"`
+		Type        string    `json:"type"`
+		Duration    string    `json:"duration"`
+	} `json:"data"`
+	Pagination struct {
+		Cursor string `json:"cursor"`
+	} `json:"pagination"`
+}
+
+type twitchStreamsResponse struct {
+	Data []struct {
+		ID          string `json:"id"`
+		ViewerCount int    `json:"viewer_count"`
+	} `json:"data"`
+}
+
+type twitchClient struct {
+	http        *common.Client
+	clientID    string
+	accessToken string
+}
+
+func getTwitchClient(ctx context.Context, dbQueries *database.Queries, c *common.Client, encryptionKey []byte, sid uuid.UUID) (*twitchClient, error) {
+	credentials, _, _, _, err := authhelp.GetSourceToken(ctx, dbQueries, encryptionKey, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(credentials, ":::")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid Twitch credentials format")
+	}
+
+	twitchTokenCacheMu.Lock()
+	cached, ok := twitchTokenCache[parts[0]]
+	twitchTokenCacheMu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return &twitchClient{http: c, clientID: parts[0], accessToken: cached.accessToken}, nil
+	}
+
+	resp, err := c.HTTPClient.PostForm("https://id.twitch.tv/oauth2/token", url.Values{
+		"client_id":     {parts[0]},
+		"client_secret": {parts[1]},
+		"grant_type":    {"client_credentials"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var token twitchTokenResponse
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, err
+	}
+
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("failed to obtain app access token: %s", token.Message)
+	}
+
+	twitchTokenCacheMu.Lock()
+	twitchTokenCache[parts[0]] = twitchCachedToken{
+		accessToken: token.AccessToken,
+		expiresAt:   time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Hour),
+	}
+	twitchTokenCacheMu.Unlock()
+
+	return &twitchClient{
+		http:        c,
+		clientID:    parts[0],
+		accessToken: token.AccessToken,
+	}, nil
+}
+
+func (t *twitchClient) get(endpoint string, params url.Values, out any) error {
+	req, err := http.NewRequest("GET", "https://api.twitch.tv/helix/"+endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Client-Id", t.clientID)
+	req.Header.Set("Authorization", "Bearer "+t.accessToken)
+
+	resp, err := t.http.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed to get a successfull response. %v: %v", resp.StatusCode, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+func (t *twitchClient) userID(login string) (string, error) {
+	var users twitchUsersResponse
+	if err := t.get("users", url.Values{"login": {login}}, &users); err != nil {
+		return "", err
+	}
+	if len(users.Data) == 0 {
+		return "", fmt.Errorf("user %s not found", login)
+	}
+	return users.Data[0].ID, nil
+}
+
+func FetchTwitchPosts(dbQueries *database.Queries, c *common.Client, encryptionKey []byte, sourceId uuid.UUID) error {
+
+	ctx := context.Background()
+
+	source, err := dbQueries.GetSourceById(ctx, sourceId)
+	if err != nil {
+		return fmt.Errorf("failed to get source: %w", err)
+	}
+
+	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
+	if err != nil {
+		return err
+	}
+
+	client, err := getTwitchClient(ctx, dbQueries, c, encryptionKey, sourceId)
+	if err != nil {
+		return fmt.Errorf("Twitch: %w", err)
+	}
+
+	broadcasterID, err := client.userID(source.UserName)
+	if err != nil {
+		return fmt.Errorf("Twitch: %w", err)
+	}
+
+	var followersCount *int
+	var followers twitchFollowersResponse
+	if err := client.get("channels/followers", url.Values{"broadcaster_id": {broadcasterID}}, &followers); err != nil {
+		log.Printf("Twitch: Failed to get followers for source %s: %v", sourceId, err)
+	} else {
+		followersCount = &followers.Total
+	}
+
+	cursor := ""
+	for {
+		params := url.Values{
+			"user_id": {broadcasterID},
+			"type":    {"all"},
+			"first":   {"100"},
+		}
+		if cursor != "" {
+			params.Set("after", cursor)
+		}
+
+		var videos twitchVideosResponse
+		if err := client.get("videos", params, &videos); err != nil {
+			return fmt.Errorf("Twitch: failed to get videos: %w", err)
+		}
+
+		for _, video := range videos.Data {
+			if exclusionMap[video.ID] {
+				continue
+			}
+
+			postType := "video"
+			if video.Type == "archive" {
+				postType = "stream"
+			}
+
+			_, err = common.ProcessScrapedPost(
+				ctx,
+				dbQueries,
+				sourceId,
+				video.ID,
+				"Twitch",
+				video.CreatedAt,
+				postType,
+				video.UserLogin,
+				strings.TrimSpace(fmt.Sprintf("%s\n\n%s", video.Title, video.Description)),
+				sql.NullInt64{},
+				sql.NullInt64{},
+				sql.NullInt64{Int64: int64(video.ViewCount), Valid: true},
+			)
+			if err != nil {
+				log.Printf("Twitch: Failed to save video %s: %v", video.ID, err)
+				continue
+			}
+
+			streamID := ""
+			if video.StreamID != nil {
+				streamID = *video.StreamID
+			}
+			duration, _ := time.ParseDuration(video.Duration)
+
+			if err := common.SaveStreamStats(ctx, dbQueries, sourceId, "Twitch", video.ID, streamID, duration); err != nil {
+				log.Printf("Twitch: Failed to save stream stats for video %s: %v", video.ID, err)
+			}
+		}
+
+		if videos.Pagination.Cursor == "" || len(videos.Data) == 0 {
+			break
+		}
+		cursor = videos.Pagination.Cursor
+	}
+
+	err = common.UpdateSourceStats(ctx, dbQueries, sourceId, func(stats *common.ProfileStats) {
+		stats.FollowersCount = followersCount
+	})
+	if err != nil {
+		log.Printf("Twitch: Failed to save stats for source %s: %v", sourceId, err)
+	}
+
+	return nil
+}
+
+func SampleTwitchViewers(dbQueries *database.Queries, c *common.Client, encryptionKey []byte, sourceId uuid.UUID) error {
+
+	ctx := context.Background()
+
+	source, err := dbQueries.GetSourceById(ctx, sourceId)
+	if err != nil {
+		return fmt.Errorf("failed to get source: %w", err)
+	}
+
+	client, err := getTwitchClient(ctx, dbQueries, c, encryptionKey, sourceId)
+	if err != nil {
+		return fmt.Errorf("Twitch: %w", err)
+	}
+
+	var streams twitchStreamsResponse
+	if err := client.get("streams", url.Values{"user_login": {source.UserName}}, &streams); err != nil {
+		return fmt.Errorf("Twitch: failed to get live stream: %w", err)
+	}
+
+	for _, stream := range streams.Data {
+		if err := common.SaveStreamViewerSample(ctx, dbQueries, sourceId, stream.ID, stream.ViewerCount); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
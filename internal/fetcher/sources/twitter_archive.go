@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+)
+
+var twitterArchiveTweetsFile = regexp.MustCompile(`^tweets?(-part\d+)?\.js$`)
+
+type twitterArchiveTweet struct {
+	Tweet struct {
+		IDStr                string `json:"id_str"`
+		FullText             string `json:"full_text"`
+		CreatedAt            string `json:"created_at"`
+		FavoriteCount        string `json:"favorite_count"`
+		RetweetCount         string `json:"retweet_count"`
+		InReplyToStatusIDStr string `json:"in_reply_to_status_id_str"`
+	} `json:"tweet"`
+}
+
+func twitterArchiveCount(value string) sql.NullInt64 {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: n, Valid: true}
+}
+
+func importTwitterArchive(ctx context.Context, dbQueries *database.Queries, source database.Source, r io.ReaderAt, size int64, filename string) (ArchiveImportResult, error) {
+	files, err := readArchiveFiles(r, size, filename, twitterArchiveTweetsFile.MatchString)
+	if err != nil {
+		return ArchiveImportResult{}, fmt.Errorf("X: failed to read archive: %w", err)
+	}
+
+	var result ArchiveImportResult
+	for name, data := range files {
+		// The file is a script assigning the array to window.YTD.tweets.partN
+		start := bytes.IndexByte(data, '[')
+		if start < 0 {
+			return result, fmt.Errorf("X: no tweets found in %s", name)
+		}
+
+		var tweets []twitterArchiveTweet
+		if err := json.Unmarshal(data[start:], &tweets); err != nil {
+			return result, fmt.Errorf("X: failed to parse %s: %w", name, err)
+		}
+
+		for _, item := range tweets {
+			tweet := item.Tweet
+			if tweet.IDStr == "" {
+				continue
+			}
+
+			createdAt, err := time.Parse(time.RubyDate, tweet.CreatedAt)
+			if err != nil {
+				return result, fmt.Errorf("X: tweet %s: %w", tweet.IDStr, err)
+			}
+
+			postType := "tweet"
+			switch {
+			case strings.HasPrefix(tweet.FullText, "RT @"):
+				postType = "repost"
+			case tweet.InReplyToStatusIDStr != "":
+				postType = "reply"
+			}
+
+			post := ManualPost{
+				URL:       "https://x.com/i/status/" + tweet.IDStr,
+				CreatedAt: createdAt,
+				PostType:  postType,
+				Content:   tweet.FullText,
+				Likes:     twitterArchiveCount(tweet.FavoriteCount),
+				Reposts:   twitterArchiveCount(tweet.RetweetCount),
+			}
+
+			_, err = dbQueries.GetPostByNetworkAndId(ctx, database.GetPostByNetworkAndIdParams{
+				NetworkInternalID: post.URL,
+				Network:           "Manual",
+			})
+			if err == nil {
+				result.Existing++
+				continue
+			}
+
+			if err := saveManualPost(ctx, dbQueries, source, post); err != nil {
+				return result, fmt.Errorf("X: failed to save tweet %s: %w", tweet.IDStr, err)
+			}
+			result.Created++
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+)
+
+type weasylUserResponse struct {
+	Login      string `json:"login"`
+	Statistics struct {
+		Followed    int `json:"followed"`
+		Following   int `json:"following"`
+		Submissions int `json:"submissions"`
+	} `json:"statistics"`
+}
+
+type weasylGalleryResponse struct {
+	Submissions []struct {
+		SubmitID int `json:"submitid"`
+	} `json:"submissions"`
+	NextID *int `json:"nextid"`
+}
+
+type weasylSubmission struct {
+	SubmitID    int       `json:"submitid"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	PostedAt    time.Time `json:"posted_at"`
+	Subtype     string    `json:"subtype"`
+	OwnerLogin  string    `json:"owner_login"`
+	Views       int       `json:"views"`
+	Favorites   int       `json:"favorites"`
+	Comments    int       `json:"comments"`
+}
+
+func fetchWeasylJSON(c *common.Client, endpoint string, out any) error {
+	req, err := http.NewRequest("GET", "https://www.weasyl.com/api/"+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed to get a successfull response. %v: %v", resp.StatusCode, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+func FetchWeasylPosts(dbQueries *database.Queries, c *common.Client, uid uuid.UUID, sourceId uuid.UUID) error {
+
+	source, err := dbQueries.GetSourceById(context.Background(), sourceId)
+	if err != nil {
+		return fmt.Errorf("failed to get source: %w", err)
+	}
+
+	exclusionMap, err := common.LoadExclusionMap(dbQueries, sourceId)
+	if err != nil {
+		return err
+	}
+
+	login := url.PathEscape(source.UserName)
+
+	var profile weasylUserResponse
+	if err := fetchWeasylJSON(c, "users/"+login+"/view", &profile); err != nil {
+		return fmt.Errorf("Weasyl: failed to get profile: %w", err)
+	}
+
+	var submissionIDs []int
+	nextID := 0
+	for {
+		endpoint := "users/" + login + "/gallery?count=100"
+		if nextID > 0 {
+			endpoint += fmt.Sprintf("&nextid=%d", nextID)
+		}
+
+		var gallery weasylGalleryResponse
+		if err := fetchWeasylJSON(c, endpoint, &gallery); err != nil {
+			return fmt.Errorf("Weasyl: failed to get gallery: %w", err)
+		}
+
+		for _, s := range gallery.Submissions {
+			submissionIDs = append(submissionIDs, s.SubmitID)
+		}
+
+		if gallery.NextID == nil || *gallery.NextID == 0 || *gallery.NextID == nextID {
+			break
+		}
+		nextID = *gallery.NextID
+	}
+
+	if len(submissionIDs) == 0 {
+		return errors.New("No content found")
+	}
+
+	for _, submitID := range submissionIDs {
+		networkID := fmt.Sprintf("%d", submitID)
+		if exclusionMap[networkID] {
+			continue
+		}
+
+		var sub weasylSubmission
+		if err := fetchWeasylJSON(c, fmt.Sprintf("submissions/%d/view", submitID), &sub); err != nil {
+			log.Printf("Weasyl: Failed to get submission %d: %v", submitID, err)
+			continue
+		}
+
+		postType := sub.Subtype
+		if postType == "" || postType == "visual" {
+			postType = "image"
+		}
+
+		postID, err := common.ProcessScrapedPost(
+			context.Background(),
+			dbQueries,
+			sourceId,
+			networkID,
+			"Weasyl",
+			sub.PostedAt,
+			postType,
+			profile.Login,
+			fmt.Sprintf("%s\n\n%s", sub.Title, common.StripHTMLToText(sub.Description)),
+			sql.NullInt64{Int64: int64(sub.Favorites), Valid: true},
+			sql.NullInt64{},
+			sql.NullInt64{Int64: int64(sub.Views), Valid: true},
+		)
+		if err != nil {
+			log.Printf("Weasyl: Failed to save submission %d: %v", submitID, err)
+			continue
+		}
+
+		if err := common.SavePostComments(context.Background(), dbQueries, postID, sub.Comments); err != nil {
+			log.Printf("Weasyl: Failed to save comment count for submission %d: %v", submitID, err)
+		}
+	}
+
+	followers := profile.Statistics.Followed
+	following := profile.Statistics.Following
+	err = common.UpdateSourceStats(context.Background(), dbQueries, sourceId, func(stats *common.ProfileStats) {
+		stats.FollowersCount = &followers
+		stats.FollowingCount = &following
+	})
+	if err != nil {
+		log.Printf("Weasyl: Failed to save stats for source %s: %v", sourceId, err)
+	}
+
+	return nil
+}
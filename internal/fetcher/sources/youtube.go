@@ -13,6 +13,7 @@ import (
 	"github.com/fluffyriot/rpsync/internal/database"
 	"github.com/fluffyriot/rpsync/internal/fetcher/common"
 	"github.com/google/uuid"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
@@ -26,24 +27,37 @@ func FetchYouTubePosts(dbQueries *database.Queries, sourceId uuid.UUID, encrypti
 		return fmt.Errorf("failed to get source: %w", err)
 	}
 
-	token, _, _, _, err := authhelp.GetSourceToken(ctx, dbQueries, encryptionKey, sourceId)
+	token, _, sourceAppData, _, err := authhelp.GetSourceToken(ctx, dbQueries, encryptionKey, sourceId)
 	if err != nil {
 		return fmt.Errorf("failed to get source token: %w", err)
 	}
 
-	creds, err := google.CredentialsFromJSON(ctx, []byte(token), youtube.YoutubeReadonlyScope)
-	if err != nil {
-		return fmt.Errorf("failed to parse credentials: %w", err)
+	var clientOption option.ClientOption
+	oauthMode := sourceAppData["auth_mode"] == "oauth"
+
+	if oauthMode {
+		clientID, _ := sourceAppData["client_id"].(string)
+		clientSecret, _ := sourceAppData["client_secret"].(string)
+		googleConfig := authhelp.GenerateGoogleConfig(clientID, clientSecret, "")
+		clientOption = option.WithTokenSource(googleConfig.TokenSource(ctx, &oauth2.Token{RefreshToken: token}))
+	} else {
+		creds, err := google.CredentialsFromJSON(ctx, []byte(token), youtube.YoutubeReadonlyScope)
+		if err != nil {
+			return fmt.Errorf("failed to parse credentials: %w", err)
+		}
+		clientOption = option.WithCredentials(creds)
 	}
 
-	service, err := youtube.NewService(ctx, option.WithCredentials(creds))
+	service, err := youtube.NewService(ctx, clientOption)
 	if err != nil {
 		return fmt.Errorf("failed to create Youtube service: %w", err)
 	}
 
 	call := service.Channels.List([]string{"contentDetails", "id", "snippet", "statistics"}).MaxResults(1)
 
-	if strings.HasPrefix(source.UserName, "@") {
+	if source.AccountID.Valid && source.AccountID.String != "" {
+		call = call.Id(source.AccountID.String)
+	} else if strings.HasPrefix(source.UserName, "@") {
 		call = call.ForHandle(source.UserName)
 	} else if strings.HasPrefix(source.UserName, "UC") && len(source.UserName) == 24 {
 		call = call.Id(source.UserName)
@@ -61,6 +75,15 @@ func FetchYouTubePosts(dbQueries *database.Queries, sourceId uuid.UUID, encrypti
 	}
 
 	channel := response.Items[0]
+
+	// Only handle-based sources follow renames; channel IDs and legacy usernames don't change
+	newHandle := ""
+	if strings.HasPrefix(source.UserName, "@") && channel.Snippet != nil {
+		newHandle = channel.Snippet.CustomUrl
+	}
+	if _, err := common.SyncSourceAccount(ctx, dbQueries, source, channel.Id, source.UserName, newHandle); err != nil {
+		return err
+	}
 	uploadsPlaylistId := channel.ContentDetails.RelatedPlaylists.Uploads
 
 	if channel.Statistics != nil {
@@ -77,6 +100,7 @@ func FetchYouTubePosts(dbQueries *database.Queries, sourceId uuid.UUID, encrypti
 	}
 
 	exclusionMap, _ := common.LoadExclusionMap(dbQueries, sourceId)
+	videoPosts := make(map[string]uuid.UUID)
 
 	nextPageToken := ""
 	for {
@@ -122,6 +146,7 @@ func FetchYouTubePosts(dbQueries *database.Queries, sourceId uuid.UUID, encrypti
 				log.Printf("Failed to create/update post %s: %v", videoId, err)
 				continue
 			}
+			videoPosts[videoId] = postID
 
 			videoCall := service.Videos.List([]string{"statistics"}).Id(videoId)
 			videoResp, err := videoCall.Do()
@@ -152,5 +177,11 @@ func FetchYouTubePosts(dbQueries *database.Queries, sourceId uuid.UUID, encrypti
 		}
 	}
 
+	if oauthMode {
+		if err := fetchYouTubeAnalytics(ctx, clientOption, dbQueries, sourceId, videoPosts); err != nil {
+			log.Printf("Failed to fetch YouTube Analytics for %s: %v", source.UserName, err)
+		}
+	}
+
 	return nil
 }
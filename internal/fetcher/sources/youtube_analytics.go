@@ -0,0 +1,237 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package sources
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/fetcher/common"
+	"github.com/google/uuid"
+	"google.golang.org/api/option"
+	"google.golang.org/api/youtubeanalytics/v2"
+)
+
+const (
+	youtubeAnalyticsDays      = 7
+	youtubeAnalyticsMaxVideos = 200
+	youtubeBaseMetrics        = "views,estimatedMinutesWatched,averageViewDuration,subscribersGained"
+	youtubeImpressionMetrics  = "videoThumbnailImpressions,videoThumbnailImpressionsClickRate"
+	youtubeDemographicsDays   = 28
+	youtubeMaxCountries       = 25
+)
+
+type youtubeAnalyticsRow map[string]any
+
+func (r youtubeAnalyticsRow) int64Value(key string) sql.NullInt64 {
+	v, ok := r[key].(float64)
+	if !ok {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(v), Valid: true}
+}
+
+func (r youtubeAnalyticsRow) floatValue(key string) sql.NullFloat64 {
+	v, ok := r[key].(float64)
+	if !ok {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: v, Valid: true}
+}
+
+func (r youtubeAnalyticsRow) stringValue(key string) string {
+	v, _ := r[key].(string)
+	return v
+}
+
+func fetchYouTubeAnalytics(ctx context.Context, clientOption option.ClientOption, dbQueries *database.Queries, sourceID uuid.UUID, videoPosts map[string]uuid.UUID) error {
+	svc, err := youtubeanalytics.NewService(ctx, clientOption)
+	if err != nil {
+		return fmt.Errorf("failed to create YouTube Analytics service: %w", err)
+	}
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -youtubeAnalyticsDays)
+
+	if err := fetchYouTubeChannelAnalytics(ctx, svc, dbQueries, sourceID, startDate, endDate); err != nil {
+		return fmt.Errorf("failed to fetch channel analytics: %w", err)
+	}
+
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		if err := fetchYouTubeVideoAnalytics(ctx, svc, dbQueries, day, videoPosts); err != nil {
+			log.Printf("Failed to fetch video analytics for %s: %v", day.Format("2006-01-02"), err)
+		}
+	}
+
+	if !common.HasDemographicsSnapshotToday(ctx, dbQueries, sourceID) {
+		demographics, err := fetchYouTubeDemographics(ctx, svc, endDate.AddDate(0, 0, -youtubeDemographicsDays), endDate)
+		if err != nil {
+			log.Printf("Failed to fetch YouTube demographics: %v", err)
+		} else if err := common.SaveDemographicsSnapshot(ctx, dbQueries, sourceID, demographics); err != nil {
+			log.Printf("Failed to save YouTube demographics: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func fetchYouTubeDemographics(ctx context.Context, svc *youtubeanalytics.Service, startDate, endDate time.Time) ([]common.DemographicEntry, error) {
+	rows, err := queryYouTubeAnalytics(ctx, svc, startDate, endDate, "ageGroup,gender", "viewerPercentage", "", "", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	ages := make(map[string]float64)
+	genders := make(map[string]float64)
+	for _, row := range rows {
+		pct := row.floatValue("viewerPercentage").Float64
+		ages[strings.TrimPrefix(row.stringValue("ageGroup"), "age")] += pct
+		genders[row.stringValue("gender")] += pct
+	}
+
+	var entries []common.DemographicEntry
+	for bucket, value := range ages {
+		entries = append(entries, common.DemographicEntry{Dimension: "age", Bucket: bucket, Value: value})
+	}
+	for bucket, value := range genders {
+		entries = append(entries, common.DemographicEntry{Dimension: "gender", Bucket: bucket, Value: value})
+	}
+
+	rows, err = queryYouTubeAnalytics(ctx, svc, startDate, endDate, "country", "views", "", "-views", youtubeMaxCountries)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		entries = append(entries, common.DemographicEntry{
+			Dimension: "country",
+			Bucket:    row.stringValue("country"),
+			Value:     row.floatValue("views").Float64,
+		})
+	}
+
+	return entries, nil
+}
+
+func fetchYouTubeChannelAnalytics(ctx context.Context, svc *youtubeanalytics.Service, dbQueries *database.Queries, sourceID uuid.UUID, startDate, endDate time.Time) error {
+	rows, err := queryYouTubeAnalytics(ctx, svc, startDate, endDate, "day", youtubeBaseMetrics+",subscribersLost", youtubeImpressionMetrics, "day", 0)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		date, err := time.Parse("2006-01-02", row.stringValue("day"))
+		if err != nil {
+			log.Printf("Error parsing YouTube Analytics date %s: %v", row.stringValue("day"), err)
+			continue
+		}
+
+		err = dbQueries.SyncSourceAnalytics(ctx, database.SyncSourceAnalyticsParams{
+			ID:                  uuid.New(),
+			Date:                date,
+			SourceID:            sourceID,
+			Views:               row.int64Value("views"),
+			WatchTimeMinutes:    row.floatValue("estimatedMinutesWatched"),
+			AverageViewDuration: row.floatValue("averageViewDuration"),
+			Impressions:         row.int64Value("videoThumbnailImpressions"),
+			ImpressionsCtr:      row.floatValue("videoThumbnailImpressionsClickRate"),
+			SubscribersGained:   row.int64Value("subscribersGained"),
+			SubscribersLost:     row.int64Value("subscribersLost"),
+		})
+		if err != nil {
+			log.Printf("Failed to save channel analytics for %s: %v", date.Format("2006-01-02"), err)
+		}
+	}
+
+	return nil
+}
+
+func fetchYouTubeVideoAnalytics(ctx context.Context, svc *youtubeanalytics.Service, dbQueries *database.Queries, day time.Time, videoPosts map[string]uuid.UUID) error {
+	rows, err := queryYouTubeAnalytics(ctx, svc, day, day, "video", youtubeBaseMetrics, youtubeImpressionMetrics, "-views", youtubeAnalyticsMaxVideos)
+	if err != nil {
+		return err
+	}
+
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+
+	for _, row := range rows {
+		postID, ok := videoPosts[row.stringValue("video")]
+		if !ok {
+			continue
+		}
+
+		err = dbQueries.SyncPostAnalytics(ctx, database.SyncPostAnalyticsParams{
+			ID:                  uuid.New(),
+			Date:                date,
+			PostID:              postID,
+			Views:               row.int64Value("views"),
+			WatchTimeMinutes:    row.floatValue("estimatedMinutesWatched"),
+			AverageViewDuration: row.floatValue("averageViewDuration"),
+			Impressions:         row.int64Value("videoThumbnailImpressions"),
+			ImpressionsCtr:      row.floatValue("videoThumbnailImpressionsClickRate"),
+			SubscribersGained:   row.int64Value("subscribersGained"),
+		})
+		if err != nil {
+			log.Printf("Failed to save video analytics for %s: %v", row.stringValue("video"), err)
+		}
+	}
+
+	return nil
+}
+
+func queryYouTubeAnalytics(ctx context.Context, svc *youtubeanalytics.Service, startDate, endDate time.Time, dimensions, metrics, optionalMetrics, sort string, maxResults int64) ([]youtubeAnalyticsRow, error) {
+	run := func(metrics string) (*youtubeanalytics.QueryResponse, error) {
+		call := svc.Reports.Query().
+			Ids("channel==MINE").
+			StartDate(startDate.Format("2006-01-02")).
+			EndDate(endDate.Format("2006-01-02")).
+			Dimensions(dimensions).
+			Metrics(metrics).
+			Context(ctx)
+		if sort != "" {
+			call = call.Sort(sort)
+		}
+		if maxResults > 0 {
+			call = call.MaxResults(maxResults)
+		}
+		return call.Do()
+	}
+
+	if optionalMetrics == "" {
+		resp, err := run(metrics)
+		if err != nil {
+			return nil, err
+		}
+		return parseYouTubeAnalyticsRows(resp), nil
+	}
+
+	resp, err := run(metrics + "," + optionalMetrics)
+	if err != nil {
+		log.Printf("YouTube Analytics impressions unavailable, retrying without them: %v", err)
+		resp, err = run(metrics)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return parseYouTubeAnalyticsRows(resp), nil
+}
+
+func parseYouTubeAnalyticsRows(resp *youtubeanalytics.QueryResponse) []youtubeAnalyticsRow {
+	rows := make([]youtubeAnalyticsRow, 0, len(resp.Rows))
+	for _, values := range resp.Rows {
+		row := make(youtubeAnalyticsRow, len(resp.ColumnHeaders))
+		for i, header := range resp.ColumnHeaders {
+			if i < len(values) {
+				row[header.Name] = values[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
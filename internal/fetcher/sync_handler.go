@@ -4,22 +4,26 @@ package fetcher
 import (
 	"context"
 	"database/sql"
+	"log"
 	"time"
 
 	"github.com/fluffyriot/rpsync/internal/database"
 	"github.com/fluffyriot/rpsync/internal/fetcher/common"
 	"github.com/fluffyriot/rpsync/internal/fetcher/sources"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/webhook"
 	"github.com/google/uuid"
 )
 
 func executeSync(
 	ctx context.Context,
 	dbQueries *database.Queries,
-	sourceID uuid.UUID,
+	source database.Source,
 	syncFunc func() error,
+	archiveUnsynced bool,
 	isLastRetry bool,
 ) error {
 	syncStartTime := time.Now()
+	sourceID := source.ID
 
 	_, err := dbQueries.UpdateSourceSyncStatusById(ctx, database.UpdateSourceSyncStatusByIdParams{
 		ID:         sourceID,
@@ -29,8 +33,16 @@ func executeSync(
 		return err
 	}
 
+	// Read before the sync so a milestone can be spotted by comparing
+	previousFollowers, err := dbQueries.GetLatestSourceFollowers(ctx, sourceID)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Source %s: reading follower count: %v", sourceID, err)
+	}
+
+	newPostsRun := common.TrackNewPosts(sourceID)
 	err = syncFunc()
 	if err != nil {
+		newPostsRun.Stop()
 		_, _ = dbQueries.UpdateSourceSyncStatusById(ctx, database.UpdateSourceSyncStatusByIdParams{
 			ID:           sourceID,
 			SyncStatus:   "Failed",
@@ -44,15 +56,22 @@ func executeSync(
 				SourceID:  uuid.NullUUID{UUID: sourceID, Valid: true},
 				Message:   err.Error(),
 			})
+			if emitErr := webhook.EmitSourceSyncFailed(ctx, dbQueries, source, err); emitErr != nil {
+				log.Printf("Source %s: %v", sourceID, emitErr)
+			}
 		}
 		return err
 	}
 
-	if err := dbQueries.ArchiveUnsyncedPosts(ctx, database.ArchiveUnsyncedPostsParams{
-		SourceID:     sourceID,
-		LastSyncedAt: syncStartTime.Add(-36 * time.Hour),
-	}); err != nil {
-		return err
+	newPosts := newPostsRun.Take()
+
+	if archiveUnsynced {
+		if err := dbQueries.ArchiveUnsyncedPosts(ctx, database.ArchiveUnsyncedPostsParams{
+			SourceID:     sourceID,
+			LastSyncedAt: syncStartTime.Add(-36 * time.Hour),
+		}); err != nil {
+			return err
+		}
 	}
 
 	_, err = dbQueries.UpdateSourceSyncStatusById(ctx, database.UpdateSourceSyncStatusByIdParams{
@@ -61,8 +80,41 @@ func executeSync(
 		StatusReason: sql.NullString{},
 		LastSynced:   sql.NullTime{Time: time.Now(), Valid: true},
 	})
+	if err != nil {
+		return err
+	}
+
+	emitSyncEvents(ctx, dbQueries, source, previousFollowers, newPosts)
+
+	return nil
+}
 
-	return err
+// emitSyncEvents queues the webhook events of a successful sync. Posts found by
+// a source's first sync are its backlog, not new, so they are not announced.
+func emitSyncEvents(ctx context.Context, dbQueries *database.Queries, source database.Source, previousFollowers sql.NullInt64, newPosts []database.Post) {
+	if source.LastSynced.Valid && len(newPosts) > 0 {
+		if err := webhook.EmitNewPosts(ctx, dbQueries, source, newPosts); err != nil {
+			log.Printf("Source %s: %v", source.ID, err)
+		}
+	}
+
+	if !previousFollowers.Valid {
+		return
+	}
+
+	followers, err := dbQueries.GetLatestSourceFollowers(ctx, source.ID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Source %s: reading follower count: %v", source.ID, err)
+		}
+		return
+	}
+
+	if followers.Valid {
+		if err := webhook.EmitFollowersMilestone(ctx, dbQueries, source, previousFollowers.Int64, followers.Int64); err != nil {
+			log.Printf("Source %s: %v", source.ID, err)
+		}
+	}
 }
 
 func SyncBySource(sid uuid.UUID, dbQueries *database.Queries, c *common.Client, ver string, encryptionKey []byte, isLastRetry bool) error {
@@ -72,15 +124,18 @@ func SyncBySource(sid uuid.UUID, dbQueries *database.Queries, c *common.Client,
 		return err
 	}
 
-	return executeSync(context.Background(), dbQueries, source.ID, func() error {
+	return executeSync(context.Background(), dbQueries, source, func() error {
 		switch source.Network {
 		case "Bluesky":
-			return sources.FetchBlueskyPosts(dbQueries, c, source.UserID, source.ID)
+			return sources.FetchBlueskyPosts(dbQueries, c, encryptionKey, source.ID)
 
 		case "Instagram":
 			if err := sources.FetchInstagramPosts(dbQueries, c, source.ID, ver, encryptionKey); err != nil {
 				return err
 			}
+			if err := sources.FetchInstagramStories(dbQueries, c, source.ID, ver, encryptionKey); err != nil {
+				log.Printf("Instagram: Failed to fetch stories for source %s: %v", source.ID, err)
+			}
 			return sources.FetchInstagramTags(dbQueries, c, source.ID, ver, encryptionKey)
 
 		case "Murrtube":
@@ -110,8 +165,68 @@ func SyncBySource(sid uuid.UUID, dbQueries *database.Queries, c *common.Client,
 		case "Discord":
 			return sources.FetchDiscordPosts(dbQueries, encryptionKey, source.ID, c)
 
+		case "FurAffinity":
+			return sources.FetchFurAffinityPosts(dbQueries, c, source.UserID, source.ID)
+
+		case "Weasyl":
+			return sources.FetchWeasylPosts(dbQueries, c, source.UserID, source.ID)
+
+		case "Inkbunny":
+			return sources.FetchInkbunnyPosts(dbQueries, c, source.UserID, source.ID)
+
+		case "DeviantArt":
+			return sources.FetchDeviantArtPosts(dbQueries, c, encryptionKey, source.ID)
+
+		case "Pixiv":
+			return sources.FetchPixivPosts(dbQueries, c, source.UserID, source.ID)
+
+		case "Twitch":
+			return sources.FetchTwitchPosts(dbQueries, c, encryptionKey, source.ID)
+
+		case "Kick":
+			return sources.FetchKickPosts(dbQueries, c, source.UserID, source.ID)
+
+		case "Patreon":
+			return sources.FetchPatreonPosts(dbQueries, c, encryptionKey, source.ID)
+
+		case "Ko-fi":
+			return sources.FetchKofiStats(dbQueries, source.ID)
+
+		case "SubscribeStar":
+			return sources.FetchSubscribeStarStats(dbQueries, c, source.ID)
+
+		case "Reddit":
+			return sources.FetchRedditPosts(dbQueries, c, source.UserID, source.ID)
+
+		case "Lemmy":
+			return sources.FetchLemmyPosts(dbQueries, c, source.UserID, source.ID)
+
+		case "Threads":
+			return sources.FetchThreadsPosts(dbQueries, c, encryptionKey, source.ID)
+
+		case "Facebook":
+			return sources.FetchFacebookPagePosts(dbQueries, c, source.ID, ver, encryptionKey)
+
+		case "Manual":
+			return nil
+
 		default:
 			return nil
 		}
-	}, isLastRetry)
+	}, source.Network != "Manual", isLastRetry)
+}
+
+func SyncInstagramStories(sid uuid.UUID, dbQueries *database.Queries, c *common.Client, ver string, encryptionKey []byte) error {
+	return sources.FetchInstagramStories(dbQueries, c, sid, ver, encryptionKey)
+}
+
+func SampleLiveViewers(sid uuid.UUID, network string, dbQueries *database.Queries, c *common.Client, encryptionKey []byte) error {
+	switch network {
+	case "Twitch":
+		return sources.SampleTwitchViewers(dbQueries, c, encryptionKey, sid)
+	case "Kick":
+		return sources.SampleKickViewers(dbQueries, c, sid)
+	default:
+		return nil
+	}
 }
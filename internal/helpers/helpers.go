@@ -3,6 +3,7 @@ package helpers
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 )
 
@@ -28,11 +29,35 @@ var AvailableSources = []SourceNetwork{
 	{Name: "Murrtube", Color: "#344aa8"},
 	{Name: "Discord", Color: "#5662f6"},
 	{Name: "FurTrack", Color: "#2d0e4c"},
+	{Name: "FurAffinity", Color: "#2e3b41"},
+	{Name: "Weasyl", Color: "#990000"},
+	{Name: "Inkbunny", Color: "#73d216"},
+	{Name: "DeviantArt", Color: "#05cc47"},
+	{Name: "Pixiv", Color: "#0096fa"},
+	{Name: "Twitch", Color: "#9146ff"},
+	{Name: "Kick", Color: "#53fc18"},
+	{Name: "Patreon", Color: "#ff424d"},
+	{Name: "Ko-fi", Color: "#13c3ff"},
+	{Name: "SubscribeStar", Color: "#ffbf00"},
+	{Name: "Reddit", Color: "#ff4500"},
+	{Name: "Lemmy", Color: "#00bc8c"},
+	{Name: "Threads", Color: "#000000"},
+	{Name: "Facebook", Color: "#0866ff"},
+	{Name: "Manual", Color: "#6b7280"},
 }
 
 var AvailableTargets = []TargetNetwork{
 	{Name: "NocoDB", Color: "#4351e8"},
 	{Name: "CSV", Color: "#45b058"},
+	{Name: "Notion", Color: "#000000"},
+	{Name: "Google Sheets", Color: "#0f9d58"},
+	{Name: "Baserow", Color: "#5190ef"},
+	{Name: "Grist", Color: "#16b378"},
+	{Name: "Teable", Color: "#1f2937"},
+	{Name: "PostgreSQL", Color: "#336791"},
+	{Name: "MySQL", Color: "#00758f"},
+	{Name: "SQLite", Color: "#003b57"},
+	{Name: "Webhook", Color: "#c73a63"},
 }
 
 func ConvNetworkToURL(network, username string) (string, error) {
@@ -55,11 +80,45 @@ func ConvNetworkToURL(network, username string) (string, error) {
 		return "https://youtube.com/" + username, nil
 	case "Discord":
 		return "https://discord.com/channels/" + username, nil
+	case "FurAffinity":
+		return "https://www.furaffinity.net/user/" + username + "/", nil
+	case "Weasyl":
+		return "https://www.weasyl.com/~" + username, nil
+	case "Inkbunny":
+		return "https://inkbunny.net/" + username, nil
+	case "DeviantArt":
+		return "https://www.deviantart.com/" + username, nil
+	case "Pixiv":
+		return "https://www.pixiv.net/users/" + username, nil
+	case "Twitch":
+		return "https://www.twitch.tv/" + username, nil
+	case "Kick":
+		return "https://kick.com/" + username, nil
+	case "Patreon":
+		return "https://www.patreon.com/" + username, nil
+	case "Ko-fi":
+		return "https://ko-fi.com/" + username, nil
+	case "SubscribeStar":
+		return "https://www.subscribestar.com/" + username, nil
+	case "Reddit":
+		return "https://www.reddit.com/user/" + username, nil
+	case "Threads":
+		return "https://www.threads.net/@" + username, nil
+	case "Facebook":
+		return "https://www.facebook.com/" + username, nil
+	case "Lemmy":
+		splits := strings.Split(username, "@")
+		return fmt.Sprintf("https://%v/u/%v", splits[1], splits[0]), nil
 	case "Mastodon":
 		splits := strings.Split(username, "@")
 		return fmt.Sprintf("https://%v/@%v", splits[1], splits[0]), nil
 	case "Google Analytics":
 		return "analytics.google.com/analytics/web/", nil
+	case "Manual":
+		if strings.HasPrefix(username, "http://") || strings.HasPrefix(username, "https://") {
+			return username, nil
+		}
+		return "", nil
 	default:
 		return "", fmt.Errorf("network %v not recognized", network)
 	}
@@ -68,6 +127,12 @@ func ConvNetworkToURL(network, username string) (string, error) {
 func ConvPostToURL(network, author, networkId string) (string, error) {
 	switch network {
 	case "Instagram":
+		if storyID, ok := strings.CutPrefix(networkId, "stories/"); ok {
+			return "https://instagram.com/stories/" + author + "/" + storyID, nil
+		}
+		if strings.HasPrefix(networkId, "archive/") {
+			return "https://instagram.com/" + author, nil
+		}
 		return "https://instagram.com/p/" + networkId, nil
 	case "Bluesky":
 		return "https://bsky.app/profile/" + author + "/post/" + networkId, nil
@@ -89,10 +154,125 @@ func ConvPostToURL(network, author, networkId string) (string, error) {
 			return "https://discord.com/channels/" + parts[0] + "/" + parts[1] + "/" + parts[2], nil
 		}
 		return "", fmt.Errorf("invalid Discord message ID format")
+	case "FurAffinity":
+		return "https://www.furaffinity.net/view/" + networkId + "/", nil
+	case "Weasyl":
+		return "https://www.weasyl.com/submission/" + networkId, nil
+	case "Inkbunny":
+		return "https://inkbunny.net/s/" + networkId, nil
+	case "DeviantArt":
+		return "https://www.deviantart.com/" + author + "/art/" + networkId, nil
+	case "Pixiv":
+		return "https://www.pixiv.net/artworks/" + networkId, nil
+	case "Twitch":
+		return "https://www.twitch.tv/videos/" + networkId, nil
+	case "Kick":
+		return "https://kick.com/" + author + "/videos/" + networkId, nil
+	case "Patreon":
+		return "https://www.patreon.com/posts/" + networkId, nil
+	case "Reddit":
+		return "https://www.reddit.com/comments/" + networkId, nil
+	case "Threads":
+		return "https://www.threads.net/@" + author + "/post/" + networkId, nil
+	case "Facebook":
+		return "https://www.facebook.com/" + networkId, nil
+	case "Lemmy":
+		splits := strings.Split(author, "@")
+		return fmt.Sprintf("https://%v/post/%v", splits[1], networkId), nil
 	case "Mastodon":
 		splits := strings.Split(author, "@")
 		return fmt.Sprintf("https://%v/@%v/%v", splits[1], splits[0], networkId), nil
+	case "Manual":
+		if strings.HasPrefix(networkId, "http://") || strings.HasPrefix(networkId, "https://") {
+			return networkId, nil
+		}
+		return "", nil
 	default:
 		return "", fmt.Errorf("network %v not recognized", network)
 	}
 }
+
+var referrerDomains = map[string]string{
+	"instagram.com":       "Instagram",
+	"l.instagram.com":     "Instagram",
+	"bsky.app":            "Bluesky",
+	"go.bsky.app":         "Bluesky",
+	"youtube.com":         "YouTube",
+	"m.youtube.com":       "YouTube",
+	"youtu.be":            "YouTube",
+	"tiktok.com":          "TikTok",
+	"t.me":                "Telegram",
+	"telegram.org":        "Telegram",
+	"discord.com":         "Discord",
+	"discordapp.com":      "Discord",
+	"badpups.com":         "BadPups",
+	"murrtube.net":        "Murrtube",
+	"furtrack.com":        "FurTrack",
+	"furaffinity.net":     "FurAffinity",
+	"weasyl.com":          "Weasyl",
+	"inkbunny.net":        "Inkbunny",
+	"deviantart.com":      "DeviantArt",
+	"pixiv.net":           "Pixiv",
+	"twitch.tv":           "Twitch",
+	"kick.com":            "Kick",
+	"patreon.com":         "Patreon",
+	"ko-fi.com":           "Ko-fi",
+	"subscribestar.com":   "SubscribeStar",
+	"subscribestar.adult": "SubscribeStar",
+	"reddit.com":          "Reddit",
+	"old.reddit.com":      "Reddit",
+	"out.reddit.com":      "Reddit",
+	"threads.net":         "Threads",
+	"threads.com":         "Threads",
+	"facebook.com":        "Facebook",
+	"l.facebook.com":      "Facebook",
+	"m.facebook.com":      "Facebook",
+	"lm.facebook.com":     "Facebook",
+}
+
+func UTMSource(network string) string {
+	return strings.ToLower(strings.ReplaceAll(network, " ", ""))
+}
+
+func BuildUTMURL(siteURL, network, campaign string) (string, error) {
+	if !strings.HasPrefix(siteURL, "http://") && !strings.HasPrefix(siteURL, "https://") {
+		siteURL = "https://" + siteURL
+	}
+
+	u, err := url.Parse(siteURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid site URL %v: %w", siteURL, err)
+	}
+
+	q := u.Query()
+	q.Set("utm_source", UTMSource(network))
+	q.Set("utm_medium", "social")
+	q.Set("utm_campaign", campaign)
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+func MatchReferrerToNetwork(sessionSource string) (string, bool) {
+	source := strings.ToLower(strings.TrimSpace(sessionSource))
+
+	for _, s := range AvailableSources {
+		if UTMSource(s.Name) == source {
+			return s.Name, true
+		}
+	}
+
+	host := strings.TrimPrefix(source, "www.")
+	for host != "" {
+		if network, ok := referrerDomains[host]; ok {
+			return network, true
+		}
+		idx := strings.Index(host, ".")
+		if idx < 0 {
+			break
+		}
+		host = host[idx+1:]
+	}
+
+	return "", false
+}
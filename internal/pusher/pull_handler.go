@@ -4,14 +4,25 @@ package pusher
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log"
+	"os"
 	"time"
 
 	"github.com/fluffyriot/rpsync/internal/database"
 	"github.com/fluffyriot/rpsync/internal/exports"
 	"github.com/fluffyriot/rpsync/internal/pusher/common"
 	"github.com/fluffyriot/rpsync/internal/pusher/targets"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/baserow"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/grist"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/gsheets"
 	"github.com/fluffyriot/rpsync/internal/pusher/targets/noco"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/notion"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/objectstore"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/tabular"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/teable"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/warehouse"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/webhook"
 	"github.com/google/uuid"
 )
 
@@ -54,7 +65,7 @@ func PullByTarget(tid uuid.UUID, dbQueries *database.Queries, c *common.Client,
 
 	switch target.TargetType {
 
-	case "NocoDB":
+	case "NocoDB", "Baserow", "Grist", "Teable":
 
 		export, err := exports.CreateLogAutoExport(target.UserID, dbQueries, target.TargetType, target.ID)
 		if err != nil {
@@ -69,62 +80,73 @@ func PullByTarget(tid uuid.UUID, dbQueries *database.Queries, c *common.Client,
 			exports.UpdateLogAutoExport(export, dbQueries, "Completed", "", "")
 		}
 
-	case "CSV":
+	case "Notion":
 
-		hasPosts, err := targets.HasPosts(dbQueries, target.UserID)
+		export, err := exports.CreateLogAutoExport(target.UserID, dbQueries, target.TargetType, target.ID)
 		if err != nil {
+			log.Println("Error creating export log:", err)
+		}
+
+		err = startNotionSync(dbQueries, c, encryptionKey, target)
+		if err != nil {
+			exports.UpdateLogAutoExport(export, dbQueries, "Failed", err.Error(), "")
 			finalErr = err
-		} else if hasPosts {
-			exportPosts, err := exports.CreateLogAutoExport(target.UserID, dbQueries, "CSV - Posts", target.ID)
-			if err != nil {
-				log.Println("Error creating posts export log:", err)
-			} else {
-				filename, err := targets.GeneratePostsCsv(dbQueries, target, exportPosts)
-				if err != nil {
-					exports.UpdateLogAutoExport(exportPosts, dbQueries, "Failed", err.Error(), filename)
-					finalErr = err
-				} else {
-					exports.UpdateLogAutoExport(exportPosts, dbQueries, "Completed", "", filename)
-				}
-			}
+		} else {
+			exports.UpdateLogAutoExport(export, dbQueries, "Completed", "", "")
 		}
 
-		hasAnalytics, err := targets.HasAnalytics(dbQueries, target.UserID)
+	case "Google Sheets":
+
+		export, err := exports.CreateLogAutoExport(target.UserID, dbQueries, target.TargetType, target.ID)
 		if err != nil {
-			if finalErr == nil {
-				finalErr = err
-			}
-		} else if hasAnalytics {
-			exportWeb, err := exports.CreateLogAutoExport(target.UserID, dbQueries, "CSV - Website", target.ID)
-			if err != nil {
-				log.Println("Error creating website export log:", err)
-			} else {
-				filename, err := targets.GenerateWebsiteCsv(dbQueries, target, exportWeb)
-				if err != nil {
-					exports.UpdateLogAutoExport(exportWeb, dbQueries, "Failed", err.Error(), filename)
-					if finalErr == nil {
-						finalErr = err
-					}
-				} else {
-					exports.UpdateLogAutoExport(exportWeb, dbQueries, "Completed", "", filename)
-				}
-			}
+			log.Println("Error creating export log:", err)
+		}
+
+		err = startSheetsSync(dbQueries, encryptionKey, target)
+		if err != nil {
+			exports.UpdateLogAutoExport(export, dbQueries, "Failed", err.Error(), "")
+			finalErr = err
+		} else {
+			exports.UpdateLogAutoExport(export, dbQueries, "Completed", "", "")
+		}
+
+	case "PostgreSQL", "MySQL":
 
-			exportPages, err := exports.CreateLogAutoExport(target.UserID, dbQueries, "CSV - Pages", target.ID)
+		export, err := exports.CreateLogAutoExport(target.UserID, dbQueries, target.TargetType, target.ID)
+		if err != nil {
+			log.Println("Error creating export log:", err)
+		}
+
+		err = warehouse.SyncWarehouse(dbQueries, encryptionKey, target)
+		if err != nil {
+			exports.UpdateLogAutoExport(export, dbQueries, "Failed", err.Error(), "")
+			finalErr = err
+		} else {
+			exports.UpdateLogAutoExport(export, dbQueries, "Completed", "", "")
+		}
+
+	case "SQLite":
+
+		export, err := exports.CreateLogAutoExport(target.UserID, dbQueries, "SQLite - Snapshot", target.ID)
+		if err != nil {
+			log.Println("Error creating snapshot export log:", err)
+		} else {
+			filename, err := targets.GenerateSqliteSnapshot(dbQueries, target, export)
 			if err != nil {
-				log.Println("Error creating website pages export log:", err)
+				exports.UpdateLogAutoExport(export, dbQueries, "Failed", err.Error(), filename)
+				finalErr = err
 			} else {
-				filename, err := targets.GeneratePageViewsCsv(dbQueries, target, exportPages)
-				if err != nil {
-					exports.UpdateLogAutoExport(exportPages, dbQueries, "Failed", err.Error(), filename)
-					if finalErr == nil {
-						finalErr = err
-					}
-				} else {
-					exports.UpdateLogAutoExport(exportPages, dbQueries, "Completed", "", filename)
-				}
+				completeStoredExport(dbQueries, encryptionKey, target, export, filename)
 			}
 		}
+
+	case "CSV":
+
+		finalErr = startCsvExport(dbQueries, encryptionKey, target)
+
+	case "Webhook":
+
+		finalErr = webhook.DeliverDue(context.Background(), dbQueries, c, encryptionKey, target)
 	}
 
 	status := "Synced"
@@ -139,6 +161,9 @@ func PullByTarget(tid uuid.UUID, dbQueries *database.Queries, c *common.Client,
 				TargetID:  uuid.NullUUID{UUID: target.ID, Valid: true},
 				Message:   finalErr.Error(),
 			})
+			if err := webhook.EmitTargetSyncFailed(context.Background(), dbQueries, target, finalErr); err != nil {
+				log.Printf("Target %s: %v", target.ID, err)
+			}
 		}
 	}
 
@@ -155,29 +180,212 @@ func PullByTarget(tid uuid.UUID, dbQueries *database.Queries, c *common.Client,
 	return finalErr
 }
 
+func tableBackend(dbQueries *database.Queries, c *common.Client, encryptionKey []byte, target database.Target) (tabular.Backend, error) {
+	switch target.TargetType {
+	case "NocoDB":
+		return noco.NewBackend(c, dbQueries, encryptionKey, target), nil
+	case "Baserow":
+		return baserow.NewBackend(c, dbQueries, encryptionKey, target)
+	case "Grist":
+		return grist.NewBackend(c, dbQueries, encryptionKey, target)
+	case "Teable":
+		return teable.NewBackend(c, dbQueries, encryptionKey, target)
+	}
+
+	return nil, fmt.Errorf("unsupported table target type: %s", target.TargetType)
+}
+
 func startDbSync(dbQueries *database.Queries, c *common.Client, encryptionKey []byte, target database.Target) error {
 
+	b, err := tableBackend(dbQueries, c, encryptionKey, target)
+	if err != nil {
+		return err
+	}
+
+	// Initialize only creates what is missing, so tables added in later releases
+	// reach existing targets too
+	if err := tabular.Initialize(dbQueries, b, target); err != nil {
+		return err
+	}
+
+	return tabular.Sync(dbQueries, b, target)
+}
+
+func startNotionSync(dbQueries *database.Queries, c *common.Client, encryptionKey []byte, target database.Target) error {
+
 	_, err := dbQueries.GetTableMappingsByTargetAndName(context.Background(), database.GetTableMappingsByTargetAndNameParams{
 		TargetID:        target.ID,
-		TargetTableName: "Analytics_Page_Stats",
+		TargetTableName: "analytics_page_stats",
 	})
 	if err != nil {
-		err := noco.InitializeNoco(dbQueries, c, encryptionKey, target)
+		err := notion.InitializeNotion(dbQueries, c, encryptionKey, target)
 		if err != nil {
 			return err
 		}
 	}
 
-	err = noco.SyncNoco(dbQueries, c, encryptionKey, target)
-	return err
+	return notion.SyncNotion(dbQueries, c, encryptionKey, target)
+}
+
+func startSheetsSync(dbQueries *database.Queries, encryptionKey []byte, target database.Target) error {
 
+	_, err := dbQueries.GetTableMappingsByTargetAndName(context.Background(), database.GetTableMappingsByTargetAndNameParams{
+		TargetID:        target.ID,
+		TargetTableName: "analytics_page_stats",
+	})
+	if err != nil {
+		err := gsheets.InitializeSheets(dbQueries, encryptionKey, target)
+		if err != nil {
+			return err
+		}
+	}
+
+	return gsheets.SyncSheets(dbQueries, encryptionKey, target)
+}
+
+func startCsvExport(dbQueries *database.Queries, encryptionKey []byte, target database.Target) error {
+
+	settings, err := targets.ParseSettings(target)
+	if err != nil {
+		return err
+	}
+
+	mode := settings.CsvModeOrDefault()
+
+	method := "CSV - Bundle"
+	switch mode {
+	case targets.CsvModeStable:
+		method = "CSV - Stable Files"
+	case targets.CsvModeDelta:
+		method = "CSV - Delta"
+	}
+
+	// The delta starts where the last successful export did, so it has to be read
+	// before this run's export is logged
+	var since time.Time
+	if mode == targets.CsvModeDelta {
+		since, err = targets.CsvDeltaSince(dbQueries, target)
+		if err != nil {
+			return err
+		}
+	}
+
+	export, err := exports.CreateLogAutoExport(target.UserID, dbQueries, method, target.ID)
+	if err != nil {
+		log.Println("Error creating CSV export log:", err)
+		return nil
+	}
+
+	if mode == targets.CsvModeStable {
+		written, err := targets.WriteStableCsv(dbQueries, target)
+		if err != nil {
+			exports.UpdateLogAutoExport(export, dbQueries, "Failed", err.Error(), "")
+			return err
+		}
+		message := fmt.Sprintf("%d files updated in %s", written, targets.CsvStableDir(target))
+		if err := mirrorStableCsv(dbQueries, encryptionKey, target); err != nil {
+			log.Printf("Error uploading stable files of target %s: %v", target.ID, err)
+			message += fmt.Sprintf(", upload failed: %v", err)
+		}
+		exports.UpdateLogAutoExport(export, dbQueries, "Completed", message, "")
+	} else {
+		filename, err := targets.GenerateCsvBundle(dbQueries, target, export, since)
+		if err != nil {
+			exports.UpdateLogAutoExport(export, dbQueries, "Failed", err.Error(), filename)
+			return err
+		}
+		if filename == "" {
+			exports.UpdateLogAutoExport(export, dbQueries, "Completed", "No data to export", "")
+		} else {
+			completeStoredExport(dbQueries, encryptionKey, target, export, filename)
+		}
+	}
+
+	if settings.CsvRetentionDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -settings.CsvRetentionDays)
+		if err := exports.PruneTargetExports(target.ID, dbQueries, encryptionKey, cutoff); err != nil {
+			log.Printf("Error pruning exports of target %s: %v", target.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// completeStoredExport marks a generated file as completed, uploading it first
+// when the target has a bucket. A failed upload keeps the local file, so the
+// export stays downloadable.
+func completeStoredExport(dbQueries *database.Queries, encryptionKey []byte, target database.Target, export database.Export, filename string) {
+	ctx := context.Background()
+
+	store, err := objectstore.Open(ctx, dbQueries, encryptionKey, target)
+	if err == nil && store == nil {
+		exports.UpdateLogAutoExport(export, dbQueries, "Completed", "", filename)
+		return
+	}
+
+	var key string
+	if err == nil {
+		key, err = store.Upload(ctx, filename)
+	}
+	if err != nil {
+		log.Printf("Error uploading export %s: %v", export.ID, err)
+		exports.UpdateLogAutoExport(export, dbQueries, "Completed", fmt.Sprintf("Kept locally, upload failed: %v", err), filename)
+		return
+	}
+
+	exports.UpdateLogAutoExport(export, dbQueries, "Completed", "", filename)
+
+	loc := store.Location()
+	err = dbQueries.SetExportStorage(ctx, database.SetExportStorageParams{
+		ID:              export.ID,
+		StorageKey:      sql.NullString{String: key, Valid: true},
+		StorageEndpoint: sql.NullString{String: loc.Endpoint, Valid: true},
+		StorageRegion:   sql.NullString{String: loc.Region, Valid: true},
+		StorageBucket:   sql.NullString{String: loc.Bucket, Valid: true},
+	})
+	if err != nil {
+		log.Printf("Error saving storage key of export %s: %v", export.ID, err)
+		return
+	}
+
+	settings, err := targets.ParseSettings(target)
+	if err == nil && !settings.S3KeepLocal {
+		if err := os.Remove(filename); err != nil {
+			log.Printf("Error deleting uploaded export file %s: %v", filename, err)
+		}
+	}
+}
+
+// mirrorStableCsv keeps the target's bucket in step with its stable files,
+// kept under the target's ID like on disk. The local files are always kept, as
+// the next run rewrites them in place.
+func mirrorStableCsv(dbQueries *database.Queries, encryptionKey []byte, target database.Target) error {
+	ctx := context.Background()
+
+	store, err := objectstore.Open(ctx, dbQueries, encryptionKey, target)
+	if err != nil || store == nil {
+		return err
+	}
+
+	return store.Mirror(ctx, targets.CsvStableDir(target), target.ID.String(), targets.CsvStableFiles())
 }
 
 func startDbRemoval(dbQueries *database.Queries, c *common.Client, targetId uuid.UUID, encryptionKey []byte, target database.Target, source database.Source) error {
-	if target.TargetType == "CSV" {
+	switch target.TargetType {
+	case "CSV", "SQLite", "Webhook":
 		return nil
+	case "Notion":
+		return notion.DeletePostsAndSourceNotion(dbQueries, c, encryptionKey, target, source)
+	case "Google Sheets":
+		return gsheets.DeletePostsAndSourceSheets(dbQueries, encryptionKey, target, source)
+	case "PostgreSQL", "MySQL":
+		return warehouse.DeletePostsAndSourceWarehouse(dbQueries, encryptionKey, target, source)
+	}
+
+	b, err := tableBackend(dbQueries, c, encryptionKey, target)
+	if err != nil {
+		return err
 	}
 
-	err := noco.DeletePostsAndSourceNoco(dbQueries, c, encryptionKey, target, source)
-	return err
+	return tabular.DeletePostsAndSource(dbQueries, b, target, source)
 }
@@ -0,0 +1,316 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package baserow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/common"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/tabular"
+)
+
+// Baserow's batch row endpoints accept at most 200 items
+const baserowBatchSize = 200
+
+type Backend struct {
+	client   *baserowClient
+	database string
+	fields   map[string]map[string]baserowField
+}
+
+func NewBackend(c *common.Client, dbQueries *database.Queries, encryptionKey []byte, target database.Target) (*Backend, error) {
+	client, err := newBaserowClient(c, dbQueries, encryptionKey, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Backend{
+		client:   client,
+		database: target.DbID.String,
+		fields:   make(map[string]map[string]baserowField),
+	}, nil
+}
+
+func (b *Backend) BatchSize() int {
+	return baserowBatchSize
+}
+
+func baserowFieldBody(column tabular.Column) map[string]any {
+	body := map[string]any{"name": column.Name}
+
+	switch column.Type {
+	case tabular.LongText:
+		body["type"] = "long_text"
+	case tabular.Number:
+		body["type"] = "number"
+		body["number_decimal_places"] = 0
+	case tabular.Decimal:
+		body["type"] = "number"
+		body["number_decimal_places"] = 2
+	case tabular.Checkbox:
+		body["type"] = "boolean"
+	case tabular.DateOnly:
+		body["type"] = "date"
+		body["date_format"] = "ISO"
+		body["date_include_time"] = false
+	case tabular.DateTime:
+		body["type"] = "date"
+		body["date_format"] = "ISO"
+		body["date_include_time"] = true
+		body["date_time_format"] = "24"
+	case tabular.URL:
+		body["type"] = "url"
+	case tabular.SingleSelect:
+		body["type"] = "single_select"
+		options := make([]baserowSelectOption, len(column.Choices))
+		for i, choice := range column.Choices {
+			options[i] = baserowSelectOption{Value: choice.Name, Color: baserowColor}
+		}
+		body["select_options"] = options
+	default:
+		body["type"] = "text"
+	}
+
+	return body
+}
+
+func (b *Backend) CreateTable(table tabular.Table) (*tabular.TableInfo, error) {
+	// The first header cell becomes the primary field, which is always ct_id
+	var created baserowTable
+	err := b.client.do("POST", "/api/database/tables/database/"+b.database+"/", map[string]any{
+		"name":             table.Name,
+		"data":             [][]string{{table.Columns[0].Name}},
+		"first_row_header": true,
+	}, &created)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create table %s: %w", table.Name, err)
+	}
+
+	tableID := strconv.Itoa(created.ID)
+	info := &tabular.TableInfo{ID: tableID, Name: created.Name, Columns: make(map[string]string)}
+
+	existing, err := b.tableFields(tableID)
+	if err != nil {
+		return nil, err
+	}
+	for name, field := range existing {
+		info.Columns[name] = strconv.Itoa(field.ID)
+	}
+
+	for _, column := range table.Columns[1:] {
+		var field baserowField
+		err := b.client.do("POST", "/api/database/fields/table/"+tableID+"/", baserowFieldBody(column), &field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create field %s: %w", column.Name, err)
+		}
+		info.Columns[field.Name] = strconv.Itoa(field.ID)
+	}
+
+	// Forget the cached fields so the new ones are picked up on the first write
+	delete(b.fields, tableID)
+
+	return info, nil
+}
+
+// UpdateColumn only adds missing select options: Baserow drops any option sent
+// without its id, clearing every cell that used it.
+func (b *Backend) UpdateColumn(tableID, columnID string, column tabular.Column) error {
+	var field baserowField
+	if err := b.client.do("GET", "/api/database/fields/"+columnID+"/", nil, &field); err != nil {
+		return fmt.Errorf("failed to get field %s: %w", column.Name, err)
+	}
+
+	if field.Type != "single_select" || column.Type != tabular.SingleSelect {
+		return nil
+	}
+
+	known := make(map[string]struct{}, len(field.SelectOptions))
+	for _, option := range field.SelectOptions {
+		known[option.Value] = struct{}{}
+	}
+
+	options := field.SelectOptions
+	for _, choice := range column.Choices {
+		if _, ok := known[choice.Name]; !ok {
+			options = append(options, baserowSelectOption{Value: choice.Name, Color: baserowColor})
+		}
+	}
+
+	if len(options) == len(field.SelectOptions) {
+		return nil
+	}
+
+	err := b.client.do("PATCH", "/api/database/fields/"+columnID+"/", map[string]any{
+		"select_options": options,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update field %s: %w", column.Name, err)
+	}
+
+	delete(b.fields, tableID)
+	return nil
+}
+
+// CreateLink returns the id of the related field on the child table, which is
+// the side that gets written when records are linked.
+func (b *Backend) CreateLink(parentTableID, childTableID, name string) (string, error) {
+	childID, err := strconv.Atoi(childTableID)
+	if err != nil {
+		return "", fmt.Errorf("invalid table id %s: %w", childTableID, err)
+	}
+
+	var field baserowField
+	err = b.client.do("POST", "/api/database/fields/table/"+parentTableID+"/", map[string]any{
+		"name":              name,
+		"type":              "link_row",
+		"link_row_table_id": childID,
+		"has_related_field": true,
+	}, &field)
+	if err != nil {
+		return "", fmt.Errorf("failed to create link %s: %w", name, err)
+	}
+
+	if field.RelatedFieldID == 0 {
+		return "", fmt.Errorf("link %s has no related field", name)
+	}
+
+	delete(b.fields, childTableID)
+	return strconv.Itoa(field.RelatedFieldID), nil
+}
+
+func (b *Backend) LinkRecords(parentTableID, childTableID, linkID, parentID string, childIDs []string) error {
+	parent, err := strconv.Atoi(parentID)
+	if err != nil {
+		return fmt.Errorf("invalid row id %s: %w", parentID, err)
+	}
+
+	items := make([]map[string]any, len(childIDs))
+	for i, childID := range childIDs {
+		id, err := strconv.Atoi(childID)
+		if err != nil {
+			return fmt.Errorf("invalid row id %s: %w", childID, err)
+		}
+		items[i] = map[string]any{
+			"id":              id,
+			"field_" + linkID: []int{parent},
+		}
+	}
+
+	return b.client.do("PATCH", "/api/database/rows/table/"+childTableID+"/batch/", map[string]any{"items": items}, nil)
+}
+
+func (b *Backend) CreateRecords(tableID string, records []tabular.Record) (map[string]string, error) {
+	fields, err := b.tableFields(tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]map[string]any, len(records))
+	for i, record := range records {
+		items[i] = baserowRow(fields, record.Fields)
+	}
+
+	var result baserowRows
+	err = b.client.do("POST", "/api/database/rows/table/"+tableID+"/batch/?user_field_names=true", map[string]any{"items": items}, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rows: %w", err)
+	}
+
+	ids := make(map[string]string, len(result.Items))
+	for _, row := range result.Items {
+		ids[row.CtID] = strconv.Itoa(row.ID)
+	}
+	return ids, nil
+}
+
+func (b *Backend) UpdateRecords(tableID string, records []tabular.Record) error {
+	fields, err := b.tableFields(tableID)
+	if err != nil {
+		return err
+	}
+
+	items := make([]map[string]any, len(records))
+	for i, record := range records {
+		id, err := strconv.Atoi(record.ID)
+		if err != nil {
+			return fmt.Errorf("invalid row id %s: %w", record.ID, err)
+		}
+		items[i] = baserowRow(fields, record.Fields)
+		items[i]["id"] = id
+	}
+
+	err = b.client.do("PATCH", "/api/database/rows/table/"+tableID+"/batch/?user_field_names=true", map[string]any{"items": items}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update rows: %w", err)
+	}
+	return nil
+}
+
+func (b *Backend) DeleteRecords(tableID string, ids []string) error {
+	items := make([]int, 0, len(ids))
+	for _, id := range ids {
+		v, err := strconv.Atoi(id)
+		if err != nil {
+			return fmt.Errorf("invalid row id %s: %w", id, err)
+		}
+		items = append(items, v)
+	}
+
+	err := b.client.do("POST", "/api/database/rows/table/"+tableID+"/batch-delete/", map[string]any{"items": items}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete rows: %w", err)
+	}
+	return nil
+}
+
+// tableFields lists a table's fields by name, cached for the rest of the sync.
+func (b *Backend) tableFields(tableID string) (map[string]baserowField, error) {
+	if fields, ok := b.fields[tableID]; ok {
+		return fields, nil
+	}
+
+	var list []baserowField
+	if err := b.client.do("GET", "/api/database/fields/table/"+tableID+"/", nil, &list); err != nil {
+		return nil, fmt.Errorf("failed to list fields: %w", err)
+	}
+
+	fields := make(map[string]baserowField, len(list))
+	for _, field := range list {
+		fields[field.Name] = field
+	}
+	b.fields[tableID] = fields
+	return fields, nil
+}
+
+// baserowRow converts record values to what Baserow expects, resolving select
+// values to their option ids.
+func baserowRow(fields map[string]baserowField, values map[string]any) map[string]any {
+	row := make(map[string]any, len(values))
+	for name, value := range values {
+		switch v := value.(type) {
+		case tabular.Date:
+			row[name] = v.String()
+		case time.Time:
+			row[name] = v.UTC().Format(time.RFC3339)
+		case string:
+			field, ok := fields[name]
+			if !ok || field.Type != "single_select" {
+				row[name] = v
+				break
+			}
+			row[name] = nil
+			for _, option := range field.SelectOptions {
+				if strings.EqualFold(option.Value, v) {
+					row[name] = option.ID
+					break
+				}
+			}
+		default:
+			row[name] = v
+		}
+	}
+	return row
+}
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package baserow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/common"
+)
+
+// baserowClient signs in with the account's email and password, since database
+// tokens can only read and write rows, not create tables or fields.
+type baserowClient struct {
+	http     *common.Client
+	host     string
+	email    string
+	password string
+	jwt      string
+}
+
+func newBaserowClient(c *common.Client, dbQueries *database.Queries, encryptionKey []byte, target database.Target) (*baserowClient, error) {
+	password, email, _, err := authhelp.GetTargetToken(context.Background(), dbQueries, encryptionKey, target.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Baserow credentials: %w", err)
+	}
+
+	return &baserowClient{
+		http:     c,
+		host:     strings.TrimRight(target.HostUrl.String, "/"),
+		email:    email,
+		password: password,
+	}, nil
+}
+
+func (b *baserowClient) login() error {
+	var result struct {
+		AccessToken string `json:"access_token"`
+		Token       string `json:"token"`
+	}
+
+	err := b.send("POST", "/api/user/token-auth/", map[string]string{
+		"email":    b.email,
+		"password": b.password,
+	}, &result, false)
+	if err != nil {
+		return fmt.Errorf("sign in: %w", err)
+	}
+
+	b.jwt = result.AccessToken
+	if b.jwt == "" {
+		b.jwt = result.Token
+	}
+	return nil
+}
+
+// do sends an authenticated request, signing in again once if the access token,
+// which only lives for a few minutes, has expired.
+func (b *baserowClient) do(method, path string, payload, out any) error {
+	if b.jwt == "" {
+		if err := b.login(); err != nil {
+			return err
+		}
+	}
+
+	err := b.send(method, path, payload, out, true)
+	if err == errUnauthorized {
+		if err := b.login(); err != nil {
+			return err
+		}
+		err = b.send(method, path, payload, out, true)
+	}
+	return err
+}
+
+var errUnauthorized = fmt.Errorf("unauthorized")
+
+func (b *baserowClient) send(method, path string, payload, out any, auth bool) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, b.host+path, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if auth {
+		req.Header.Set("Authorization", "JWT "+b.jwt)
+	}
+
+	resp, err := b.http.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if auth && resp.StatusCode == http.StatusUnauthorized {
+		return errUnauthorized
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package baserow
+
+// Baserow select options take one of its named colors rather than a hex value
+const baserowColor = "light-blue"
+
+type baserowTable struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type baserowSelectOption struct {
+	ID    int    `json:"id,omitempty"`
+	Value string `json:"value"`
+	Color string `json:"color"`
+}
+
+type baserowField struct {
+	ID             int                   `json:"id"`
+	Name           string                `json:"name"`
+	Type           string                `json:"type"`
+	Primary        bool                  `json:"primary"`
+	SelectOptions  []baserowSelectOption `json:"select_options,omitempty"`
+	RelatedFieldID int                   `json:"link_row_related_field_id,omitempty"`
+}
+
+type baserowRows struct {
+	Items []struct {
+		ID   int    `json:"id"`
+		CtID string `json:"ct_id"`
+	} `json:"items"`
+}
@@ -2,11 +2,14 @@
 package targets
 
 import (
+	"archive/zip"
 	"context"
-	"encoding/csv"
+	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
+	"path/filepath"
 	"time"
 
 	"github.com/fluffyriot/rpsync/internal/database"
@@ -14,199 +17,532 @@ import (
 	"github.com/google/uuid"
 )
 
-func HasPosts(dbQueries *database.Queries, userID uuid.UUID) (bool, error) {
+// CsvOutput is one file of the export bundle. Key is what gets stored in the
+// target settings and profiles, so it must never change once released, and
+// neither may column keys.
+type CsvOutput struct {
+	Key     string
+	Label   string
+	Columns []ExportColumn
+	// rows returns the rows changed since the given time, or all rows when it is
+	// zero. Each row holds one value per column, nil when there is none.
+	rows func(ctx context.Context, dbQueries *database.Queries, target database.Target, since time.Time) ([][]any, error)
+}
 
-	count, err := dbQueries.CheckCountOfPostsForUser(context.Background(), userID)
-	if err != nil {
-		return false, err
+var CsvOutputs = []CsvOutput{
+	{"posts", "Posts with the latest reactions", []ExportColumn{
+		{"ct_id", ColText},
+		{"posted_at", ColTime},
+		{"last_updated", ColTime},
+		{"is_archived", ColBool},
+		{"network", ColText},
+		{"post_type", ColText},
+		{"post_author", ColText},
+		{"likes", ColInt},
+		{"reposts", ColInt},
+		{"views", ColInt},
+		{"url", ColText},
+		{"content", ColText},
+	}, postsCsvRows},
+	{"website", "Website visitors", []ExportColumn{
+		{"ct_id", ColText},
+		{"date", ColDate},
+		{"visitors", ColInt},
+		{"avg_session_duration", ColFloat},
+		{"source_network", ColText},
+		{"source_username", ColText},
+	}, websiteCsvRows},
+	{"webpages", "Website page views", []ExportColumn{
+		{"ct_id", ColText},
+		{"date", ColDate},
+		{"url_path", ColText},
+		{"views", ColInt},
+		{"source_network", ColText},
+		{"source_username", ColText},
+	}, pageViewsCsvRows},
+	{"demographics", "Audience demographics", []ExportColumn{
+		{"ct_id", ColText},
+		{"date", ColDate},
+		{"dimension", ColText},
+		{"bucket", ColText},
+		{"value", ColFloat},
+		{"source_network", ColText},
+		{"source_username", ColText},
+	}, demographicsCsvRows},
+	{"source_stats", "Daily source statistics", []ExportColumn{
+		{"ct_id", ColText},
+		{"date", ColDate},
+		{"followers_count", ColInt},
+		{"following_count", ColInt},
+		{"posts_count", ColInt},
+		{"average_likes", ColFloat},
+		{"average_reposts", ColFloat},
+		{"average_views", ColFloat},
+		{"source_network", ColText},
+		{"source_username", ColText},
+	}, sourceStatsCsvRows},
+	// post_id matches ct_id of posts
+	{"reaction_history", "Full reaction history", []ExportColumn{
+		{"post_id", ColText},
+		{"synced_at", ColTime},
+		{"likes", ColInt},
+		{"reposts", ColInt},
+		{"views", ColInt},
+		{"comments", ColInt},
+		{"quotes", ColInt},
+		{"bookmarks", ColInt},
+		{"source_network", ColText},
+		{"source_username", ColText},
+	}, reactionHistoryCsvRows},
+}
+
+func CsvOutputByKey(key string) (CsvOutput, bool) {
+	for _, o := range CsvOutputs {
+		if o.Key == key {
+			return o, true
+		}
 	}
-	return count > 0, nil
+	return CsvOutput{}, false
 }
 
-func HasAnalytics(dbQueries *database.Queries, userID uuid.UUID) (bool, error) {
-	count, err := dbQueries.CheckCountOfAnalyticsSiteStatsForUser(context.Background(), userID)
+// Statistics of recent days are still updated by later syncs, so delta exports
+// repeat this many days before the previous export. Rows keep their ct_id, so
+// the newest file wins when deltas are merged.
+const csvDeltaStatsDays = 9
+
+// CsvDeltaSince returns when the target was last exported successfully, or the
+// zero time when it never was, in which case a delta holds everything.
+func CsvDeltaSince(dbQueries *database.Queries, target database.Target) (time.Time, error) {
+	last, err := dbQueries.GetLastCompletedExportForTarget(context.Background(), uuid.NullUUID{UUID: target.ID, Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
 	if err != nil {
-		return false, err
+		return time.Time{}, fmt.Errorf("fetching last export: %w", err)
 	}
-	return count > 0, nil
+	return last.CreatedAt, nil
+}
+
+// exportTable is one output ready to be encoded
+type exportTable struct {
+	output  CsvOutput
+	profile Profile
+	rows    [][]any
 }
 
-func GeneratePostsCsv(dbQueries *database.Queries, target database.Target, export database.Export) (string, error) {
-	posts, err := dbQueries.GetAllPostsWithTheLatestInfoForUser(context.Background(), target.UserID)
+// collectTables fetches every output enabled for the target and narrows it to
+// the target's profile. Outputs without rows are skipped unless keepEmpty is set,
+// in which case they come back with no rows.
+func collectTables(ctx context.Context, dbQueries *database.Queries, target database.Target, settings Settings, since time.Time, keepEmpty bool) ([]exportTable, error) {
+	var tables []exportTable
+	for _, output := range CsvOutputs {
+		if !settings.CsvEnabled(output.Key) {
+			if keepEmpty {
+				tables = append(tables, exportTable{output: output})
+			}
+			continue
+		}
+
+		rows, err := output.rows(ctx, dbQueries, target, since)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s: %w", output.Key, err)
+		}
+		if len(rows) == 0 && !keepEmpty {
+			continue
+		}
+
+		profile, err := LoadProfile(ctx, dbQueries, target, output)
+		if err != nil {
+			return nil, err
+		}
+
+		projected := make([][]any, len(rows))
+		for i, row := range rows {
+			projected[i] = profile.project(row)
+		}
+
+		tables = append(tables, exportTable{output: output, profile: profile, rows: projected})
+	}
+	return tables, nil
+}
+
+func (t exportTable) encode(w io.Writer, settings Settings) error {
+	return encodeTable(w, settings.ExportFormatOrDefault(), settings.Delimiter(), t.output.Key, t.profile, t.rows)
+}
+
+// GenerateCsvBundle writes every output enabled for the target into one zip
+// file, in the target's format and limited to rows changed since the given time
+// unless it is zero. Outputs without data are left out; when none has data no
+// file is written and the returned filename is empty.
+func GenerateCsvBundle(dbQueries *database.Queries, target database.Target, export database.Export, since time.Time) (string, error) {
+	settings, err := ParseSettings(target)
 	if err != nil {
-		return "", fmt.Errorf("fetching posts: %w", err)
+		return "", err
 	}
 
-	if len(posts) == 0 {
-		return "", nil
+	kind := settings.ExportFormatOrDefault()
+	if !since.IsZero() {
+		kind += "_delta"
 	}
 
-	filename := fmt.Sprintf("outputs/export_id_%s_posts_%s.csv", export.ID.String(), time.Now().Format("20060102_150405"))
-	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
+	filename := fmt.Sprintf("outputs/export_id_%s_%s_%s.zip", export.ID.String(), kind, time.Now().Format("20060102_150405"))
+	tmpName := filename + ".tmp"
+
+	written, err := writeCsvBundle(dbQueries, target, settings, since, tmpName)
+	if err != nil || written == 0 {
+		os.Remove(tmpName)
 		return "", err
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	if err := writer.Write([]string{
-		"ct_id",
-		"posted_at",
-		"last_updated",
-		"is_archived",
-		"network",
-		"post_type",
-		"post_author",
-		"likes",
-		"reposts",
-		"views",
-		"url",
-		"content",
-	}); err != nil {
+	// The file only appears under its final name once it is complete
+	if err := os.Rename(tmpName, filename); err != nil {
+		os.Remove(tmpName)
 		return "", err
 	}
 
-	for _, r := range posts {
-		content := ""
-		if r.Content.Valid {
-			content = r.Content.String
+	return filename, nil
+}
+
+func writeCsvBundle(dbQueries *database.Queries, target database.Target, settings Settings, since time.Time, path string) (int, error) {
+	tables, err := collectTables(context.Background(), dbQueries, target, settings, since, false)
+	if err != nil || len(tables) == 0 {
+		return 0, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	stamp := time.Now()
+	ext := fileExtension(settings.ExportFormatOrDefault())
+
+	for _, t := range tables {
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     t.output.Key + "." + ext,
+			Method:   zip.Deflate,
+			Modified: stamp,
+		})
+		if err != nil {
+			return 0, err
 		}
-		network := ""
-		if r.Network.Valid {
-			network = r.Network.String
+
+		if err := t.encode(entry, settings); err != nil {
+			return 0, fmt.Errorf("writing %s: %w", t.output.Key, err)
 		}
-		reactionsSyncedAt := ""
-		if r.ReactionsSyncedAt.Valid {
-			reactionsSyncedAt = r.ReactionsSyncedAt.Time.Format(time.RFC3339)
+	}
+
+	if err := archive.Close(); err != nil {
+		return 0, err
+	}
+
+	return len(tables), file.Close()
+}
+
+// CsvStableDir is where stable mode keeps the target's files
+func CsvStableDir(target database.Target) string {
+	return filepath.Join("outputs", "csv", target.ID.String())
+}
+
+// CsvStableFiles lists every file name WriteStableCsv can write, in any format
+func CsvStableFiles() []string {
+	var names []string
+	for _, o := range CsvOutputs {
+		for _, f := range ExportFormats {
+			names = append(names, o.Key+"."+fileExtension(f.Key))
 		}
-		likes := ""
-		if r.Likes.Valid {
-			likes = strconv.FormatInt(r.Likes.Int64, 10)
+	}
+	return names
+}
+
+// WriteStableCsv replaces one file per enabled output under CsvStableDir, so
+// other tools can read the same paths after every run. Each file is swapped in
+// with a rename and readers never see a partial file. Files of outputs that are
+// disabled, have no data or were written in another format are removed.
+func WriteStableCsv(dbQueries *database.Queries, target database.Target) (int, error) {
+	settings, err := ParseSettings(target)
+	if err != nil {
+		return 0, err
+	}
+
+	dir := CsvStableDir(target)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return 0, err
+	}
+
+	tables, err := collectTables(context.Background(), dbQueries, target, settings, time.Time{}, true)
+	if err != nil {
+		return 0, err
+	}
+
+	ext := fileExtension(settings.ExportFormatOrDefault())
+
+	written := 0
+	for _, t := range tables {
+		for _, f := range ExportFormats {
+			if f.Key == ext && len(t.rows) > 0 {
+				continue
+			}
+			stale := filepath.Join(dir, t.output.Key+"."+f.Key)
+			if err := os.Remove(stale); err != nil && !os.IsNotExist(err) {
+				return written, err
+			}
 		}
-		reposts := ""
-		if r.Reposts.Valid {
-			reposts = strconv.FormatInt(r.Reposts.Int64, 10)
+
+		if len(t.rows) == 0 {
+			continue
 		}
-		views := ""
-		if r.Views.Valid {
-			views = strconv.FormatInt(r.Views.Int64, 10)
+
+		if err := replaceFile(filepath.Join(dir, t.output.Key+"."+ext), func(w io.Writer) error {
+			return t.encode(w, settings)
+		}); err != nil {
+			return written, fmt.Errorf("writing %s: %w", t.output.Key, err)
+		}
+		written++
+	}
+
+	return written, nil
+}
+
+func replaceFile(path string, write func(io.Writer) error) error {
+	tmpName := path + ".tmp"
+
+	file, err := os.OpenFile(tmpName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = write(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpName, path)
+	}
+	if err != nil {
+		os.Remove(tmpName)
+	}
+	return err
+}
+
+// statsChanged reports whether a statistic dated date may have changed since
+// the previous export
+func statsChanged(date, since time.Time) bool {
+	if since.IsZero() {
+		return true
+	}
+	return !date.Before(since.AddDate(0, 0, -csvDeltaStatsDays))
+}
+
+func cellInt(v sql.NullInt64) any {
+	if !v.Valid {
+		return nil
+	}
+	return v.Int64
+}
+
+func cellFloat(v sql.NullFloat64) any {
+	if !v.Valid {
+		return nil
+	}
+	return v.Float64
+}
+
+func cellTime(v sql.NullTime) any {
+	if !v.Valid {
+		return nil
+	}
+	return v.Time
+}
+
+func postsCsvRows(ctx context.Context, dbQueries *database.Queries, target database.Target, since time.Time) ([][]any, error) {
+	posts, err := dbQueries.GetAllPostsWithTheLatestInfoForUser(ctx, target.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]any, 0, len(posts))
+	for _, r := range posts {
+		// Imports add posts of any age, so what counts is when the row was written
+		if !since.IsZero() && !r.LastSyncedAt.After(since) && !r.ReactionsRecordedAt.Time.After(since) {
+			continue
 		}
 
-		url, _ := helpers.ConvPostToURL(network, r.Author, r.NetworkInternalID)
+		url, _ := helpers.ConvPostToURL(r.Network.String, r.Author, r.NetworkInternalID)
 
-		if err := writer.Write([]string{
+		rows = append(rows, []any{
 			r.ID.String(),
-			r.CreatedAt.Format(time.RFC3339),
-			reactionsSyncedAt,
-			strconv.FormatBool(r.IsArchived),
-			network,
+			r.CreatedAt,
+			cellTime(r.ReactionsSyncedAt),
+			r.IsArchived,
+			r.Network.String,
 			r.PostType,
 			r.Author,
-			likes,
-			reposts,
-			views,
+			cellInt(r.Likes),
+			cellInt(r.Reposts),
+			cellInt(r.Views),
 			url,
-			content,
-		}); err != nil {
-			return "", err
-		}
+			r.Content.String,
+		})
 	}
 
-	return filename, nil
+	return rows, nil
 }
 
-func GenerateWebsiteCsv(dbQueries *database.Queries, target database.Target, export database.Export) (string, error) {
-	stats, err := dbQueries.GetAllAnalyticsSiteStatsForUser(context.Background(), target.UserID)
+func websiteCsvRows(ctx context.Context, dbQueries *database.Queries, target database.Target, since time.Time) ([][]any, error) {
+	stats, err := dbQueries.GetAllAnalyticsSiteStatsForUser(ctx, target.UserID)
 	if err != nil {
-		return "", fmt.Errorf("fetching site stats: %w", err)
+		return nil, err
 	}
 
-	if len(stats) == 0 {
-		return "", nil
+	rows := make([][]any, 0, len(stats))
+	for _, s := range stats {
+		if !statsChanged(s.Date, since) {
+			continue
+		}
+		rows = append(rows, []any{
+			s.ID.String(),
+			s.Date,
+			int64(s.Visitors),
+			s.AvgSessionDuration,
+			s.SourceNetwork,
+			s.SourceUserName,
+		})
 	}
 
-	filename := fmt.Sprintf("outputs/export_id_%s_website_%s.csv", export.ID.String(), time.Now().Format("20060102_150405"))
-	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
+	return rows, nil
+}
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	if err := writer.Write([]string{
-		"ct_id",
-		"date",
-		"visitors",
-		"avg_session_duration",
-		"source_network",
-		"source_username",
-	}); err != nil {
-		return "", err
+func pageViewsCsvRows(ctx context.Context, dbQueries *database.Queries, target database.Target, since time.Time) ([][]any, error) {
+	stats, err := dbQueries.GetAllAnalyticsPageStatsForUser(ctx, target.UserID)
+	if err != nil {
+		return nil, err
 	}
 
+	rows := make([][]any, 0, len(stats))
 	for _, s := range stats {
-		if err := writer.Write([]string{
+		if !statsChanged(s.Date, since) {
+			continue
+		}
+		rows = append(rows, []any{
 			s.ID.String(),
-			s.Date.Format("2006-01-02"),
-			strconv.Itoa(s.Visitors),
-			fmt.Sprintf("%f", s.AvgSessionDuration),
+			s.Date,
+			s.UrlPath,
+			int64(s.Views),
 			s.SourceNetwork,
 			s.SourceUserName,
-		}); err != nil {
-			return "", err
+		})
+	}
+
+	return rows, nil
+}
+
+func demographicsCsvRows(ctx context.Context, dbQueries *database.Queries, target database.Target, since time.Time) ([][]any, error) {
+	demographics, err := dbQueries.GetAudienceDemographicsForUser(ctx, target.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]any, 0, len(demographics))
+	for _, d := range demographics {
+		if !statsChanged(d.Date, since) {
+			continue
 		}
+		rows = append(rows, []any{
+			d.ID.String(),
+			d.Date,
+			d.Dimension,
+			d.Bucket,
+			d.Value,
+			d.SourceNetwork,
+			d.SourceUserName,
+		})
 	}
 
-	return filename, nil
+	return rows, nil
 }
 
-func GeneratePageViewsCsv(dbQueries *database.Queries, target database.Target, export database.Export) (string, error) {
-	stats, err := dbQueries.GetAllAnalyticsPageStatsForUser(context.Background(), target.UserID)
+// userSources maps the user's source IDs to their sources so stats rows can
+// carry the network and username like the other outputs do
+func userSources(ctx context.Context, dbQueries *database.Queries, target database.Target) (map[string]database.Source, error) {
+	sources, err := dbQueries.GetUserSources(ctx, target.UserID)
 	if err != nil {
-		return "", fmt.Errorf("fetching pages stats: %w", err)
+		return nil, err
 	}
 
-	if len(stats) == 0 {
-		return "", nil
+	byID := make(map[string]database.Source, len(sources))
+	for _, s := range sources {
+		byID[s.ID.String()] = s
 	}
+	return byID, nil
+}
 
-	filename := fmt.Sprintf("outputs/export_id_%s_webpages_%s.csv", export.ID.String(), time.Now().Format("20060102_150405"))
-	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+func sourceStatsCsvRows(ctx context.Context, dbQueries *database.Queries, target database.Target, since time.Time) ([][]any, error) {
+	stats, err := dbQueries.GetAllSourcesStatsForUser(ctx, target.UserID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	if err := writer.Write([]string{
-		"ct_id",
-		"date",
-		"url_path",
-		"views",
-		"source_network",
-		"source_username",
-	}); err != nil {
-		return "", err
+	sources, err := userSources(ctx, dbQueries, target)
+	if err != nil {
+		return nil, err
 	}
 
+	rows := make([][]any, 0, len(stats))
 	for _, s := range stats {
-		if err := writer.Write([]string{
+		if !statsChanged(s.Date, since) {
+			continue
+		}
+		source := sources[s.SourceID.String()]
+		rows = append(rows, []any{
 			s.ID.String(),
-			s.Date.Format("2006-01-02"),
-			s.UrlPath,
-			strconv.Itoa(s.Views),
-			s.SourceNetwork,
-			s.SourceUserName,
-		}); err != nil {
-			return "", err
+			s.Date,
+			cellInt(s.FollowersCount),
+			cellInt(s.FollowingCount),
+			cellInt(s.PostsCount),
+			cellFloat(s.AverageLikes),
+			cellFloat(s.AverageReposts),
+			cellFloat(s.AverageViews),
+			source.Network,
+			source.UserName,
+		})
+	}
+
+	return rows, nil
+}
+
+func reactionHistoryCsvRows(ctx context.Context, dbQueries *database.Queries, target database.Target, since time.Time) ([][]any, error) {
+	history, err := dbQueries.GetReactionsHistoryForUserRecordedAfter(ctx, database.GetReactionsHistoryForUserRecordedAfterParams{
+		UserID:     target.UserID,
+		RecordedAt: since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := userSources(ctx, dbQueries, target)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]any, len(history))
+	for i, h := range history {
+		source := sources[h.SourceID.String()]
+		rows[i] = []any{
+			h.PostID.String(),
+			h.SyncedAt,
+			cellInt(h.Likes),
+			cellInt(h.Reposts),
+			cellInt(h.Views),
+			cellInt(h.Comments),
+			cellInt(h.Quotes),
+			cellInt(h.Bookmarks),
+			source.Network,
+			source.UserName,
 		}
 	}
 
-	return filename, nil
+	return rows, nil
 }
@@ -0,0 +1,254 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package targets
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCsv     = "csv"
+	FormatXlsx    = "xlsx"
+	FormatJsonl   = "jsonl"
+	FormatParquet = "parquet"
+)
+
+var ExportFormats = []struct {
+	Key   string
+	Label string
+}{
+	{FormatCsv, "CSV"},
+	{FormatXlsx, "Excel (XLSX)"},
+	{FormatJsonl, "JSON Lines"},
+	{FormatParquet, "Parquet"},
+}
+
+var CsvDelimiters = []struct {
+	Key   string
+	Label string
+}{
+	{",", "Comma"},
+	{";", "Semicolon"},
+	{"\t", "Tab"},
+	{"|", "Pipe"},
+}
+
+// encodeTable writes one output in the given format. Rows hold values of the
+// output's column types, already narrowed to the profile's columns; nil is an
+// empty cell.
+func encodeTable(w io.Writer, format string, delimiter rune, name string, profile Profile, rows [][]any) error {
+	switch format {
+	case FormatXlsx:
+		return encodeXlsx(w, name, profile, rows)
+	case FormatJsonl:
+		return encodeJsonl(w, profile, rows)
+	case FormatParquet:
+		return encodeParquet(w, name, profile, rows)
+	}
+	return encodeCsv(w, delimiter, profile, rows)
+}
+
+// textCell renders a value the way text formats show it
+func textCell(c ProfileColumn, v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return fmt.Sprintf("%f", v)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return formatDate(v, c.Type, c.DateFormat)
+	}
+	return fmt.Sprint(v)
+}
+
+func encodeCsv(w io.Writer, delimiter rune, profile Profile, rows [][]any) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	if err := writer.Write(profile.Headers()); err != nil {
+		return err
+	}
+
+	record := make([]string, len(profile.Columns))
+	for _, row := range rows {
+		for i, c := range profile.Columns {
+			record[i] = textCell(c, row[i])
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// encodeJsonl writes one object per row with keys in profile order. Numbers and
+// booleans stay typed; dates use the column's date format, unix as a number.
+func encodeJsonl(w io.Writer, profile Profile, rows [][]any) error {
+	out := bufio.NewWriter(w)
+
+	keys := make([][]byte, len(profile.Columns))
+	for i, c := range profile.Columns {
+		key, err := json.Marshal(c.Header)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+
+	var line bytes.Buffer
+	for _, row := range rows {
+		line.Reset()
+		line.WriteByte('{')
+		for i, c := range profile.Columns {
+			if i > 0 {
+				line.WriteByte(',')
+			}
+			line.Write(keys[i])
+			line.WriteByte(':')
+
+			value := row[i]
+			if t, ok := value.(time.Time); ok {
+				if c.DateFormat == "unix" {
+					value = t.Unix()
+				} else {
+					value = formatDate(t, c.Type, c.DateFormat)
+				}
+			}
+
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			line.Write(encoded)
+		}
+		line.WriteString("}\n")
+
+		if _, err := out.Write(line.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return out.Flush()
+}
+
+func encodeXlsx(w io.Writer, name string, profile Profile, rows [][]any) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := f.GetSheetName(0)
+	if err := f.SetSheetName(sheet, name); err != nil {
+		return err
+	}
+
+	sw, err := f.NewStreamWriter(name)
+	if err != nil {
+		return err
+	}
+
+	header := make([]any, len(profile.Columns))
+	for i, h := range profile.Headers() {
+		header[i] = h
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	for r, row := range rows {
+		values := make([]any, len(profile.Columns))
+		for i, c := range profile.Columns {
+			if t, ok := row[i].(time.Time); ok {
+				values[i] = formatDate(t, c.Type, c.DateFormat)
+			} else {
+				values[i] = row[i]
+			}
+		}
+
+		cell, err := excelize.CoordinatesToCellName(1, r+2)
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow(cell, values); err != nil {
+			return err
+		}
+	}
+
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+
+	return f.Write(w)
+}
+
+// encodeParquet stores values natively, so date formats do not apply: times
+// become UTC millisecond timestamps and dates Parquet dates. Columns are
+// ordered by header, as Parquet groups are.
+func encodeParquet(w io.Writer, name string, profile Profile, rows [][]any) error {
+	group := parquet.Group{}
+	for _, c := range profile.Columns {
+		var node parquet.Node
+		switch c.Type {
+		case ColInt:
+			node = parquet.Int(64)
+		case ColFloat:
+			node = parquet.Leaf(parquet.DoubleType)
+		case ColBool:
+			node = parquet.Leaf(parquet.BooleanType)
+		case ColTime:
+			node = parquet.Timestamp(parquet.Millisecond)
+		case ColDate:
+			node = parquet.Date()
+		default:
+			node = parquet.String()
+		}
+		group[c.Header] = parquet.Optional(node)
+	}
+
+	writer := parquet.NewWriter(w, parquet.NewSchema(name, group), parquet.Compression(&parquet.Zstd))
+
+	for _, row := range rows {
+		record := make(map[string]any, len(profile.Columns))
+		for i, c := range profile.Columns {
+			value := row[i]
+			if t, ok := value.(time.Time); ok {
+				if c.Type == ColDate {
+					// Parquet dates count days since the epoch
+					value = int32(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400)
+				} else {
+					value = t.UTC()
+				}
+			}
+			record[c.Header] = value
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// fileExtension is the extension of files written in format
+func fileExtension(format string) string {
+	switch format {
+	case FormatXlsx, FormatJsonl, FormatParquet:
+		return format
+	}
+	return FormatCsv
+}
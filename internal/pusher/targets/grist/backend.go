@@ -0,0 +1,252 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package grist
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/common"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/tabular"
+)
+
+const gristBatchSize = 500
+
+// gristLinkColumn is the reference column added to every child table
+const gristLinkColumn = "source"
+
+var gristColumnTypes = map[tabular.ColumnType]string{
+	tabular.Text:         "Text",
+	tabular.LongText:     "Text",
+	tabular.Number:       "Int",
+	tabular.Decimal:      "Numeric",
+	tabular.Checkbox:     "Bool",
+	tabular.DateOnly:     "Date",
+	tabular.DateTime:     "DateTime:UTC",
+	tabular.URL:          "Text",
+	tabular.SingleSelect: "Choice",
+}
+
+type Backend struct {
+	client *gristClient
+}
+
+func NewBackend(c *common.Client, dbQueries *database.Queries, encryptionKey []byte, target database.Target) (*Backend, error) {
+	client, err := newGristClient(c, dbQueries, encryptionKey, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Backend{client: client}, nil
+}
+
+func (b *Backend) BatchSize() int {
+	return gristBatchSize
+}
+
+func gristColumnFor(column tabular.Column) (gristColumn, error) {
+	col := gristColumn{
+		ID: column.Name,
+		Fields: gristColumnFields{
+			Label: column.Name,
+			Type:  gristColumnTypes[column.Type],
+		},
+	}
+
+	var options *gristWidgetOptions
+	switch column.Type {
+	case tabular.URL:
+		options = &gristWidgetOptions{Widget: "HyperLink"}
+	case tabular.LongText:
+		options = &gristWidgetOptions{Widget: "TextBox"}
+	case tabular.SingleSelect:
+		options = &gristWidgetOptions{ChoiceOptions: make(map[string]gristChoiceOption)}
+		for _, choice := range column.Choices {
+			options.Choices = append(options.Choices, choice.Name)
+			options.ChoiceOptions[choice.Name] = gristChoiceOption{FillColor: choice.Color}
+		}
+	}
+
+	if options != nil {
+		data, err := json.Marshal(options)
+		if err != nil {
+			return col, fmt.Errorf("marshal widget options: %w", err)
+		}
+		col.Fields.WidgetOptions = string(data)
+	}
+
+	return col, nil
+}
+
+// CreateTable reports column IDs as the column names, since Grist keeps the IDs
+// it was given. The table ID itself is normalized, e.g. posts becomes Posts.
+func (b *Backend) CreateTable(table tabular.Table) (*tabular.TableInfo, error) {
+	req := gristTable{ID: table.Name}
+	for _, column := range table.Columns {
+		col, err := gristColumnFor(column)
+		if err != nil {
+			return nil, err
+		}
+		req.Columns = append(req.Columns, col)
+	}
+
+	var result struct {
+		Tables []gristTable `json:"tables"`
+	}
+	if err := b.client.do("POST", "/tables", map[string]any{"tables": []gristTable{req}}, &result); err != nil {
+		return nil, fmt.Errorf("failed to create table %s: %w", table.Name, err)
+	}
+	if len(result.Tables) != 1 {
+		return nil, fmt.Errorf("failed to create table %s: no table returned", table.Name)
+	}
+
+	info := &tabular.TableInfo{ID: result.Tables[0].ID, Name: table.Name, Columns: make(map[string]string)}
+	for _, column := range table.Columns {
+		info.Columns[column.Name] = column.Name
+	}
+
+	return info, nil
+}
+
+func (b *Backend) UpdateColumn(tableID, columnID string, column tabular.Column) error {
+	col, err := gristColumnFor(column)
+	if err != nil {
+		return err
+	}
+	col.ID = columnID
+
+	if err := b.client.do("PATCH", "/tables/"+tableID+"/columns", map[string]any{"columns": []gristColumn{col}}, nil); err != nil {
+		return fmt.Errorf("failed to update column %s: %w", column.Name, err)
+	}
+	return nil
+}
+
+// CreateLink adds a reference column pointing at the parent table to the child
+// table. Grist has no separate one-to-many side, so the link name is unused.
+func (b *Backend) CreateLink(parentTableID, childTableID, name string) (string, error) {
+	req := gristColumn{
+		ID: gristLinkColumn,
+		Fields: gristColumnFields{
+			Label: gristLinkColumn,
+			Type:  "Ref:" + parentTableID,
+		},
+	}
+
+	var result struct {
+		Columns []gristColumn `json:"columns"`
+	}
+	if err := b.client.do("POST", "/tables/"+childTableID+"/columns", map[string]any{"columns": []gristColumn{req}}, &result); err != nil {
+		return "", fmt.Errorf("failed to create link %s: %w", name, err)
+	}
+	if len(result.Columns) != 1 {
+		return "", fmt.Errorf("failed to create link %s: no column returned", name)
+	}
+
+	return result.Columns[0].ID, nil
+}
+
+func (b *Backend) LinkRecords(parentTableID, childTableID, linkID, parentID string, childIDs []string) error {
+	parent, err := strconv.Atoi(parentID)
+	if err != nil {
+		return fmt.Errorf("invalid record id %s: %w", parentID, err)
+	}
+
+	records := make([]gristRecord, len(childIDs))
+	for i, childID := range childIDs {
+		id, err := strconv.Atoi(childID)
+		if err != nil {
+			return fmt.Errorf("invalid record id %s: %w", childID, err)
+		}
+		records[i] = gristRecord{ID: id, Fields: map[string]any{linkID: parent}}
+	}
+
+	return b.client.do("PATCH", "/tables/"+childTableID+"/records", map[string]any{"records": records}, nil)
+}
+
+// CreateRecords reads the new rows back by ct_id, since Grist only answers a
+// create with the row IDs
+func (b *Backend) CreateRecords(tableID string, records []tabular.Record) (map[string]string, error) {
+	req := make([]gristRecord, len(records))
+	ctIDs := make([]any, len(records))
+	for i, record := range records {
+		req[i] = gristRecord{Fields: gristFields(record.Fields)}
+		ctIDs[i] = record.Fields["ct_id"]
+	}
+
+	if err := b.client.do("POST", "/tables/"+tableID+"/records", map[string]any{"records": req}, nil); err != nil {
+		return nil, fmt.Errorf("failed to create records: %w", err)
+	}
+
+	filter, err := json.Marshal(map[string][]any{"ct_id": ctIDs})
+	if err != nil {
+		return nil, fmt.Errorf("marshal filter: %w", err)
+	}
+
+	var result struct {
+		Records []gristRecord `json:"records"`
+	}
+	if err := b.client.do("GET", "/tables/"+tableID+"/records?filter="+url.QueryEscape(string(filter)), nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to read created records: %w", err)
+	}
+
+	ids := make(map[string]string, len(result.Records))
+	for _, record := range result.Records {
+		if ctID, ok := record.Fields["ct_id"].(string); ok {
+			ids[ctID] = strconv.Itoa(record.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (b *Backend) UpdateRecords(tableID string, records []tabular.Record) error {
+	req := make([]gristRecord, len(records))
+	for i, record := range records {
+		id, err := strconv.Atoi(record.ID)
+		if err != nil {
+			return fmt.Errorf("invalid record id %s: %w", record.ID, err)
+		}
+		req[i] = gristRecord{ID: id, Fields: gristFields(record.Fields)}
+	}
+
+	if err := b.client.do("PATCH", "/tables/"+tableID+"/records", map[string]any{"records": req}, nil); err != nil {
+		return fmt.Errorf("failed to update records: %w", err)
+	}
+	return nil
+}
+
+func (b *Backend) DeleteRecords(tableID string, ids []string) error {
+	rowIDs := make([]int, 0, len(ids))
+	for _, id := range ids {
+		v, err := strconv.Atoi(id)
+		if err != nil {
+			return fmt.Errorf("invalid record id %s: %w", id, err)
+		}
+		rowIDs = append(rowIDs, v)
+	}
+
+	if err := b.client.do("POST", "/tables/"+tableID+"/data/delete", rowIDs, nil); err != nil {
+		return fmt.Errorf("failed to delete records: %w", err)
+	}
+	return nil
+}
+
+// gristFields sends dates and times as Unix seconds, which is how Grist stores
+// Date and DateTime cells.
+func gristFields(fields map[string]any) map[string]any {
+	out := make(map[string]any, len(fields))
+	for name, value := range fields {
+		switch v := value.(type) {
+		case tabular.Date:
+			t := time.Time(v)
+			out[name] = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix()
+		case time.Time:
+			out[name] = v.Unix()
+		default:
+			out[name] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package grist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/common"
+)
+
+type gristClient struct {
+	http   *common.Client
+	docURL string
+	token  string
+}
+
+func newGristClient(c *common.Client, dbQueries *database.Queries, encryptionKey []byte, target database.Target) (*gristClient, error) {
+	token, _, _, err := authhelp.GetTargetToken(context.Background(), dbQueries, encryptionKey, target.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Grist API key: %w", err)
+	}
+
+	return &gristClient{
+		http:   c,
+		docURL: strings.TrimRight(target.HostUrl.String, "/") + "/api/docs/" + target.DbID.String,
+		token:  token,
+	}, nil
+}
+
+func (g *gristClient) do(method, path string, payload, out any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, g.docURL+path, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+g.token)
+
+	resp, err := g.http.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
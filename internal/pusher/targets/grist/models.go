@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package grist
+
+type gristColumn struct {
+	ID     string            `json:"id"`
+	Fields gristColumnFields `json:"fields"`
+}
+
+type gristColumnFields struct {
+	Label         string `json:"label,omitempty"`
+	Type          string `json:"type,omitempty"`
+	WidgetOptions string `json:"widgetOptions,omitempty"`
+}
+
+type gristTable struct {
+	ID      string        `json:"id"`
+	Columns []gristColumn `json:"columns,omitempty"`
+}
+
+type gristRecord struct {
+	ID     int            `json:"id,omitempty"`
+	Fields map[string]any `json:"fields"`
+}
+
+type gristChoiceOption struct {
+	FillColor string `json:"fillColor,omitempty"`
+}
+
+type gristWidgetOptions struct {
+	Widget        string                       `json:"widget,omitempty"`
+	Choices       []string                     `json:"choices,omitempty"`
+	ChoiceOptions map[string]gristChoiceOption `json:"choiceOptions,omitempty"`
+}
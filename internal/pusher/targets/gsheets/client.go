@@ -0,0 +1,227 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package gsheets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+const (
+	// Sheets allows 60 write requests per minute per user, so writes are batched
+	sheetsBatchSize  = 500
+	sheetsMaxRetries = 6
+
+	// Cells hold at most 50000 characters
+	sheetsCellLimit = 50000
+)
+
+type sheetsClient struct {
+	svc           *sheets.Service
+	spreadsheetID string
+}
+
+func newSheetsClient(dbQueries *database.Queries, encryptionKey []byte, target database.Target) (*sheetsClient, error) {
+	ctx := context.Background()
+
+	token, _, _, err := authhelp.GetTargetToken(ctx, dbQueries, encryptionKey, target.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target token: %w", err)
+	}
+
+	opts, err := clientOptions(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := sheets.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sheets client: %w", err)
+	}
+
+	return &sheetsClient{svc: svc, spreadsheetID: target.DbID.String}, nil
+}
+
+// clientOptions authenticates with the service account key stored as the
+// target's token. Tests replace it to talk to a fake server.
+var clientOptions = func(ctx context.Context, token string) ([]option.ClientOption, error) {
+	creds, err := google.CredentialsFromJSON(ctx, []byte(token), sheets.SpreadsheetsScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	return []option.ClientOption{option.WithCredentials(creds)}, nil
+}
+
+// withRetry backs off on quota and server errors, which the Sheets API returns
+// whenever the per-minute request quota is used up.
+func withRetry(call func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= sheetsMaxRetries {
+			return err
+		}
+
+		var apiErr *googleapi.Error
+		if !errors.As(err, &apiErr) || (apiErr.Code != http.StatusTooManyRequests && apiErr.Code < 500) {
+			return err
+		}
+
+		delay := time.Duration(1<<attempt) * time.Second
+		log.Printf("Google Sheets: request returned %d, retrying in %v", apiErr.Code, delay)
+		time.Sleep(delay)
+	}
+}
+
+// sheetTitles maps sheet IDs to their current titles, so tabs can be renamed in the spreadsheet.
+func (s *sheetsClient) sheetTitles() (map[int64]string, error) {
+	var spreadsheet *sheets.Spreadsheet
+	err := withRetry(func() error {
+		var err error
+		spreadsheet, err = s.svc.Spreadsheets.Get(s.spreadsheetID).Fields("sheets.properties").Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make(map[int64]string, len(spreadsheet.Sheets))
+	for _, sheet := range spreadsheet.Sheets {
+		titles[sheet.Properties.SheetId] = sheet.Properties.Title
+	}
+
+	return titles, nil
+}
+
+func (s *sheetsClient) addSheet(title string) (int64, error) {
+	var resp *sheets.BatchUpdateSpreadsheetResponse
+	err := withRetry(func() error {
+		var err error
+		resp, err = s.svc.Spreadsheets.BatchUpdate(s.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: []*sheets.Request{
+				{AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: title}}},
+			},
+		}).Do()
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Replies[0].AddSheet.Properties.SheetId, nil
+}
+
+func (s *sheetsClient) writeHeader(title string, columns []string) error {
+	header := make([]any, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+
+	return withRetry(func() error {
+		_, err := s.svc.Spreadsheets.Values.Update(s.spreadsheetID, quoteTitle(title)+"!A1", &sheets.ValueRange{
+			Values: [][]any{header},
+		}).ValueInputOption("RAW").Do()
+		return err
+	})
+}
+
+func (s *sheetsClient) readValues(title string) ([][]any, error) {
+	var resp *sheets.ValueRange
+	err := withRetry(func() error {
+		var err error
+		resp, err = s.svc.Spreadsheets.Values.Get(s.spreadsheetID, quoteTitle(title)).ValueRenderOption("UNFORMATTED_VALUE").Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Values, nil
+}
+
+func (s *sheetsClient) updateRows(ranges []*sheets.ValueRange) error {
+	for start := 0; start < len(ranges); start += sheetsBatchSize {
+		end := min(start+sheetsBatchSize, len(ranges))
+
+		err := withRetry(func() error {
+			_, err := s.svc.Spreadsheets.Values.BatchUpdate(s.spreadsheetID, &sheets.BatchUpdateValuesRequest{
+				ValueInputOption: "RAW",
+				Data:             ranges[start:end],
+			}).Do()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *sheetsClient) appendRows(title string, rows [][]any) error {
+	for start := 0; start < len(rows); start += sheetsBatchSize {
+		end := min(start+sheetsBatchSize, len(rows))
+
+		err := withRetry(func() error {
+			_, err := s.svc.Spreadsheets.Values.Append(s.spreadsheetID, quoteTitle(title)+"!A1", &sheets.ValueRange{
+				Values: rows[start:end],
+			}).ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Do()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteRows removes rows by zero-based index, bottom first so earlier indexes stay valid.
+func (s *sheetsClient) deleteRows(sheetID int64, rows []int) error {
+	sort.Sort(sort.Reverse(sort.IntSlice(rows)))
+
+	for start := 0; start < len(rows); start += sheetsBatchSize {
+		end := min(start+sheetsBatchSize, len(rows))
+
+		var requests []*sheets.Request
+		for _, row := range rows[start:end] {
+			requests = append(requests, &sheets.Request{
+				DeleteDimension: &sheets.DeleteDimensionRequest{
+					Range: &sheets.DimensionRange{
+						SheetId:    sheetID,
+						Dimension:  "ROWS",
+						StartIndex: int64(row),
+						EndIndex:   int64(row) + 1,
+					},
+				},
+			})
+		}
+
+		err := withRetry(func() error {
+			_, err := s.svc.Spreadsheets.BatchUpdate(s.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+				Requests: requests,
+			}).Do()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func quoteTitle(title string) string {
+	return "'" + strings.ReplaceAll(title, "'", "''") + "'"
+}
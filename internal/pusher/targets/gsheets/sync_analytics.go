@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package gsheets
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets"
+	"github.com/google/uuid"
+)
+
+func syncSheetsSiteStats(w *tabWriter, dbQueries *database.Queries, target database.Target) error {
+
+	sources, err := dbQueries.GetTargetSources(context.Background(), target.ID)
+	if err != nil {
+		return err
+	}
+
+	dateThreshold := time.Now().AddDate(0, 0, -9)
+	var newStats []uuid.UUID
+
+	for _, source := range sources {
+		syncedStats, err := dbQueries.GetSyncedSiteStatsForUpdate(context.Background(), database.GetSyncedSiteStatsForUpdateParams{
+			TargetID: target.ID,
+			SourceID: source.SourceID,
+			Date:     dateThreshold,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, stat := range syncedStats {
+			w.upsert(stat.TargetRecordID, []any{stat.ID.String(), stat.SourceID.String(), dateCell(stat.Date), stat.Visitors, stat.AvgSessionDuration})
+		}
+
+		unsyncedStats, err := dbQueries.GetUnsyncedSiteStatsForTarget(context.Background(), database.GetUnsyncedSiteStatsForTargetParams{
+			TargetID: target.ID,
+			SourceID: source.SourceID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, stat := range unsyncedStats {
+			w.upsert(stat.ID.String(), []any{stat.ID.String(), stat.SourceID.String(), dateCell(stat.Date), stat.Visitors, stat.AvgSessionDuration})
+			newStats = append(newStats, stat.ID)
+		}
+	}
+
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	for _, id := range newStats {
+		_, err := dbQueries.AddAnalyticsSiteStatToTarget(context.Background(), database.AddAnalyticsSiteStatToTargetParams{
+			ID:             uuid.New(),
+			SyncedAt:       time.Now(),
+			StatID:         uuid.NullUUID{UUID: id, Valid: true},
+			TargetID:       target.ID,
+			TargetRecordID: id.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to map site stat: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func syncSheetsPageStats(w *tabWriter, dbQueries *database.Queries, target database.Target) error {
+
+	sources, err := dbQueries.GetTargetSources(context.Background(), target.ID)
+	if err != nil {
+		return err
+	}
+
+	dateThreshold := time.Now().AddDate(0, 0, -9)
+	var newStats []uuid.UUID
+
+	for _, source := range sources {
+		syncedStats, err := dbQueries.GetSyncedPageStatsForUpdate(context.Background(), database.GetSyncedPageStatsForUpdateParams{
+			TargetID: target.ID,
+			SourceID: source.SourceID,
+			Date:     dateThreshold,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, stat := range syncedStats {
+			w.upsert(stat.TargetRecordID, []any{stat.ID.String(), stat.SourceID.String(), dateCell(stat.Date), stat.UrlPath, stat.Views})
+		}
+
+		unsyncedStats, err := dbQueries.GetUnsyncedPageStatsForTarget(context.Background(), database.GetUnsyncedPageStatsForTargetParams{
+			TargetID: target.ID,
+			SourceID: source.SourceID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, stat := range unsyncedStats {
+			w.upsert(stat.ID.String(), []any{stat.ID.String(), stat.SourceID.String(), dateCell(stat.Date), stat.UrlPath, stat.Views})
+			newStats = append(newStats, stat.ID)
+		}
+	}
+
+	err = targets.RemoveOrphanedPageStats(dbQueries, target.ID, func(keys []string) error {
+		for _, key := range keys {
+			w.remove(key)
+		}
+		return w.flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range newStats {
+		_, err := dbQueries.AddAnalyticsPageStatToTarget(context.Background(), database.AddAnalyticsPageStatToTargetParams{
+			ID:             uuid.New(),
+			SyncedAt:       time.Now(),
+			StatID:         uuid.NullUUID{UUID: id, Valid: true},
+			TargetID:       target.ID,
+			TargetRecordID: id.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to map page stat: %w", err)
+		}
+	}
+
+	return nil
+}
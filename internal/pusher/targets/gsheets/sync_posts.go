@@ -0,0 +1,215 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package gsheets
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/helpers"
+	"github.com/google/uuid"
+)
+
+func SyncSheets(dbQueries *database.Queries, encryptionKey []byte, target database.Target) error {
+
+	client, err := newSheetsClient(dbQueries, encryptionKey, target)
+	if err != nil {
+		return err
+	}
+
+	titles, err := client.sheetTitles()
+	if err != nil {
+		return fmt.Errorf("failed to read spreadsheet: %w", err)
+	}
+
+	steps := []struct {
+		tab  SheetTab
+		sync func(*tabWriter, *database.Queries, database.Target) error
+	}{
+		{sheetTabs[0], syncSheetsSources},
+		{sheetTabs[1], syncSheetsPosts},
+		{sheetTabs[2], syncSheetsSourcesStats},
+		{sheetTabs[3], syncSheetsSiteStats},
+		{sheetTabs[4], syncSheetsPageStats},
+	}
+
+	for _, step := range steps {
+		w, err := openTab(client, dbQueries, target, step.tab, titles)
+		if err != nil {
+			return err
+		}
+		if err := step.sync(w, dbQueries, target); err != nil {
+			return fmt.Errorf("failed to sync %s: %w", step.tab.Title, err)
+		}
+	}
+
+	return nil
+}
+
+func postRow(post database.GetAllPostsWithTheLatestInfoForUserRow) ([]any, error) {
+	url, err := helpers.ConvPostToURL(post.Network.String, post.Author, post.NetworkInternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	reactionsSyncedAt := ""
+	if post.ReactionsSyncedAt.Valid {
+		reactionsSyncedAt = timeCell(post.ReactionsSyncedAt.Time)
+	}
+
+	return []any{
+		post.ID.String(),
+		post.SourceID.String(),
+		timeCell(post.CreatedAt),
+		reactionsSyncedAt,
+		post.IsArchived,
+		post.NetworkInternalID,
+		post.Network.String,
+		post.PostType,
+		post.Author,
+		textCell(post.Content.String),
+		post.Likes.Int64,
+		post.Views.Int64,
+		post.Reposts.Int64,
+		url,
+	}, nil
+}
+
+func syncSheetsPosts(w *tabWriter, dbQueries *database.Queries, target database.Target) error {
+
+	posts, err := dbQueries.GetAllPostsWithTheLatestInfoForUser(context.Background(), target.UserID)
+	if err != nil {
+		return err
+	}
+
+	mappedPosts, err := dbQueries.GetPostsPreviouslySynced(context.Background(), target.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching mapped posts: %w", err)
+	}
+
+	mappedMap := make(map[uuid.UUID]database.PostsOnTarget, len(mappedPosts))
+	var removePosts []database.PostsOnTarget
+	for _, m := range mappedPosts {
+		if !m.PostID.Valid {
+			removePosts = append(removePosts, m)
+		} else {
+			mappedMap[m.PostID.UUID] = m
+		}
+	}
+
+	localMap := make(map[uuid.UUID]struct{}, len(posts))
+	var newPosts []uuid.UUID
+
+	for _, post := range posts {
+		localMap[post.ID] = struct{}{}
+
+		row, err := postRow(post)
+		if err != nil {
+			return err
+		}
+		w.upsert(post.ID.String(), row)
+
+		if _, ok := mappedMap[post.ID]; !ok {
+			newPosts = append(newPosts, post.ID)
+		}
+	}
+
+	for id, m := range mappedMap {
+		if _, ok := localMap[id]; !ok {
+			removePosts = append(removePosts, m)
+		}
+	}
+
+	for _, m := range removePosts {
+		w.remove(m.TargetPostID)
+	}
+
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	for _, id := range newPosts {
+		_, err := dbQueries.AddPostToTarget(context.Background(), database.AddPostToTargetParams{
+			ID:            uuid.New(),
+			FirstSyncedAt: time.Now(),
+			PostID:        uuid.NullUUID{UUID: id, Valid: true},
+			TargetID:      target.ID,
+			TargetPostID:  id.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to map post: %w", err)
+		}
+	}
+
+	for _, m := range removePosts {
+		if err := dbQueries.DeletePostOnTarget(context.Background(), m.ID); err != nil {
+			log.Printf("Warning: Failed to delete posts_on_target mapping: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func DeletePostsAndSourceSheets(dbQueries *database.Queries, encryptionKey []byte, target database.Target, source database.Source) error {
+
+	sourceMapping, err := dbQueries.GetTargetSourceBySource(context.Background(), database.GetTargetSourceBySourceParams{
+		TargetID: target.ID,
+		SourceID: source.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching source mapping: %w", err)
+	}
+
+	client, err := newSheetsClient(dbQueries, encryptionKey, target)
+	if err != nil {
+		return err
+	}
+
+	titles, err := client.sheetTitles()
+	if err != nil {
+		return fmt.Errorf("failed to read spreadsheet: %w", err)
+	}
+
+	sources, err := openTab(client, dbQueries, target, sheetTabs[0], titles)
+	if err != nil {
+		return err
+	}
+	sources.remove(sourceMapping.TargetSourceID)
+	if err := sources.flush(); err != nil {
+		return err
+	}
+
+	postsToDelete, err := dbQueries.GetPostsBySourceAndTarget(context.Background(), database.GetPostsBySourceAndTargetParams{
+		TargetID: target.ID,
+		SourceID: source.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	posts, err := openTab(client, dbQueries, target, sheetTabs[1], titles)
+	if err != nil {
+		return err
+	}
+	for _, post := range postsToDelete {
+		posts.remove(post.TargetPostID)
+	}
+	if err := posts.flush(); err != nil {
+		return err
+	}
+
+	err = dbQueries.DeletePostsOnTargetAndSource(context.Background(), database.DeletePostsOnTargetAndSourceParams{
+		TargetID: target.ID,
+		SourceID: source.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return dbQueries.DeleteSourceTarget(context.Background(), database.DeleteSourceTargetParams{
+		TargetID: target.ID,
+		SourceID: source.ID,
+	})
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package gsheets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/helpers"
+	"github.com/google/uuid"
+)
+
+func sourceRow(source database.Source) []any {
+	url, _ := helpers.ConvNetworkToURL(source.Network, source.UserName)
+
+	lastSynced := ""
+	if source.LastSynced.Valid {
+		lastSynced = timeCell(source.LastSynced.Time)
+	}
+
+	return []any{source.ID.String(), source.Network, source.UserName, url, lastSynced}
+}
+
+func syncSheetsSources(w *tabWriter, dbQueries *database.Queries, target database.Target) error {
+	userSources, err := dbQueries.GetUserSources(context.Background(), target.UserID)
+	if err != nil {
+		return fmt.Errorf("error fetching user sources: %w", err)
+	}
+
+	mappedSources, err := dbQueries.GetTargetSources(context.Background(), target.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching target sources: %w", err)
+	}
+
+	mappedMap := make(map[uuid.UUID]struct{}, len(mappedSources))
+	for _, m := range mappedSources {
+		mappedMap[m.SourceID] = struct{}{}
+	}
+
+	localSources := make(map[uuid.UUID]struct{}, len(userSources))
+	var newSources []database.Source
+
+	for _, source := range userSources {
+		localSources[source.ID] = struct{}{}
+		w.upsert(source.ID.String(), sourceRow(source))
+
+		if _, ok := mappedMap[source.ID]; !ok {
+			newSources = append(newSources, source)
+		}
+	}
+
+	var removedSources []database.SourcesOnTarget
+	for _, m := range mappedSources {
+		if _, ok := localSources[m.SourceID]; !ok {
+			w.remove(m.TargetSourceID)
+			removedSources = append(removedSources, m)
+		}
+	}
+
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	for _, source := range newSources {
+		_, err := dbQueries.AddSourceToTarget(context.Background(), database.AddSourceToTargetParams{
+			ID:             uuid.New(),
+			SourceID:       source.ID,
+			TargetID:       target.ID,
+			TargetSourceID: source.ID.String(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, m := range removedSources {
+		err := dbQueries.DeleteSourceTarget(context.Background(), database.DeleteSourceTargetParams{
+			TargetID: target.ID,
+			SourceID: m.SourceID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete source target mapping: %w", err)
+		}
+	}
+
+	return nil
+}
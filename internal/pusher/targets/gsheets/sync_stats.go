@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package gsheets
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+func sourcesStatRow(id, sourceID uuid.UUID, date time.Time, followers, following, posts sql.NullInt64, likes, reposts, views sql.NullFloat64) []any {
+	return []any{
+		id.String(),
+		sourceID.String(),
+		dateCell(date),
+		followers.Int64,
+		following.Int64,
+		posts.Int64,
+		likes.Float64,
+		reposts.Float64,
+		views.Float64,
+	}
+}
+
+func syncSheetsSourcesStats(w *tabWriter, dbQueries *database.Queries, target database.Target) error {
+
+	sources, err := dbQueries.GetTargetSources(context.Background(), target.ID)
+	if err != nil {
+		return err
+	}
+
+	dateThreshold := time.Now().AddDate(0, 0, -2)
+	var newStats []uuid.UUID
+
+	for _, source := range sources {
+		syncedStats, err := dbQueries.GetSyncedSourcesStatsForUpdate(context.Background(), database.GetSyncedSourcesStatsForUpdateParams{
+			TargetID: target.ID,
+			SourceID: source.SourceID,
+			Date:     dateThreshold,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, stat := range syncedStats {
+			w.upsert(stat.TargetRecordID, sourcesStatRow(stat.ID, stat.SourceID, stat.Date, stat.FollowersCount, stat.FollowingCount, stat.PostsCount, stat.AverageLikes, stat.AverageReposts, stat.AverageViews))
+		}
+
+		unsyncedStats, err := dbQueries.GetUnsyncedSourcesStatsForTarget(context.Background(), database.GetUnsyncedSourcesStatsForTargetParams{
+			SourceID: source.SourceID,
+			TargetID: target.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, stat := range unsyncedStats {
+			w.upsert(stat.ID.String(), sourcesStatRow(stat.ID, stat.SourceID, stat.Date, stat.FollowersCount, stat.FollowingCount, stat.PostsCount, stat.AverageLikes, stat.AverageReposts, stat.AverageViews))
+			newStats = append(newStats, stat.ID)
+		}
+	}
+
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	for _, id := range newStats {
+		_, err := dbQueries.AddSourcesStatToTarget(context.Background(), database.AddSourcesStatToTargetParams{
+			ID:             uuid.New(),
+			SyncedAt:       time.Now(),
+			StatID:         id,
+			TargetID:       target.ID,
+			TargetRecordID: id.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to map sources stat: %w", err)
+		}
+	}
+
+	return nil
+}
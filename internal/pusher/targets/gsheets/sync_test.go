@@ -0,0 +1,343 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package gsheets
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+	"github.com/pressly/goose/v3"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+
+	_ "github.com/lib/pq"
+)
+
+// TestSyncTwoTargets syncs one user's data to two spreadsheets. Both targets
+// key their rows by RPSync's IDs, so their mappings hold the same values.
+//
+// It needs a scratch database: RPSYNC_TEST_DATABASE_URL=postgres://... go test
+func TestSyncTwoTargets(t *testing.T) {
+	dbQueries := testDatabase(t)
+	encryptionKey := make([]byte, 32)
+	ctx := context.Background()
+
+	fake := newFakeSheets()
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	defaultOptions := clientOptions
+	clientOptions = func(context.Context, string) ([]option.ClientOption, error) {
+		return []option.ClientOption{option.WithEndpoint(srv.URL + "/"), option.WithoutAuthentication()}, nil
+	}
+	defer func() { clientOptions = defaultOptions }()
+
+	now := time.Now()
+	user, err := dbQueries.CreateUser(ctx, database.CreateUserParams{
+		ID:         uuid.New(),
+		Username:   "sheets-test-" + uuid.NewString()[:8],
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		SyncPeriod: "30m",
+	})
+	if err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+
+	source, err := dbQueries.CreateSource(ctx, database.CreateSourceParams{
+		ID:         uuid.New(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Network:    "Bluesky",
+		UserName:   "example.bsky.social",
+		UserID:     user.ID,
+		IsActive:   true,
+		SyncStatus: "Synced",
+	})
+	if err != nil {
+		t.Fatalf("creating source: %v", err)
+	}
+
+	_, err = dbQueries.CreatePost(ctx, database.CreatePostParams{
+		ID:                uuid.New(),
+		CreatedAt:         now,
+		LastSyncedAt:      now,
+		SourceID:          source.ID,
+		NetworkInternalID: "3kabc",
+		Content:           sql.NullString{String: "hello", Valid: true},
+		PostType:          "post",
+		Author:            "example.bsky.social",
+	})
+	if err != nil {
+		t.Fatalf("creating post: %v", err)
+	}
+
+	_, err = dbQueries.CreateSourceStat(ctx, database.CreateSourceStatParams{
+		ID:             uuid.New(),
+		Date:           now,
+		SourceID:       source.ID,
+		FollowersCount: sql.NullInt64{Int64: 10, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("creating source stat: %v", err)
+	}
+
+	for i := range 2 {
+		target, err := dbQueries.CreateTarget(ctx, database.CreateTargetParams{
+			ID:            uuid.New(),
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			TargetType:    "Google Sheets",
+			UserID:        user.ID,
+			DbID:          sql.NullString{String: "spreadsheet-" + strconv.Itoa(i), Valid: true},
+			IsActive:      true,
+			SyncFrequency: "PT30M",
+			SyncStatus:    "Initialized",
+			Settings:      json.RawMessage("{}"),
+		})
+		if err != nil {
+			t.Fatalf("creating target %d: %v", i, err)
+		}
+
+		if err := authhelp.InsertTargetToken(ctx, dbQueries, target.ID, "{}", target.DbID.String, encryptionKey); err != nil {
+			t.Fatalf("storing token of target %d: %v", i, err)
+		}
+
+		if err := InitializeSheets(dbQueries, encryptionKey, target); err != nil {
+			t.Fatalf("initializing target %d: %v", i, err)
+		}
+
+		// The second run has to find the rows the first one wrote
+		for run := range 2 {
+			if err := SyncSheets(dbQueries, encryptionKey, target); err != nil {
+				t.Fatalf("syncing target %d, run %d: %v", i, run, err)
+			}
+		}
+
+		mapped, err := dbQueries.GetPostsPreviouslySynced(ctx, target.ID)
+		if err != nil {
+			t.Fatalf("reading post mappings of target %d: %v", i, err)
+		}
+		if len(mapped) != 1 {
+			t.Errorf("target %d has %d post mappings, want 1", i, len(mapped))
+		}
+
+		if rows := fake.rows(target.DbID.String, "Posts"); len(rows) != 2 {
+			t.Errorf("target %d has %d rows in Posts, want a header and one post", i, len(rows))
+		}
+	}
+}
+
+func testDatabase(t *testing.T) *database.Queries {
+	t.Helper()
+
+	dbURL := os.Getenv("RPSYNC_TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("RPSYNC_TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("postgres"); err != nil {
+		t.Fatal(err)
+	}
+	if err := goose.Up(db, "../../../../sql/schema"); err != nil {
+		t.Fatalf("migrating database: %v", err)
+	}
+
+	return database.New(db)
+}
+
+type fakeSheet struct {
+	id    int64
+	title string
+	rows  [][]any
+}
+
+// fakeSheets serves the part of the Sheets API the target uses, holding every
+// spreadsheet in memory
+type fakeSheets struct {
+	mu           sync.Mutex
+	nextID       int64
+	spreadsheets map[string][]*fakeSheet
+}
+
+func newFakeSheets() *fakeSheets {
+	return &fakeSheets{nextID: 1, spreadsheets: make(map[string][]*fakeSheet)}
+}
+
+func (f *fakeSheets) rows(spreadsheetID, title string) [][]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if sheet := f.sheet(spreadsheetID, title); sheet != nil {
+		return sheet.rows
+	}
+	return nil
+}
+
+func (f *fakeSheets) sheet(spreadsheetID, title string) *fakeSheet {
+	for _, s := range f.spreadsheets[spreadsheetID] {
+		if s.title == title {
+			return s
+		}
+	}
+	return nil
+}
+
+func (f *fakeSheets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v4/spreadsheets/")
+
+	spreadsheetID, valuesPath, isValues := strings.Cut(path, "/values")
+	switch {
+	case isValues && valuesPath == ":batchUpdate":
+		var req sheets.BatchUpdateValuesRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		for _, data := range req.Data {
+			if !f.write(w, spreadsheetID, data.Range, data.Values) {
+				return
+			}
+		}
+		reply(w, &sheets.BatchUpdateValuesResponse{})
+
+	case isValues && strings.HasSuffix(valuesPath, ":append"):
+		var req sheets.ValueRange
+		if !decode(w, r, &req) {
+			return
+		}
+		title, _ := parseRange(strings.TrimSuffix(strings.TrimPrefix(valuesPath, "/"), ":append"))
+		sheet := f.sheet(spreadsheetID, title)
+		if sheet == nil {
+			http.Error(w, "no such sheet", http.StatusBadRequest)
+			return
+		}
+		sheet.rows = append(sheet.rows, req.Values...)
+		reply(w, &sheets.AppendValuesResponse{})
+
+	case isValues && r.Method == http.MethodPut:
+		var req sheets.ValueRange
+		if !decode(w, r, &req) {
+			return
+		}
+		if f.write(w, spreadsheetID, strings.TrimPrefix(valuesPath, "/"), req.Values) {
+			reply(w, &sheets.UpdateValuesResponse{})
+		}
+
+	case isValues:
+		title, _ := parseRange(strings.TrimPrefix(valuesPath, "/"))
+		sheet := f.sheet(spreadsheetID, title)
+		if sheet == nil {
+			http.Error(w, "no such sheet", http.StatusBadRequest)
+			return
+		}
+		reply(w, &sheets.ValueRange{Values: sheet.rows})
+
+	case strings.HasSuffix(path, ":batchUpdate"):
+		spreadsheetID = strings.TrimSuffix(path, ":batchUpdate")
+		var req sheets.BatchUpdateSpreadsheetRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		resp := &sheets.BatchUpdateSpreadsheetResponse{}
+		for _, request := range req.Requests {
+			resp.Replies = append(resp.Replies, f.update(spreadsheetID, request))
+		}
+		reply(w, resp)
+
+	default:
+		resp := &sheets.Spreadsheet{}
+		for _, s := range f.spreadsheets[path] {
+			resp.Sheets = append(resp.Sheets, &sheets.Sheet{
+				Properties: &sheets.SheetProperties{SheetId: s.id, Title: s.title},
+			})
+		}
+		reply(w, resp)
+	}
+}
+
+func (f *fakeSheets) update(spreadsheetID string, request *sheets.Request) *sheets.Response {
+	if request.AddSheet != nil {
+		sheet := &fakeSheet{id: f.nextID, title: request.AddSheet.Properties.Title}
+		f.nextID++
+		f.spreadsheets[spreadsheetID] = append(f.spreadsheets[spreadsheetID], sheet)
+		return &sheets.Response{AddSheet: &sheets.AddSheetResponse{
+			Properties: &sheets.SheetProperties{SheetId: sheet.id, Title: sheet.title},
+		}}
+	}
+
+	if d := request.DeleteDimension; d != nil {
+		for _, s := range f.spreadsheets[spreadsheetID] {
+			if s.id == d.Range.SheetId && int(d.Range.EndIndex) <= len(s.rows) {
+				s.rows = append(s.rows[:d.Range.StartIndex], s.rows[d.Range.EndIndex:]...)
+			}
+		}
+	}
+
+	return &sheets.Response{}
+}
+
+// write replaces rows starting at the row named by an A1 range
+func (f *fakeSheets) write(w http.ResponseWriter, spreadsheetID, rng string, values [][]any) bool {
+	title, row := parseRange(rng)
+	sheet := f.sheet(spreadsheetID, title)
+	if sheet == nil {
+		http.Error(w, "no such sheet", http.StatusBadRequest)
+		return false
+	}
+
+	for i, values := range values {
+		index := row - 1 + i
+		for len(sheet.rows) <= index {
+			sheet.rows = append(sheet.rows, nil)
+		}
+		sheet.rows[index] = values
+	}
+	return true
+}
+
+// parseRange splits 'Title'!A5 into the title and the one-based row, 1 when
+// the range names no cell
+func parseRange(rng string) (string, int) {
+	title, cell, _ := strings.Cut(rng, "!")
+	title = strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(title, "'"), "'"), "''", "'")
+
+	row, err := strconv.Atoi(strings.TrimLeft(cell, "ABCDEFGHIJKLMNOPQRSTUVWXYZ"))
+	if err != nil {
+		row = 1
+	}
+	return title, row
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func reply(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package gsheets
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"google.golang.org/api/sheets/v4"
+)
+
+// tabWriter holds the rows currently in a tab and collects changes to them, so a
+// sync costs one read and a handful of batched writes per tab.
+type tabWriter struct {
+	client  *sheetsClient
+	title   string
+	sheetID int64
+	rows    map[string]int
+	values  map[string][]any
+	updates []*sheets.ValueRange
+	appends [][]any
+	deletes []int
+}
+
+func openTab(client *sheetsClient, dbQueries *database.Queries, target database.Target, tab SheetTab, titles map[int64]string) (*tabWriter, error) {
+	mapping, err := dbQueries.GetTableMappingsByTargetAndName(context.Background(), database.GetTableMappingsByTargetAndNameParams{
+		TargetID:        target.ID,
+		TargetTableName: tab.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s tab mapping: %w", tab.Name, err)
+	}
+
+	sheetID, err := strconv.ParseInt(mapping.TargetTableCode.String, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sheet id for %s: %w", tab.Name, err)
+	}
+
+	title, ok := titles[sheetID]
+	if !ok {
+		return nil, fmt.Errorf("%s tab was removed from the spreadsheet", tab.Title)
+	}
+
+	values, err := client.readValues(title)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s tab: %w", title, err)
+	}
+
+	w := &tabWriter{
+		client:  client,
+		title:   title,
+		sheetID: sheetID,
+		rows:    make(map[string]int, len(values)),
+		values:  make(map[string][]any, len(values)),
+	}
+
+	// Row 0 is the header
+	for i := 1; i < len(values); i++ {
+		if len(values[i]) == 0 {
+			continue
+		}
+		key := cellString(values[i][0])
+		if key == "" {
+			continue
+		}
+		w.rows[key] = i
+		w.values[key] = values[i]
+	}
+
+	return w, nil
+}
+
+// upsert rewrites the row with the given key if any cell changed, or appends it
+// when the key is not in the tab yet.
+func (w *tabWriter) upsert(key string, row []any) {
+	index, ok := w.rows[key]
+	if !ok {
+		w.appends = append(w.appends, row)
+		return
+	}
+
+	if sameRow(w.values[key], row) {
+		return
+	}
+
+	w.updates = append(w.updates, &sheets.ValueRange{
+		Range:  fmt.Sprintf("%s!A%d", quoteTitle(w.title), index+1),
+		Values: [][]any{row},
+	})
+}
+
+func (w *tabWriter) remove(key string) {
+	if index, ok := w.rows[key]; ok {
+		w.deletes = append(w.deletes, index)
+		delete(w.rows, key)
+	}
+}
+
+func (w *tabWriter) flush() error {
+	if err := w.client.updateRows(w.updates); err != nil {
+		return fmt.Errorf("failed to update %s rows: %w", w.title, err)
+	}
+	if err := w.client.deleteRows(w.sheetID, w.deletes); err != nil {
+		return fmt.Errorf("failed to delete %s rows: %w", w.title, err)
+	}
+	if err := w.client.appendRows(w.title, w.appends); err != nil {
+		return fmt.Errorf("failed to append %s rows: %w", w.title, err)
+	}
+
+	w.updates, w.deletes, w.appends = nil, nil, nil
+	return nil
+}
+
+func sameRow(existing, row []any) bool {
+	for i, value := range row {
+		var current any
+		if i < len(existing) {
+			current = existing[i]
+		}
+		if cellString(current) != cellString(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// cellString normalizes values for comparison, since the API reads every number back as float64.
+func cellString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func timeCell(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+func dateCell(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func textCell(s string) string {
+	if runes := []rune(s); len(runes) > sheetsCellLimit {
+		return string(runes[:sheetsCellLimit])
+	}
+	return s
+}
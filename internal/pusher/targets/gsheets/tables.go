@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package gsheets
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+// SheetTab is a tab RPSync maintains in the spreadsheet. The first column is always
+// ct_id, the row's ID in RPSync, which is how rows are found again on later syncs.
+type SheetTab struct {
+	Name    string
+	Title   string
+	Columns []string
+}
+
+var sheetTabs = []SheetTab{
+	{
+		Name:    "sources",
+		Title:   "Sources",
+		Columns: []string{"ct_id", "network", "username", "url", "last_synced"},
+	},
+	{
+		Name:  "posts",
+		Title: "Posts",
+		Columns: []string{
+			"ct_id", "source_id", "created_at", "reactions_synced_at", "is_archived", "network_internal_id",
+			"network", "post_type", "author", "content", "likes", "views", "reposts", "url",
+		},
+	},
+	{
+		Name:  "sources_stats",
+		Title: "Source Stats",
+		Columns: []string{
+			"ct_id", "source_id", "date", "followers_count", "following_count", "posts_count",
+			"average_likes", "average_reposts", "average_views",
+		},
+	},
+	{
+		Name:    "analytics_site_stats",
+		Title:   "Website",
+		Columns: []string{"ct_id", "source_id", "date", "visitors", "avg_session_duration"},
+	},
+	{
+		Name:    "analytics_page_stats",
+		Title:   "Pages",
+		Columns: []string{"ct_id", "source_id", "date", "page_path", "views"},
+	},
+}
+
+func InitializeSheets(dbQueries *database.Queries, encryptionKey []byte, target database.Target) error {
+	client, err := newSheetsClient(dbQueries, encryptionKey, target)
+	if err != nil {
+		return err
+	}
+
+	titles, err := client.sheetTitles()
+	if err != nil {
+		return fmt.Errorf("failed to read spreadsheet: %w", err)
+	}
+
+	for _, tab := range sheetTabs {
+		_, err := dbQueries.GetTableMappingsByTargetAndName(context.Background(), database.GetTableMappingsByTargetAndNameParams{
+			TargetID:        target.ID,
+			TargetTableName: tab.Name,
+		})
+		if err == nil {
+			continue
+		}
+
+		sheetID, found := int64(0), false
+		for id, title := range titles {
+			if title == tab.Title {
+				sheetID, found = id, true
+				break
+			}
+		}
+
+		if found {
+			log.Printf("Google Sheets: Mapping existing %s tab", tab.Title)
+		} else {
+			sheetID, err = client.addSheet(tab.Title)
+			if err != nil {
+				return fmt.Errorf("create %s tab: %w", tab.Title, err)
+			}
+		}
+
+		if err := client.writeHeader(tab.Title, tab.Columns); err != nil {
+			return fmt.Errorf("write %s header: %w", tab.Title, err)
+		}
+
+		mapping, err := dbQueries.CreateMappingForTable(context.Background(), database.CreateMappingForTableParams{
+			ID:              uuid.New(),
+			CreatedAt:       time.Now(),
+			SourceTableName: tab.Name,
+			TargetTableName: tab.Name,
+			TargetTableCode: sql.NullString{String: strconv.FormatInt(sheetID, 10), Valid: true},
+			TargetID:        target.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("create %s table mapping: %w", tab.Name, err)
+		}
+
+		for i, column := range tab.Columns {
+			_, err := dbQueries.CreateMappingForColumn(context.Background(), database.CreateMappingForColumnParams{
+				ID:               uuid.New(),
+				CreatedAt:        time.Now(),
+				TableMappingID:   mapping.ID,
+				SourceColumnName: column,
+				TargetColumnName: column,
+				TargetColumnCode: sql.NullString{String: columnLetter(i), Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("create %s column mapping %s: %w", tab.Name, column, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// columnLetter converts a zero-based column index to A1 notation (0 -> A, 26 -> AA).
+func columnLetter(index int) string {
+	letters := ""
+	for index >= 0 {
+		letters = string(rune('A'+index%26)) + letters
+		index = index/26 - 1
+	}
+	return letters
+}
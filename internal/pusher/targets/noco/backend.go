@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package noco
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/common"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/tabular"
+)
+
+// NocoDB accepts at most 10 records per link request, so every batch stays at that size
+const nocoBatchSize = 10
+
+var nocoColumnTypes = map[tabular.ColumnType]string{
+	tabular.Text:         "SingleLineText",
+	tabular.LongText:     "LongText",
+	tabular.Number:       "Number",
+	tabular.Decimal:      "Decimal",
+	tabular.Checkbox:     "Checkbox",
+	tabular.DateOnly:     "Date",
+	tabular.DateTime:     "DateTime",
+	tabular.URL:          "URL",
+	tabular.SingleSelect: "SingleSelect",
+}
+
+type Backend struct {
+	c             *common.Client
+	dbQueries     *database.Queries
+	encryptionKey []byte
+	target        database.Target
+}
+
+func NewBackend(c *common.Client, dbQueries *database.Queries, encryptionKey []byte, target database.Target) *Backend {
+	return &Backend{c: c, dbQueries: dbQueries, encryptionKey: encryptionKey, target: target}
+}
+
+func (b *Backend) BatchSize() int {
+	return nocoBatchSize
+}
+
+func nocoColumn(column tabular.Column) NocoColumn {
+	col := NocoColumn{
+		Title:  column.Name,
+		Type:   nocoColumnTypes[column.Type],
+		Unique: column.Unique,
+	}
+
+	if column.Type == tabular.SingleSelect {
+		var choices []NocoColumnTypeOptions
+		for _, choice := range column.Choices {
+			choices = append(choices, NocoColumnTypeOptions{Title: choice.Name, Color: choice.Color})
+		}
+		col.Options = NocoColumnTypeSelectOptions{Choices: choices}
+	}
+
+	return col
+}
+
+func (b *Backend) CreateTable(table tabular.Table) (*tabular.TableInfo, error) {
+	url := b.target.HostUrl.String +
+		"/api/v3/meta/bases/" +
+		b.target.DbID.String +
+		"/tables"
+
+	nocoTable := NocoTable{
+		Title:       table.Name,
+		Description: table.Description,
+	}
+	for _, column := range table.Columns {
+		nocoTable.Fields = append(nocoTable.Fields, nocoColumn(column))
+	}
+
+	resp, err := createNocoTable(b.c, b.dbQueries, b.encryptionKey, b.target.ID, url, nocoTable)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &tabular.TableInfo{ID: resp.ID, Name: resp.Title, Columns: make(map[string]string)}
+	for _, field := range resp.Fields {
+		info.Columns[field.Title] = field.ID
+	}
+
+	return info, nil
+}
+
+func (b *Backend) UpdateColumn(tableID, columnID string, column tabular.Column) error {
+	return updateNocoColumn(b.c, b.dbQueries, b.encryptionKey, b.target, tableID, columnID, nocoColumn(column))
+}
+
+func (b *Backend) CreateLink(parentTableID, childTableID, name string) (string, error) {
+	col, err := createNocoColumn(b.c, b.dbQueries, b.encryptionKey, b.target, parentTableID, NocoColumn{
+		Title: name,
+		Type:  "Links",
+		Options: NocoColumnTypeRelation{
+			RelationType:   "hm",
+			RelatedTableId: childTableID,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return col.ID, nil
+}
+
+func (b *Backend) LinkRecords(parentTableID, childTableID, linkID, parentID string, childIDs []string) error {
+	parent, err := strconv.Atoi(parentID)
+	if err != nil {
+		return fmt.Errorf("invalid record id %s: %w", parentID, err)
+	}
+
+	children, err := recordIDs(childIDs)
+	if err != nil {
+		return err
+	}
+
+	return linkChildrenToParent(b.c, b.dbQueries, b.encryptionKey, b.target, parentTableID, linkID, parent, children)
+}
+
+func (b *Backend) CreateRecords(tableID string, records []tabular.Record) (map[string]string, error) {
+	nocoRecords := make([]NocoTableRecord, len(records))
+	for i, record := range records {
+		nocoRecords[i] = NocoTableRecord{Fields: nocoFields(record.Fields)}
+	}
+
+	created, err := createNocoRecords(b.c, b.dbQueries, b.encryptionKey, b.target, tableID, nocoRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]string, len(created))
+	for _, rec := range created {
+		var id float64
+		if val, ok := rec["Id"].(float64); ok {
+			id = val
+		} else if val, ok := rec["id"].(float64); ok {
+			id = val
+		} else {
+			return nil, fmt.Errorf("created record has no id: %v", rec)
+		}
+
+		fields, _ := rec["fields"].(map[string]any)
+		ctID, ok := fields["ct_id"].(string)
+		if !ok {
+			return nil, fmt.Errorf("created record %.0f has no ct_id", id)
+		}
+		ids[ctID] = fmt.Sprintf("%.0f", id)
+	}
+
+	return ids, nil
+}
+
+func (b *Backend) UpdateRecords(tableID string, records []tabular.Record) error {
+	nocoRecords := make([]NocoTableRecord, len(records))
+	for i, record := range records {
+		id, err := strconv.Atoi(record.ID)
+		if err != nil {
+			return fmt.Errorf("invalid record id %s: %w", record.ID, err)
+		}
+		nocoRecords[i] = NocoTableRecord{Id: id, Fields: nocoFields(record.Fields)}
+	}
+
+	return updateNocoRecords(b.c, b.dbQueries, b.encryptionKey, b.target, tableID, nocoRecords)
+}
+
+func (b *Backend) DeleteRecords(tableID string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var records []NocoDeleteRecord
+	for _, id := range ids {
+		v, _ := strconv.Atoi(id)
+		records = append(records, NocoDeleteRecord{ID: v})
+	}
+
+	return deleteNocoRecords(b.c, b.dbQueries, b.encryptionKey, b.target, tableID, records)
+}
+
+func nocoFields(fields map[string]any) map[string]any {
+	out := make(map[string]any, len(fields))
+	for name, value := range fields {
+		switch v := value.(type) {
+		case tabular.Date:
+			out[name] = v.String()
+		case time.Time:
+			out[name] = v.UTC().Format(time.RFC3339)
+		default:
+			out[name] = v
+		}
+	}
+	return out
+}
+
+func recordIDs(ids []string) ([]int, error) {
+	out := make([]int, len(ids))
+	for i, id := range ids {
+		v, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid record id %s: %w", id, err)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
@@ -3,7 +3,6 @@ package noco
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,15 +13,7 @@ import (
 	"github.com/fluffyriot/rpsync/internal/pusher/common"
 )
 
-func linkChildrenToParent(c *common.Client, dbQueries *database.Queries, encryptionKey []byte, target database.Target, parentTableMapping database.TableMapping, columnName string, parentRecordID int, childRecordIDs []int) error {
-
-	colMapping, err := dbQueries.GetColumnMappingsByTableAndName(context.Background(), database.GetColumnMappingsByTableAndNameParams{
-		TableMappingID:   parentTableMapping.ID,
-		TargetColumnName: columnName,
-	})
-	if err != nil {
-		return err
-	}
+func linkChildrenToParent(c *common.Client, dbQueries *database.Queries, encryptionKey []byte, target database.Target, parentTableID, columnID string, parentRecordID int, childRecordIDs []int) error {
 
 	if len(childRecordIDs) > 10 {
 		return fmt.Errorf("cannot link more than 10 records per request, got %d", len(childRecordIDs))
@@ -40,8 +31,8 @@ func linkChildrenToParent(c *common.Client, dbQueries *database.Queries, encrypt
 	url := target.HostUrl.String +
 		"/api/v3/data/" +
 		target.DbID.String +
-		"/" + parentTableMapping.TargetTableCode.String +
-		"/links/" + colMapping.TargetColumnCode.String +
+		"/" + parentTableID +
+		"/links/" + columnID +
 		"/" + fmt.Sprintf("%d", parentRecordID)
 
 	body, err := json.Marshal(linkRecords)
@@ -1,44 +1,15 @@
 // SPDX-License-Identifier: AGPL-3.0-only
 package noco
 
-import "time"
-
 type NocoTableRecord struct {
-	Id     int              `json:"id,omitempty"`
-	Fields NocoRecordFields `json:"fields,omitempty"`
+	Id     int            `json:"id,omitempty"`
+	Fields map[string]any `json:"fields,omitempty"`
 }
 
 type NocoDeleteRecord struct {
 	ID int `json:"id"`
 }
 
-type NocoRecordFields struct {
-	ID                 string    `json:"ct_id"`
-	CreatedAt          time.Time `json:"created_at,omitempty"`
-	LastSynced         time.Time `json:"last_synced,omitempty"`
-	IsArchived         bool      `json:"is_archived"`
-	NetworkInternalID  string    `json:"network_internal_id,omitempty"`
-	Network            string    `json:"network,omitempty"`
-	Username           string    `json:"username,omitempty"`
-	PostType           string    `json:"post_type,omitempty"`
-	Author             string    `json:"author,omitempty"`
-	Content            string    `json:"content,omitempty"`
-	Likes              int       `json:"likes,omitempty"`
-	Views              int       `json:"views,omitempty"`
-	Reposts            int       `json:"reposts,omitempty"`
-	URL                string    `json:"URL,omitempty"`
-	Date               time.Time `json:"date,omitempty"`
-	Visitors           int       `json:"visitors,omitempty"`
-	AvgSessionDuration float64   `json:"avg_session_duration,omitempty"`
-	PagePath           string    `json:"page_path,omitempty"`
-	FollowersCount     int       `json:"followers_count,omitempty"`
-	FollowingCount     int       `json:"following_count,omitempty"`
-	PostsCount         int       `json:"posts_count,omitempty"`
-	AverageLikes       float64   `json:"average_likes,omitempty"`
-	AverageReposts     float64   `json:"average_reposts,omitempty"`
-	AverageViews       float64   `json:"average_views,omitempty"`
-}
-
 type NocoColumnTypeOptions struct {
 	Title string `json:"title"`
 	Color string `json:"color,omitempty"`
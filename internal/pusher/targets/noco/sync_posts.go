@@ -1,500 +0,0 @@
-// SPDX-License-Identifier: AGPL-3.0-only
-package noco
-
-import (
-	"context"
-	"fmt"
-	"log"
-	"strconv"
-	"time"
-
-	"github.com/fluffyriot/rpsync/internal/database"
-	"github.com/fluffyriot/rpsync/internal/helpers"
-	"github.com/fluffyriot/rpsync/internal/pusher/common"
-	"github.com/google/uuid"
-)
-
-func SyncNoco(dbQueries *database.Queries, c *common.Client, encryptionKey []byte, target database.Target) error {
-
-	sourcesTable, err := dbQueries.GetTableMappingsByTargetAndName(context.Background(), database.GetTableMappingsByTargetAndNameParams{
-		TargetID:        target.ID,
-		TargetTableName: "sources",
-	})
-	if err != nil {
-		return fmt.Errorf("failed to get target source table: %w", err)
-	}
-
-	err = syncNocoSources(c, dbQueries, encryptionKey, target, sourcesTable.TargetTableCode.String)
-	if err != nil {
-		return fmt.Errorf("failed to sync sources: %w", err)
-	}
-
-	if err := syncNocoAnalyticsSiteStats(dbQueries, c, encryptionKey, target); err != nil {
-		return fmt.Errorf("failed to sync site stats: %w", err)
-	}
-
-	if err := syncNocoAnalyticsPageStats(dbQueries, c, encryptionKey, target); err != nil {
-		return fmt.Errorf("failed to sync page stats: %w", err)
-	}
-
-	if err := syncNocoSourcesStats(dbQueries, c, encryptionKey, target); err != nil {
-		return fmt.Errorf("failed to sync sources stats: %w", err)
-	}
-
-	posts, err := dbQueries.GetAllPostsWithTheLatestInfoForUser(context.Background(), target.UserID)
-	if err != nil {
-		return err
-	}
-
-	targetTable, err := dbQueries.GetTableMappingsByTargetAndName(context.Background(), database.GetTableMappingsByTargetAndNameParams{
-		TargetID:        target.ID,
-		TargetTableName: "posts",
-	})
-	if err != nil {
-		return fmt.Errorf("failed to get target table: %w", err)
-	}
-
-	mappedPosts, err := dbQueries.GetPostsPreviouslySynced(context.Background(), target.ID)
-	if err != nil {
-		return fmt.Errorf("error fetching mapped posts: %w", err)
-	}
-
-	var createPosts []database.GetAllPostsWithTheLatestInfoForUserRow
-	var removePosts []database.PostsOnTarget
-	var updatePosts []database.GetAllPostsWithTheLatestInfoForUserRow
-
-	localMap := make(map[string]database.GetAllPostsWithTheLatestInfoForUserRow, len(posts))
-	mappedMap := make(map[string]database.PostsOnTarget, len(mappedPosts))
-
-	for _, p := range posts {
-		localMap[p.ID.String()] = p
-	}
-	for _, m := range mappedPosts {
-		if !m.PostID.Valid {
-			removePosts = append(removePosts, m)
-		} else {
-			mappedMap[m.PostID.UUID.String()] = m
-		}
-	}
-
-	for id, p := range localMap {
-		if _, ok := mappedMap[id]; !ok {
-			createPosts = append(createPosts, p)
-		} else {
-			updatePosts = append(updatePosts, p)
-		}
-	}
-	for id, m := range mappedMap {
-		if _, ok := localMap[id]; !ok {
-			removePosts = append(removePosts, m)
-		}
-	}
-
-	const batchSize = 10
-
-	var records []NocoTableRecord
-	var currentBatch []database.GetAllPostsWithTheLatestInfoForUserRow
-
-	for _, post := range createPosts {
-
-		url, err := helpers.ConvPostToURL(post.Network.String, post.Author, post.NetworkInternalID)
-		if err != nil {
-			return err
-		}
-
-		fieldMap := NocoRecordFields{
-			ID:                post.ID.String(),
-			CreatedAt:         post.CreatedAt,
-			LastSynced:        time.Now(),
-			IsArchived:        post.IsArchived,
-			NetworkInternalID: post.NetworkInternalID,
-			PostType:          post.PostType,
-			Author:            post.Author,
-			Content:           post.Content.String,
-			Likes:             int(post.Likes.Int64),
-			Views:             int(post.Views.Int64),
-			Reposts:           int(post.Reposts.Int64),
-			URL:               url,
-		}
-
-		records = append(records, NocoTableRecord{
-			Fields: fieldMap,
-		})
-		currentBatch = append(currentBatch, post)
-
-		if len(records) == batchSize {
-			if err := processCreateBatch(dbQueries, c, encryptionKey, target, targetTable.TargetTableCode.String, records, currentBatch, sourcesTable); err != nil {
-				return err
-			}
-			currentBatch = currentBatch[:0]
-			records = records[:0]
-		}
-	}
-
-	if err := processCreateBatch(dbQueries, c, encryptionKey, target, targetTable.TargetTableCode.String, records, currentBatch, sourcesTable); err != nil {
-		return err
-	}
-
-	var recordRemove []NocoDeleteRecord
-
-	for _, post := range removePosts {
-		v, _ := strconv.Atoi(post.TargetPostID)
-		intId := v
-
-		recordRemove = append(recordRemove, NocoDeleteRecord{
-			ID: intId,
-		})
-
-		if len(recordRemove) == batchSize {
-			if err := processDeleteBatch(dbQueries, c, encryptionKey, target, targetTable.TargetTableCode.String, recordRemove); err != nil {
-				return err
-			}
-			recordRemove = recordRemove[:0]
-		}
-	}
-
-	if err := processDeleteBatch(dbQueries, c, encryptionKey, target, targetTable.TargetTableCode.String, recordRemove); err != nil {
-		return err
-	}
-
-	for _, post := range removePosts {
-		err := dbQueries.DeletePostOnTarget(context.Background(), post.ID)
-		if err != nil {
-			log.Printf("Warning: Failed to delete posts_on_target mapping: %v", err)
-		}
-	}
-
-	var recordsUpdate []NocoTableRecord
-	var currentUpdateBatch []database.GetAllPostsWithTheLatestInfoForUserRow
-
-	for _, post := range updatePosts {
-		mappedPost, ok := mappedMap[post.ID.String()]
-		if !ok {
-			continue
-		}
-
-		url, err := helpers.ConvPostToURL(post.Network.String, post.Author, post.NetworkInternalID)
-		if err != nil {
-			return err
-		}
-
-		targetPostIDVal, err := strconv.Atoi(mappedPost.TargetPostID)
-		if err != nil {
-			return fmt.Errorf("invalid target post id %s: %w", mappedPost.TargetPostID, err)
-		}
-
-		safeTargetID := targetPostIDVal
-
-		fieldMap := NocoRecordFields{
-			ID:                post.ID.String(),
-			CreatedAt:         post.CreatedAt,
-			LastSynced:        time.Now(),
-			IsArchived:        post.IsArchived,
-			NetworkInternalID: post.NetworkInternalID,
-			PostType:          post.PostType,
-			Author:            post.Author,
-			Content:           post.Content.String,
-			Likes:             int(post.Likes.Int64),
-			Views:             int(post.Views.Int64),
-			Reposts:           int(post.Reposts.Int64),
-			URL:               url,
-		}
-
-		recordsUpdate = append(recordsUpdate, NocoTableRecord{
-			Id:     safeTargetID,
-			Fields: fieldMap,
-		})
-		currentUpdateBatch = append(currentUpdateBatch, post)
-
-		if len(recordsUpdate) == batchSize {
-			if err := processUpdateBatch(dbQueries, c, encryptionKey, target, targetTable.TargetTableCode.String, recordsUpdate); err != nil {
-				return err
-			}
-			currentUpdateBatch = currentUpdateBatch[:0]
-			recordsUpdate = recordsUpdate[:0]
-		}
-	}
-
-	if err := processUpdateBatch(dbQueries, c, encryptionKey, target, targetTable.TargetTableCode.String, recordsUpdate); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func processCreateBatch(
-	dbQueries *database.Queries,
-	c *common.Client,
-	encryptionKey []byte,
-	target database.Target,
-	tableCode string,
-	records []NocoTableRecord,
-	postsInBatch []database.GetAllPostsWithTheLatestInfoForUserRow,
-	sourcesTable database.TableMapping,
-) error {
-	if len(records) == 0 {
-		return nil
-	}
-	createdRecords, err := createNocoRecords(c, dbQueries, encryptionKey, target, tableCode, records)
-	if err != nil {
-		return err
-	}
-
-	type postInfo struct {
-		nocoPostId int
-		sourceId   uuid.UUID
-	}
-	postMapping := make(map[string]postInfo)
-
-	for _, rec := range createdRecords {
-		var id float64
-		if val, ok := rec["Id"].(float64); ok {
-			id = val
-		} else if val, ok := rec["id"].(float64); ok {
-			id = val
-		} else {
-			continue
-		}
-
-		fields, ok := rec["fields"].(map[string]any)
-		if !ok {
-			continue
-		}
-
-		ctId, ok := fields["ct_id"].(string)
-		if !ok {
-			continue
-		}
-
-		parsedCtId, err := uuid.Parse(ctId)
-		if err != nil {
-			return fmt.Errorf("failed to parse ct_id: %w", err)
-		}
-
-		_, err = dbQueries.AddPostToTarget(context.Background(), database.AddPostToTargetParams{
-			ID:            uuid.New(),
-			FirstSyncedAt: time.Now(),
-			PostID:        uuid.NullUUID{UUID: parsedCtId, Valid: true},
-			TargetID:      target.ID,
-			TargetPostID:  fmt.Sprintf("%.0f", id),
-		})
-
-		if err != nil {
-			return fmt.Errorf("failed to map post: %w", err)
-		}
-
-		for _, post := range postsInBatch {
-			if post.ID == parsedCtId {
-				postMapping[ctId] = postInfo{
-					nocoPostId: int(id),
-					sourceId:   post.SourceID,
-				}
-				break
-			}
-		}
-	}
-
-	postsBySource := make(map[uuid.UUID][]int)
-	for _, info := range postMapping {
-		postsBySource[info.sourceId] = append(postsBySource[info.sourceId], info.nocoPostId)
-	}
-
-	for sourceId, postIds := range postsBySource {
-		sourceMapping, err := dbQueries.GetTargetSourceBySource(context.Background(), database.GetTargetSourceBySourceParams{
-			TargetID: target.ID,
-			SourceID: sourceId,
-		})
-		if err != nil {
-			continue
-		}
-
-		sourceNocoIdInt, err := strconv.Atoi(sourceMapping.TargetSourceID)
-		if err != nil {
-			log.Printf("Invalid source ID %s: %v", sourceMapping.TargetSourceID, err)
-			continue
-		}
-		sourceNocoId := sourceNocoIdInt
-
-		err = linkChildrenToParent(
-			c,
-			dbQueries,
-			encryptionKey,
-			target,
-			sourcesTable,
-			"posts",
-			sourceNocoId,
-			postIds,
-		)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-func processDeleteBatch(
-	dbQueries *database.Queries,
-	c *common.Client,
-	encryptionKey []byte,
-	target database.Target,
-	tableCode string,
-	records []NocoDeleteRecord,
-) error {
-	if len(records) == 0 {
-		return nil
-	}
-
-	if err := deleteNocoRecords(
-		c,
-		dbQueries,
-		encryptionKey,
-		target,
-		tableCode,
-		records,
-	); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func processUpdateBatch(
-	dbQueries *database.Queries,
-	c *common.Client,
-	encryptionKey []byte,
-	target database.Target,
-	tableCode string,
-	records []NocoTableRecord,
-) error {
-	if len(records) == 0 {
-		return nil
-	}
-
-	if err := updateNocoRecords(
-		c,
-		dbQueries,
-		encryptionKey,
-		target,
-		tableCode,
-		records,
-	); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func DeletePostsAndSourceNoco(dbQueries *database.Queries, c *common.Client, encryptionKey []byte, target database.Target, source database.Source) error {
-
-	sourceMapping, err := dbQueries.GetTargetSourceBySource(context.Background(), database.GetTargetSourceBySourceParams{
-		TargetID: target.ID,
-		SourceID: source.ID,
-	})
-	if err != nil {
-		return fmt.Errorf("error fetching source mapping: %w", err)
-	}
-
-	sourceIdInt, err := strconv.Atoi(sourceMapping.TargetSourceID)
-	if err != nil {
-		return fmt.Errorf("invalid target source id: %w", err)
-	}
-	sourceId32 := sourceIdInt
-
-	sourceRecords := []NocoDeleteRecord{
-		{ID: sourceId32},
-	}
-
-	sourcesTable, err := dbQueries.GetTableMappingsByTargetAndName(context.Background(), database.GetTableMappingsByTargetAndNameParams{
-		TargetID:        target.ID,
-		TargetTableName: "sources",
-	})
-	if err != nil {
-		return err
-	}
-
-	if err := deleteNocoRecords(
-		c,
-		dbQueries,
-		encryptionKey,
-		target,
-		sourcesTable.TargetTableCode.String,
-		sourceRecords,
-	); err != nil {
-		return fmt.Errorf("failed to delete source from NocoDB: %w", err)
-	}
-
-	postsTable, err := dbQueries.GetTableMappingsByTargetAndName(context.Background(), database.GetTableMappingsByTargetAndNameParams{
-		TargetID:        target.ID,
-		TargetTableName: "posts",
-	})
-	if err != nil {
-		return err
-	}
-
-	postsToDelete, err := dbQueries.GetPostsBySourceAndTarget(context.Background(), database.GetPostsBySourceAndTargetParams{
-		TargetID: target.ID,
-		SourceID: source.ID,
-	})
-
-	const batchSize = 10
-
-	var recordRemove []NocoDeleteRecord
-
-	flushRemove := func() error {
-		if len(recordRemove) == 0 {
-			return nil
-		}
-
-		if err := deleteNocoRecords(
-			c,
-			dbQueries,
-			encryptionKey,
-			target,
-			postsTable.TargetTableCode.String,
-			recordRemove,
-		); err != nil {
-			return err
-		}
-
-		recordRemove = recordRemove[:0]
-		return nil
-	}
-
-	for _, post := range postsToDelete {
-		v, _ := strconv.Atoi(post.TargetPostID)
-		intId := v
-
-		recordRemove = append(recordRemove, NocoDeleteRecord{
-			ID: intId,
-		})
-
-		if len(recordRemove) == batchSize {
-			if err := flushRemove(); err != nil {
-				return err
-			}
-		}
-	}
-
-	if err := flushRemove(); err != nil {
-		return err
-	}
-
-	err = dbQueries.DeletePostsOnTargetAndSource(context.Background(), database.DeletePostsOnTargetAndSourceParams{
-		TargetID: target.ID,
-		SourceID: source.ID,
-	})
-	if err != nil {
-		return err
-	}
-
-	err = dbQueries.DeleteSourceTarget(context.Background(), database.DeleteSourceTargetParams{
-		TargetID: target.ID,
-		SourceID: source.ID,
-	})
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
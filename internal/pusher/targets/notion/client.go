@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/common"
+)
+
+const (
+	notionAPI     = "https://api.notion.com/v1"
+	notionVersion = "2022-06-28"
+
+	// Notion allows an average of three requests per second per integration
+	notionRequestInterval = 350 * time.Millisecond
+	notionMaxRetries      = 5
+)
+
+type notionClient struct {
+	http     *common.Client
+	token    string
+	lastCall time.Time
+}
+
+func newNotionClient(c *common.Client, dbQueries *database.Queries, encryptionKey []byte, target database.Target) (*notionClient, error) {
+	token, _, _, err := authhelp.GetTargetToken(context.Background(), dbQueries, encryptionKey, target.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Notion token: %w", err)
+	}
+
+	return &notionClient{http: c, token: token}, nil
+}
+
+func (n *notionClient) do(method, path string, payload any, out any) error {
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if wait := notionRequestInterval - time.Since(n.lastCall); wait > 0 {
+			time.Sleep(wait)
+		}
+
+		req, err := http.NewRequest(method, notionAPI+path, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+n.token)
+		req.Header.Set("Notion-Version", notionVersion)
+		req.Header.Set("Content-Type", "application/json")
+
+		n.lastCall = time.Now()
+		resp, err := n.http.HTTPClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("send request: %w", err)
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("read response body: %w", err)
+		}
+
+		if (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500) && attempt < notionMaxRetries {
+			delay := time.Duration(attempt+1) * time.Second
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				delay = time.Duration(seconds) * time.Second
+			}
+			log.Printf("Notion: %s %s returned %d, retrying in %v", method, path, resp.StatusCode, delay)
+			time.Sleep(delay)
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(respBody))
+		}
+
+		if out == nil {
+			return nil
+		}
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+		return nil
+	}
+}
+
+func (n *notionClient) createPage(databaseID string, properties map[string]any) (string, error) {
+	var page NotionPage
+	err := n.do("POST", "/pages", map[string]any{
+		"parent":     map[string]string{"database_id": databaseID},
+		"properties": properties,
+	}, &page)
+	if err != nil {
+		return "", err
+	}
+
+	return page.ID, nil
+}
+
+func (n *notionClient) updatePage(pageID string, properties map[string]any) error {
+	return n.do("PATCH", "/pages/"+pageID, map[string]any{
+		"properties": properties,
+	}, nil)
+}
+
+// archivePage moves the page to the trash, which is how the API deletes pages.
+func (n *notionClient) archivePage(pageID string) error {
+	return n.do("PATCH", "/pages/"+pageID, map[string]any{
+		"archived": true,
+	}, nil)
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package notion
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// Rich text objects are capped at 2000 characters, and a property takes at most 100 of them
+const (
+	notionTextLimit  = 2000
+	notionTextBlocks = 100
+)
+
+type NotionPage struct {
+	ID string `json:"id"`
+}
+
+type NotionRichText struct {
+	PlainText string `json:"plain_text"`
+}
+
+type NotionProperty struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type NotionDatabase struct {
+	ID     string           `json:"id"`
+	Object string           `json:"object"`
+	Title  []NotionRichText `json:"title"`
+	Parent struct {
+		Type   string `json:"type"`
+		PageID string `json:"page_id"`
+	} `json:"parent"`
+	Properties map[string]NotionProperty `json:"properties"`
+}
+
+type NotionSearchResponse struct {
+	Results    []NotionDatabase `json:"results"`
+	HasMore    bool             `json:"has_more"`
+	NextCursor string           `json:"next_cursor"`
+}
+
+// NotionTable describes a database RPSync keeps under the target's parent page.
+// The title property is always ct_id, the row's ID in RPSync.
+type NotionTable struct {
+	Name        string
+	Description string
+	Properties  map[string]any
+}
+
+func notionText(content string) []map[string]any {
+	var blocks []map[string]any
+	for content != "" && len(blocks) < notionTextBlocks {
+		chunk := content
+		if utf8.RuneCountInString(chunk) > notionTextLimit {
+			chunk = string([]rune(chunk)[:notionTextLimit])
+		}
+		content = content[len(chunk):]
+		blocks = append(blocks, map[string]any{"text": map[string]string{"content": chunk}})
+	}
+	if blocks == nil {
+		return []map[string]any{}
+	}
+	return blocks
+}
+
+func titleValue(content string) map[string]any {
+	return map[string]any{"title": notionText(content)}
+}
+
+func textValue(content string) map[string]any {
+	return map[string]any{"rich_text": notionText(content)}
+}
+
+func numberValue(n float64) map[string]any {
+	return map[string]any{"number": n}
+}
+
+func checkboxValue(b bool) map[string]any {
+	return map[string]any{"checkbox": b}
+}
+
+func dateTimeValue(t time.Time) map[string]any {
+	return map[string]any{"date": map[string]string{"start": t.UTC().Format(time.RFC3339)}}
+}
+
+func dateValue(t time.Time) map[string]any {
+	return map[string]any{"date": map[string]string{"start": t.Format("2006-01-02")}}
+}
+
+func urlValue(u string) map[string]any {
+	if u == "" {
+		return map[string]any{"url": nil}
+	}
+	return map[string]any{"url": u}
+}
+
+func selectValue(name string) map[string]any {
+	if name == "" {
+		return map[string]any{"select": nil}
+	}
+	return map[string]any{"select": map[string]string{"name": name}}
+}
+
+func relationValue(pageIDs ...string) map[string]any {
+	relations := make([]map[string]string, 0, len(pageIDs))
+	for _, id := range pageIDs {
+		if id != "" {
+			relations = append(relations, map[string]string{"id": id})
+		}
+	}
+	return map[string]any{"relation": relations}
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package notion
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets"
+	"github.com/google/uuid"
+)
+
+func siteStatProperties(id uuid.UUID, date time.Time, visitors int, avgSessionDuration float64, sourcePage string) map[string]any {
+	return map[string]any{
+		"ct_id":                titleValue(id.String()),
+		"date":                 dateValue(date),
+		"visitors":             numberValue(float64(visitors)),
+		"avg_session_duration": numberValue(avgSessionDuration),
+		"source":               relationValue(sourcePage),
+	}
+}
+
+func pageStatProperties(id uuid.UUID, date time.Time, urlPath string, views int, sourcePage string) map[string]any {
+	return map[string]any{
+		"ct_id":     titleValue(id.String()),
+		"date":      dateValue(date),
+		"page_path": textValue(urlPath),
+		"views":     numberValue(float64(views)),
+		"source":    relationValue(sourcePage),
+	}
+}
+
+func syncNotionSiteStats(client *notionClient, dbQueries *database.Queries, target database.Target, databaseID string, sourcePages map[uuid.UUID]string) error {
+
+	dateThreshold := time.Now().AddDate(0, 0, -9)
+
+	for sourceID, sourcePage := range sourcePages {
+
+		syncedStats, err := dbQueries.GetSyncedSiteStatsForUpdate(context.Background(), database.GetSyncedSiteStatsForUpdateParams{
+			TargetID: target.ID,
+			SourceID: sourceID,
+			Date:     dateThreshold,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, stat := range syncedStats {
+			properties := siteStatProperties(stat.ID, stat.Date, stat.Visitors, stat.AvgSessionDuration, sourcePage)
+			if err := client.updatePage(stat.TargetRecordID, properties); err != nil {
+				return err
+			}
+		}
+
+		unsyncedStats, err := dbQueries.GetUnsyncedSiteStatsForTarget(context.Background(), database.GetUnsyncedSiteStatsForTargetParams{
+			TargetID: target.ID,
+			SourceID: sourceID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, stat := range unsyncedStats {
+			pageID, err := client.createPage(databaseID, siteStatProperties(stat.ID, stat.Date, stat.Visitors, stat.AvgSessionDuration, sourcePage))
+			if err != nil {
+				return err
+			}
+
+			_, err = dbQueries.AddAnalyticsSiteStatToTarget(context.Background(), database.AddAnalyticsSiteStatToTargetParams{
+				ID:             uuid.New(),
+				SyncedAt:       time.Now(),
+				StatID:         uuid.NullUUID{UUID: stat.ID, Valid: true},
+				TargetID:       target.ID,
+				TargetRecordID: pageID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to map site stat: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func syncNotionPageStats(client *notionClient, dbQueries *database.Queries, target database.Target, databaseID string, sourcePages map[uuid.UUID]string) error {
+
+	dateThreshold := time.Now().AddDate(0, 0, -9)
+
+	for sourceID, sourcePage := range sourcePages {
+
+		syncedStats, err := dbQueries.GetSyncedPageStatsForUpdate(context.Background(), database.GetSyncedPageStatsForUpdateParams{
+			TargetID: target.ID,
+			SourceID: sourceID,
+			Date:     dateThreshold,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, stat := range syncedStats {
+			properties := pageStatProperties(stat.ID, stat.Date, stat.UrlPath, stat.Views, sourcePage)
+			if err := client.updatePage(stat.TargetRecordID, properties); err != nil {
+				return err
+			}
+		}
+
+		unsyncedStats, err := dbQueries.GetUnsyncedPageStatsForTarget(context.Background(), database.GetUnsyncedPageStatsForTargetParams{
+			TargetID: target.ID,
+			SourceID: sourceID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, stat := range unsyncedStats {
+			pageID, err := client.createPage(databaseID, pageStatProperties(stat.ID, stat.Date, stat.UrlPath, stat.Views, sourcePage))
+			if err != nil {
+				return err
+			}
+
+			_, err = dbQueries.AddAnalyticsPageStatToTarget(context.Background(), database.AddAnalyticsPageStatToTargetParams{
+				ID:             uuid.New(),
+				SyncedAt:       time.Now(),
+				StatID:         uuid.NullUUID{UUID: stat.ID, Valid: true},
+				TargetID:       target.ID,
+				TargetRecordID: pageID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to map page stat: %w", err)
+			}
+		}
+	}
+
+	return targets.RemoveOrphanedPageStats(dbQueries, target.ID, func(pageIDs []string) error {
+		for _, pageID := range pageIDs {
+			if err := client.archivePage(pageID); err != nil {
+				log.Printf("Notion: Failed to archive page stat %s: %v", pageID, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package notion
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/helpers"
+	"github.com/fluffyriot/rpsync/internal/pusher/common"
+	"github.com/google/uuid"
+)
+
+func SyncNotion(dbQueries *database.Queries, c *common.Client, encryptionKey []byte, target database.Target) error {
+
+	client, err := newNotionClient(c, dbQueries, encryptionKey, target)
+	if err != nil {
+		return err
+	}
+
+	tables := make(map[string]string)
+	for _, name := range []string{"sources", "posts", "sources_stats", "analytics_site_stats", "analytics_page_stats"} {
+		mapping, err := dbQueries.GetTableMappingsByTargetAndName(context.Background(), database.GetTableMappingsByTargetAndNameParams{
+			TargetID:        target.ID,
+			TargetTableName: name,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get %s database mapping: %w", name, err)
+		}
+		tables[name] = mapping.TargetTableCode.String
+	}
+
+	sourcePages, err := syncNotionSources(client, dbQueries, target, tables["sources"])
+	if err != nil {
+		return fmt.Errorf("failed to sync sources: %w", err)
+	}
+
+	if err := syncNotionSourcesStats(client, dbQueries, target, tables["sources_stats"], sourcePages); err != nil {
+		return fmt.Errorf("failed to sync sources stats: %w", err)
+	}
+
+	if err := syncNotionSiteStats(client, dbQueries, target, tables["analytics_site_stats"], sourcePages); err != nil {
+		return fmt.Errorf("failed to sync site stats: %w", err)
+	}
+
+	if err := syncNotionPageStats(client, dbQueries, target, tables["analytics_page_stats"], sourcePages); err != nil {
+		return fmt.Errorf("failed to sync page stats: %w", err)
+	}
+
+	if err := syncNotionPosts(client, dbQueries, target, tables["posts"], sourcePages); err != nil {
+		return fmt.Errorf("failed to sync posts: %w", err)
+	}
+
+	return nil
+}
+
+func postProperties(post database.GetAllPostsWithTheLatestInfoForUserRow, sourcePage string) (map[string]any, error) {
+	url, err := helpers.ConvPostToURL(post.Network.String, post.Author, post.NetworkInternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"ct_id":               titleValue(post.ID.String()),
+		"created_at":          dateTimeValue(post.CreatedAt),
+		"last_synced":         dateTimeValue(time.Now()),
+		"is_archived":         checkboxValue(post.IsArchived),
+		"network_internal_id": textValue(post.NetworkInternalID),
+		"network":             selectValue(post.Network.String),
+		"post_type":           selectValue(post.PostType),
+		"author":              textValue(post.Author),
+		"content":             textValue(post.Content.String),
+		"likes":               numberValue(float64(post.Likes.Int64)),
+		"views":               numberValue(float64(post.Views.Int64)),
+		"reposts":             numberValue(float64(post.Reposts.Int64)),
+		"URL":                 urlValue(url),
+		"source":              relationValue(sourcePage),
+	}, nil
+}
+
+func syncNotionPosts(client *notionClient, dbQueries *database.Queries, target database.Target, databaseID string, sourcePages map[uuid.UUID]string) error {
+
+	posts, err := dbQueries.GetAllPostsWithTheLatestInfoForUser(context.Background(), target.UserID)
+	if err != nil {
+		return err
+	}
+
+	mappedPosts, err := dbQueries.GetPostsPreviouslySynced(context.Background(), target.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching mapped posts: %w", err)
+	}
+
+	mappedMap := make(map[uuid.UUID]database.PostsOnTarget, len(mappedPosts))
+	var removePosts []database.PostsOnTarget
+	for _, m := range mappedPosts {
+		if !m.PostID.Valid {
+			removePosts = append(removePosts, m)
+		} else {
+			mappedMap[m.PostID.UUID] = m
+		}
+	}
+
+	// Every page is a separate request, so unchanged posts are only rewritten on the
+	// first run of the day, which also picks up posts archived since then
+	fullRefresh := !target.LastSynced.Valid ||
+		target.LastSynced.Time.Truncate(24*time.Hour) != time.Now().Truncate(24*time.Hour)
+
+	localMap := make(map[uuid.UUID]struct{}, len(posts))
+
+	for _, post := range posts {
+		localMap[post.ID] = struct{}{}
+
+		mapped, exists := mappedMap[post.ID]
+		if exists && !fullRefresh && !post.ReactionsSyncedAt.Time.After(target.LastSynced.Time) {
+			continue
+		}
+
+		properties, err := postProperties(post, sourcePages[post.SourceID])
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			if err := client.updatePage(mapped.TargetPostID, properties); err != nil {
+				return fmt.Errorf("failed to update post %s: %w", post.ID, err)
+			}
+			continue
+		}
+
+		pageID, err := client.createPage(databaseID, properties)
+		if err != nil {
+			return fmt.Errorf("failed to create post %s: %w", post.ID, err)
+		}
+
+		_, err = dbQueries.AddPostToTarget(context.Background(), database.AddPostToTargetParams{
+			ID:            uuid.New(),
+			FirstSyncedAt: time.Now(),
+			PostID:        uuid.NullUUID{UUID: post.ID, Valid: true},
+			TargetID:      target.ID,
+			TargetPostID:  pageID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to map post: %w", err)
+		}
+	}
+
+	for id, m := range mappedMap {
+		if _, ok := localMap[id]; !ok {
+			removePosts = append(removePosts, m)
+		}
+	}
+
+	for _, post := range removePosts {
+		if err := client.archivePage(post.TargetPostID); err != nil {
+			log.Printf("Notion: Failed to archive post page %s: %v", post.TargetPostID, err)
+		}
+
+		if err := dbQueries.DeletePostOnTarget(context.Background(), post.ID); err != nil {
+			log.Printf("Warning: Failed to delete posts_on_target mapping: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func DeletePostsAndSourceNotion(dbQueries *database.Queries, c *common.Client, encryptionKey []byte, target database.Target, source database.Source) error {
+
+	sourceMapping, err := dbQueries.GetTargetSourceBySource(context.Background(), database.GetTargetSourceBySourceParams{
+		TargetID: target.ID,
+		SourceID: source.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching source mapping: %w", err)
+	}
+
+	client, err := newNotionClient(c, dbQueries, encryptionKey, target)
+	if err != nil {
+		return err
+	}
+
+	if err := client.archivePage(sourceMapping.TargetSourceID); err != nil {
+		return fmt.Errorf("failed to delete source from Notion: %w", err)
+	}
+
+	postsToDelete, err := dbQueries.GetPostsBySourceAndTarget(context.Background(), database.GetPostsBySourceAndTargetParams{
+		TargetID: target.ID,
+		SourceID: source.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, post := range postsToDelete {
+		if err := client.archivePage(post.TargetPostID); err != nil {
+			return fmt.Errorf("failed to delete post from Notion: %w", err)
+		}
+	}
+
+	err = dbQueries.DeletePostsOnTargetAndSource(context.Background(), database.DeletePostsOnTargetAndSourceParams{
+		TargetID: target.ID,
+		SourceID: source.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return dbQueries.DeleteSourceTarget(context.Background(), database.DeleteSourceTargetParams{
+		TargetID: target.ID,
+		SourceID: source.ID,
+	})
+}
@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package notion
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/helpers"
+	"github.com/google/uuid"
+)
+
+func sourceProperties(source database.Source) map[string]any {
+	url, _ := helpers.ConvNetworkToURL(source.Network, source.UserName)
+
+	properties := map[string]any{
+		"ct_id":    titleValue(source.ID.String()),
+		"network":  selectValue(source.Network),
+		"username": textValue(source.UserName),
+		"URL":      urlValue(url),
+	}
+	if source.LastSynced.Valid {
+		properties["last_synced"] = dateTimeValue(source.LastSynced.Time)
+	}
+
+	return properties
+}
+
+// syncNotionSources creates, refreshes and removes source pages, returning the page
+// ID of every synced source so child rows can link to it.
+func syncNotionSources(client *notionClient, dbQueries *database.Queries, target database.Target, databaseID string) (map[uuid.UUID]string, error) {
+	userSources, err := dbQueries.GetUserSources(context.Background(), target.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user sources: %w", err)
+	}
+
+	mappedSources, err := dbQueries.GetTargetSources(context.Background(), target.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching target sources: %w", err)
+	}
+
+	pages := make(map[uuid.UUID]string, len(mappedSources))
+	for _, m := range mappedSources {
+		pages[m.SourceID] = m.TargetSourceID
+	}
+
+	localSources := make(map[uuid.UUID]struct{}, len(userSources))
+
+	for _, source := range userSources {
+		localSources[source.ID] = struct{}{}
+
+		if pageID, ok := pages[source.ID]; ok {
+			if err := client.updatePage(pageID, sourceProperties(source)); err != nil {
+				return nil, fmt.Errorf("failed to update source %s: %w", source.ID, err)
+			}
+			continue
+		}
+
+		pageID, err := client.createPage(databaseID, sourceProperties(source))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create source %s: %w", source.ID, err)
+		}
+
+		_, err = dbQueries.AddSourceToTarget(context.Background(), database.AddSourceToTargetParams{
+			ID:             uuid.New(),
+			SourceID:       source.ID,
+			TargetID:       target.ID,
+			TargetSourceID: pageID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		pages[source.ID] = pageID
+	}
+
+	for _, m := range mappedSources {
+		if _, ok := localSources[m.SourceID]; ok {
+			continue
+		}
+
+		if err := client.archivePage(m.TargetSourceID); err != nil {
+			log.Printf("Notion: Failed to archive source page %s: %v", m.TargetSourceID, err)
+		}
+
+		err := dbQueries.DeleteSourceTarget(context.Background(), database.DeleteSourceTargetParams{
+			TargetID: target.ID,
+			SourceID: m.SourceID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete source target mapping: %w", err)
+		}
+		delete(pages, m.SourceID)
+	}
+
+	return pages, nil
+}
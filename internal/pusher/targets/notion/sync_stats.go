@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package notion
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+func sourcesStatProperties(id uuid.UUID, date time.Time, followers, following, posts sql.NullInt64, likes, reposts, views sql.NullFloat64, sourcePage string) map[string]any {
+	return map[string]any{
+		"ct_id":           titleValue(id.String()),
+		"date":            dateValue(date),
+		"followers_count": numberValue(float64(followers.Int64)),
+		"following_count": numberValue(float64(following.Int64)),
+		"posts_count":     numberValue(float64(posts.Int64)),
+		"average_likes":   numberValue(likes.Float64),
+		"average_reposts": numberValue(reposts.Float64),
+		"average_views":   numberValue(views.Float64),
+		"source":          relationValue(sourcePage),
+	}
+}
+
+func syncNotionSourcesStats(client *notionClient, dbQueries *database.Queries, target database.Target, databaseID string, sourcePages map[uuid.UUID]string) error {
+
+	dateThreshold := time.Now().AddDate(0, 0, -2)
+
+	for sourceID, sourcePage := range sourcePages {
+
+		syncedStats, err := dbQueries.GetSyncedSourcesStatsForUpdate(context.Background(), database.GetSyncedSourcesStatsForUpdateParams{
+			TargetID: target.ID,
+			SourceID: sourceID,
+			Date:     dateThreshold,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, stat := range syncedStats {
+			properties := sourcesStatProperties(stat.ID, stat.Date, stat.FollowersCount, stat.FollowingCount, stat.PostsCount, stat.AverageLikes, stat.AverageReposts, stat.AverageViews, sourcePage)
+			if err := client.updatePage(stat.TargetRecordID, properties); err != nil {
+				return err
+			}
+		}
+
+		unsyncedStats, err := dbQueries.GetUnsyncedSourcesStatsForTarget(context.Background(), database.GetUnsyncedSourcesStatsForTargetParams{
+			SourceID: sourceID,
+			TargetID: target.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, stat := range unsyncedStats {
+			properties := sourcesStatProperties(stat.ID, stat.Date, stat.FollowersCount, stat.FollowingCount, stat.PostsCount, stat.AverageLikes, stat.AverageReposts, stat.AverageViews, sourcePage)
+			pageID, err := client.createPage(databaseID, properties)
+			if err != nil {
+				return err
+			}
+
+			_, err = dbQueries.AddSourcesStatToTarget(context.Background(), database.AddSourcesStatToTargetParams{
+				ID:             uuid.New(),
+				SyncedAt:       time.Now(),
+				StatID:         stat.ID,
+				TargetID:       target.ID,
+				TargetRecordID: pageID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to map sources stat: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package notion
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/common"
+	"github.com/google/uuid"
+)
+
+var notionIDPattern = regexp.MustCompile(`[0-9a-fA-F]{32}`)
+
+// notionPageID accepts a page ID with or without dashes, or the page's share link.
+func notionPageID(raw string) string {
+	compact := strings.ReplaceAll(strings.TrimSpace(raw), "-", "")
+	matches := notionIDPattern.FindAllString(compact, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return strings.ToLower(matches[len(matches)-1])
+}
+
+func notionTables(sourcesDatabaseID string) []NotionTable {
+	sourceRelation := map[string]any{
+		"relation": map[string]any{
+			"database_id":     sourcesDatabaseID,
+			"single_property": map[string]any{},
+		},
+	}
+
+	return []NotionTable{
+		{
+			Name:        "posts",
+			Description: "Posts from your social networks",
+			Properties: map[string]any{
+				"created_at":          map[string]any{"date": map[string]any{}},
+				"last_synced":         map[string]any{"date": map[string]any{}},
+				"is_archived":         map[string]any{"checkbox": map[string]any{}},
+				"network_internal_id": map[string]any{"rich_text": map[string]any{}},
+				"network":             map[string]any{"select": map[string]any{}},
+				"post_type":           map[string]any{"select": map[string]any{}},
+				"author":              map[string]any{"rich_text": map[string]any{}},
+				"content":             map[string]any{"rich_text": map[string]any{}},
+				"likes":               map[string]any{"number": map[string]any{}},
+				"views":               map[string]any{"number": map[string]any{}},
+				"reposts":             map[string]any{"number": map[string]any{}},
+				"URL":                 map[string]any{"url": map[string]any{}},
+				"source":              sourceRelation,
+			},
+		},
+		{
+			Name:        "sources_stats",
+			Description: "Daily profile statistics (followers, following, averages)",
+			Properties: map[string]any{
+				"date":            map[string]any{"date": map[string]any{}},
+				"followers_count": map[string]any{"number": map[string]any{}},
+				"following_count": map[string]any{"number": map[string]any{}},
+				"posts_count":     map[string]any{"number": map[string]any{}},
+				"average_likes":   map[string]any{"number": map[string]any{}},
+				"average_reposts": map[string]any{"number": map[string]any{}},
+				"average_views":   map[string]any{"number": map[string]any{}},
+				"source":          sourceRelation,
+			},
+		},
+		{
+			Name:        "analytics_site_stats",
+			Description: "Daily website analytics (visitors, session duration)",
+			Properties: map[string]any{
+				"date":                 map[string]any{"date": map[string]any{}},
+				"visitors":             map[string]any{"number": map[string]any{}},
+				"avg_session_duration": map[string]any{"number": map[string]any{}},
+				"source":               sourceRelation,
+			},
+		},
+		{
+			Name:        "analytics_page_stats",
+			Description: "Daily page view analytics",
+			Properties: map[string]any{
+				"date":      map[string]any{"date": map[string]any{}},
+				"page_path": map[string]any{"rich_text": map[string]any{}},
+				"views":     map[string]any{"number": map[string]any{}},
+				"source":    sourceRelation,
+			},
+		},
+	}
+}
+
+func InitializeNotion(dbQueries *database.Queries, c *common.Client, encryptionKey []byte, target database.Target) error {
+	parentID := notionPageID(target.DbID.String)
+	if parentID == "" {
+		return fmt.Errorf("invalid Notion page ID: %s", target.DbID.String)
+	}
+
+	client, err := newNotionClient(c, dbQueries, encryptionKey, target)
+	if err != nil {
+		return err
+	}
+
+	sourcesMapping, err := ensureNotionTable(client, dbQueries, target, parentID, NotionTable{
+		Name:        "sources",
+		Description: "Social media sources",
+		Properties: map[string]any{
+			"network":     map[string]any{"select": map[string]any{}},
+			"username":    map[string]any{"rich_text": map[string]any{}},
+			"URL":         map[string]any{"url": map[string]any{}},
+			"last_synced": map[string]any{"date": map[string]any{}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("create sources database: %w", err)
+	}
+
+	for _, table := range notionTables(sourcesMapping.TargetTableCode.String) {
+		if _, err := ensureNotionTable(client, dbQueries, target, parentID, table); err != nil {
+			return fmt.Errorf("create %s database: %w", table.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ensureNotionTable returns the mapping for a table, adopting a database with the same
+// name under the parent page before creating a new one.
+func ensureNotionTable(client *notionClient, dbQueries *database.Queries, target database.Target, parentID string, table NotionTable) (database.TableMapping, error) {
+	mapping, err := dbQueries.GetTableMappingsByTargetAndName(context.Background(), database.GetTableMappingsByTargetAndNameParams{
+		TargetID:        target.ID,
+		TargetTableName: table.Name,
+	})
+	if err == nil {
+		return mapping, nil
+	}
+
+	db, err := findNotionDatabase(client, parentID, table.Name)
+	if err != nil {
+		return database.TableMapping{}, err
+	}
+
+	if db != nil {
+		log.Printf("Notion: Mapping existing %s database %s", table.Name, db.ID)
+		db, err = adoptNotionDatabase(client, db, table)
+	} else {
+		db, err = createNotionDatabase(client, parentID, table)
+	}
+	if err != nil {
+		return database.TableMapping{}, err
+	}
+
+	mapping, err = dbQueries.CreateMappingForTable(context.Background(), database.CreateMappingForTableParams{
+		ID:              uuid.New(),
+		CreatedAt:       time.Now(),
+		SourceTableName: table.Name,
+		TargetTableName: table.Name,
+		TargetTableCode: sql.NullString{String: db.ID, Valid: true},
+		TargetID:        target.ID,
+	})
+	if err != nil {
+		return database.TableMapping{}, fmt.Errorf("create %s table mapping: %w", table.Name, err)
+	}
+
+	for name, property := range db.Properties {
+		_, err := dbQueries.CreateMappingForColumn(context.Background(), database.CreateMappingForColumnParams{
+			ID:               uuid.New(),
+			CreatedAt:        time.Now(),
+			TableMappingID:   mapping.ID,
+			SourceColumnName: name,
+			TargetColumnName: name,
+			TargetColumnCode: sql.NullString{String: property.ID, Valid: true},
+		})
+		if err != nil {
+			return database.TableMapping{}, fmt.Errorf("create %s column mapping %s: %w", table.Name, name, err)
+		}
+	}
+
+	return mapping, nil
+}
+
+func findNotionDatabase(client *notionClient, parentID, name string) (*NotionDatabase, error) {
+	payload := map[string]any{
+		"query":     name,
+		"filter":    map[string]string{"property": "object", "value": "database"},
+		"page_size": 100,
+	}
+
+	for {
+		var result NotionSearchResponse
+		if err := client.do("POST", "/search", payload, &result); err != nil {
+			return nil, err
+		}
+
+		for _, db := range result.Results {
+			if notionPageID(db.Parent.PageID) != parentID || len(db.Title) == 0 {
+				continue
+			}
+			if db.Title[0].PlainText == name {
+				return &db, nil
+			}
+		}
+
+		if !result.HasMore || result.NextCursor == "" {
+			return nil, nil
+		}
+		payload["start_cursor"] = result.NextCursor
+	}
+}
+
+func createNotionDatabase(client *notionClient, parentID string, table NotionTable) (*NotionDatabase, error) {
+	properties := map[string]any{"ct_id": map[string]any{"title": map[string]any{}}}
+	for name, property := range table.Properties {
+		properties[name] = property
+	}
+
+	var db NotionDatabase
+	err := client.do("POST", "/databases", map[string]any{
+		"parent":      map[string]string{"type": "page_id", "page_id": parentID},
+		"title":       notionText(table.Name),
+		"description": notionText(table.Description),
+		"properties":  properties,
+		"is_inline":   false,
+	}, &db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &db, nil
+}
+
+// adoptNotionDatabase renames the title property to ct_id and adds any missing properties.
+func adoptNotionDatabase(client *notionClient, db *NotionDatabase, table NotionTable) (*NotionDatabase, error) {
+	properties := make(map[string]any)
+	for name, property := range db.Properties {
+		if property.Type == "title" && name != "ct_id" {
+			properties[name] = map[string]any{"name": "ct_id"}
+		}
+	}
+	for name, property := range table.Properties {
+		if _, ok := db.Properties[name]; !ok {
+			properties[name] = property
+		}
+	}
+
+	if len(properties) == 0 {
+		return db, nil
+	}
+
+	var updated NotionDatabase
+	if err := client.do("PATCH", "/databases/"+db.ID, map[string]any{"properties": properties}, &updated); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package objectstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// presignExpiry is how long a download link handed to the browser stays valid
+const presignExpiry = 15 * time.Minute
+
+// Store is the S3-compatible bucket a target's exports are uploaded to
+type Store struct {
+	client *minio.Client
+	loc    Location
+	prefix string
+}
+
+// Location is where an uploaded object lives. Exports keep it next to their
+// object key, since the target's settings may point elsewhere later.
+type Location struct {
+	Endpoint string
+	Region   string
+	Bucket   string
+}
+
+// New connects to the bucket described by settings
+func New(settings targets.Settings, accessKey, secretKey string) (*Store, error) {
+	loc := Location{Endpoint: settings.S3Endpoint, Region: settings.S3Region, Bucket: settings.S3Bucket}
+	return connect(loc, settings.S3Prefix, accessKey, secretKey)
+}
+
+// connect opens the bucket at loc. The endpoint may carry an http:// scheme to
+// turn TLS off; anything else uses https.
+func connect(loc Location, prefix, accessKey, secretKey string) (*Store, error) {
+	endpoint := loc.Endpoint
+	secure := true
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("parsing S3 endpoint: %w", err)
+		}
+		endpoint = u.Host
+		secure = u.Scheme == "https"
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: secure,
+		Region: loc.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connecting to S3: %w", err)
+	}
+
+	return &Store{
+		client: client,
+		loc:    loc,
+		prefix: prefix,
+	}, nil
+}
+
+// Open returns the store of the target, or nil when it keeps exports locally
+func Open(ctx context.Context, dbQueries *database.Queries, encryptionKey []byte, target database.Target) (*Store, error) {
+	settings, err := targets.ParseSettings(target)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.S3Enabled() {
+		return nil, nil
+	}
+
+	secretKey, accessKey, _, err := authhelp.GetTargetToken(ctx, dbQueries, encryptionKey, target.ID)
+	if err != nil {
+		return nil, fmt.Errorf("reading S3 credentials: %w", err)
+	}
+
+	return New(settings, accessKey, secretKey)
+}
+
+// OpenExport returns the bucket an export was uploaded to, whatever the
+// target's settings say now. It uses the target's stored credentials.
+func OpenExport(ctx context.Context, dbQueries *database.Queries, encryptionKey []byte, export database.Export) (*Store, error) {
+	if !export.StorageBucket.Valid || !export.TargetID.Valid {
+		return nil, fmt.Errorf("export %s has no stored bucket", export.ID)
+	}
+
+	secretKey, accessKey, _, err := authhelp.GetTargetToken(ctx, dbQueries, encryptionKey, export.TargetID.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("reading S3 credentials: %w", err)
+	}
+
+	return connect(ExportLocation(export), "", accessKey, secretKey)
+}
+
+// ExportLocation is where the export was uploaded to
+func ExportLocation(export database.Export) Location {
+	return Location{
+		Endpoint: export.StorageEndpoint.String,
+		Region:   export.StorageRegion.String,
+		Bucket:   export.StorageBucket.String,
+	}
+}
+
+// Location is where the store uploads to
+func (s *Store) Location() Location {
+	return s.loc
+}
+
+// Check makes sure the bucket exists and the credentials can reach it
+func (s *Store) Check(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.loc.Bucket)
+	if err != nil {
+		return fmt.Errorf("checking S3 bucket: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("S3 bucket %s does not exist", s.loc.Bucket)
+	}
+	return nil
+}
+
+// Upload copies a local file into the bucket under the prefix and returns its
+// object key
+func (s *Store) Upload(ctx context.Context, path string) (string, error) {
+	key := s.prefix + filepath.Base(path)
+	return key, s.put(ctx, key, path)
+}
+
+func (s *Store) put(ctx context.Context, key, path string) error {
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	_, err := s.client.FPutObject(ctx, s.loc.Bucket, key, path, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("uploading %s: %w", key, err)
+	}
+	return nil
+}
+
+// Mirror uploads each of names found in dir to keyDir under the prefix, and
+// removes from the bucket the ones that are not there anymore
+func (s *Store) Mirror(ctx context.Context, dir, keyDir string, names []string) error {
+	for _, name := range names {
+		key := s.prefix + keyDir + "/" + name
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err != nil {
+			if !os.IsNotExist(err) {
+				return err
+			}
+			if err := s.Remove(ctx, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.put(ctx, key, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove deletes an object. Removing one that does not exist is not an error.
+func (s *Store) Remove(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.loc.Bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("removing %s: %w", key, err)
+	}
+	return nil
+}
+
+// PresignedUrl is a short-lived link that downloads the object as filename
+func (s *Store) PresignedUrl(ctx context.Context, key, filename string) (*url.URL, error) {
+	params := url.Values{}
+	params.Set("response-content-disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	u, err := s.client.PresignedGetObject(ctx, s.loc.Bucket, key, presignExpiry, params)
+	if err != nil {
+		return nil, fmt.Errorf("signing download of %s: %w", key, err)
+	}
+	return u, nil
+}
+
+// StoredSecret returns the secret key kept for the target when it was saved
+// with accessKey, so settings can be changed without typing it again
+func StoredSecret(ctx context.Context, dbQueries *database.Queries, encryptionKey []byte, targetID uuid.UUID, accessKey string) (string, error) {
+	secretKey, storedAccessKey, _, err := authhelp.GetTargetToken(ctx, dbQueries, encryptionKey, targetID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && storedAccessKey != accessKey) {
+		return "", fmt.Errorf("an S3 secret key is required")
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading S3 credentials: %w", err)
+	}
+	return secretKey, nil
+}
+
+// SaveCredentials replaces the target's access key and secret
+func SaveCredentials(ctx context.Context, dbQueries *database.Queries, encryptionKey []byte, targetID uuid.UUID, accessKey, secretKey string) error {
+	if err := RemoveCredentials(ctx, dbQueries, targetID); err != nil {
+		return err
+	}
+	return authhelp.InsertTargetToken(ctx, dbQueries, targetID, secretKey, accessKey, encryptionKey)
+}
+
+// RemoveCredentials forgets the target's access key and secret, if any
+func RemoveCredentials(ctx context.Context, dbQueries *database.Queries, targetID uuid.UUID) error {
+	token, err := dbQueries.GetTokenByTarget(ctx, uuid.NullUUID{UUID: targetID, Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading S3 credentials: %w", err)
+	}
+	return dbQueries.DeleteTokenById(ctx, token.ID)
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package targets
+
+import (
+	"context"
+	"log"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+// RemoveOrphanedPageStats deletes the target's page stat records whose stat is
+// gone, then their mappings. Page stats are replaced when analytics are
+// re-fetched, leaving mappings without a stat. remove gets the target record IDs,
+// possibly none, and mappings are only dropped once it succeeds.
+func RemoveOrphanedPageStats(dbQueries *database.Queries, targetID uuid.UUID, remove func(recordIDs []string) error) error {
+	mappings, err := dbQueries.GetPageStatsOnTarget(context.Background(), targetID)
+	if err != nil {
+		return err
+	}
+
+	var orphaned []database.AnalyticsPageStatsOnTarget
+	var recordIDs []string
+	for _, m := range mappings {
+		if !m.StatID.Valid {
+			orphaned = append(orphaned, m)
+			recordIDs = append(recordIDs, m.TargetRecordID)
+		}
+	}
+
+	if err := remove(recordIDs); err != nil {
+		return err
+	}
+
+	for _, m := range orphaned {
+		if err := dbQueries.DeleteAnalyticsPageStatOnTarget(context.Background(), m.ID); err != nil {
+			log.Printf("Warning: failed to delete mapping %s: %v", m.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,318 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package targets
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+type ColumnType int
+
+const (
+	ColText ColumnType = iota
+	ColInt
+	ColFloat
+	ColBool
+	ColTime
+	ColDate
+)
+
+type ExportColumn struct {
+	Key  string
+	Type ColumnType
+}
+
+func (c ExportColumn) IsDate() bool {
+	return c.Type == ColTime || c.Type == ColDate
+}
+
+// DateFormats are the layouts a profile can pick for date and time columns.
+// Keys are stored in column_mappings.target_column_code.
+var DateFormats = []struct {
+	Key   string
+	Label string
+}{
+	{"iso", "ISO 8601 (2024-01-31T18:30:00Z)"},
+	{"datetime", "2024-01-31 18:30:00"},
+	{"date", "2024-01-31"},
+	{"dmy", "31/01/2024"},
+	{"mdy", "01/31/2024"},
+	{"unix", "Unix seconds"},
+}
+
+func validDateFormat(key string) bool {
+	for _, f := range DateFormats {
+		if f.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// formatDate renders t the way format asks. "iso" keeps what exports always
+// wrote: RFC 3339 for times and the bare date for dates.
+func formatDate(t time.Time, typ ColumnType, format string) string {
+	switch format {
+	case "datetime":
+		return t.Format("2006-01-02 15:04:05")
+	case "date":
+		return t.Format("2006-01-02")
+	case "dmy":
+		return t.Format("02/01/2006")
+	case "mdy":
+		return t.Format("01/02/2006")
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	if typ == ColDate {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339)
+}
+
+type ProfileColumn struct {
+	ExportColumn
+	Header     string
+	DateFormat string
+	// index is the position of the column in the rows of the output
+	index int
+}
+
+// Profile is the column set an output is written with. Columns keep the order
+// the output defines them in.
+type Profile struct {
+	Columns []ProfileColumn
+	// Custom is false when the target has no profile stored for the output
+	Custom bool
+}
+
+func (p Profile) Headers() []string {
+	headers := make([]string, len(p.Columns))
+	for i, c := range p.Columns {
+		headers[i] = c.Header
+	}
+	return headers
+}
+
+// Includes reports whether the profile writes the column with the given key
+func (p Profile) Includes(key string) bool {
+	_, ok := p.Column(key)
+	return ok
+}
+
+func (p Profile) Column(key string) (ProfileColumn, bool) {
+	for _, c := range p.Columns {
+		if c.Key == key {
+			return c, true
+		}
+	}
+	return ProfileColumn{}, false
+}
+
+// project picks the profile's columns out of a full output row
+func (p Profile) project(row []any) []any {
+	out := make([]any, len(p.Columns))
+	for i, c := range p.Columns {
+		out[i] = row[c.index]
+	}
+	return out
+}
+
+func defaultProfile(output CsvOutput) Profile {
+	columns := make([]ProfileColumn, len(output.Columns))
+	for i, c := range output.Columns {
+		columns[i] = ProfileColumn{ExportColumn: c, Header: c.Key, DateFormat: "iso", index: i}
+	}
+	return Profile{Columns: columns}
+}
+
+// LoadProfile reads the target's profile for output from table_mappings and
+// column_mappings. Without one every column is written under its own name.
+func LoadProfile(ctx context.Context, dbQueries *database.Queries, target database.Target, output CsvOutput) (Profile, error) {
+	table, err := dbQueries.GetTableMappingsByTargetAndName(ctx, database.GetTableMappingsByTargetAndNameParams{
+		TargetID:        target.ID,
+		TargetTableName: output.Key,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return defaultProfile(output), nil
+	}
+	if err != nil {
+		return Profile{}, fmt.Errorf("fetching %s profile: %w", output.Key, err)
+	}
+
+	mappings, err := dbQueries.GetColumnMappingsByTable(ctx, table.ID)
+	if err != nil {
+		return Profile{}, fmt.Errorf("fetching %s profile columns: %w", output.Key, err)
+	}
+
+	byKey := make(map[string]database.ColumnMapping, len(mappings))
+	for _, m := range mappings {
+		byKey[m.SourceColumnName] = m
+	}
+
+	profile := Profile{Custom: true}
+	for i, c := range output.Columns {
+		m, ok := byKey[c.Key]
+		if !ok {
+			continue
+		}
+		format := "iso"
+		if m.TargetColumnCode.Valid && validDateFormat(m.TargetColumnCode.String) {
+			format = m.TargetColumnCode.String
+		}
+		profile.Columns = append(profile.Columns, ProfileColumn{ExportColumn: c, Header: m.TargetColumnName, DateFormat: format, index: i})
+	}
+
+	// A profile whose columns were all dropped in a later release falls back to
+	// the full set instead of writing empty files
+	if len(profile.Columns) == 0 {
+		return defaultProfile(output), nil
+	}
+
+	return profile, nil
+}
+
+// ProfileColumnInput is one column of a profile as submitted by the user
+type ProfileColumnInput struct {
+	Key        string
+	Header     string
+	DateFormat string
+}
+
+// ProfileChange is the new profile of one output: either the given columns
+// under their headers, or every column again when Reset is set
+type ProfileChange struct {
+	Output  CsvOutput
+	Reset   bool
+	Columns []ProfileColumnInput
+}
+
+// SaveProfiles checks every change first and then applies them all in one
+// transaction, so a rejected output leaves each profile as it was.
+func SaveProfiles(ctx context.Context, db *sql.DB, dbQueries *database.Queries, target database.Target, changes []ProfileChange) error {
+	for _, change := range changes {
+		if change.Reset {
+			continue
+		}
+		if err := checkProfile(change.Output, change.Columns); err != nil {
+			return err
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := dbQueries.WithTx(tx)
+	for _, change := range changes {
+		if err := resetProfile(ctx, q, target, change.Output); err != nil {
+			return err
+		}
+		if change.Reset {
+			continue
+		}
+		if err := writeProfile(ctx, q, target, change.Output, change.Columns); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// checkProfile validates columns for output and fills in missing headers
+func checkProfile(output CsvOutput, columns []ProfileColumnInput) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("%s: at least one column must be selected", output.Key)
+	}
+
+	known := make(map[string]bool, len(output.Columns))
+	for _, c := range output.Columns {
+		known[c.Key] = true
+	}
+
+	seen := make(map[string]bool)
+	for i, c := range columns {
+		if !known[c.Key] {
+			return fmt.Errorf("%s: unknown column %q", output.Key, c.Key)
+		}
+		header := strings.TrimSpace(c.Header)
+		if header == "" {
+			header = c.Key
+		}
+		if seen[header] {
+			return fmt.Errorf("%s: header %q is used twice", output.Key, header)
+		}
+		seen[header] = true
+		columns[i].Header = header
+
+		if c.DateFormat != "" && !validDateFormat(c.DateFormat) {
+			return fmt.Errorf("%s: unknown date format %q", output.Key, c.DateFormat)
+		}
+	}
+
+	return nil
+}
+
+// writeProfile stores checked columns as the profile of output. Only they are
+// written, under their headers.
+func writeProfile(ctx context.Context, dbQueries *database.Queries, target database.Target, output CsvOutput, columns []ProfileColumnInput) error {
+	known := make(map[string]ExportColumn, len(output.Columns))
+	for _, c := range output.Columns {
+		known[c.Key] = c
+	}
+
+	table, err := dbQueries.CreateMappingForTable(ctx, database.CreateMappingForTableParams{
+		ID:              uuid.New(),
+		CreatedAt:       time.Now(),
+		SourceTableName: output.Key,
+		TargetTableName: output.Key,
+		TargetID:        target.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("saving %s profile: %w", output.Key, err)
+	}
+
+	for _, c := range columns {
+		var format sql.NullString
+		if known[c.Key].IsDate() && c.DateFormat != "" {
+			format = sql.NullString{String: c.DateFormat, Valid: true}
+		}
+
+		_, err := dbQueries.CreateMappingForColumn(ctx, database.CreateMappingForColumnParams{
+			ID:               uuid.New(),
+			CreatedAt:        time.Now(),
+			TableMappingID:   table.ID,
+			SourceColumnName: c.Key,
+			TargetColumnName: c.Header,
+			TargetColumnCode: format,
+		})
+		if err != nil {
+			return fmt.Errorf("saving %s profile column %s: %w", output.Key, c.Key, err)
+		}
+	}
+
+	return nil
+}
+
+// resetProfile drops the target's profile for output so every column is
+// written again. Column mappings go with the table mapping.
+func resetProfile(ctx context.Context, dbQueries *database.Queries, target database.Target, output CsvOutput) error {
+	err := dbQueries.DeleteTableMappingByTargetAndName(ctx, database.DeleteTableMappingByTargetAndNameParams{
+		TargetID:        target.ID,
+		TargetTableName: output.Key,
+	})
+	if err != nil {
+		return fmt.Errorf("removing %s profile: %w", output.Key, err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package targets
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDate(t *testing.T) {
+	at := time.Date(2024, 1, 31, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		typ    ColumnType
+		format string
+		want   string
+	}{
+		{ColTime, "", "2024-01-31T18:30:00Z"},
+		{ColTime, "iso", "2024-01-31T18:30:00Z"},
+		{ColDate, "iso", "2024-01-31"},
+		{ColTime, "datetime", "2024-01-31 18:30:00"},
+		{ColTime, "date", "2024-01-31"},
+		{ColDate, "dmy", "31/01/2024"},
+		{ColDate, "mdy", "01/31/2024"},
+		{ColTime, "unix", "1706725800"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDate(at, tt.typ, tt.format); got != tt.want {
+			t.Errorf("formatDate(%v, %q) = %q, want %q", tt.typ, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestCheckProfile(t *testing.T) {
+	output := CsvOutputs[0]
+
+	columns := []ProfileColumnInput{
+		{Key: "ct_id"},
+		{Key: "posted_at", Header: " Posted ", DateFormat: "dmy"},
+		{Key: "likes", Header: "Likes"},
+	}
+	if err := checkProfile(output, columns); err != nil {
+		t.Fatalf("checking profile: %v", err)
+	}
+	if columns[0].Header != "ct_id" || columns[1].Header != "Posted" {
+		t.Errorf("headers are %q and %q, want the key and the trimmed header", columns[0].Header, columns[1].Header)
+	}
+
+	invalid := []struct {
+		name    string
+		columns []ProfileColumnInput
+	}{
+		{"no columns", nil},
+		{"unknown column", []ProfileColumnInput{{Key: "nope"}}},
+		{"header used twice", []ProfileColumnInput{{Key: "likes", Header: "Count"}, {Key: "views", Header: "Count"}}},
+		{"header matching a defaulted key", []ProfileColumnInput{{Key: "likes"}, {Key: "views", Header: "likes"}}},
+		{"unknown date format", []ProfileColumnInput{{Key: "posted_at", DateFormat: "rfc822"}}},
+	}
+	for _, tt := range invalid {
+		if err := checkProfile(output, tt.columns); err == nil {
+			t.Errorf("%s: accepted", tt.name)
+		}
+	}
+}
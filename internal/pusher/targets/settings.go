@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package targets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+)
+
+const (
+	// CsvModeBundle writes a new timestamped zip with the full dataset every run
+	CsvModeBundle = "bundle"
+	// CsvModeStable replaces one CSV per output at a fixed path every run
+	CsvModeStable = "stable"
+	// CsvModeDelta writes a timestamped zip with only the rows changed since the last export
+	CsvModeDelta = "delta"
+)
+
+var CsvModes = []struct {
+	Key   string
+	Label string
+}{
+	{CsvModeBundle, "New zip with everything on each run"},
+	{CsvModeStable, "Stable files replaced on each run"},
+	{CsvModeDelta, "New zip with changes since the last run"},
+}
+
+const (
+	WebhookPostCreated        = "post.created"
+	WebhookFollowersMilestone = "source.followers_milestone"
+	WebhookSourceSyncFailed   = "source.sync_failed"
+	WebhookTargetSyncFailed   = "target.sync_failed"
+	// WebhookPing is only sent on request and cannot be turned off
+	WebhookPing = "ping"
+)
+
+var WebhookEvents = []struct {
+	Key   string
+	Label string
+}{
+	{WebhookPostCreated, "New posts"},
+	{WebhookFollowersMilestone, "Follower milestones"},
+	{WebhookSourceSyncFailed, "Source sync failures"},
+	{WebhookTargetSyncFailed, "Target sync failures"},
+}
+
+// Settings holds the per target options stored in targets.settings.
+// Outputs are listed as disabled so ones added later are on by default.
+type Settings struct {
+	CsvDisabled []string `json:"csv_disabled,omitempty"`
+	CsvMode     string   `json:"csv_mode,omitempty"`
+	// CsvRetentionDays prunes exports older than this many days, 0 keeps them all
+	CsvRetentionDays int    `json:"csv_retention_days,omitempty"`
+	ExportFormat     string `json:"export_format,omitempty"`
+	CsvDelimiter     string `json:"csv_delimiter,omitempty"`
+	// WebhookDisabled lists the events a webhook target is not sent
+	WebhookDisabled []string `json:"webhook_disabled,omitempty"`
+	// Exports are uploaded to an S3-compatible bucket when S3Bucket is set. The
+	// access key and secret are kept as the target's token.
+	S3Endpoint  string `json:"s3_endpoint,omitempty"`
+	S3Region    string `json:"s3_region,omitempty"`
+	S3Bucket    string `json:"s3_bucket,omitempty"`
+	S3Prefix    string `json:"s3_prefix,omitempty"`
+	S3KeepLocal bool   `json:"s3_keep_local,omitempty"`
+}
+
+func ParseSettings(target database.Target) (Settings, error) {
+	var s Settings
+	if len(target.Settings) == 0 {
+		return s, nil
+	}
+	if err := json.Unmarshal(target.Settings, &s); err != nil {
+		return s, fmt.Errorf("invalid target settings: %w", err)
+	}
+	return s, nil
+}
+
+func (s Settings) Encode() (json.RawMessage, error) {
+	return json.Marshal(s)
+}
+
+func (s Settings) CsvEnabled(key string) bool {
+	return !slices.Contains(s.CsvDisabled, key)
+}
+
+func (s Settings) WebhookEnabled(event string) bool {
+	return event == WebhookPing || !slices.Contains(s.WebhookDisabled, event)
+}
+
+func (s Settings) S3Enabled() bool {
+	return s.S3Bucket != ""
+}
+
+func (s Settings) CsvModeOrDefault() string {
+	if s.CsvMode == "" {
+		return CsvModeBundle
+	}
+	return s.CsvMode
+}
+
+func (s Settings) ExportFormatOrDefault() string {
+	if s.ExportFormat == "" {
+		return FormatCsv
+	}
+	return s.ExportFormat
+}
+
+// Delimiter is the field separator of CSV files
+func (s Settings) Delimiter() rune {
+	if s.CsvDelimiter == "" {
+		return ','
+	}
+	return []rune(s.CsvDelimiter)[0]
+}
+
+// SetFileFormat picks the format of the exported files and, for CSV, the
+// delimiter
+func (s *Settings) SetFileFormat(format, delimiter string) error {
+	known := false
+	for _, f := range ExportFormats {
+		if f.Key == format {
+			known = true
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown export format %q", format)
+	}
+
+	known = false
+	for _, d := range CsvDelimiters {
+		if d.Key == delimiter {
+			known = true
+		}
+	}
+	if !known {
+		return fmt.Errorf("unsupported delimiter %q", delimiter)
+	}
+
+	s.ExportFormat = format
+	s.CsvDelimiter = delimiter
+	return nil
+}
+
+// SetCsvOptions disables every CSV output that is not in enabled. At least one
+// known output has to stay enabled.
+func (s *Settings) SetCsvOptions(enabled []string, mode string, retentionDays int) error {
+	var disabled []string
+	for _, o := range CsvOutputs {
+		if !slices.Contains(enabled, o.Key) {
+			disabled = append(disabled, o.Key)
+		}
+	}
+	if len(disabled) == len(CsvOutputs) {
+		return fmt.Errorf("at least one CSV output must be selected")
+	}
+
+	known := false
+	for _, m := range CsvModes {
+		if m.Key == mode {
+			known = true
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown CSV mode %q", mode)
+	}
+
+	if retentionDays < 0 {
+		return fmt.Errorf("retention must be zero or more days")
+	}
+
+	s.CsvDisabled = disabled
+	s.CsvMode = mode
+	s.CsvRetentionDays = retentionDays
+	return nil
+}
+
+// SetWebhookEvents disables every webhook event that is not in enabled. At
+// least one event has to stay enabled.
+func (s *Settings) SetWebhookEvents(enabled []string) error {
+	var disabled []string
+	for _, e := range WebhookEvents {
+		if !slices.Contains(enabled, e.Key) {
+			disabled = append(disabled, e.Key)
+		}
+	}
+	if len(disabled) == len(WebhookEvents) {
+		return fmt.Errorf("at least one webhook event must be selected")
+	}
+
+	s.WebhookDisabled = disabled
+	return nil
+}
+
+// SetS3Options points uploads at a bucket, or turns them off when bucket is
+// empty. The prefix is used as a folder, so it always ends with a slash.
+func (s *Settings) SetS3Options(endpoint, region, bucket, prefix string, keepLocal bool) error {
+	bucket = strings.TrimSpace(bucket)
+	if bucket == "" {
+		s.S3Endpoint, s.S3Region, s.S3Bucket, s.S3Prefix, s.S3KeepLocal = "", "", "", "", false
+		return nil
+	}
+
+	endpoint = strings.TrimRight(strings.TrimSpace(endpoint), "/")
+	if endpoint == "" {
+		return fmt.Errorf("an S3 endpoint is required with a bucket")
+	}
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			return fmt.Errorf("S3 endpoint must be a host or an http(s) URL without a path")
+		}
+	} else if strings.Contains(endpoint, "/") {
+		return fmt.Errorf("S3 endpoint must be a host or an http(s) URL without a path")
+	}
+
+	prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	s.S3Endpoint = endpoint
+	s.S3Region = strings.TrimSpace(region)
+	s.S3Bucket = bucket
+	s.S3Prefix = prefix
+	s.S3KeepLocal = keepLocal
+	return nil
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package targets
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSetCsvOptions(t *testing.T) {
+	var s Settings
+	if err := s.SetCsvOptions([]string{"posts", "website"}, CsvModeStable, 30); err != nil {
+		t.Fatalf("setting options: %v", err)
+	}
+
+	for _, o := range CsvOutputs {
+		want := o.Key == "posts" || o.Key == "website"
+		if s.CsvEnabled(o.Key) != want {
+			t.Errorf("output %s enabled is %v, want %v", o.Key, !want, want)
+		}
+	}
+	if s.CsvModeOrDefault() != CsvModeStable || s.CsvRetentionDays != 30 {
+		t.Errorf("mode %q with %d days retention, want stable with 30", s.CsvMode, s.CsvRetentionDays)
+	}
+
+	// A failed change keeps the previous options
+	before := slices.Clone(s.CsvDisabled)
+	invalid := []struct {
+		name      string
+		enabled   []string
+		mode      string
+		retention int
+	}{
+		{"no outputs", nil, CsvModeBundle, 0},
+		{"unknown outputs only", []string{"nope"}, CsvModeBundle, 0},
+		{"unknown mode", []string{"posts"}, "weekly", 0},
+		{"negative retention", []string{"posts"}, CsvModeDelta, -1},
+	}
+	for _, tt := range invalid {
+		if err := s.SetCsvOptions(tt.enabled, tt.mode, tt.retention); err == nil {
+			t.Errorf("%s: accepted", tt.name)
+		}
+	}
+	if !slices.Equal(s.CsvDisabled, before) || s.CsvMode != CsvModeStable || s.CsvRetentionDays != 30 {
+		t.Errorf("rejected options changed the settings to %+v", s)
+	}
+}
+
+func TestSetS3Options(t *testing.T) {
+	var s Settings
+	if err := s.SetS3Options(" https://s3.example.com/ ", " eu-west-1 ", " exports ", "/rpsync/csv/", true); err != nil {
+		t.Fatalf("setting options: %v", err)
+	}
+	want := Settings{
+		S3Endpoint:  "https://s3.example.com",
+		S3Region:    "eu-west-1",
+		S3Bucket:    "exports",
+		S3Prefix:    "rpsync/csv/",
+		S3KeepLocal: true,
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("settings are %+v, want %+v", s, want)
+	}
+
+	if err := s.SetS3Options("minio:9000", "", "exports", "", false); err != nil {
+		t.Fatalf("setting a bare host: %v", err)
+	}
+	if s.S3Endpoint != "minio:9000" || s.S3Prefix != "" {
+		t.Errorf("endpoint %q with prefix %q, want minio:9000 without a prefix", s.S3Endpoint, s.S3Prefix)
+	}
+
+	for _, endpoint := range []string{"", "ftp://s3.example.com", "https://s3.example.com/bucket", "s3.example.com/bucket"} {
+		if err := s.SetS3Options(endpoint, "", "exports", "", false); err == nil {
+			t.Errorf("endpoint %q was accepted", endpoint)
+		}
+	}
+
+	// Without a bucket, uploads are off and nothing else is kept
+	if err := s.SetS3Options("https://s3.example.com", "eu-west-1", " ", "rpsync", true); err != nil {
+		t.Fatalf("turning uploads off: %v", err)
+	}
+	if s.S3Enabled() || s.S3Endpoint != "" || s.S3Region != "" || s.S3Prefix != "" || s.S3KeepLocal {
+		t.Errorf("settings are %+v after turning uploads off", s)
+	}
+}
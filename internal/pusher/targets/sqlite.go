@@ -0,0 +1,315 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package targets
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/helpers"
+	_ "modernc.org/sqlite"
+)
+
+// snapshotSchemaVersion is bumped whenever a table or column in the snapshot changes
+const snapshotSchemaVersion = 1
+
+var snapshotSchema = []string{
+	`CREATE TABLE snapshot_info (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	)`,
+	`CREATE TABLE sources (
+		id TEXT PRIMARY KEY,
+		network TEXT NOT NULL,
+		username TEXT NOT NULL,
+		profile_url TEXT,
+		is_active INTEGER NOT NULL,
+		created_at TEXT NOT NULL,
+		last_synced_at TEXT
+	)`,
+	`CREATE TABLE posts (
+		id TEXT PRIMARY KEY,
+		source_id TEXT NOT NULL REFERENCES sources(id),
+		network_internal_id TEXT NOT NULL,
+		post_type TEXT NOT NULL,
+		author TEXT NOT NULL,
+		content TEXT,
+		url TEXT,
+		is_archived INTEGER NOT NULL,
+		created_at TEXT NOT NULL
+	)`,
+	`CREATE TABLE post_reactions (
+		post_id TEXT NOT NULL REFERENCES posts(id),
+		synced_at TEXT NOT NULL,
+		likes INTEGER,
+		reposts INTEGER,
+		views INTEGER,
+		comments INTEGER,
+		quotes INTEGER,
+		bookmarks INTEGER,
+		PRIMARY KEY (post_id, synced_at)
+	)`,
+	`CREATE TABLE source_stats (
+		id TEXT PRIMARY KEY,
+		source_id TEXT NOT NULL REFERENCES sources(id),
+		date TEXT NOT NULL,
+		followers_count INTEGER,
+		following_count INTEGER,
+		posts_count INTEGER,
+		average_likes REAL,
+		average_reposts REAL,
+		average_views REAL
+	)`,
+	`CREATE TABLE site_stats (
+		id TEXT PRIMARY KEY,
+		source_id TEXT NOT NULL REFERENCES sources(id),
+		date TEXT NOT NULL,
+		visitors INTEGER NOT NULL,
+		avg_session_duration REAL NOT NULL
+	)`,
+	`CREATE TABLE page_stats (
+		id TEXT PRIMARY KEY,
+		source_id TEXT NOT NULL REFERENCES sources(id),
+		date TEXT NOT NULL,
+		page_path TEXT NOT NULL,
+		views INTEGER NOT NULL
+	)`,
+	`CREATE TABLE audience_demographics (
+		id TEXT PRIMARY KEY,
+		source_id TEXT NOT NULL REFERENCES sources(id),
+		date TEXT NOT NULL,
+		dimension TEXT NOT NULL,
+		bucket TEXT NOT NULL,
+		value REAL NOT NULL
+	)`,
+	`CREATE INDEX posts_source_idx ON posts (source_id, created_at)`,
+	`CREATE INDEX post_reactions_synced_idx ON post_reactions (synced_at)`,
+	`CREATE INDEX source_stats_source_idx ON source_stats (source_id, date)`,
+	`CREATE INDEX site_stats_source_idx ON site_stats (source_id, date)`,
+	`CREATE INDEX page_stats_source_idx ON page_stats (source_id, date)`,
+	`CREATE INDEX audience_demographics_source_idx ON audience_demographics (source_id, date)`,
+}
+
+// GenerateSqliteSnapshot writes every source, post, reaction history entry and
+// statistic of the user into one SQLite file with foreign keys between them.
+func GenerateSqliteSnapshot(dbQueries *database.Queries, target database.Target, export database.Export) (string, error) {
+	filename := fmt.Sprintf("outputs/export_id_%s_snapshot_%s.sqlite", export.ID.String(), time.Now().Format("20060102_150405"))
+	tmpName := filename + ".tmp"
+
+	os.Remove(tmpName)
+	if err := writeSqliteSnapshot(dbQueries, target, tmpName); err != nil {
+		os.Remove(tmpName)
+		return "", err
+	}
+
+	if err := os.Chmod(tmpName, 0600); err != nil {
+		os.Remove(tmpName)
+		return "", err
+	}
+
+	// The file only appears under its final name once it is complete
+	if err := os.Rename(tmpName, filename); err != nil {
+		os.Remove(tmpName)
+		return "", err
+	}
+
+	return filename, nil
+}
+
+func writeSqliteSnapshot(dbQueries *database.Queries, target database.Target, path string) error {
+	ctx := context.Background()
+
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=journal_mode(OFF)&_pragma=synchronous(OFF)")
+	if err != nil {
+		return fmt.Errorf("opening snapshot: %w", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range snapshotSchema {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("creating snapshot schema: %w", err)
+		}
+	}
+
+	info := map[string]string{
+		"schema_version": fmt.Sprintf("%d", snapshotSchemaVersion),
+		"generated_at":   time.Now().UTC().Format(time.RFC3339),
+		"target_id":      target.ID.String(),
+	}
+	for key, value := range info {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO snapshot_info (key, value) VALUES (?, ?)", key, value); err != nil {
+			return err
+		}
+	}
+
+	steps := []struct {
+		name  string
+		write func(context.Context, *sql.Tx, *database.Queries, database.Target) error
+	}{
+		{"sources", writeSnapshotSources},
+		{"posts", writeSnapshotPosts},
+		{"post reactions", writeSnapshotReactions},
+		{"source stats", writeSnapshotSourceStats},
+		{"site stats", writeSnapshotSiteStats},
+		{"page stats", writeSnapshotPageStats},
+		{"audience demographics", writeSnapshotDemographics},
+	}
+
+	for _, step := range steps {
+		if err := step.write(ctx, tx, dbQueries, target); err != nil {
+			return fmt.Errorf("writing %s: %w", step.name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// insertRows prepares stmt once and runs it for every row
+func insertRows(ctx context.Context, tx *sql.Tx, stmt string, rows [][]any) error {
+	prepared, err := tx.PrepareContext(ctx, stmt)
+	if err != nil {
+		return err
+	}
+	defer prepared.Close()
+
+	for _, row := range rows {
+		if _, err := prepared.ExecContext(ctx, row...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func sqliteDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func sqliteNullTime(t sql.NullTime) any {
+	if !t.Valid {
+		return nil
+	}
+	return sqliteTime(t.Time)
+}
+
+func writeSnapshotSources(ctx context.Context, tx *sql.Tx, dbQueries *database.Queries, target database.Target) error {
+	sources, err := dbQueries.GetUserSources(ctx, target.UserID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, len(sources))
+	for i, s := range sources {
+		url, _ := helpers.ConvNetworkToURL(s.Network, s.UserName)
+		rows[i] = []any{s.ID.String(), s.Network, s.UserName, url, s.IsActive, sqliteTime(s.CreatedAt), sqliteNullTime(s.LastSynced)}
+	}
+
+	return insertRows(ctx, tx, `INSERT INTO sources (id, network, username, profile_url, is_active, created_at, last_synced_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`, rows)
+}
+
+func writeSnapshotPosts(ctx context.Context, tx *sql.Tx, dbQueries *database.Queries, target database.Target) error {
+	posts, err := dbQueries.GetAllPostsWithTheLatestInfoForUser(ctx, target.UserID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, len(posts))
+	for i, p := range posts {
+		url, _ := helpers.ConvPostToURL(p.Network.String, p.Author, p.NetworkInternalID)
+		rows[i] = []any{p.ID.String(), p.SourceID.String(), p.NetworkInternalID, p.PostType, p.Author, p.Content, url, p.IsArchived, sqliteTime(p.CreatedAt)}
+	}
+
+	return insertRows(ctx, tx, `INSERT INTO posts (id, source_id, network_internal_id, post_type, author, content, url, is_archived, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, rows)
+}
+
+func writeSnapshotReactions(ctx context.Context, tx *sql.Tx, dbQueries *database.Queries, target database.Target) error {
+	history, err := dbQueries.GetReactionsHistoryForUserSince(ctx, database.GetReactionsHistoryForUserSinceParams{
+		UserID: target.UserID,
+	})
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, len(history))
+	for i, h := range history {
+		rows[i] = []any{h.PostID.String(), sqliteTime(h.SyncedAt), h.Likes, h.Reposts, h.Views, h.Comments, h.Quotes, h.Bookmarks}
+	}
+
+	return insertRows(ctx, tx, `INSERT INTO post_reactions (post_id, synced_at, likes, reposts, views, comments, quotes, bookmarks)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, rows)
+}
+
+func writeSnapshotSourceStats(ctx context.Context, tx *sql.Tx, dbQueries *database.Queries, target database.Target) error {
+	stats, err := dbQueries.GetAllSourcesStatsForUser(ctx, target.UserID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, len(stats))
+	for i, s := range stats {
+		rows[i] = []any{s.ID.String(), s.SourceID.String(), sqliteDate(s.Date), s.FollowersCount, s.FollowingCount, s.PostsCount, s.AverageLikes, s.AverageReposts, s.AverageViews}
+	}
+
+	return insertRows(ctx, tx, `INSERT INTO source_stats (id, source_id, date, followers_count, following_count, posts_count, average_likes, average_reposts, average_views)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, rows)
+}
+
+func writeSnapshotSiteStats(ctx context.Context, tx *sql.Tx, dbQueries *database.Queries, target database.Target) error {
+	stats, err := dbQueries.GetAllAnalyticsSiteStatsForUser(ctx, target.UserID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, len(stats))
+	for i, s := range stats {
+		rows[i] = []any{s.ID.String(), s.SourceID.String(), sqliteDate(s.Date), s.Visitors, s.AvgSessionDuration}
+	}
+
+	return insertRows(ctx, tx, `INSERT INTO site_stats (id, source_id, date, visitors, avg_session_duration)
+		VALUES (?, ?, ?, ?, ?)`, rows)
+}
+
+func writeSnapshotPageStats(ctx context.Context, tx *sql.Tx, dbQueries *database.Queries, target database.Target) error {
+	stats, err := dbQueries.GetAllAnalyticsPageStatsForUser(ctx, target.UserID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, len(stats))
+	for i, s := range stats {
+		rows[i] = []any{s.ID.String(), s.SourceID.String(), sqliteDate(s.Date), s.UrlPath, s.Views}
+	}
+
+	return insertRows(ctx, tx, `INSERT INTO page_stats (id, source_id, date, page_path, views)
+		VALUES (?, ?, ?, ?, ?)`, rows)
+}
+
+func writeSnapshotDemographics(ctx context.Context, tx *sql.Tx, dbQueries *database.Queries, target database.Target) error {
+	demographics, err := dbQueries.GetAudienceDemographicsForUser(ctx, target.UserID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, len(demographics))
+	for i, d := range demographics {
+		rows[i] = []any{d.ID.String(), d.SourceID.String(), sqliteDate(d.Date), d.Dimension, d.Bucket, d.Value}
+	}
+
+	return insertRows(ctx, tx, `INSERT INTO audience_demographics (id, source_id, date, dimension, bucket, value)
+		VALUES (?, ?, ?, ?, ?, ?)`, rows)
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package tabular
+
+import "time"
+
+// Backend is a spreadsheet-style database RPSync keeps tables in. The shared sync
+// flow creates the tables, tracks records through the *_on_target mappings and
+// links every row to its source; a backend only translates those calls to its API.
+type Backend interface {
+	// BatchSize is the largest number of records a single request may carry
+	BatchSize() int
+
+	CreateTable(table Table) (*TableInfo, error)
+	UpdateColumn(tableID, columnID string, column Column) error
+
+	// CreateLink adds a one-to-many link from parent rows to child rows and returns
+	// the ID LinkRecords needs to use it.
+	CreateLink(parentTableID, childTableID, name string) (string, error)
+	LinkRecords(parentTableID, childTableID, linkID, parentID string, childIDs []string) error
+
+	// CreateRecords returns the IDs of the new records keyed by their ct_id, as
+	// read back from the response rather than by position in it
+	CreateRecords(tableID string, records []Record) (map[string]string, error)
+	UpdateRecords(tableID string, records []Record) error
+	DeleteRecords(tableID string, ids []string) error
+}
+
+type ColumnType string
+
+const (
+	Text         ColumnType = "text"
+	LongText     ColumnType = "long_text"
+	Number       ColumnType = "number"
+	Decimal      ColumnType = "decimal"
+	Checkbox     ColumnType = "checkbox"
+	DateOnly     ColumnType = "date"
+	DateTime     ColumnType = "date_time"
+	URL          ColumnType = "url"
+	SingleSelect ColumnType = "single_select"
+)
+
+type Choice struct {
+	Name  string
+	Color string
+}
+
+type Column struct {
+	Name    string
+	Type    ColumnType
+	Unique  bool
+	Choices []Choice
+}
+
+type Table struct {
+	Name        string
+	Description string
+	Columns     []Column
+}
+
+type TableInfo struct {
+	ID      string
+	Name    string
+	Columns map[string]string
+}
+
+// Record is a row keyed by column name. Values are strings, numbers, bools,
+// time.Time for DateTime columns, Date for DateOnly columns, or nil.
+type Record struct {
+	ID     string
+	Fields map[string]any
+}
+
+// Date is a calendar day, kept apart from time.Time so backends can tell Date
+// columns from DateTime ones.
+type Date time.Time
+
+func (d Date) String() string {
+	return time.Time(d).Format("2006-01-02")
+}
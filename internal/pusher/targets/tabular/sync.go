@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package tabular
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+// tableSync carries what every step of a sync needs: the backend, the target,
+// the sources table and where each source's row lives.
+type tableSync struct {
+	dbQueries   *database.Queries
+	b           Backend
+	target      database.Target
+	sources     database.TableMapping
+	sourceRows  map[uuid.UUID]string
+	linkColumns map[string]string
+}
+
+func Sync(dbQueries *database.Queries, b Backend, target database.Target) error {
+
+	sourcesTable, err := dbQueries.GetTableMappingsByTargetAndName(context.Background(), database.GetTableMappingsByTargetAndNameParams{
+		TargetID:        target.ID,
+		TargetTableName: "sources",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get target source table: %w", err)
+	}
+
+	s := &tableSync{
+		dbQueries:   dbQueries,
+		b:           b,
+		target:      target,
+		sources:     sourcesTable,
+		linkColumns: make(map[string]string),
+	}
+
+	if err := s.syncSources(); err != nil {
+		return fmt.Errorf("failed to sync sources: %w", err)
+	}
+
+	if err := s.syncSiteStats(); err != nil {
+		return fmt.Errorf("failed to sync site stats: %w", err)
+	}
+
+	if err := s.syncPageStats(); err != nil {
+		return fmt.Errorf("failed to sync page stats: %w", err)
+	}
+
+	if err := s.syncSourcesStats(); err != nil {
+		return fmt.Errorf("failed to sync sources stats: %w", err)
+	}
+
+	if err := s.syncAudienceDemographics(); err != nil {
+		return fmt.Errorf("failed to sync audience demographics: %w", err)
+	}
+
+	if err := s.syncPosts(); err != nil {
+		return fmt.Errorf("failed to sync posts: %w", err)
+	}
+
+	return nil
+}
+
+func (s *tableSync) tableID(name string) (string, error) {
+	mapping, err := s.dbQueries.GetTableMappingsByTargetAndName(context.Background(), database.GetTableMappingsByTargetAndNameParams{
+		TargetID:        s.target.ID,
+		TargetTableName: name,
+	})
+	if err != nil {
+		return "", err
+	}
+	return mapping.TargetTableCode.String, nil
+}
+
+// create adds records a batch at a time, calling saved with the offset of each
+// batch and the IDs the backend gave its records.
+func (s *tableSync) create(tableID string, records []Record, saved func(offset int, ids []string) error) error {
+	batchSize := s.b.BatchSize()
+
+	for start := 0; start < len(records); start += batchSize {
+		end := min(start+batchSize, len(records))
+
+		created, err := s.b.CreateRecords(tableID, records[start:end])
+		if err != nil {
+			return err
+		}
+
+		ids := make([]string, end-start)
+		for i, record := range records[start:end] {
+			ctID, _ := record.Fields["ct_id"].(string)
+			id, ok := created[ctID]
+			if !ok {
+				return fmt.Errorf("created record %s was not returned", ctID)
+			}
+			ids[i] = id
+		}
+
+		if err := saved(start, ids); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *tableSync) update(tableID string, records []Record) error {
+	batchSize := s.b.BatchSize()
+
+	for start := 0; start < len(records); start += batchSize {
+		end := min(start+batchSize, len(records))
+		if err := s.b.UpdateRecords(tableID, records[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *tableSync) delete(tableID string, ids []string) error {
+	batchSize := s.b.BatchSize()
+
+	for start := 0; start < len(ids); start += batchSize {
+		end := min(start+batchSize, len(ids))
+		if err := s.b.DeleteRecords(tableID, ids[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// link attaches child records to their source's row through the named link column
+func (s *tableSync) link(childTableID, linkName string, sourceID uuid.UUID, childIDs []string) error {
+	parentID, ok := s.sourceRows[sourceID]
+	if !ok || len(childIDs) == 0 {
+		return nil
+	}
+
+	linkID, ok := s.linkColumns[linkName]
+	if !ok {
+		colMapping, err := s.dbQueries.GetColumnMappingsByTableAndName(context.Background(), database.GetColumnMappingsByTableAndNameParams{
+			TableMappingID:   s.sources.ID,
+			TargetColumnName: linkName,
+		})
+		if err != nil {
+			return err
+		}
+		linkID = colMapping.TargetColumnCode.String
+		s.linkColumns[linkName] = linkID
+	}
+
+	return s.b.LinkRecords(s.sources.TargetTableCode.String, childTableID, linkID, parentID, childIDs)
+}
+
+func DeletePostsAndSource(dbQueries *database.Queries, b Backend, target database.Target, source database.Source) error {
+
+	sourceMapping, err := dbQueries.GetTargetSourceBySource(context.Background(), database.GetTargetSourceBySourceParams{
+		TargetID: target.ID,
+		SourceID: source.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching source mapping: %w", err)
+	}
+
+	s := &tableSync{dbQueries: dbQueries, b: b, target: target}
+
+	sourcesTableID, err := s.tableID("sources")
+	if err != nil {
+		return err
+	}
+
+	if err := b.DeleteRecords(sourcesTableID, []string{sourceMapping.TargetSourceID}); err != nil {
+		return fmt.Errorf("failed to delete source from %s: %w", target.TargetType, err)
+	}
+
+	postsTableID, err := s.tableID("posts")
+	if err != nil {
+		return err
+	}
+
+	postsToDelete, err := dbQueries.GetPostsBySourceAndTarget(context.Background(), database.GetPostsBySourceAndTargetParams{
+		TargetID: target.ID,
+		SourceID: source.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, len(postsToDelete))
+	for i, post := range postsToDelete {
+		ids[i] = post.TargetPostID
+	}
+
+	if err := s.delete(postsTableID, ids); err != nil {
+		return fmt.Errorf("failed to delete posts from %s: %w", target.TargetType, err)
+	}
+
+	err = dbQueries.DeletePostsOnTargetAndSource(context.Background(), database.DeletePostsOnTargetAndSourceParams{
+		TargetID: target.ID,
+		SourceID: source.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return dbQueries.DeleteSourceTarget(context.Background(), database.DeleteSourceTargetParams{
+		TargetID: target.ID,
+		SourceID: source.ID,
+	})
+}
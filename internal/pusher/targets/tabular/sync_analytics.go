@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package tabular
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets"
+	"github.com/google/uuid"
+)
+
+func siteStatRecord(id uuid.UUID, date time.Time, visitors int, avgSessionDuration float64) Record {
+	return Record{Fields: map[string]any{
+		"ct_id":                id.String(),
+		"date":                 Date(date),
+		"visitors":             visitors,
+		"avg_session_duration": avgSessionDuration,
+	}}
+}
+
+func pageStatRecord(id uuid.UUID, date time.Time, urlPath string, views int) Record {
+	return Record{Fields: map[string]any{
+		"ct_id":     id.String(),
+		"date":      Date(date),
+		"page_path": urlPath,
+		"views":     views,
+	}}
+}
+
+func (s *tableSync) syncSiteStats() error {
+
+	tableID, err := s.tableID("analytics_site_stats")
+	if err != nil {
+		return nil
+	}
+
+	dateThreshold := time.Now().AddDate(0, 0, -9)
+
+	for sourceID := range s.sourceRows {
+
+		syncedStats, err := s.dbQueries.GetSyncedSiteStatsForUpdate(context.Background(), database.GetSyncedSiteStatsForUpdateParams{
+			TargetID: s.target.ID,
+			SourceID: sourceID,
+			Date:     dateThreshold,
+		})
+		if err != nil {
+			return err
+		}
+
+		updateRecords := make([]Record, len(syncedStats))
+		for i, stat := range syncedStats {
+			updateRecords[i] = siteStatRecord(stat.ID, stat.Date, stat.Visitors, stat.AvgSessionDuration)
+			updateRecords[i].ID = stat.TargetRecordID
+		}
+		if err := s.update(tableID, updateRecords); err != nil {
+			return err
+		}
+
+		unsyncedStats, err := s.dbQueries.GetUnsyncedSiteStatsForTarget(context.Background(), database.GetUnsyncedSiteStatsForTargetParams{
+			TargetID: s.target.ID,
+			SourceID: sourceID,
+		})
+		if err != nil {
+			return err
+		}
+
+		createRecords := make([]Record, len(unsyncedStats))
+		for i, stat := range unsyncedStats {
+			createRecords[i] = siteStatRecord(stat.ID, stat.Date, stat.Visitors, stat.AvgSessionDuration)
+		}
+
+		err = s.create(tableID, createRecords, func(offset int, ids []string) error {
+			for i, id := range ids {
+				_, err := s.dbQueries.AddAnalyticsSiteStatToTarget(context.Background(), database.AddAnalyticsSiteStatToTargetParams{
+					ID:             uuid.New(),
+					SyncedAt:       time.Now(),
+					StatID:         uuid.NullUUID{UUID: unsyncedStats[offset+i].ID, Valid: true},
+					TargetID:       s.target.ID,
+					TargetRecordID: id,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to map site stat: %w", err)
+				}
+			}
+
+			if err := s.link(tableID, "site_stats", sourceID, ids); err != nil {
+				log.Printf("Failed to link site stats to source: %v", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *tableSync) syncPageStats() error {
+
+	tableID, err := s.tableID("analytics_page_stats")
+	if err != nil {
+		return nil
+	}
+
+	dateThreshold := time.Now().AddDate(0, 0, -9)
+
+	for sourceID := range s.sourceRows {
+
+		syncedStats, err := s.dbQueries.GetSyncedPageStatsForUpdate(context.Background(), database.GetSyncedPageStatsForUpdateParams{
+			TargetID: s.target.ID,
+			SourceID: sourceID,
+			Date:     dateThreshold,
+		})
+		if err != nil {
+			return err
+		}
+
+		updateRecords := make([]Record, len(syncedStats))
+		for i, stat := range syncedStats {
+			updateRecords[i] = pageStatRecord(stat.ID, stat.Date, stat.UrlPath, stat.Views)
+			updateRecords[i].ID = stat.TargetRecordID
+		}
+		if err := s.update(tableID, updateRecords); err != nil {
+			return err
+		}
+
+		unsyncedStats, err := s.dbQueries.GetUnsyncedPageStatsForTarget(context.Background(), database.GetUnsyncedPageStatsForTargetParams{
+			TargetID: s.target.ID,
+			SourceID: sourceID,
+		})
+		if err != nil {
+			return err
+		}
+
+		createRecords := make([]Record, len(unsyncedStats))
+		for i, stat := range unsyncedStats {
+			createRecords[i] = pageStatRecord(stat.ID, stat.Date, stat.UrlPath, stat.Views)
+		}
+
+		err = s.create(tableID, createRecords, func(offset int, ids []string) error {
+			for i, id := range ids {
+				_, err := s.dbQueries.AddAnalyticsPageStatToTarget(context.Background(), database.AddAnalyticsPageStatToTargetParams{
+					ID:             uuid.New(),
+					SyncedAt:       time.Now(),
+					StatID:         uuid.NullUUID{UUID: unsyncedStats[offset+i].ID, Valid: true},
+					TargetID:       s.target.ID,
+					TargetRecordID: id,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to map page stat: %w", err)
+				}
+			}
+
+			if err := s.link(tableID, "page_stats", sourceID, ids); err != nil {
+				log.Printf("Failed to link page stats to source: %v", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return targets.RemoveOrphanedPageStats(s.dbQueries, s.target.ID, func(ids []string) error {
+		return s.delete(tableID, ids)
+	})
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package tabular
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/helpers"
+	"github.com/google/uuid"
+)
+
+func postRecord(post database.GetAllPostsWithTheLatestInfoForUserRow) (Record, error) {
+	url, err := helpers.ConvPostToURL(post.Network.String, post.Author, post.NetworkInternalID)
+	if err != nil {
+		return Record{}, err
+	}
+
+	return Record{Fields: map[string]any{
+		"ct_id":               post.ID.String(),
+		"created_at":          post.CreatedAt,
+		"last_synced":         time.Now(),
+		"is_archived":         post.IsArchived,
+		"network_internal_id": post.NetworkInternalID,
+		"post_type":           post.PostType,
+		"author":              post.Author,
+		"content":             post.Content.String,
+		"likes":               post.Likes.Int64,
+		"views":               post.Views.Int64,
+		"reposts":             post.Reposts.Int64,
+		"URL":                 url,
+	}}, nil
+}
+
+func (s *tableSync) syncPosts() error {
+
+	tableID, err := s.tableID("posts")
+	if err != nil {
+		return fmt.Errorf("failed to get target table: %w", err)
+	}
+
+	posts, err := s.dbQueries.GetAllPostsWithTheLatestInfoForUser(context.Background(), s.target.UserID)
+	if err != nil {
+		return err
+	}
+
+	mappedPosts, err := s.dbQueries.GetPostsPreviouslySynced(context.Background(), s.target.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching mapped posts: %w", err)
+	}
+
+	mappedMap := make(map[uuid.UUID]database.PostsOnTarget, len(mappedPosts))
+	var removePosts []database.PostsOnTarget
+	for _, m := range mappedPosts {
+		if !m.PostID.Valid {
+			removePosts = append(removePosts, m)
+		} else {
+			mappedMap[m.PostID.UUID] = m
+		}
+	}
+
+	localMap := make(map[uuid.UUID]struct{}, len(posts))
+	var createPosts []database.GetAllPostsWithTheLatestInfoForUserRow
+	var createRecords, updateRecords []Record
+
+	for _, post := range posts {
+		localMap[post.ID] = struct{}{}
+
+		record, err := postRecord(post)
+		if err != nil {
+			return err
+		}
+
+		if mapped, ok := mappedMap[post.ID]; ok {
+			record.ID = mapped.TargetPostID
+			updateRecords = append(updateRecords, record)
+		} else {
+			createPosts = append(createPosts, post)
+			createRecords = append(createRecords, record)
+		}
+	}
+
+	for id, m := range mappedMap {
+		if _, ok := localMap[id]; !ok {
+			removePosts = append(removePosts, m)
+		}
+	}
+
+	err = s.create(tableID, createRecords, func(offset int, ids []string) error {
+		postsBySource := make(map[uuid.UUID][]string)
+
+		for i, id := range ids {
+			post := createPosts[offset+i]
+
+			_, err := s.dbQueries.AddPostToTarget(context.Background(), database.AddPostToTargetParams{
+				ID:            uuid.New(),
+				FirstSyncedAt: time.Now(),
+				PostID:        uuid.NullUUID{UUID: post.ID, Valid: true},
+				TargetID:      s.target.ID,
+				TargetPostID:  id,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to map post: %w", err)
+			}
+
+			postsBySource[post.SourceID] = append(postsBySource[post.SourceID], id)
+		}
+
+		for sourceID, postIDs := range postsBySource {
+			if err := s.link(tableID, "posts", sourceID, postIDs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	removeIDs := make([]string, len(removePosts))
+	for i, post := range removePosts {
+		removeIDs[i] = post.TargetPostID
+	}
+
+	if err := s.delete(tableID, removeIDs); err != nil {
+		return err
+	}
+
+	for _, post := range removePosts {
+		err := s.dbQueries.DeletePostOnTarget(context.Background(), post.ID)
+		if err != nil {
+			log.Printf("Warning: Failed to delete posts_on_target mapping: %v", err)
+		}
+	}
+
+	return s.update(tableID, updateRecords)
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package tabular
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/helpers"
+	"github.com/google/uuid"
+)
+
+func sourceRecord(source database.Source) Record {
+	url, _ := helpers.ConvNetworkToURL(source.Network, source.UserName)
+
+	var lastSynced any
+	if source.LastSynced.Valid {
+		lastSynced = source.LastSynced.Time
+	}
+
+	return Record{Fields: map[string]any{
+		"ct_id":       source.ID.String(),
+		"network":     source.Network,
+		"username":    source.UserName,
+		"URL":         url,
+		"last_synced": lastSynced,
+	}}
+}
+
+func (s *tableSync) syncSources() error {
+	tableID := s.sources.TargetTableCode.String
+
+	userSources, err := s.dbQueries.GetUserSources(context.Background(), s.target.UserID)
+	if err != nil {
+		return fmt.Errorf("error fetching user sources: %w", err)
+	}
+
+	mappedSources, err := s.dbQueries.GetTargetSources(context.Background(), s.target.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching target sources: %w", err)
+	}
+
+	s.sourceRows = make(map[uuid.UUID]string, len(mappedSources))
+	for _, m := range mappedSources {
+		s.sourceRows[m.SourceID] = m.TargetSourceID
+	}
+
+	localSources := make(map[uuid.UUID]struct{}, len(userSources))
+	var createSources []database.Source
+	var records []Record
+
+	for _, source := range userSources {
+		localSources[source.ID] = struct{}{}
+		if _, ok := s.sourceRows[source.ID]; !ok {
+			createSources = append(createSources, source)
+			records = append(records, sourceRecord(source))
+		}
+	}
+
+	err = s.create(tableID, records, func(offset int, ids []string) error {
+		for i, id := range ids {
+			source := createSources[offset+i]
+
+			_, err := s.dbQueries.AddSourceToTarget(context.Background(), database.AddSourceToTargetParams{
+				ID:             uuid.New(),
+				SourceID:       source.ID,
+				TargetID:       s.target.ID,
+				TargetSourceID: id,
+			})
+			if err != nil {
+				return err
+			}
+			s.sourceRows[source.ID] = id
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var removeSources []database.SourcesOnTarget
+	var removeIDs []string
+	for _, m := range mappedSources {
+		if _, ok := localSources[m.SourceID]; !ok {
+			removeSources = append(removeSources, m)
+			removeIDs = append(removeIDs, m.TargetSourceID)
+		}
+	}
+
+	if err := s.delete(tableID, removeIDs); err != nil {
+		return err
+	}
+
+	for _, source := range removeSources {
+		err := s.dbQueries.DeleteSourceTarget(context.Background(), database.DeleteSourceTargetParams{
+			TargetID: s.target.ID,
+			SourceID: source.SourceID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete source target mapping: %w", err)
+		}
+		delete(s.sourceRows, source.SourceID)
+	}
+
+	// New networks are added to the select options as sources gain support for them
+	colMapping, err := s.dbQueries.GetColumnMappingsByTableAndName(context.Background(), database.GetColumnMappingsByTableAndNameParams{
+		TableMappingID:   s.sources.ID,
+		TargetColumnName: "network",
+	})
+	if err == nil && colMapping.TargetColumnCode.Valid {
+		if err := s.b.UpdateColumn(tableID, colMapping.TargetColumnCode.String, networkColumn()); err != nil {
+			log.Printf("Failed to update network column options: %v", err)
+		}
+	}
+
+	return nil
+}
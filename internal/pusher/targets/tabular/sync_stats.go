@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package tabular
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/google/uuid"
+)
+
+func sourcesStatRecord(id uuid.UUID, date time.Time, followers, following, posts sql.NullInt64, likes, reposts, views sql.NullFloat64) Record {
+	return Record{Fields: map[string]any{
+		"ct_id":           id.String(),
+		"date":            Date(date),
+		"followers_count": followers.Int64,
+		"following_count": following.Int64,
+		"posts_count":     posts.Int64,
+		"average_likes":   likes.Float64,
+		"average_reposts": reposts.Float64,
+		"average_views":   views.Float64,
+	}}
+}
+
+func (s *tableSync) syncSourcesStats() error {
+
+	tableID, err := s.tableID("sources_stats")
+	if err != nil {
+		return nil
+	}
+
+	dateThreshold := time.Now().AddDate(0, 0, -2)
+
+	for sourceID := range s.sourceRows {
+
+		syncedStats, err := s.dbQueries.GetSyncedSourcesStatsForUpdate(context.Background(), database.GetSyncedSourcesStatsForUpdateParams{
+			TargetID: s.target.ID,
+			SourceID: sourceID,
+			Date:     dateThreshold,
+		})
+		if err != nil {
+			return err
+		}
+
+		updateRecords := make([]Record, len(syncedStats))
+		for i, stat := range syncedStats {
+			updateRecords[i] = sourcesStatRecord(stat.ID, stat.Date, stat.FollowersCount, stat.FollowingCount, stat.PostsCount, stat.AverageLikes, stat.AverageReposts, stat.AverageViews)
+			updateRecords[i].ID = stat.TargetRecordID
+		}
+		if err := s.update(tableID, updateRecords); err != nil {
+			return err
+		}
+
+		unsyncedStats, err := s.dbQueries.GetUnsyncedSourcesStatsForTarget(context.Background(), database.GetUnsyncedSourcesStatsForTargetParams{
+			SourceID: sourceID,
+			TargetID: s.target.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		createRecords := make([]Record, len(unsyncedStats))
+		for i, stat := range unsyncedStats {
+			createRecords[i] = sourcesStatRecord(stat.ID, stat.Date, stat.FollowersCount, stat.FollowingCount, stat.PostsCount, stat.AverageLikes, stat.AverageReposts, stat.AverageViews)
+		}
+
+		err = s.create(tableID, createRecords, func(offset int, ids []string) error {
+			for i, id := range ids {
+				_, err := s.dbQueries.AddSourcesStatToTarget(context.Background(), database.AddSourcesStatToTargetParams{
+					ID:             uuid.New(),
+					SyncedAt:       time.Now(),
+					StatID:         unsyncedStats[offset+i].ID,
+					TargetID:       s.target.ID,
+					TargetRecordID: id,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to map sources stat: %w", err)
+				}
+			}
+
+			if err := s.link(tableID, "sources_stats", sourceID, ids); err != nil {
+				log.Printf("Failed to link sources stats to source: %v", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *tableSync) syncAudienceDemographics() error {
+
+	tableID, err := s.tableID("audience_demographics")
+	if err != nil {
+		return nil
+	}
+
+	for sourceID := range s.sourceRows {
+
+		unsynced, err := s.dbQueries.GetUnsyncedAudienceDemographicsForTarget(context.Background(), database.GetUnsyncedAudienceDemographicsForTargetParams{
+			SourceID: sourceID,
+			TargetID: s.target.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		records := make([]Record, len(unsynced))
+		for i, d := range unsynced {
+			records[i] = Record{Fields: map[string]any{
+				"ct_id":     d.ID.String(),
+				"date":      Date(d.Date),
+				"dimension": d.Dimension,
+				"bucket":    d.Bucket,
+				"value":     d.Value,
+			}}
+		}
+
+		err = s.create(tableID, records, func(offset int, ids []string) error {
+			for i, id := range ids {
+				_, err := s.dbQueries.AddAudienceDemographicToTarget(context.Background(), database.AddAudienceDemographicToTargetParams{
+					ID:             uuid.New(),
+					SyncedAt:       time.Now(),
+					DemographicID:  unsynced[offset+i].ID,
+					TargetID:       s.target.ID,
+					TargetRecordID: id,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to map audience demographic: %w", err)
+				}
+			}
+
+			if err := s.link(tableID, "demographics", sourceID, ids); err != nil {
+				log.Printf("Failed to link audience demographics to source: %v", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
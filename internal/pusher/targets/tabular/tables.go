@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package tabular
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/helpers"
+	"github.com/google/uuid"
+)
+
+var childTables = []Table{
+	{
+		Name:        "posts",
+		Description: "Posts from your social networks",
+		Columns: []Column{
+			{Name: "ct_id", Type: Text, Unique: true},
+			{Name: "created_at", Type: DateTime},
+			{Name: "last_synced", Type: DateTime},
+			{Name: "is_archived", Type: Checkbox},
+			{Name: "network_internal_id", Type: Text},
+			{Name: "post_type", Type: Text},
+			{Name: "author", Type: Text},
+			{Name: "content", Type: LongText},
+			{Name: "likes", Type: Number},
+			{Name: "views", Type: Number},
+			{Name: "reposts", Type: Number},
+			{Name: "URL", Type: URL},
+		},
+	},
+	{
+		Name:        "analytics_site_stats",
+		Description: "Daily website analytics (visitors, session duration)",
+		Columns: []Column{
+			{Name: "ct_id", Type: Text, Unique: true},
+			{Name: "date", Type: DateOnly},
+			{Name: "visitors", Type: Number},
+			{Name: "avg_session_duration", Type: Decimal},
+		},
+	},
+	{
+		Name:        "analytics_page_stats",
+		Description: "Daily page view analytics",
+		Columns: []Column{
+			{Name: "ct_id", Type: Text, Unique: true},
+			{Name: "date", Type: DateOnly},
+			{Name: "page_path", Type: Text},
+			{Name: "views", Type: Number},
+		},
+	},
+	{
+		Name:        "sources_stats",
+		Description: "Daily profile statistics (followers, following, averages)",
+		Columns: []Column{
+			{Name: "ct_id", Type: Text, Unique: true},
+			{Name: "date", Type: DateOnly},
+			{Name: "followers_count", Type: Number},
+			{Name: "following_count", Type: Number},
+			{Name: "posts_count", Type: Number},
+			{Name: "average_likes", Type: Decimal},
+			{Name: "average_reposts", Type: Decimal},
+			{Name: "average_views", Type: Decimal},
+		},
+	},
+	{
+		Name:        "audience_demographics",
+		Description: "Daily audience demographics snapshots (age, gender, country, city)",
+		Columns: []Column{
+			{Name: "ct_id", Type: Text, Unique: true},
+			{Name: "date", Type: DateOnly},
+			{Name: "dimension", Type: Text},
+			{Name: "bucket", Type: Text},
+			{Name: "value", Type: Decimal},
+		},
+	},
+}
+
+// sourceLinks names the link column on the sources table for each child table
+var sourceLinks = map[string]string{
+	"posts":         "posts",
+	"site_stats":    "analytics_site_stats",
+	"page_stats":    "analytics_page_stats",
+	"sources_stats": "sources_stats",
+	"demographics":  "audience_demographics",
+}
+
+func networkColumn() Column {
+	var choices []Choice
+	for _, source := range helpers.AvailableSources {
+		choices = append(choices, Choice{Name: source.Name, Color: source.Color})
+	}
+
+	return Column{Name: "network", Type: SingleSelect, Choices: choices}
+}
+
+func sourcesTable() Table {
+	return Table{
+		Name:        "sources",
+		Description: "Social media sources",
+		Columns: []Column{
+			{Name: "ct_id", Type: Text, Unique: true},
+			networkColumn(),
+			{Name: "username", Type: Text},
+			{Name: "URL", Type: URL},
+			{Name: "last_synced", Type: DateTime},
+		},
+	}
+}
+
+// Initialize creates every table the target is missing, then links the child
+// tables to sources. It is safe to run again after a partial failure.
+func Initialize(dbQueries *database.Queries, b Backend, target database.Target) error {
+	log.Printf("Initializing %s target %s", target.TargetType, target.ID)
+
+	tables := make(map[string]database.TableMapping)
+	for _, table := range childTables {
+		mapping, err := ensureTable(dbQueries, b, target, table)
+		if err != nil {
+			return fmt.Errorf("create %s table: %w", table.Name, err)
+		}
+		tables[table.Name] = mapping
+	}
+
+	sourcesMapping, err := ensureTable(dbQueries, b, target, sourcesTable())
+	if err != nil {
+		return fmt.Errorf("create sources table: %w", err)
+	}
+
+	for linkName, childName := range sourceLinks {
+		_, err := dbQueries.GetColumnMappingsByTableAndName(context.Background(), database.GetColumnMappingsByTableAndNameParams{
+			TableMappingID:   sourcesMapping.ID,
+			TargetColumnName: linkName,
+		})
+		if err == nil {
+			continue
+		}
+
+		log.Printf("Sources column mapping missing: %s. Creating in %s...", linkName, target.TargetType)
+
+		linkID, err := b.CreateLink(sourcesMapping.TargetTableCode.String, tables[childName].TargetTableCode.String, linkName)
+		if err != nil {
+			return fmt.Errorf("failed to create column %s: %w", linkName, err)
+		}
+
+		_, err = dbQueries.CreateMappingForColumn(context.Background(), database.CreateMappingForColumnParams{
+			ID:               uuid.New(),
+			CreatedAt:        time.Now(),
+			TableMappingID:   sourcesMapping.ID,
+			SourceColumnName: linkName,
+			TargetColumnName: linkName,
+			TargetColumnCode: sql.NullString{String: linkID, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create column mapping for %s: %w", linkName, err)
+		}
+	}
+
+	return nil
+}
+
+func ensureTable(dbQueries *database.Queries, b Backend, target database.Target, table Table) (database.TableMapping, error) {
+	mapping, err := dbQueries.GetTableMappingsByTargetAndName(context.Background(), database.GetTableMappingsByTargetAndNameParams{
+		TargetID:        target.ID,
+		TargetTableName: table.Name,
+	})
+	if err == nil {
+		return mapping, nil
+	}
+
+	info, err := b.CreateTable(table)
+	if err != nil {
+		return database.TableMapping{}, err
+	}
+
+	mapping, err = dbQueries.CreateMappingForTable(context.Background(), database.CreateMappingForTableParams{
+		ID:              uuid.New(),
+		CreatedAt:       time.Now(),
+		SourceTableName: table.Name,
+		TargetTableName: table.Name,
+		TargetTableCode: sql.NullString{String: info.ID, Valid: true},
+		TargetID:        target.ID,
+	})
+	if err != nil {
+		return database.TableMapping{}, fmt.Errorf("create %s table mapping: %w", table.Name, err)
+	}
+
+	for name, columnID := range info.Columns {
+		_, err := dbQueries.CreateMappingForColumn(context.Background(), database.CreateMappingForColumnParams{
+			ID:               uuid.New(),
+			CreatedAt:        time.Now(),
+			TableMappingID:   mapping.ID,
+			SourceColumnName: name,
+			TargetColumnName: name,
+			TargetColumnCode: sql.NullString{String: columnID, Valid: true},
+		})
+		if err != nil {
+			return database.TableMapping{}, fmt.Errorf("create %s column mapping %s: %w", table.Name, name, err)
+		}
+	}
+
+	return mapping, nil
+}
@@ -0,0 +1,247 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package teable
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/common"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/tabular"
+)
+
+const teableBatchSize = 500
+
+type Backend struct {
+	client *teableClient
+	baseID string
+}
+
+func NewBackend(c *common.Client, dbQueries *database.Queries, encryptionKey []byte, target database.Target) (*Backend, error) {
+	client, err := newTeableClient(c, dbQueries, encryptionKey, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Backend{client: client, baseID: target.DbID.String}, nil
+}
+
+func (b *Backend) BatchSize() int {
+	return teableBatchSize
+}
+
+func dateFormatting(withTime bool) map[string]any {
+	timeFormat := "None"
+	if withTime {
+		timeFormat = "HH:mm"
+	}
+	return map[string]any{
+		"formatting": map[string]any{
+			"date":     "YYYY-MM-DD",
+			"time":     timeFormat,
+			"timeZone": "UTC",
+		},
+	}
+}
+
+func numberFormatting(precision int) map[string]any {
+	return map[string]any{
+		"formatting": map[string]any{
+			"type":      "decimal",
+			"precision": precision,
+		},
+	}
+}
+
+func teableFieldFor(column tabular.Column) teableField {
+	field := teableField{Name: column.Name}
+
+	switch column.Type {
+	case tabular.LongText:
+		field.Type = "longText"
+	case tabular.Number:
+		field.Type = "number"
+		field.Options = numberFormatting(0)
+	case tabular.Decimal:
+		field.Type = "number"
+		field.Options = numberFormatting(2)
+	case tabular.Checkbox:
+		field.Type = "checkbox"
+	case tabular.DateOnly:
+		field.Type = "date"
+		field.Options = dateFormatting(false)
+	case tabular.DateTime:
+		field.Type = "date"
+		field.Options = dateFormatting(true)
+	case tabular.URL:
+		field.Type = "singleLineText"
+		field.Options = map[string]any{"showAs": map[string]any{"type": "url"}}
+	case tabular.SingleSelect:
+		field.Type = "singleSelect"
+		choices := make([]teableChoice, len(column.Choices))
+		for i, choice := range column.Choices {
+			choices[i] = teableChoice{Name: choice.Name}
+		}
+		field.Options = map[string]any{"choices": choices}
+	default:
+		field.Type = "singleLineText"
+	}
+
+	return field
+}
+
+func (b *Backend) CreateTable(table tabular.Table) (*tabular.TableInfo, error) {
+	fields := make([]teableField, len(table.Columns))
+	for i, column := range table.Columns {
+		fields[i] = teableFieldFor(column)
+	}
+
+	// The first field becomes the primary field, which is always ct_id
+	var created teableTable
+	err := b.client.do("POST", "/api/base/"+b.baseID+"/table/", map[string]any{
+		"name":         table.Name,
+		"description":  table.Description,
+		"fields":       fields,
+		"records":      []teableRecord{},
+		"fieldKeyType": "name",
+	}, &created)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create table %s: %w", table.Name, err)
+	}
+
+	info := &tabular.TableInfo{ID: created.ID, Name: created.Name, Columns: make(map[string]string)}
+	for _, field := range created.Fields {
+		info.Columns[field.Name] = field.ID
+	}
+
+	return info, nil
+}
+
+func (b *Backend) UpdateColumn(tableID, columnID string, column tabular.Column) error {
+	err := b.client.do("PUT", "/api/table/"+tableID+"/field/"+columnID+"/convert", teableFieldFor(column), nil)
+	if err != nil {
+		return fmt.Errorf("failed to update field %s: %w", column.Name, err)
+	}
+	return nil
+}
+
+// CreateLink returns the id of the symmetric field Teable adds to the child
+// table, which is the side that gets written when records are linked.
+func (b *Backend) CreateLink(parentTableID, childTableID, name string) (string, error) {
+	var field struct {
+		ID      string `json:"id"`
+		Options struct {
+			SymmetricFieldID string `json:"symmetricFieldId"`
+		} `json:"options"`
+	}
+
+	err := b.client.do("POST", "/api/table/"+parentTableID+"/field", teableField{
+		Name: name,
+		Type: "link",
+		Options: map[string]any{
+			"relationship":   "oneMany",
+			"foreignTableId": childTableID,
+		},
+	}, &field)
+	if err != nil {
+		return "", fmt.Errorf("failed to create link %s: %w", name, err)
+	}
+
+	if field.Options.SymmetricFieldID == "" {
+		return "", fmt.Errorf("link %s has no symmetric field", name)
+	}
+
+	return field.Options.SymmetricFieldID, nil
+}
+
+func (b *Backend) LinkRecords(parentTableID, childTableID, linkID, parentID string, childIDs []string) error {
+	records := make([]teableRecord, len(childIDs))
+	for i, childID := range childIDs {
+		records[i] = teableRecord{ID: childID, Fields: map[string]any{
+			linkID: map[string]string{"id": parentID},
+		}}
+	}
+
+	return b.client.do("PATCH", "/api/table/"+childTableID+"/record", map[string]any{
+		"fieldKeyType": "id",
+		"records":      records,
+	}, nil)
+}
+
+func (b *Backend) CreateRecords(tableID string, records []tabular.Record) (map[string]string, error) {
+	req := make([]teableRecord, len(records))
+	for i, record := range records {
+		req[i] = teableRecord{Fields: teableFields(record.Fields)}
+	}
+
+	var result struct {
+		Records []teableRecord `json:"records"`
+	}
+	err := b.client.do("POST", "/api/table/"+tableID+"/record", map[string]any{
+		"fieldKeyType": "name",
+		"typecast":     true,
+		"records":      req,
+	}, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create records: %w", err)
+	}
+
+	ids := make(map[string]string, len(result.Records))
+	for _, record := range result.Records {
+		ctID, ok := record.Fields["ct_id"].(string)
+		if !ok {
+			return nil, fmt.Errorf("created record %s has no ct_id", record.ID)
+		}
+		ids[ctID] = record.ID
+	}
+	return ids, nil
+}
+
+func (b *Backend) UpdateRecords(tableID string, records []tabular.Record) error {
+	req := make([]teableRecord, len(records))
+	for i, record := range records {
+		req[i] = teableRecord{ID: record.ID, Fields: teableFields(record.Fields)}
+	}
+
+	err := b.client.do("PATCH", "/api/table/"+tableID+"/record", map[string]any{
+		"fieldKeyType": "name",
+		"typecast":     true,
+		"records":      req,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update records: %w", err)
+	}
+	return nil
+}
+
+func (b *Backend) DeleteRecords(tableID string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := url.Values{}
+	for _, id := range ids {
+		query.Add("recordIds", id)
+	}
+
+	if err := b.client.do("DELETE", "/api/table/"+tableID+"/record?"+query.Encode(), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete records: %w", err)
+	}
+	return nil
+}
+
+func teableFields(fields map[string]any) map[string]any {
+	out := make(map[string]any, len(fields))
+	for name, value := range fields {
+		switch v := value.(type) {
+		case tabular.Date:
+			out[name] = v.String() + "T00:00:00.000Z"
+		case time.Time:
+			out[name] = v.UTC().Format(time.RFC3339)
+		default:
+			out[name] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package teable
+
+type teableField struct {
+	ID      string         `json:"id,omitempty"`
+	Name    string         `json:"name"`
+	Type    string         `json:"type"`
+	Options map[string]any `json:"options,omitempty"`
+}
+
+type teableTable struct {
+	ID     string        `json:"id"`
+	Name   string        `json:"name"`
+	Fields []teableField `json:"fields"`
+}
+
+type teableRecord struct {
+	ID     string         `json:"id,omitempty"`
+	Fields map[string]any `json:"fields"`
+}
+
+type teableChoice struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package warehouse
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
+
+type columnType int
+
+const (
+	colID columnType = iota
+	colText
+	// colName is a short text key, since MySQL cannot index long VARCHARs
+	colName
+	colLongText
+	colInt
+	colFloat
+	colBool
+	colDate
+	colTimestamp
+)
+
+// dialect holds everything that differs between the supported warehouses. Table
+// names are always qualified with the schema the target was set up with.
+type dialect struct {
+	name        string
+	driver      string
+	types       map[columnType]string
+	placeholder func(n int) string
+	quote       func(name string) string
+	// upsertTail finishes a multi-row INSERT so rows whose key already exists are updated
+	upsertTail func(keys, columns []string) string
+	// commentTable and commentColumn return nil when comments are inlined in CREATE TABLE
+	commentTable  func(table, comment string) []string
+	commentColumn func(table, column, comment string) []string
+	inlineComment func(comment string) string
+}
+
+var validSchema = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,62}$`)
+
+// ValidSchemaName reports whether name can be used as the warehouse schema
+func ValidSchemaName(name string) bool {
+	return validSchema.MatchString(name)
+}
+
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+var postgresDialect = dialect{
+	name:   "PostgreSQL",
+	driver: "postgres",
+	types: map[columnType]string{
+		colID:        "UUID",
+		colText:      "TEXT",
+		colName:      "TEXT",
+		colLongText:  "TEXT",
+		colInt:       "BIGINT",
+		colFloat:     "DOUBLE PRECISION",
+		colBool:      "BOOLEAN",
+		colDate:      "DATE",
+		colTimestamp: "TIMESTAMPTZ",
+	},
+	placeholder: func(n int) string {
+		return fmt.Sprintf("$%d", n)
+	},
+	quote: func(name string) string {
+		return `"` + name + `"`
+	},
+	upsertTail: func(keys, columns []string) string {
+		var sets []string
+		for _, c := range columns {
+			sets = append(sets, fmt.Sprintf(`"%s" = EXCLUDED."%s"`, c, c))
+		}
+		return fmt.Sprintf(` ON CONFLICT ("%s") DO UPDATE SET %s`, strings.Join(keys, `", "`), strings.Join(sets, ", "))
+	},
+	commentTable: func(table, comment string) []string {
+		return []string{fmt.Sprintf("COMMENT ON TABLE %s IS %s", table, sqlString(comment))}
+	},
+	commentColumn: func(table, column, comment string) []string {
+		return []string{fmt.Sprintf(`COMMENT ON COLUMN %s."%s" IS %s`, table, column, sqlString(comment))}
+	},
+	inlineComment: func(comment string) string {
+		return ""
+	},
+}
+
+var mysqlDialect = dialect{
+	name:   "MySQL",
+	driver: "mysql",
+	types: map[columnType]string{
+		colID:        "CHAR(36)",
+		colText:      "VARCHAR(1024)",
+		colName:      "VARCHAR(64)",
+		colLongText:  "MEDIUMTEXT",
+		colInt:       "BIGINT",
+		colFloat:     "DOUBLE",
+		colBool:      "BOOLEAN",
+		colDate:      "DATE",
+		colTimestamp: "DATETIME",
+	},
+	placeholder: func(n int) string {
+		return "?"
+	},
+	quote: func(name string) string {
+		return "`" + name + "`"
+	},
+	upsertTail: func(keys, columns []string) string {
+		var sets []string
+		for _, c := range columns {
+			sets = append(sets, fmt.Sprintf("`%s` = VALUES(`%s`)", c, c))
+		}
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	},
+	commentTable: func(table, comment string) []string {
+		return []string{fmt.Sprintf("ALTER TABLE %s COMMENT = %s", table, sqlString(comment))}
+	},
+	commentColumn: func(table, column, comment string) []string {
+		return nil
+	},
+	inlineComment: func(comment string) string {
+		return " COMMENT " + sqlString(comment)
+	},
+}
+
+func dialectFor(targetType string) (dialect, error) {
+	switch targetType {
+	case "PostgreSQL":
+		return postgresDialect, nil
+	case "MySQL":
+		return mysqlDialect, nil
+	}
+	return dialect{}, fmt.Errorf("unsupported warehouse type: %s", targetType)
+}
+
+// dsn adjusts the connection string so values come back in the types the sync
+// expects. MySQL needs parseTime for DATE columns and UTC for timestamps.
+func (d dialect) dsn(connection string) (string, error) {
+	if d.driver != "mysql" {
+		return connection, nil
+	}
+
+	cfg, err := mysql.ParseDSN(connection)
+	if err != nil {
+		return "", fmt.Errorf("invalid MySQL connection string: %w", err)
+	}
+	cfg.ParseTime = true
+	cfg.Loc = time.UTC
+	return cfg.FormatDSN(), nil
+}
+
+func (d dialect) table(schema, name string) string {
+	return d.quote(schema) + "." + d.quote(name)
+}
@@ -0,0 +1,23 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package warehouse
+
+import "testing"
+
+func TestUpsertTail(t *testing.T) {
+	keys := []string{"post_id", "date"}
+	columns := []string{"likes", "views"}
+
+	tests := []struct {
+		d    dialect
+		want string
+	}{
+		{postgresDialect, ` ON CONFLICT ("post_id", "date") DO UPDATE SET "likes" = EXCLUDED."likes", "views" = EXCLUDED."views"`},
+		{mysqlDialect, " ON DUPLICATE KEY UPDATE `likes` = VALUES(`likes`), `views` = VALUES(`views`)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.d.upsertTail(keys, columns); got != tt.want {
+			t.Errorf("%s upsert tail is %s, want %s", tt.d.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package warehouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+type column struct {
+	name    string
+	typ     columnType
+	comment string
+}
+
+type tableDef struct {
+	name    string
+	comment string
+	keys    []string
+	columns []column
+}
+
+func (t tableDef) columnNames() []string {
+	names := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// The warehouse schema is a contract with downstream dashboards: columns are only
+// ever added, never renamed, retyped or dropped. Changes go in a new migration.
+var (
+	dimSources = tableDef{
+		name:    "dim_sources",
+		comment: "One row per social media or website source tracked in RPSync",
+		keys:    []string{"source_id"},
+		columns: []column{
+			{"source_id", colID, "Stable RPSync source ID"},
+			{"network", colText, "Platform name, e.g. Instagram or Google Analytics"},
+			{"username", colText, "Current account name on the platform"},
+			{"profile_url", colText, "Link to the account"},
+			{"is_active", colBool, "Whether RPSync still syncs this source"},
+			{"created_at", colTimestamp, "When the source was added to RPSync (UTC)"},
+			{"last_synced_at", colTimestamp, "Last successful fetch from the platform (UTC)"},
+			{"loaded_at", colTimestamp, "When this row was last written by RPSync (UTC)"},
+		},
+	}
+
+	dimPosts = tableDef{
+		name:    "dim_posts",
+		comment: "One row per post; counters live in fact_post_reactions_daily",
+		keys:    []string{"post_id"},
+		columns: []column{
+			{"post_id", colID, "Stable RPSync post ID"},
+			{"source_id", colID, "Source the post belongs to, see dim_sources"},
+			{"network", colText, "Platform name"},
+			{"network_internal_id", colText, "Post ID on the platform"},
+			{"post_type", colText, "Platform specific type, e.g. post, reel, repost"},
+			{"author", colText, "Account that published the post"},
+			{"content", colLongText, "Post text or title"},
+			{"post_url", colText, "Link to the post"},
+			{"is_archived", colBool, "Whether the post was removed from the platform"},
+			{"created_at", colTimestamp, "When the post was published (UTC)"},
+			{"loaded_at", colTimestamp, "When this row was last written by RPSync (UTC)"},
+		},
+	}
+
+	factPostReactions = tableDef{
+		name:    "fact_post_reactions_daily",
+		comment: "Latest reaction counters seen for each post on each day",
+		keys:    []string{"post_id", "date"},
+		columns: []column{
+			{"post_id", colID, "See dim_posts"},
+			{"date", colDate, "Day the counters were captured (UTC)"},
+			{"source_id", colID, "See dim_sources"},
+			{"likes", colInt, "Total likes on that day"},
+			{"reposts", colInt, "Total reposts on that day"},
+			{"views", colInt, "Total views on that day"},
+			{"comments", colInt, "Total comments on that day, where the platform reports them"},
+			{"quotes", colInt, "Total quotes on that day, where the platform reports them"},
+			{"bookmarks", colInt, "Total bookmarks on that day, where the platform reports them"},
+		},
+	}
+
+	factSourceStats = tableDef{
+		name:    "fact_source_stats_daily",
+		comment: "Daily account level statistics for each source",
+		keys:    []string{"stat_id"},
+		columns: []column{
+			{"stat_id", colID, "Stable RPSync statistic ID"},
+			{"source_id", colID, "See dim_sources"},
+			{"date", colDate, "Day of the statistic"},
+			{"followers_count", colInt, "Followers at the end of the day"},
+			{"following_count", colInt, "Accounts followed at the end of the day"},
+			{"posts_count", colInt, "Posts published by the account"},
+			{"average_likes", colFloat, "Average likes per post"},
+			{"average_reposts", colFloat, "Average reposts per post"},
+			{"average_views", colFloat, "Average views per post"},
+		},
+	}
+
+	factSiteStats = tableDef{
+		name:    "fact_site_stats_daily",
+		comment: "Daily website totals from analytics sources",
+		keys:    []string{"stat_id"},
+		columns: []column{
+			{"stat_id", colID, "Stable RPSync statistic ID"},
+			{"source_id", colID, "See dim_sources"},
+			{"date", colDate, "Day of the statistic"},
+			{"visitors", colInt, "Unique visitors"},
+			{"avg_session_duration", colFloat, "Average session duration in seconds"},
+		},
+	}
+
+	factPageStats = tableDef{
+		name:    "fact_page_stats_daily",
+		comment: "Daily page views per path from analytics sources",
+		keys:    []string{"stat_id"},
+		columns: []column{
+			{"stat_id", colID, "Stable RPSync statistic ID"},
+			{"source_id", colID, "See dim_sources"},
+			{"date", colDate, "Day of the statistic"},
+			{"page_path", colText, "URL path of the page"},
+			{"views", colInt, "Page views"},
+		},
+	}
+)
+
+// syncState is bookkeeping rather than data: how far each incremental load got.
+// Deleting a row makes the next sync reload that table in full.
+var syncState = tableDef{
+	name:    "rpsync_sync_state",
+	comment: "How far RPSync's incremental loads got; delete a row to reload its table",
+	keys:    []string{"name"},
+	columns: []column{
+		{"name", colName, "Table the load writes"},
+		{"loaded_through", colTimestamp, "Latest change in RPSync already loaded (UTC)"},
+	},
+}
+
+// migration brings the warehouse schema from version-1 to version. Migrations are
+// append-only; SchemaVersion is the version of the last one.
+type migration struct {
+	version int
+	apply   func(d dialect, schema string) []string
+}
+
+var migrations = []migration{
+	{1, func(d dialect, schema string) []string {
+		var stmts []string
+		for _, t := range []tableDef{dimSources, dimPosts, factPostReactions, factSourceStats, factSiteStats, factPageStats} {
+			stmts = append(stmts, createTable(d, schema, t)...)
+		}
+		stmts = append(stmts, createIndex(d, schema, factPostReactions.name, "source_id", "date"))
+		stmts = append(stmts, createIndex(d, schema, factSourceStats.name, "source_id", "date"))
+		stmts = append(stmts, createIndex(d, schema, factSiteStats.name, "source_id", "date"))
+		stmts = append(stmts, createIndex(d, schema, factPageStats.name, "source_id", "date"))
+		return stmts
+	}},
+	{2, func(d dialect, schema string) []string {
+		return createTable(d, schema, syncState)
+	}},
+}
+
+var SchemaVersion = migrations[len(migrations)-1].version
+
+const versionTable = "rpsync_schema_version"
+
+func createTable(d dialect, schema string, t tableDef) []string {
+	name := d.table(schema, t.name)
+
+	var defs []string
+	for _, c := range t.columns {
+		def := d.quote(c.name) + " " + d.types[c.typ]
+		for _, k := range t.keys {
+			if k == c.name {
+				def += " NOT NULL"
+			}
+		}
+		defs = append(defs, def+d.inlineComment(c.comment))
+	}
+
+	keys := make([]string, len(t.keys))
+	for i, k := range t.keys {
+		keys[i] = d.quote(k)
+	}
+	defs = append(defs, "PRIMARY KEY ("+strings.Join(keys, ", ")+")")
+
+	stmts := []string{fmt.Sprintf("CREATE TABLE %s (\n    %s\n)", name, strings.Join(defs, ",\n    "))}
+	stmts = append(stmts, d.commentTable(name, t.comment)...)
+	for _, c := range t.columns {
+		stmts = append(stmts, d.commentColumn(name, c.name, c.comment)...)
+	}
+	return stmts
+}
+
+func createIndex(d dialect, schema, table string, columns ...string) string {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = d.quote(c)
+	}
+	index := d.quote(table + "_" + strings.Join(columns, "_") + "_idx")
+	return fmt.Sprintf("CREATE INDEX %s ON %s (%s)", index, d.table(schema, table), strings.Join(quoted, ", "))
+}
+
+// migrate creates the schema if needed and applies every migration the warehouse
+// has not seen yet, recording each in rpsync_schema_version.
+func (w *warehouse) migrate() error {
+	ctx := context.Background()
+
+	var exists int
+	err := w.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM information_schema.schemata WHERE schema_name = "+w.d.placeholder(1), w.schema,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check schema: %w", err)
+	}
+	if exists == 0 {
+		stmt := "CREATE SCHEMA " + w.d.quote(w.schema)
+		if _, err := w.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to create schema %s: %w", w.schema, err)
+		}
+	}
+
+	versions := w.d.table(w.schema, versionTable)
+	_, err = w.db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (%s INTEGER NOT NULL PRIMARY KEY, %s %s NOT NULL)",
+		versions, w.d.quote("version"), w.d.quote("applied_at"), w.d.types[colTimestamp],
+	))
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", versionTable, err)
+	}
+
+	var current sql.NullInt64
+	if err := w.db.QueryRowContext(ctx, "SELECT MAX("+w.d.quote("version")+") FROM "+versions).Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	if current.Int64 > int64(SchemaVersion) {
+		return fmt.Errorf("warehouse schema is at version %d, newer than this RPSync release supports (%d)", current.Int64, SchemaVersion)
+	}
+
+	for _, m := range migrations {
+		if int64(m.version) <= current.Int64 {
+			continue
+		}
+
+		log.Printf("Applying warehouse schema version %d to %s target %s", m.version, w.d.name, w.schema)
+
+		if err := w.applyMigration(ctx, m, versions); err != nil {
+			return fmt.Errorf("schema version %d: %w", m.version, err)
+		}
+	}
+
+	return nil
+}
+
+// applyMigration runs in a transaction so a failed PostgreSQL migration can simply
+// be retried. MySQL commits DDL implicitly, so there a failure may need cleanup.
+func (w *warehouse) applyMigration(ctx context.Context, m migration, versions string) error {
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range m.apply(w.d, w.schema) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO "+versions+" VALUES ("+w.d.placeholder(1)+", "+w.d.placeholder(2)+")",
+		m.version, time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record version: %w", err)
+	}
+
+	return tx.Commit()
+}
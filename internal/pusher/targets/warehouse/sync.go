@@ -0,0 +1,483 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package warehouse
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/helpers"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets"
+	"github.com/google/uuid"
+)
+
+// reactionsLoadOverlap is how far before the previous run's newest history row
+// the next run starts reading
+const reactionsLoadOverlap = time.Minute
+
+func SyncWarehouse(dbQueries *database.Queries, encryptionKey []byte, target database.Target) error {
+
+	w, err := openWarehouse(dbQueries, encryptionKey, target)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	steps := []struct {
+		name string
+		sync func(*warehouse, *database.Queries, database.Target) error
+	}{
+		{dimSources.name, syncSources},
+		{dimPosts.name, syncPosts},
+		{factPostReactions.name, syncReactions},
+		{factSourceStats.name, syncSourcesStats},
+		{factSiteStats.name, syncSiteStats},
+		{factPageStats.name, syncPageStats},
+	}
+
+	for _, step := range steps {
+		if err := step.sync(w, dbQueries, target); err != nil {
+			return fmt.Errorf("failed to sync %s: %w", step.name, err)
+		}
+	}
+
+	return nil
+}
+
+func syncSources(w *warehouse, dbQueries *database.Queries, target database.Target) error {
+	userSources, err := dbQueries.GetUserSources(context.Background(), target.UserID)
+	if err != nil {
+		return fmt.Errorf("error fetching user sources: %w", err)
+	}
+
+	mappedSources, err := dbQueries.GetTargetSources(context.Background(), target.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching target sources: %w", err)
+	}
+
+	mappedMap := make(map[uuid.UUID]struct{}, len(mappedSources))
+	for _, m := range mappedSources {
+		mappedMap[m.SourceID] = struct{}{}
+	}
+
+	now := time.Now().UTC()
+	localSources := make(map[uuid.UUID]struct{}, len(userSources))
+	var rows [][]any
+	var newSources []database.Source
+
+	for _, source := range userSources {
+		localSources[source.ID] = struct{}{}
+
+		url, _ := helpers.ConvNetworkToURL(source.Network, source.UserName)
+		rows = append(rows, []any{
+			source.ID.String(),
+			source.Network,
+			source.UserName,
+			url,
+			source.IsActive,
+			source.CreatedAt.UTC(),
+			nullTime(source.LastSynced),
+			now,
+		})
+
+		if _, ok := mappedMap[source.ID]; !ok {
+			newSources = append(newSources, source)
+		}
+	}
+
+	if err := w.upsert(dimSources, rows); err != nil {
+		return err
+	}
+
+	for _, source := range newSources {
+		_, err := dbQueries.AddSourceToTarget(context.Background(), database.AddSourceToTargetParams{
+			ID:             uuid.New(),
+			SourceID:       source.ID,
+			TargetID:       target.ID,
+			TargetSourceID: source.ID.String(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, m := range mappedSources {
+		if _, ok := localSources[m.SourceID]; ok {
+			continue
+		}
+		if err := deleteSourceRows(w, m.TargetSourceID); err != nil {
+			return err
+		}
+		err := dbQueries.DeleteSourceTarget(context.Background(), database.DeleteSourceTargetParams{
+			TargetID: target.ID,
+			SourceID: m.SourceID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete source target mapping: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// syncPosts rewrites every post, since titles, authors and the archived flag can
+// change at any time; counters are loaded separately as facts.
+func syncPosts(w *warehouse, dbQueries *database.Queries, target database.Target) error {
+	posts, err := dbQueries.GetAllPostsWithTheLatestInfoForUser(context.Background(), target.UserID)
+	if err != nil {
+		return err
+	}
+
+	mappedPosts, err := dbQueries.GetPostsPreviouslySynced(context.Background(), target.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching mapped posts: %w", err)
+	}
+
+	mappedMap := make(map[uuid.UUID]struct{}, len(mappedPosts))
+	var removePosts []database.PostsOnTarget
+	for _, m := range mappedPosts {
+		if !m.PostID.Valid {
+			removePosts = append(removePosts, m)
+		} else {
+			mappedMap[m.PostID.UUID] = struct{}{}
+		}
+	}
+
+	now := time.Now().UTC()
+	localMap := make(map[uuid.UUID]struct{}, len(posts))
+	var rows [][]any
+	var newPosts []uuid.UUID
+
+	for _, post := range posts {
+		localMap[post.ID] = struct{}{}
+
+		url, err := helpers.ConvPostToURL(post.Network.String, post.Author, post.NetworkInternalID)
+		if err != nil {
+			return err
+		}
+
+		rows = append(rows, []any{
+			post.ID.String(),
+			post.SourceID.String(),
+			post.Network.String,
+			post.NetworkInternalID,
+			post.PostType,
+			post.Author,
+			post.Content.String,
+			url,
+			post.IsArchived,
+			post.CreatedAt.UTC(),
+			now,
+		})
+
+		if _, ok := mappedMap[post.ID]; !ok {
+			newPosts = append(newPosts, post.ID)
+		}
+	}
+
+	for _, m := range mappedPosts {
+		if m.PostID.Valid {
+			if _, ok := localMap[m.PostID.UUID]; !ok {
+				removePosts = append(removePosts, m)
+			}
+		}
+	}
+
+	if err := w.upsert(dimPosts, rows); err != nil {
+		return err
+	}
+
+	for _, id := range newPosts {
+		_, err := dbQueries.AddPostToTarget(context.Background(), database.AddPostToTargetParams{
+			ID:            uuid.New(),
+			FirstSyncedAt: time.Now(),
+			PostID:        uuid.NullUUID{UUID: id, Valid: true},
+			TargetID:      target.ID,
+			TargetPostID:  id.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to map post: %w", err)
+		}
+	}
+
+	removeIDs := make([]string, len(removePosts))
+	for i, post := range removePosts {
+		removeIDs[i] = post.TargetPostID
+	}
+
+	if err := w.deleteWhere(factPostReactions, "post_id", removeIDs); err != nil {
+		return err
+	}
+	if err := w.deleteWhere(dimPosts, "post_id", removeIDs); err != nil {
+		return err
+	}
+
+	for _, post := range removePosts {
+		if err := dbQueries.DeletePostOnTarget(context.Background(), post.ID); err != nil {
+			log.Printf("Warning: Failed to delete posts_on_target mapping: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// syncReactions loads history rows written since the last run, whatever day
+// they are for, so backfilled days from imports reach the warehouse too.
+func syncReactions(w *warehouse, dbQueries *database.Queries, target database.Target) error {
+	through, err := w.loadedThrough(factPostReactions.name)
+	if err != nil {
+		return err
+	}
+
+	// A row written while the previous run was reading may carry an earlier
+	// timestamp than the newest one it saw, so the window overlaps a little
+	since := time.Time{}
+	if through.Valid {
+		since = through.Time.Add(-reactionsLoadOverlap)
+	}
+
+	history, err := dbQueries.GetReactionsHistoryForUserRecordedAfter(context.Background(), database.GetReactionsHistoryForUserRecordedAfterParams{
+		UserID:     target.UserID,
+		RecordedAt: since,
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching reactions history: %w", err)
+	}
+	if len(history) == 0 {
+		return nil
+	}
+
+	rows := make([][]any, len(history))
+	for i, h := range history {
+		rows[i] = []any{
+			h.PostID.String(),
+			day(h.SyncedAt),
+			h.SourceID.String(),
+			nullInt(h.Likes),
+			nullInt(h.Reposts),
+			nullInt(h.Views),
+			nullInt(h.Comments),
+			nullInt(h.Quotes),
+			nullInt(h.Bookmarks),
+		}
+	}
+
+	if err := w.upsert(factPostReactions, rows); err != nil {
+		return err
+	}
+
+	return w.setLoadedThrough(factPostReactions.name, history[len(history)-1].RecordedAt)
+}
+
+func syncSourcesStats(w *warehouse, dbQueries *database.Queries, target database.Target) error {
+	sources, err := dbQueries.GetTargetSources(context.Background(), target.ID)
+	if err != nil {
+		return err
+	}
+
+	dateThreshold := time.Now().AddDate(0, 0, -2)
+
+	for _, source := range sources {
+		syncedStats, err := dbQueries.GetSyncedSourcesStatsForUpdate(context.Background(), database.GetSyncedSourcesStatsForUpdateParams{
+			TargetID: target.ID,
+			SourceID: source.SourceID,
+			Date:     dateThreshold,
+		})
+		if err != nil {
+			return err
+		}
+
+		unsyncedStats, err := dbQueries.GetUnsyncedSourcesStatsForTarget(context.Background(), database.GetUnsyncedSourcesStatsForTargetParams{
+			SourceID: source.SourceID,
+			TargetID: target.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		var rows [][]any
+		for _, stat := range syncedStats {
+			rows = append(rows, []any{
+				stat.ID.String(), stat.SourceID.String(), day(stat.Date),
+				nullInt(stat.FollowersCount), nullInt(stat.FollowingCount), nullInt(stat.PostsCount),
+				nullFloat(stat.AverageLikes), nullFloat(stat.AverageReposts), nullFloat(stat.AverageViews),
+			})
+		}
+		for _, stat := range unsyncedStats {
+			rows = append(rows, []any{
+				stat.ID.String(), stat.SourceID.String(), day(stat.Date),
+				nullInt(stat.FollowersCount), nullInt(stat.FollowingCount), nullInt(stat.PostsCount),
+				nullFloat(stat.AverageLikes), nullFloat(stat.AverageReposts), nullFloat(stat.AverageViews),
+			})
+		}
+
+		if err := w.upsert(factSourceStats, rows); err != nil {
+			return err
+		}
+
+		for _, stat := range unsyncedStats {
+			_, err := dbQueries.AddSourcesStatToTarget(context.Background(), database.AddSourcesStatToTargetParams{
+				ID:             uuid.New(),
+				SyncedAt:       time.Now(),
+				StatID:         stat.ID,
+				TargetID:       target.ID,
+				TargetRecordID: stat.ID.String(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to map sources stat: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func syncSiteStats(w *warehouse, dbQueries *database.Queries, target database.Target) error {
+	sources, err := dbQueries.GetTargetSources(context.Background(), target.ID)
+	if err != nil {
+		return err
+	}
+
+	dateThreshold := time.Now().AddDate(0, 0, -9)
+
+	for _, source := range sources {
+		syncedStats, err := dbQueries.GetSyncedSiteStatsForUpdate(context.Background(), database.GetSyncedSiteStatsForUpdateParams{
+			TargetID: target.ID,
+			SourceID: source.SourceID,
+			Date:     dateThreshold,
+		})
+		if err != nil {
+			return err
+		}
+
+		unsyncedStats, err := dbQueries.GetUnsyncedSiteStatsForTarget(context.Background(), database.GetUnsyncedSiteStatsForTargetParams{
+			TargetID: target.ID,
+			SourceID: source.SourceID,
+		})
+		if err != nil {
+			return err
+		}
+
+		var rows [][]any
+		for _, stat := range syncedStats {
+			rows = append(rows, []any{stat.ID.String(), source.SourceID.String(), day(stat.Date), stat.Visitors, stat.AvgSessionDuration})
+		}
+		for _, stat := range unsyncedStats {
+			rows = append(rows, []any{stat.ID.String(), source.SourceID.String(), day(stat.Date), stat.Visitors, stat.AvgSessionDuration})
+		}
+
+		if err := w.upsert(factSiteStats, rows); err != nil {
+			return err
+		}
+
+		for _, stat := range unsyncedStats {
+			_, err := dbQueries.AddAnalyticsSiteStatToTarget(context.Background(), database.AddAnalyticsSiteStatToTargetParams{
+				ID:             uuid.New(),
+				SyncedAt:       time.Now(),
+				StatID:         uuid.NullUUID{UUID: stat.ID, Valid: true},
+				TargetID:       target.ID,
+				TargetRecordID: stat.ID.String(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to map site stat: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func syncPageStats(w *warehouse, dbQueries *database.Queries, target database.Target) error {
+	sources, err := dbQueries.GetTargetSources(context.Background(), target.ID)
+	if err != nil {
+		return err
+	}
+
+	dateThreshold := time.Now().AddDate(0, 0, -9)
+
+	for _, source := range sources {
+		syncedStats, err := dbQueries.GetSyncedPageStatsForUpdate(context.Background(), database.GetSyncedPageStatsForUpdateParams{
+			TargetID: target.ID,
+			SourceID: source.SourceID,
+			Date:     dateThreshold,
+		})
+		if err != nil {
+			return err
+		}
+
+		unsyncedStats, err := dbQueries.GetUnsyncedPageStatsForTarget(context.Background(), database.GetUnsyncedPageStatsForTargetParams{
+			TargetID: target.ID,
+			SourceID: source.SourceID,
+		})
+		if err != nil {
+			return err
+		}
+
+		var rows [][]any
+		for _, stat := range syncedStats {
+			rows = append(rows, []any{stat.ID.String(), source.SourceID.String(), day(stat.Date), stat.UrlPath, stat.Views})
+		}
+		for _, stat := range unsyncedStats {
+			rows = append(rows, []any{stat.ID.String(), source.SourceID.String(), day(stat.Date), stat.UrlPath, stat.Views})
+		}
+
+		if err := w.upsert(factPageStats, rows); err != nil {
+			return err
+		}
+
+		for _, stat := range unsyncedStats {
+			_, err := dbQueries.AddAnalyticsPageStatToTarget(context.Background(), database.AddAnalyticsPageStatToTargetParams{
+				ID:             uuid.New(),
+				SyncedAt:       time.Now(),
+				StatID:         uuid.NullUUID{UUID: stat.ID, Valid: true},
+				TargetID:       target.ID,
+				TargetRecordID: stat.ID.String(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to map page stat: %w", err)
+			}
+		}
+	}
+
+	return targets.RemoveOrphanedPageStats(dbQueries, target.ID, func(statIDs []string) error {
+		return w.deleteWhere(factPageStats, "stat_id", statIDs)
+	})
+}
+
+// deleteSourceRows removes a source and everything loaded for it
+func deleteSourceRows(w *warehouse, sourceID string) error {
+	for _, t := range []tableDef{factPostReactions, factSourceStats, factSiteStats, factPageStats, dimPosts} {
+		if err := w.deleteWhere(t, "source_id", []string{sourceID}); err != nil {
+			return err
+		}
+	}
+	return w.deleteWhere(dimSources, "source_id", []string{sourceID})
+}
+
+func DeletePostsAndSourceWarehouse(dbQueries *database.Queries, encryptionKey []byte, target database.Target, source database.Source) error {
+
+	w, err := openWarehouse(dbQueries, encryptionKey, target)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	if err := deleteSourceRows(w, source.ID.String()); err != nil {
+		return err
+	}
+
+	err = dbQueries.DeletePostsOnTargetAndSource(context.Background(), database.DeletePostsOnTargetAndSourceParams{
+		TargetID: target.ID,
+		SourceID: source.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return dbQueries.DeleteSourceTarget(context.Background(), database.DeleteSourceTargetParams{
+		TargetID: target.ID,
+		SourceID: source.ID,
+	})
+}
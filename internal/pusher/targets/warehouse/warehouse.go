@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package warehouse
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+)
+
+// Keeps every batch well under PostgreSQL's 65535 bind parameter limit
+const batchRows = 500
+
+type warehouse struct {
+	db     *sql.DB
+	d      dialect
+	schema string
+}
+
+// openWarehouse connects with the connection string stored as the target's token
+// and makes sure the schema is at the current version.
+func openWarehouse(dbQueries *database.Queries, encryptionKey []byte, target database.Target) (*warehouse, error) {
+	d, err := dialectFor(target.TargetType)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := target.DbID.String
+	if !ValidSchemaName(schema) {
+		return nil, fmt.Errorf("invalid schema name %q", schema)
+	}
+
+	connection, _, _, err := authhelp.GetTargetToken(context.Background(), dbQueries, encryptionKey, target.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection string: %w", err)
+	}
+
+	dsn, err := d.dsn(connection)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(d.driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s connection: %w", d.name, err)
+	}
+	db.SetMaxOpenConns(2)
+	db.SetConnMaxLifetime(5 * time.Minute)
+
+	if err := db.PingContext(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to %s: %w", d.name, err)
+	}
+
+	w := &warehouse{db: db, d: d, schema: schema}
+	if err := w.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *warehouse) Close() error {
+	return w.db.Close()
+}
+
+// upsert writes rows in batches, replacing any row whose key already exists.
+// Each row holds one value per column of t, in order.
+func (w *warehouse) upsert(t tableDef, rows [][]any) error {
+	columns := t.columnNames()
+
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = w.d.quote(c)
+	}
+
+	var updates []string
+	for _, c := range columns {
+		isKey := false
+		for _, k := range t.keys {
+			if k == c {
+				isKey = true
+			}
+		}
+		if !isKey {
+			updates = append(updates, c)
+		}
+	}
+
+	prefix := "INSERT INTO " + w.d.table(w.schema, t.name) + " (" + strings.Join(quoted, ", ") + ") VALUES "
+	tail := w.d.upsertTail(t.keys, updates)
+
+	for start := 0; start < len(rows); start += batchRows {
+		end := min(start+batchRows, len(rows))
+
+		var values []string
+		var args []any
+		for _, row := range rows[start:end] {
+			if len(row) != len(columns) {
+				return fmt.Errorf("%s row has %d values, expected %d", t.name, len(row), len(columns))
+			}
+			placeholders := make([]string, len(row))
+			for i := range row {
+				placeholders[i] = w.d.placeholder(len(args) + i + 1)
+			}
+			values = append(values, "("+strings.Join(placeholders, ", ")+")")
+			args = append(args, row...)
+		}
+
+		if _, err := w.db.ExecContext(context.Background(), prefix+strings.Join(values, ", ")+tail, args...); err != nil {
+			return fmt.Errorf("failed to write %s: %w", t.name, err)
+		}
+	}
+
+	return nil
+}
+
+// deleteWhere removes rows of t whose column matches any of the values
+func (w *warehouse) deleteWhere(t tableDef, column string, values []string) error {
+	for start := 0; start < len(values); start += batchRows {
+		end := min(start+batchRows, len(values))
+
+		placeholders := make([]string, end-start)
+		args := make([]any, end-start)
+		for i, v := range values[start:end] {
+			placeholders[i] = w.d.placeholder(i + 1)
+			args[i] = v
+		}
+
+		stmt := fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)",
+			w.d.table(w.schema, t.name), w.d.quote(column), strings.Join(placeholders, ", "))
+		if _, err := w.db.ExecContext(context.Background(), stmt, args...); err != nil {
+			return fmt.Errorf("failed to delete from %s: %w", t.name, err)
+		}
+	}
+
+	return nil
+}
+
+// loadedThrough returns where the named incremental load stopped, if it ran
+func (w *warehouse) loadedThrough(name string) (sql.NullTime, error) {
+	var through sql.NullTime
+	err := w.db.QueryRowContext(context.Background(),
+		"SELECT "+w.d.quote("loaded_through")+" FROM "+w.d.table(w.schema, syncState.name)+
+			" WHERE "+w.d.quote("name")+" = "+w.d.placeholder(1), name,
+	).Scan(&through)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return through, fmt.Errorf("failed to read %s load state: %w", name, err)
+	}
+	return through, nil
+}
+
+// setLoadedThrough records where the named incremental load stopped
+func (w *warehouse) setLoadedThrough(name string, through time.Time) error {
+	return w.upsert(syncState, [][]any{{name, through.UTC()}})
+}
+
+func nullInt(v sql.NullInt64) any {
+	if !v.Valid {
+		return nil
+	}
+	return v.Int64
+}
+
+func nullFloat(v sql.NullFloat64) any {
+	if !v.Valid {
+		return nil
+	}
+	return v.Float64
+}
+
+func nullTime(v sql.NullTime) any {
+	if !v.Valid {
+		return nil
+	}
+	return v.Time.UTC()
+}
+
+// day strips the time so DATE columns get the calendar day in UTC
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
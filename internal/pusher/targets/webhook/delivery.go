@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/authhelp"
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/pusher/common"
+)
+
+const (
+	deliveryTimeout = 15 * time.Second
+	// claimLease keeps a claimed delivery from being picked up by a concurrent
+	// run while it is sent
+	claimLease = 5 * time.Minute
+	// deliveryRetention is how long sent and failed deliveries stay in the log
+	deliveryRetention = 30 * 24 * time.Hour
+)
+
+// retryDelays is the wait after each failed attempt. A delivery is given up
+// after the last one.
+var retryDelays = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+	6 * time.Hour,
+}
+
+// NewSecret generates a signing secret for targets created without one
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ValidateUrl accepts absolute http and https endpoints
+func ValidateUrl(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook URL must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// Sign is the value of the X-Rpsync-Signature header: the hex HMAC-SHA256 of
+// the timestamp, a dot and the body, keyed with the target's secret
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeliverDue sends the target's pending deliveries that are due. Failures are
+// recorded on each delivery and rescheduled; the error only reports them.
+func DeliverDue(ctx context.Context, dbQueries *database.Queries, c *common.Client, encryptionKey []byte, target database.Target) error {
+	secret, _, _, err := authhelp.GetTargetToken(ctx, dbQueries, encryptionKey, target.ID)
+	if err != nil {
+		return fmt.Errorf("reading webhook secret: %w", err)
+	}
+
+	now := time.Now()
+	deliveries, err := dbQueries.ClaimDueWebhookDeliveries(ctx, database.ClaimDueWebhookDeliveriesParams{
+		LeaseUntil: now.Add(claimLease),
+		TargetID:   target.ID,
+		DueBefore:  now,
+	})
+	if err != nil {
+		return fmt.Errorf("claiming webhook deliveries: %w", err)
+	}
+
+	failed := 0
+	for _, d := range deliveries {
+		status, sendErr := send(ctx, c, target.HostUrl.String, secret, d)
+		if sendErr != nil {
+			failed++
+		}
+		if err := recordAttempt(ctx, dbQueries, d, status, sendErr); err != nil {
+			return err
+		}
+	}
+
+	err = dbQueries.DeleteWebhookDeliveriesBefore(ctx, database.DeleteWebhookDeliveriesBeforeParams{
+		TargetID:  target.ID,
+		CreatedAt: now.Add(-deliveryRetention),
+	})
+	if err != nil {
+		log.Printf("Webhook target %s: pruning delivery log: %v", target.ID, err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d webhook deliveries failed", failed, len(deliveries))
+	}
+	return nil
+}
+
+// send posts one delivery and returns the response status, 0 when there was no
+// response
+func send(ctx context.Context, c *common.Client, endpoint, secret string, d database.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "rpsync-webhook")
+	req.Header.Set("X-Rpsync-Event", d.Event)
+	req.Header.Set("X-Rpsync-Delivery", d.ID.String())
+	req.Header.Set("X-Rpsync-Timestamp", timestamp)
+	req.Header.Set("X-Rpsync-Signature", Sign(secret, timestamp, d.Payload))
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return resp.StatusCode, nil
+}
+
+func recordAttempt(ctx context.Context, dbQueries *database.Queries, d database.WebhookDelivery, status int, sendErr error) error {
+	now := time.Now()
+	attempts := d.Attempts + 1
+
+	params := database.UpdateWebhookDeliveryAttemptParams{
+		ID:             d.ID,
+		Status:         "Delivered",
+		Attempts:       attempts,
+		NextAttemptAt:  now,
+		LastAttemptAt:  sql.NullTime{Time: now, Valid: true},
+		ResponseStatus: sql.NullInt32{Int32: int32(status), Valid: status != 0},
+	}
+
+	if sendErr != nil {
+		params.LastError = sql.NullString{String: sendErr.Error(), Valid: true}
+		if int(attempts) > len(retryDelays) {
+			params.Status = "Failed"
+		} else {
+			params.Status = "Pending"
+			params.NextAttemptAt = now.Add(retryDelays[attempts-1])
+		}
+	}
+
+	if err := dbQueries.UpdateWebhookDeliveryAttempt(ctx, params); err != nil {
+		return fmt.Errorf("recording webhook delivery %s: %w", d.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package webhook
+
+import "testing"
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"post.created"}`)
+
+	// Computed independently, the way a receiver would check it
+	want := "sha256=8f0454ae1586316fc8bb42932f81497d1efae78468e51a96fc91fa5093a03739"
+	if got := Sign("whsec_test", "1767225600", body); got != want {
+		t.Errorf("signature is %s, want %s", got, want)
+	}
+
+	if Sign("whsec_test", "1767225601", body) == want {
+		t.Error("signature does not cover the timestamp")
+	}
+	if Sign("whsec_other", "1767225600", body) == want {
+		t.Error("signature does not depend on the secret")
+	}
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/helpers"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets"
+	"github.com/google/uuid"
+)
+
+// envelope is the body of every delivery
+type envelope struct {
+	ID        uuid.UUID `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+type sourceData struct {
+	ID       uuid.UUID `json:"id"`
+	Network  string    `json:"network"`
+	Username string    `json:"username"`
+}
+
+type targetData struct {
+	ID   uuid.UUID `json:"id"`
+	Type string    `json:"type"`
+	Name string    `json:"name"`
+}
+
+type postData struct {
+	ID                uuid.UUID `json:"id"`
+	NetworkInternalID string    `json:"network_internal_id"`
+	Type              string    `json:"type"`
+	Author            string    `json:"author"`
+	Content           string    `json:"content"`
+	Url               string    `json:"url,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func newSourceData(source database.Source) sourceData {
+	return sourceData{ID: source.ID, Network: source.Network, Username: source.UserName}
+}
+
+// Enqueue records one delivery of event for a webhook target. It is sent by
+// the next delivery run.
+func Enqueue(ctx context.Context, dbQueries *database.Queries, target database.Target, event string, data any) error {
+	id := uuid.New()
+	now := time.Now()
+
+	payload, err := json.Marshal(envelope{ID: id, Event: event, CreatedAt: now.UTC(), Data: data})
+	if err != nil {
+		return fmt.Errorf("encoding %s payload: %w", event, err)
+	}
+
+	_, err = dbQueries.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		ID:            id,
+		CreatedAt:     now,
+		TargetID:      target.ID,
+		Event:         event,
+		Payload:       payload,
+		NextAttemptAt: now,
+	})
+	if err != nil {
+		return fmt.Errorf("queueing %s delivery: %w", event, err)
+	}
+	return nil
+}
+
+// Emit queues event for every active webhook target of the user that is
+// subscribed to it
+func Emit(ctx context.Context, dbQueries *database.Queries, userID uuid.UUID, event string, data any) error {
+	userTargets, err := dbQueries.GetUserActiveTargets(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("fetching webhook targets: %w", err)
+	}
+
+	for _, t := range userTargets {
+		if t.TargetType != "Webhook" {
+			continue
+		}
+		settings, err := targets.ParseSettings(t)
+		if err != nil {
+			log.Printf("Webhook target %s: %v", t.ID, err)
+		}
+		if !settings.WebhookEnabled(event) {
+			continue
+		}
+		if err := Enqueue(ctx, dbQueries, t, event, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EmitNewPosts sends one post.created event per post
+func EmitNewPosts(ctx context.Context, dbQueries *database.Queries, source database.Source, posts []database.Post) error {
+	for _, p := range posts {
+		url, err := helpers.ConvPostToURL(source.Network, p.Author, p.NetworkInternalID)
+		if err != nil {
+			url = ""
+		}
+
+		err = Emit(ctx, dbQueries, source.UserID, targets.WebhookPostCreated, struct {
+			Source sourceData `json:"source"`
+			Post   postData   `json:"post"`
+		}{
+			Source: newSourceData(source),
+			Post: postData{
+				ID:                p.ID,
+				NetworkInternalID: p.NetworkInternalID,
+				Type:              p.PostType,
+				Author:            p.Author,
+				Content:           p.Content.String,
+				Url:               url,
+				CreatedAt:         p.CreatedAt.UTC(),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// milestone is the highest of 100, 250, 500, 1000, 2500, ... reached by count,
+// or 0 below 100
+func milestone(count int64) int64 {
+	var reached int64
+	for base := int64(100); base <= count; base *= 10 {
+		for _, m := range []int64{base, base * 5 / 2, base * 5} {
+			if m <= count {
+				reached = m
+			}
+		}
+	}
+	return reached
+}
+
+// EmitFollowersMilestone sends source.followers_milestone when the follower
+// count passed a milestone since the previous sync
+func EmitFollowersMilestone(ctx context.Context, dbQueries *database.Queries, source database.Source, previous, current int64) error {
+	reached := milestone(current)
+	if reached == 0 || reached <= milestone(previous) {
+		return nil
+	}
+
+	return Emit(ctx, dbQueries, source.UserID, targets.WebhookFollowersMilestone, struct {
+		Source            sourceData `json:"source"`
+		Milestone         int64      `json:"milestone"`
+		Followers         int64      `json:"followers"`
+		PreviousFollowers int64      `json:"previous_followers"`
+	}{
+		Source:            newSourceData(source),
+		Milestone:         reached,
+		Followers:         current,
+		PreviousFollowers: previous,
+	})
+}
+
+func EmitSourceSyncFailed(ctx context.Context, dbQueries *database.Queries, source database.Source, syncErr error) error {
+	return Emit(ctx, dbQueries, source.UserID, targets.WebhookSourceSyncFailed, struct {
+		Source sourceData `json:"source"`
+		Error  string     `json:"error"`
+	}{
+		Source: newSourceData(source),
+		Error:  syncErr.Error(),
+	})
+}
+
+// EmitTargetSyncFailed sends target.sync_failed. Webhook targets do not report
+// their own failures, which would only queue more deliveries to failing hooks.
+func EmitTargetSyncFailed(ctx context.Context, dbQueries *database.Queries, target database.Target, syncErr error) error {
+	if target.TargetType == "Webhook" {
+		return nil
+	}
+
+	return Emit(ctx, dbQueries, target.UserID, targets.WebhookTargetSyncFailed, struct {
+		Target targetData `json:"target"`
+		Error  string     `json:"error"`
+	}{
+		Target: targetData{ID: target.ID, Type: target.TargetType, Name: target.DbID.String},
+		Error:  syncErr.Error(),
+	})
+}
+
+// EmitPing queues a ping for one target to test its endpoint
+func EmitPing(ctx context.Context, dbQueries *database.Queries, target database.Target) error {
+	return Enqueue(ctx, dbQueries, target, targets.WebhookPing, struct {
+		Message string `json:"message"`
+	}{"Test delivery from rpsync"})
+}
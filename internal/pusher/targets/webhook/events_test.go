@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: AGPL-3.0-only
+package webhook
+
+import "testing"
+
+func TestMilestone(t *testing.T) {
+	tests := []struct {
+		count int64
+		want  int64
+	}{
+		{0, 0},
+		{99, 0},
+		{100, 100},
+		{249, 100},
+		{250, 250},
+		{499, 250},
+		{500, 500},
+		{999, 500},
+		{1000, 1000},
+		{2600, 2500},
+		{7500, 5000},
+		{1_000_000, 1_000_000},
+	}
+
+	for _, tt := range tests {
+		if got := milestone(tt.count); got != tt.want {
+			t.Errorf("milestone(%d) = %d, want %d", tt.count, got, tt.want)
+		}
+	}
+}
@@ -3,9 +3,11 @@ package stats
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/fluffyriot/rpsync/internal/database"
+	"github.com/fluffyriot/rpsync/internal/helpers"
 	"github.com/google/uuid"
 	"golang.org/x/sync/errgroup"
 )
@@ -124,6 +126,7 @@ type SummaryChart struct {
 type DashboardSummary struct {
 	Engagement SummaryChart `json:"engagement"`
 	Followers  SummaryChart `json:"followers"`
+	Supporters SummaryChart `json:"supporters"`
 }
 
 func GetDashboardSummary(dbQueries *database.Queries, userID uuid.UUID) (*DashboardSummary, error) {
@@ -132,8 +135,9 @@ func GetDashboardSummary(dbQueries *database.Queries, userID uuid.UUID) (*Dashbo
 	startDate := now.AddDate(0, 0, -13)
 
 	var (
-		engStats      []database.GetTotalDailyEngagementStatsRow
-		followerStats []database.GetTotalDailyFollowerStatsRow
+		engStats       []database.GetTotalDailyEngagementStatsRow
+		followerStats  []database.GetTotalDailyFollowerStatsRow
+		supporterStats []database.GetTotalDailySupporterStatsRow
 	)
 
 	g, ctx := errgroup.WithContext(ctx)
@@ -158,6 +162,16 @@ func GetDashboardSummary(dbQueries *database.Queries, userID uuid.UUID) (*Dashbo
 		return err
 	})
 
+	g.Go(func() error {
+		var err error
+		supporterStats, err = dbQueries.GetTotalDailySupporterStats(ctx, database.GetTotalDailySupporterStatsParams{
+			UserID:  userID,
+			Column2: startDate,
+			Column3: now,
+		})
+		return err
+	})
+
 	if err := g.Wait(); err != nil {
 		return nil, err
 	}
@@ -171,6 +185,10 @@ func GetDashboardSummary(dbQueries *database.Queries, userID uuid.UUID) (*Dashbo
 			CurrentPeriod:  make([]ChartPoint, 0),
 			PreviousPeriod: make([]ChartPoint, 0),
 		},
+		Supporters: SummaryChart{
+			CurrentPeriod:  make([]ChartPoint, 0),
+			PreviousPeriod: make([]ChartPoint, 0),
+		},
 	}
 
 	for i, stat := range engStats {
@@ -197,5 +215,156 @@ func GetDashboardSummary(dbQueries *database.Queries, userID uuid.UUID) (*Dashbo
 		}
 	}
 
+	for i, stat := range supporterStats {
+		point := ChartPoint{
+			Date:  stat.PeriodDate.Format("2006-01-02"),
+			Value: int64(stat.TotalSupporters),
+		}
+		if i < 7 {
+			summary.Supporters.PreviousPeriod = append(summary.Supporters.PreviousPeriod, point)
+		} else {
+			summary.Supporters.CurrentPeriod = append(summary.Supporters.CurrentPeriod, point)
+		}
+	}
+
 	return summary, nil
 }
+
+type NetworkReferral struct {
+	Network  string `json:"network"`
+	Sessions int64  `json:"sessions"`
+}
+
+func GetReferralStats(dbQueries *database.Queries, userID uuid.UUID) ([]NetworkReferral, error) {
+	rows, err := dbQueries.GetReferralSessionsForUser(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]int64)
+	for _, row := range rows {
+		network, ok := helpers.MatchReferrerToNetwork(row.SessionSource)
+		if !ok {
+			continue
+		}
+		totals[network] += int64(row.TotalSessions)
+	}
+
+	result := make([]NetworkReferral, 0, len(totals))
+	for _, s := range helpers.AvailableSources {
+		if sessions, ok := totals[s.Name]; ok {
+			result = append(result, NetworkReferral{
+				Network:  s.Name,
+				Sessions: sessions,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+const maxDemographicBuckets = 10
+
+type DemographicBucket struct {
+	Bucket string    `json:"bucket"`
+	Shares []float64 `json:"shares"`
+}
+
+type DemographicSeries struct {
+	SourceID  uuid.UUID           `json:"source_id"`
+	Network   string              `json:"network"`
+	Username  string              `json:"username"`
+	Dimension string              `json:"dimension"`
+	Dates     []string            `json:"dates"`
+	Buckets   []DemographicBucket `json:"buckets"`
+}
+
+func GetDemographicsStats(dbQueries *database.Queries, userID uuid.UUID) ([]DemographicSeries, error) {
+	rows, err := dbQueries.GetAudienceDemographicsForUser(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	type seriesKey struct {
+		sourceID  uuid.UUID
+		dimension string
+	}
+
+	type seriesData struct {
+		series  *DemographicSeries
+		dateIdx map[string]int
+		totals  []float64
+		values  map[string]map[int]float64
+	}
+
+	var order []seriesKey
+	data := make(map[seriesKey]*seriesData)
+
+	for _, row := range rows {
+		key := seriesKey{sourceID: row.SourceID, dimension: row.Dimension}
+		d, ok := data[key]
+		if !ok {
+			d = &seriesData{
+				series: &DemographicSeries{
+					SourceID:  row.SourceID,
+					Network:   row.SourceNetwork,
+					Username:  row.SourceUserName,
+					Dimension: row.Dimension,
+				},
+				dateIdx: make(map[string]int),
+				values:  make(map[string]map[int]float64),
+			}
+			data[key] = d
+			order = append(order, key)
+		}
+
+		date := row.Date.Format("2006-01-02")
+		idx, ok := d.dateIdx[date]
+		if !ok {
+			idx = len(d.series.Dates)
+			d.dateIdx[date] = idx
+			d.series.Dates = append(d.series.Dates, date)
+			d.totals = append(d.totals, 0)
+		}
+		d.totals[idx] += row.Value
+
+		if _, ok := d.values[row.Bucket]; !ok {
+			d.values[row.Bucket] = make(map[int]float64)
+		}
+		d.values[row.Bucket][idx] = row.Value
+	}
+
+	result := make([]DemographicSeries, 0, len(order))
+	for _, key := range order {
+		d := data[key]
+		last := len(d.series.Dates) - 1
+
+		for bucket, values := range d.values {
+			shares := make([]float64, len(d.series.Dates))
+			for idx := range shares {
+				if d.totals[idx] > 0 {
+					shares[idx] = values[idx] / d.totals[idx] * 100
+				}
+			}
+			d.series.Buckets = append(d.series.Buckets, DemographicBucket{
+				Bucket: bucket,
+				Shares: shares,
+			})
+		}
+
+		sort.Slice(d.series.Buckets, func(i, j int) bool {
+			a, b := d.series.Buckets[i], d.series.Buckets[j]
+			if a.Shares[last] != b.Shares[last] {
+				return a.Shares[last] > b.Shares[last]
+			}
+			return a.Bucket < b.Bucket
+		})
+		if len(d.series.Buckets) > maxDemographicBuckets {
+			d.series.Buckets = d.series.Buckets[:maxDemographicBuckets]
+		}
+
+		result = append(result, *d.series)
+	}
+
+	return result, nil
+}
@@ -16,6 +16,7 @@ import (
 	fetcher_common "github.com/fluffyriot/rpsync/internal/fetcher/common"
 	"github.com/fluffyriot/rpsync/internal/pusher"
 	"github.com/fluffyriot/rpsync/internal/pusher/common"
+	"github.com/fluffyriot/rpsync/internal/pusher/targets/webhook"
 	"github.com/google/uuid"
 )
 
@@ -98,6 +99,58 @@ func SyncUser(ctx context.Context, userID uuid.UUID, db *database.Queries, f *fe
 	)
 }
 
+func SyncInstagramStories(ctx context.Context, db *database.Queries, f *fetcher_common.Client, cfg *config.AppConfig) {
+	sources, err := db.GetActiveSourcesByNetwork(ctx, "Instagram")
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Worker Error getting Instagram sources for stories: %v", err)
+		}
+		return
+	}
+
+	for _, source := range sources {
+		if err := fetcher.SyncInstagramStories(source.ID, db, f, cfg.InstagramAPIVersion, cfg.TokenEncryptionKey); err != nil {
+			log.Printf("Worker Story sync error (source=%s): %v", source.ID, err)
+		}
+	}
+}
+
+func SampleLiveStreams(ctx context.Context, db *database.Queries, f *fetcher_common.Client, cfg *config.AppConfig) {
+	for _, network := range []string{"Twitch", "Kick"} {
+		sources, err := db.GetActiveSourcesByNetwork(ctx, network)
+		if err != nil {
+			if err != sql.ErrNoRows {
+				log.Printf("Worker Error getting %s sources for live sampling: %v", network, err)
+			}
+			continue
+		}
+
+		for _, source := range sources {
+			if err := fetcher.SampleLiveViewers(source.ID, network, db, f, cfg.TokenEncryptionKey); err != nil {
+				log.Printf("Worker Live sampling error (source=%s): %v", source.ID, err)
+			}
+		}
+	}
+}
+
+// DeliverWebhooks sends the due deliveries of every active webhook target,
+// including retries of earlier failures
+func DeliverWebhooks(ctx context.Context, db *database.Queries, p *common.Client, cfg *config.AppConfig) {
+	webhookTargets, err := db.GetActiveTargetsByType(ctx, "Webhook")
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Worker Error getting webhook targets: %v", err)
+		}
+		return
+	}
+
+	for _, target := range webhookTargets {
+		if err := webhook.DeliverDue(ctx, db, p, cfg.TokenEncryptionKey, target); err != nil {
+			log.Printf("Worker Webhook delivery error (target=%s): %v", target.ID, err)
+		}
+	}
+}
+
 func RunSyncSource(sid uuid.UUID, db *database.Queries, f *fetcher_common.Client, cfg *config.AppConfig) {
 	log.Printf("Worker: Starting manual sync for source %s", sid)
 	syncSourceInternal(sid, db, f, cfg)
@@ -14,6 +14,12 @@ import (
 	"github.com/google/uuid"
 )
 
+const instagramStoryPollInterval = time.Hour
+
+const liveStreamPollInterval = 5 * time.Minute
+
+const webhookDeliveryInterval = time.Minute
+
 type Worker struct {
 	DB               *database.Queries
 	Fetcher          *fetcher_common.Client
@@ -80,6 +86,12 @@ func (w *Worker) Start() {
 		}
 	}()
 
+	go w.spawnStoryWorker(instagramStoryPollInterval)
+
+	go w.spawnLiveStreamWorker(liveStreamPollInterval)
+
+	go w.spawnWebhookWorker(webhookDeliveryInterval)
+
 	log.Println("Background worker system started")
 }
 
@@ -97,6 +109,48 @@ func (w *Worker) spawnUserWorker(userID uuid.UUID, interval time.Duration) {
 	}
 }
 
+func (w *Worker) spawnStoryWorker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			SyncInstagramStories(context.Background(), w.DB, w.Fetcher, w.Config)
+		case <-w.StopChan:
+			return
+		}
+	}
+}
+
+func (w *Worker) spawnLiveStreamWorker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			SampleLiveStreams(context.Background(), w.DB, w.Fetcher, w.Config)
+		case <-w.StopChan:
+			return
+		}
+	}
+}
+
+func (w *Worker) spawnWebhookWorker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			DeliverWebhooks(context.Background(), w.DB, w.Puller, w.Config)
+		case <-w.StopChan:
+			return
+		}
+	}
+}
+
 func (w *Worker) Stop() {
 	w.mu.Lock()
 	if !w.active {
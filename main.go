@@ -41,6 +41,8 @@ func main() {
 	resetPwdFlag := flag.Bool("reset-password", false, "Reset user password")
 	reset2FAFlag := flag.Bool("reset-2fa", false, "Reset 2FA (TOTP) for a user")
 	resetUserFlag := flag.String("username", "", "Username (required for --reset-password and --reset-2fa)")
+	importArchiveFlag := flag.String("import-archive", "", "Import a platform data export (Instagram/TikTok/X ZIP or JSON, Bluesky CAR) into a source")
+	importSourceFlag := flag.String("source-id", "", "Source ID (required for --import-archive)")
 	flag.Parse()
 
 	var pv projectVersion
@@ -128,6 +130,14 @@ func main() {
 		return
 	}
 
+	if *importArchiveFlag != "" {
+		if dbConn == nil {
+			log.Fatal("Database connection failed, cannot import archive")
+		}
+		cli.HandleImportArchive(dbQueries, *importSourceFlag, *importArchiveFlag)
+		return
+	}
+
 	w := worker.NewWorker(dbQueries, clientFetch, clientPull, cfg)
 
 	upd := updater.NewUpdater(config.AppVersion)
@@ -165,6 +175,8 @@ func main() {
 	r.GET("/register", h.UserSetupViewHandler)
 	r.POST("/register", h.UserSetupHandler)
 
+	r.POST("/webhooks/kofi/:source_id", h.KofiWebhookHandler)
+
 	authorized := r.Group("/")
 	authorized.Use(middleware.AuthMiddleware(dbQueries))
 
@@ -201,9 +213,15 @@ func main() {
 	authorized.GET("/auth/facebook/login", h.FacebookLoginHandler)
 	authorized.GET("/auth/facebook/callback", h.FacebookCallbackHandler)
 	authorized.POST("/auth/facebook/refresh", h.FacebookRefreshTokenHandler)
+	authorized.GET("/auth/google/login", h.GoogleLoginHandler)
+	authorized.GET("/auth/google/callback", h.GoogleCallbackHandler)
+	authorized.GET("/auth/patreon/login", h.PatreonLoginHandler)
+	authorized.GET("/auth/patreon/callback", h.PatreonCallbackHandler)
 
 	authorized.GET("/auth/tiktok/login", h.TikTokLoginHandler)
 	authorized.GET("/auth/tiktok/check", h.TikTokCheckHandler)
+	authorized.GET("/auth/telegram/login", h.TelegramLoginHandler)
+	authorized.POST("/auth/telegram/verify", h.TelegramVerifyHandler)
 
 	authorized.GET("/exports", h.ExportsHandler)
 	authorized.POST("/exports/deleteAll", h.ExportDeleteAllHandler)
@@ -221,6 +239,10 @@ func main() {
 	authorized.POST("/sources/sync", h.SyncSourceHandler)
 	authorized.GET("/sources/cookies/export", h.HandleExportCookies)
 	authorized.POST("/sources/cookies/import", h.HandleImportCookies)
+	authorized.POST("/sources/manual/entry", h.ManualEntryHandler)
+	authorized.POST("/sources/manual/import", h.ManualImportHandler)
+	authorized.GET("/sources/archive", h.ArchiveImportViewHandler)
+	authorized.POST("/sources/archive", h.ArchiveImportHandler)
 	authorized.PUT("/sources/:source_id/channels", h.UpdateSourceChannelsHandler)
 	authorized.GET("/sources/:source_id/channels", h.GetSourceChannelsHandler)
 
@@ -232,11 +254,18 @@ func main() {
 	authorized.POST("/targets/activate", h.ActivateTargetHandler)
 	authorized.POST("/targets/delete", h.DeleteTargetHandler)
 	authorized.POST("/targets/sync", h.SyncTargetHandler)
+	authorized.POST("/targets/settings", h.UpdateTargetSettingsHandler)
+	authorized.GET("/targets/profile", h.TargetProfileHandler)
+	authorized.POST("/targets/profile", h.UpdateTargetProfileHandler)
+	authorized.GET("/targets/webhook", h.WebhookDeliveriesHandler)
+	authorized.POST("/targets/webhook/test", h.SendWebhookTestHandler)
+	authorized.POST("/targets/webhook/retry", h.RetryWebhookDeliveryHandler)
 
 	authorized.GET("/analytics/engagement", h.AnalyticsEngagementHandler)
 	authorized.GET("/analytics/website", h.AnalyticsWebsiteHandler)
 	authorized.GET("/analytics/summary", h.AnalyticsDashboardSummaryHandler)
 	authorized.GET("/analytics/top-sources", h.AnalyticsTopSourcesHandler)
+	authorized.GET("/analytics/demographics", h.AnalyticsDemographicsHandler)
 
 	authorized.GET("/posts", h.PostsHandler)
 
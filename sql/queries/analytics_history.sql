@@ -0,0 +1,111 @@
+-- name: SyncPostAnalytics :exec
+INSERT INTO
+    posts_analytics_history (
+        id,
+        date,
+        post_id,
+        views,
+        watch_time_minutes,
+        average_view_duration,
+        impressions,
+        impressions_ctr,
+        subscribers_gained,
+        reach,
+        saves,
+        shares,
+        profile_visits,
+        comments
+    )
+VALUES (
+        $1,
+        $2,
+        $3,
+        $4,
+        $5,
+        $6,
+        $7,
+        $8,
+        $9,
+        $10,
+        $11,
+        $12,
+        $13,
+        $14
+    )
+ON CONFLICT (post_id, date) DO
+UPDATE
+SET
+    views = EXCLUDED.views,
+    watch_time_minutes = EXCLUDED.watch_time_minutes,
+    average_view_duration = EXCLUDED.average_view_duration,
+    impressions = COALESCE(
+        EXCLUDED.impressions,
+        posts_analytics_history.impressions
+    ),
+    impressions_ctr = COALESCE(
+        EXCLUDED.impressions_ctr,
+        posts_analytics_history.impressions_ctr
+    ),
+    subscribers_gained = EXCLUDED.subscribers_gained,
+    reach = EXCLUDED.reach,
+    saves = EXCLUDED.saves,
+    shares = EXCLUDED.shares,
+    profile_visits = EXCLUDED.profile_visits,
+    comments = EXCLUDED.comments;
+
+-- name: SyncSourceAnalytics :exec
+INSERT INTO
+    sources_analytics_history (
+        id,
+        date,
+        source_id,
+        views,
+        watch_time_minutes,
+        average_view_duration,
+        impressions,
+        impressions_ctr,
+        subscribers_gained,
+        subscribers_lost
+    )
+VALUES (
+        $1,
+        $2,
+        $3,
+        $4,
+        $5,
+        $6,
+        $7,
+        $8,
+        $9,
+        $10
+    )
+ON CONFLICT (source_id, date) DO
+UPDATE
+SET
+    views = EXCLUDED.views,
+    watch_time_minutes = EXCLUDED.watch_time_minutes,
+    average_view_duration = EXCLUDED.average_view_duration,
+    impressions = COALESCE(
+        EXCLUDED.impressions,
+        sources_analytics_history.impressions
+    ),
+    impressions_ctr = COALESCE(
+        EXCLUDED.impressions_ctr,
+        sources_analytics_history.impressions_ctr
+    ),
+    subscribers_gained = EXCLUDED.subscribers_gained,
+    subscribers_lost = EXCLUDED.subscribers_lost;
+
+-- name: GetLatestPostCommentsForUser :many
+SELECT DISTINCT
+    ON (pah.post_id) p.source_id,
+    p.network_internal_id,
+    pah.comments
+FROM
+    posts_analytics_history pah
+    JOIN posts p ON pah.post_id = p.id
+    JOIN sources s ON p.source_id = s.id
+WHERE
+    s.user_id = $1
+    AND pah.comments IS NOT NULL
+ORDER BY pah.post_id, pah.date DESC;
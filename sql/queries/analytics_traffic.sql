@@ -0,0 +1,57 @@
+-- name: CreateAnalyticsTrafficSource :one
+INSERT INTO
+    analytics_traffic_sources (
+        id,
+        date,
+        session_source,
+        session_medium,
+        session_campaign_name,
+        sessions,
+        source_id
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (
+    source_id,
+    date,
+    session_source,
+    session_medium,
+    session_campaign_name
+) DO
+UPDATE
+SET
+    sessions = EXCLUDED.sessions
+RETURNING
+    *;
+
+-- name: GetPostSiteVisitsForUser :many
+SELECT
+    p.source_id,
+    p.network_internal_id,
+    COALESCE(SUM(t.sessions), 0)::BIGINT AS site_visits
+FROM
+    posts p
+    JOIN sources s ON p.source_id = s.id
+    JOIN analytics_traffic_sources t ON t.session_campaign_name = p.network_internal_id
+    AND t.session_source = LOWER(REPLACE(s.network, ' ', ''))
+    JOIN sources ga ON t.source_id = ga.id
+WHERE
+    s.user_id = $1
+    AND ga.user_id = $1
+GROUP BY
+    p.source_id,
+    p.network_internal_id;
+
+-- name: GetReferralSessionsForUser :many
+SELECT
+    t.session_source,
+    t.session_medium,
+    COALESCE(SUM(t.sessions), 0)::BIGINT AS total_sessions
+FROM
+    analytics_traffic_sources t
+    JOIN sources src ON t.source_id = src.id
+WHERE
+    src.user_id = $1
+GROUP BY
+    t.session_source,
+    t.session_medium
+ORDER BY total_sessions DESC;
@@ -175,4 +175,28 @@ GROUP BY
     s.id
 ORDER BY total_interactions DESC
 OFFSET
-    3;
\ No newline at end of file
+    3;
+-- name: GetTotalDailySupporterStats :many
+SELECT
+    calendar.date::date as period_date,
+    COALESCE(
+        (
+            SELECT SUM(COALESCE(supporters_count, 0))
+            FROM (
+                    SELECT DISTINCT
+                        ON (ss.source_id) ss.supporters_count
+                    FROM sources_stats ss
+                        JOIN sources s ON ss.source_id = s.id
+                    WHERE
+                        s.user_id = $1
+                        AND ss.supporters_count IS NOT NULL
+                        AND ss.date < calendar.date + INTERVAL '1 day'
+                    ORDER BY ss.source_id, ss.date DESC
+                ) as distinct_sources
+        ),
+        0
+    )::BIGINT as total_supporters
+FROM generate_series(
+        date_trunc('day', $2::timestamp), date_trunc('day', $3::timestamp), '1 day'::interval
+    ) as calendar (date)
+ORDER BY calendar.date ASC;
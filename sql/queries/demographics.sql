@@ -0,0 +1,77 @@
+-- name: CreateAudienceDemographic :exec
+INSERT INTO
+    audience_demographics (
+        id,
+        date,
+        source_id,
+        dimension,
+        bucket,
+        value
+    )
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (
+    source_id,
+    date,
+    dimension,
+    bucket
+) DO
+UPDATE
+SET
+    value = EXCLUDED.value;
+
+-- name: CheckCountOfAudienceDemographicsForSourceOnDate :one
+SELECT COUNT(*)
+FROM audience_demographics
+WHERE
+    source_id = $1
+    AND date = $2;
+
+-- name: CheckCountOfAudienceDemographicsForUser :one
+SELECT COUNT(*)
+FROM audience_demographics ad
+    JOIN sources s ON ad.source_id = s.id
+WHERE
+    s.user_id = $1;
+
+-- name: GetAudienceDemographicsForUser :many
+SELECT
+    ad.id, ad.date, ad.source_id, ad.dimension, ad.bucket, ad.value,
+    s.network as source_network,
+    s.user_name as source_user_name
+FROM
+    audience_demographics ad
+    JOIN sources s ON ad.source_id = s.id
+WHERE
+    s.user_id = $1
+ORDER BY ad.date ASC, ad.source_id, ad.dimension, ad.value DESC;
+
+-- name: GetUnsyncedAudienceDemographicsForTarget :many
+SELECT ad.*
+FROM audience_demographics ad
+WHERE
+    ad.source_id = $1
+    AND NOT EXISTS (
+        SELECT 1
+        FROM audience_demographics_on_target adot
+        WHERE
+            adot.demographic_id = ad.id
+            AND adot.target_id = $2
+    );
+
+-- name: AddAudienceDemographicToTarget :one
+INSERT INTO
+    audience_demographics_on_target (
+        id,
+        synced_at,
+        demographic_id,
+        target_id,
+        target_record_id
+    )
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (demographic_id, target_id) DO
+UPDATE
+SET
+    synced_at = $2,
+    target_record_id = $5
+RETURNING
+    *;
@@ -52,4 +52,41 @@ SELECT * FROM exports where user_id = $1 ORDER BY created_at DESC;
 DELETE FROM exports WHERE user_id = $1;
 
 -- name: GetExportById :one
-SELECT * FROM exports WHERE id = $1;
\ No newline at end of file
+SELECT * FROM exports WHERE id = $1;
+
+-- name: DeleteExportById :exec
+DELETE FROM exports WHERE id = $1;
+
+-- name: GetLastCompletedExportForTarget :one
+SELECT *
+FROM exports
+WHERE
+    target_id = $1
+    AND export_status = 'Completed'
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetTargetExportsCreatedBefore :many
+SELECT *
+FROM exports
+WHERE
+    target_id = $1
+    AND created_at < $2
+ORDER BY created_at;
+
+-- name: SetExportStorage :exec
+UPDATE exports
+SET
+    storage_key = $2,
+    storage_endpoint = $3,
+    storage_region = $4,
+    storage_bucket = $5
+WHERE
+    id = $1;
+
+-- name: CountStoredExportsByTarget :one
+SELECT COUNT(*)
+FROM exports
+WHERE
+    target_id = $1
+    AND storage_key IS NOT NULL;
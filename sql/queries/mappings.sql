@@ -37,4 +37,8 @@ where cm.table_mapping_id = $1 and cm.target_column_name = $2;
 
 -- name: GetTableMappingsByTargetAndCode :one
 SELECT * FROM table_mappings
-WHERE target_id = $1 AND target_table_code = $2;
\ No newline at end of file
+WHERE target_id = $1 AND target_table_code = $2;
+
+-- name: DeleteTableMappingByTargetAndName :exec
+DELETE FROM table_mappings
+WHERE target_id = $1 AND target_table_name = $2;
\ No newline at end of file
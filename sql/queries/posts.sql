@@ -33,6 +33,14 @@ where
     network_internal_id = $1
     and sources.network = $2;
 
+-- name: GetPostBySourceAndCreatedAt :one
+SELECT *
+FROM posts
+WHERE
+    source_id = $1
+    AND created_at = $2
+LIMIT 1;
+
 -- name: CheckCountOfPostsForUser :one
 SELECT COUNT(*)
 FROM posts p
@@ -44,6 +52,7 @@ where
 SELECT
     p.id,
     p.created_at,
+    p.last_synced_at,
     p.source_id,
     p.is_archived,
     p.network_internal_id,
@@ -54,6 +63,7 @@ SELECT
     u.username AS current_user_name,
     s.user_name AS source_user_name,
     r.synced_at AS reactions_synced_at,
+    r.recorded_at AS reactions_recorded_at,
     r.likes,
     r.reposts,
     r.views
@@ -86,7 +96,10 @@ SELECT
     (
         COALESCE(r.likes, 0) + COALESCE(r.reposts, 0)
     )::bigint AS interactions,
-    r.views
+    r.views,
+    r.upvote_ratio,
+    r.quotes,
+    r.bookmarks
 FROM
     posts p
     left join sources s ON p.source_id = s.id
@@ -115,7 +128,8 @@ SET
     is_archived = $3,
     content = $4,
     post_type = $5,
-    author = $6
+    author = $6,
+    is_imported = false
 WHERE
     id = $1
 RETURNING
@@ -127,4 +141,28 @@ SET
     is_archived = true
 WHERE
     source_id = $1
-    AND last_synced_at < $2;
\ No newline at end of file
+    AND last_synced_at < $2
+    AND NOT is_imported;
+
+-- name: MarkPostImported :exec
+UPDATE posts SET is_imported = true WHERE id = $1;
+
+-- name: ClaimImportedPost :one
+UPDATE posts
+SET
+    network_internal_id = $1
+WHERE
+    id = (
+        SELECT p.id
+        FROM posts p
+        WHERE
+            p.source_id = $2
+            AND p.created_at = $3
+            AND p.is_imported
+            AND p.network_internal_id LIKE 'archive/%'
+        LIMIT 1
+    )
+RETURNING
+    *;
+-- name: UpdatePostsAuthorForSource :exec
+UPDATE posts SET author = $3 WHERE source_id = $1 AND author = $2;
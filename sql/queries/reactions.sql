@@ -18,7 +18,8 @@ SET
     likes = EXCLUDED.likes,
     reposts = EXCLUDED.reposts,
     views = EXCLUDED.views,
-    synced_at = EXCLUDED.synced_at
+    synced_at = EXCLUDED.synced_at,
+    recorded_at = NOW()
 RETURNING
     *;
 
@@ -52,7 +53,73 @@ GROUP BY
     DATE (p.created_at)
 ORDER BY s.id, date ASC;
 
+-- name: GetReactionsHistoryForUserSince :many
+SELECT
+    prh.post_id,
+    p.source_id,
+    prh.synced_at,
+    prh.likes,
+    prh.reposts,
+    prh.views,
+    pah.comments,
+    prh.quotes,
+    prh.bookmarks
+FROM
+    posts_reactions_history prh
+    JOIN posts p ON prh.post_id = p.id
+    JOIN sources s ON p.source_id = s.id
+    LEFT JOIN posts_analytics_history pah ON pah.post_id = prh.post_id
+    AND pah.date = date_trunc('day', prh.synced_at)
+WHERE
+    s.user_id = $1
+    AND prh.synced_at >= $2
+ORDER BY prh.synced_at ASC;
+
+-- name: GetReactionsHistoryForUserRecordedAfter :many
+SELECT
+    prh.post_id,
+    p.source_id,
+    prh.synced_at,
+    prh.recorded_at,
+    prh.likes,
+    prh.reposts,
+    prh.views,
+    pah.comments,
+    prh.quotes,
+    prh.bookmarks
+FROM
+    posts_reactions_history prh
+    JOIN posts p ON prh.post_id = p.id
+    JOIN sources s ON p.source_id = s.id
+    LEFT JOIN posts_analytics_history pah ON pah.post_id = prh.post_id
+    AND pah.date = date_trunc('day', prh.synced_at)
+WHERE
+    s.user_id = $1
+    AND prh.recorded_at > $2
+ORDER BY prh.recorded_at ASC;
+
 -- name: DeleteOldStats :exec
-DELETE from posts_reactions_history
+DELETE from posts_reactions_history prh
+USING posts p
 where
-    synced_at < now() - INTERVAL '365 days';
\ No newline at end of file
+    prh.post_id = p.id
+    AND prh.synced_at < now() - INTERVAL '365 days'
+    -- Posts only known from an archive have no newer history to replace it
+    AND NOT p.is_imported;
+
+-- name: UpdateReactionCommunityStats :exec
+UPDATE posts_reactions_history
+SET
+    upvote_ratio = $2,
+    recorded_at = NOW()
+WHERE
+    id = $1;
+
+-- name: UpdateReactionEngagementStats :exec
+UPDATE posts_reactions_history
+SET
+    quotes = $2,
+    bookmarks = $3,
+    recorded_at = NOW()
+WHERE
+    id = $1;
@@ -0,0 +1,25 @@
+-- name: GetReactionBreakdownForUser :many
+SELECT p.source_id, p.network_internal_id, prb.reaction, prb.count
+FROM
+    posts_reactions_breakdown prb
+    JOIN posts p ON prb.post_id = p.id
+    JOIN sources s ON p.source_id = s.id
+WHERE
+    s.user_id = $1
+ORDER BY p.id, prb.count DESC;
+
+-- name: SyncReactionBreakdown :exec
+INSERT INTO
+    posts_reactions_breakdown (
+        id,
+        synced_at,
+        post_id,
+        reaction,
+        count
+    )
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (post_id, reaction) DO
+UPDATE
+SET
+    count = EXCLUDED.count,
+    synced_at = EXCLUDED.synced_at;
@@ -0,0 +1,21 @@
+-- name: CreateSourceRename :one
+INSERT INTO
+    source_username_history (
+        id,
+        source_id,
+        old_user_name,
+        new_user_name,
+        changed_at
+    )
+VALUES ($1, $2, $3, $4, $5)
+RETURNING
+    *;
+
+-- name: GetUserSourceRenames :many
+SELECT h.*
+FROM
+    source_username_history h
+    JOIN sources s ON h.source_id = s.id
+WHERE
+    s.user_id = $1
+ORDER BY h.changed_at DESC;
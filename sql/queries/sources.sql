@@ -23,6 +23,10 @@ LIMIT 1;
 SELECT * FROM sources
 where user_id = $1 and is_active = TRUE;
 
+-- name: GetActiveSourcesByNetwork :many
+SELECT * FROM sources
+where network = $1 and is_active = TRUE;
+
 -- name: GetUserSources :many
 SELECT * FROM sources
 where user_id = $1
@@ -53,4 +57,14 @@ RETURNING *;
 UPDATE sources
 SET sync_status = $2, status_reason = $3, last_synced = $4
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: UpdateSourceAccountID :exec
+UPDATE sources
+SET account_id = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: RenameSource :exec
+UPDATE sources
+SET user_name = $2, updated_at = NOW()
+WHERE id = $1;
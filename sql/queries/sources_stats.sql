@@ -62,4 +62,64 @@ FROM posts p
         ORDER BY post_id, synced_at DESC
     ) prh ON p.id = prh.post_id
 WHERE
-    p.source_id = $1;
\ No newline at end of file
+    p.source_id = $1;
+
+-- name: BackfillSourceFollowers :exec
+INSERT INTO
+    sources_stats (
+        id,
+        date,
+        source_id,
+        followers_count
+    )
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (source_id, date) DO
+UPDATE
+SET
+    followers_count = COALESCE(
+        sources_stats.followers_count,
+        EXCLUDED.followers_count
+    );
+
+-- name: UpdateSourceNotificationsEnabled :exec
+UPDATE sources_stats
+SET
+    notifications_enabled_pct = $1
+WHERE
+    source_id = $2
+    AND date = $3;
+
+
+-- name: UpdateSourceOnlineCount :exec
+UPDATE sources_stats
+SET
+    online_count = $1
+WHERE
+    source_id = $2
+    AND date = $3;
+
+-- name: UpdateSourceSupporters :exec
+UPDATE sources_stats
+SET
+    supporters_count = $1
+WHERE
+    source_id = $2
+    AND date = $3;
+
+-- name: UpdateSourceCommunityStats :exec
+UPDATE sources_stats
+SET
+    karma = $1,
+    community_subscribers = $2
+WHERE
+    source_id = $3
+    AND date = $4;
+
+-- name: GetLatestSourceFollowers :one
+SELECT followers_count
+FROM sources_stats
+WHERE
+    source_id = $1
+    AND followers_count IS NOT NULL
+ORDER BY date DESC
+LIMIT 1;
@@ -1,5 +1,5 @@
 -- name: GetAllSourcesStatsForUser :many
-SELECT ss.id, ss.date, ss.source_id, ss.followers_count, ss.following_count, ss.posts_count, ss.average_likes, ss.average_reposts, ss.average_views
+SELECT ss.id, ss.date, ss.source_id, ss.followers_count, ss.following_count, ss.posts_count, ss.average_likes, ss.average_reposts, ss.average_views, ss.notifications_enabled_pct, ss.online_count, ss.supporters_count, ss.karma, ss.community_subscribers
 FROM sources_stats ss
     LEFT JOIN sources s ON ss.source_id = s.id
 WHERE
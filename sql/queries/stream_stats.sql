@@ -0,0 +1,67 @@
+-- name: CreateStreamViewerSample :exec
+INSERT INTO
+    stream_viewer_samples (
+        id,
+        source_id,
+        stream_id,
+        sampled_at,
+        viewers
+    )
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetStreamViewerSummary :one
+SELECT
+    COUNT(*) AS sample_count,
+    COALESCE(MAX(viewers), 0)::BIGINT AS peak_viewers,
+    COALESCE(AVG(viewers), 0)::FLOAT AS average_viewers
+FROM stream_viewer_samples
+WHERE
+    source_id = $1
+    AND stream_id = $2;
+
+-- name: SyncPostStreamStats :exec
+INSERT INTO
+    posts_stream_stats (
+        id,
+        post_id,
+        stream_id,
+        duration_seconds,
+        peak_viewers,
+        average_viewers,
+        updated_at
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (post_id) DO
+UPDATE
+SET
+    stream_id = COALESCE(
+        EXCLUDED.stream_id,
+        posts_stream_stats.stream_id
+    ),
+    duration_seconds = COALESCE(
+        EXCLUDED.duration_seconds,
+        posts_stream_stats.duration_seconds
+    ),
+    peak_viewers = COALESCE(
+        EXCLUDED.peak_viewers,
+        posts_stream_stats.peak_viewers
+    ),
+    average_viewers = COALESCE(
+        EXCLUDED.average_viewers,
+        posts_stream_stats.average_viewers
+    ),
+    updated_at = EXCLUDED.updated_at;
+
+-- name: GetPostStreamStatsForUser :many
+SELECT
+    p.source_id,
+    p.network_internal_id,
+    pss.duration_seconds,
+    pss.peak_viewers,
+    pss.average_viewers
+FROM
+    posts_stream_stats pss
+    JOIN posts p ON pss.post_id = p.id
+    JOIN sources s ON p.source_id = s.id
+WHERE
+    s.user_id = $1;
@@ -0,0 +1,51 @@
+-- name: CreateSupporterEvent :exec
+INSERT INTO
+    supporter_events (
+        id,
+        source_id,
+        external_id,
+        received_at,
+        event_type,
+        supporter_key,
+        supporter_name,
+        amount,
+        currency,
+        tier_name,
+        is_subscription,
+        message
+    )
+VALUES (
+        $1,
+        $2,
+        $3,
+        $4,
+        $5,
+        $6,
+        $7,
+        $8,
+        $9,
+        $10,
+        $11,
+        $12
+    )
+ON CONFLICT (source_id, external_id) DO NOTHING;
+
+-- name: CountActiveSubscribers :one
+SELECT COUNT(DISTINCT supporter_key)
+FROM supporter_events
+WHERE
+    source_id = $1
+    AND is_subscription = TRUE
+    AND received_at >= $2;
+
+-- name: GetActiveSubscriberTiers :many
+SELECT
+    COALESCE(tier_name, '')::TEXT AS tier_name,
+    COUNT(DISTINCT supporter_key) AS supporters
+FROM supporter_events
+WHERE
+    source_id = $1
+    AND is_subscription = TRUE
+    AND received_at >= $2
+GROUP BY
+    tier_name;
@@ -1,5 +1,5 @@
 -- name: CreateTarget :one
-INSERT INTO targets (id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, host_url)
+INSERT INTO targets (id, created_at, updated_at, target_type, user_id, db_id, is_active, sync_frequency, sync_status, host_url, settings)
 VALUES (
     $1,
     $2,
@@ -10,7 +10,8 @@ VALUES (
     $7,
     $8,
     $9,
-    $10
+    $10,
+    $11
 )
 RETURNING *;
 
@@ -40,4 +41,13 @@ where id = $1;
 UPDATE targets
 SET sync_status = $2, status_reason = $3, last_synced = $4
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: UpdateTargetSettings :exec
+UPDATE targets
+SET settings = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetActiveTargetsByType :many
+SELECT * FROM targets
+where target_type = $1 and is_active = TRUE;
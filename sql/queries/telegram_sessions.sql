@@ -0,0 +1,23 @@
+-- name: GetTelegramSessionBySource :one
+SELECT * FROM telegram_sessions WHERE source_id = $1;
+
+-- name: UpsertTelegramSession :exec
+INSERT INTO
+    telegram_sessions (
+        id,
+        source_id,
+        encrypted_session,
+        nonce,
+        created_at,
+        updated_at
+    )
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (source_id) DO
+UPDATE
+SET
+    encrypted_session = EXCLUDED.encrypted_session,
+    nonce = EXCLUDED.nonce,
+    updated_at = EXCLUDED.updated_at;
+
+-- name: DeleteTelegramSessionBySource :exec
+DELETE FROM telegram_sessions WHERE source_id = $1;
@@ -40,4 +40,11 @@ SET
     profile_id = $2,
     updated_at = $3
 WHERE
-    id = $1;
\ No newline at end of file
+    id = $1;
+-- name: UpdateTokenAppData :exec
+UPDATE tokens
+SET
+    source_app_data = $2,
+    updated_at = $3
+WHERE
+    id = $1;
@@ -0,0 +1,71 @@
+-- name: CreateWebhookDelivery :one
+INSERT INTO
+    webhook_deliveries (
+        id,
+        created_at,
+        target_id,
+        event,
+        payload,
+        next_attempt_at
+    )
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING
+    *;
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET
+    next_attempt_at = sqlc.arg(lease_until)
+WHERE
+    id IN (
+        SELECT d.id
+        FROM webhook_deliveries d
+        WHERE
+            d.target_id = sqlc.arg(target_id)
+            AND d.status = 'Pending'
+            AND d.next_attempt_at <= sqlc.arg(due_before)
+        ORDER BY d.next_attempt_at
+        LIMIT 20
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING
+    *;
+
+-- name: UpdateWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET
+    status = $2,
+    attempts = $3,
+    next_attempt_at = $4,
+    last_attempt_at = $5,
+    response_status = $6,
+    last_error = $7
+WHERE
+    id = $1;
+
+-- name: GetWebhookDeliveriesByTarget :many
+SELECT *
+FROM webhook_deliveries
+WHERE
+    target_id = $1
+ORDER BY created_at DESC
+LIMIT 100;
+
+-- name: GetWebhookDeliveryById :one
+SELECT * FROM webhook_deliveries WHERE id = $1;
+
+-- name: RequeueWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET
+    status = 'Pending',
+    attempts = 0,
+    next_attempt_at = $2
+WHERE
+    id = $1;
+
+-- name: DeleteWebhookDeliveriesBefore :exec
+DELETE FROM webhook_deliveries
+WHERE
+    target_id = $1
+    AND status <> 'Pending'
+    AND created_at < $2;
@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE analytics_traffic_sources (
+    id UUID PRIMARY KEY,
+    date TIMESTAMP NOT NULL,
+    session_source TEXT NOT NULL,
+    session_medium TEXT NOT NULL,
+    session_campaign_name TEXT NOT NULL,
+    sessions BIGINT NOT NULL DEFAULT 0,
+    source_id UUID NOT NULL,
+    CONSTRAINT fk_source_traffic FOREIGN KEY (source_id) REFERENCES sources (id) ON DELETE CASCADE,
+    CONSTRAINT unique_traffic_source UNIQUE (
+        source_id,
+        date,
+        session_source,
+        session_medium,
+        session_campaign_name
+    )
+);
+
+CREATE INDEX idx_analytics_traffic_sources_campaign ON analytics_traffic_sources (session_campaign_name);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_analytics_traffic_sources_campaign;
+
+DROP TABLE analytics_traffic_sources;
@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE posts_analytics_history (
+    id UUID PRIMARY KEY,
+    date TIMESTAMP NOT NULL,
+    post_id UUID NOT NULL,
+    views BIGINT,
+    watch_time_minutes FLOAT,
+    average_view_duration FLOAT,
+    impressions BIGINT,
+    impressions_ctr FLOAT,
+    subscribers_gained BIGINT,
+    CONSTRAINT fk_post_analytics FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE,
+    CONSTRAINT unique_post_analytics_date UNIQUE (post_id, date)
+);
+
+CREATE TABLE sources_analytics_history (
+    id UUID PRIMARY KEY,
+    date TIMESTAMP NOT NULL,
+    source_id UUID NOT NULL,
+    views BIGINT,
+    watch_time_minutes FLOAT,
+    average_view_duration FLOAT,
+    impressions BIGINT,
+    impressions_ctr FLOAT,
+    subscribers_gained BIGINT,
+    subscribers_lost BIGINT,
+    CONSTRAINT fk_source_analytics FOREIGN KEY (source_id) REFERENCES sources (id) ON DELETE CASCADE,
+    CONSTRAINT unique_source_analytics_date UNIQUE (source_id, date)
+);
+
+-- +goose Down
+DROP TABLE sources_analytics_history;
+
+DROP TABLE posts_analytics_history;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE posts_analytics_history
+ADD COLUMN reach BIGINT,
+ADD COLUMN saves BIGINT,
+ADD COLUMN shares BIGINT,
+ADD COLUMN profile_visits BIGINT;
+
+-- +goose Down
+ALTER TABLE posts_analytics_history
+DROP COLUMN reach,
+DROP COLUMN saves,
+DROP COLUMN shares,
+DROP COLUMN profile_visits;
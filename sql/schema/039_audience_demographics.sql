@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE audience_demographics (
+    id UUID PRIMARY KEY,
+    date TIMESTAMP NOT NULL,
+    source_id UUID NOT NULL,
+    dimension TEXT NOT NULL,
+    bucket TEXT NOT NULL,
+    value FLOAT NOT NULL,
+    CONSTRAINT fk_source_demographics FOREIGN KEY (source_id) REFERENCES sources (id) ON DELETE CASCADE,
+    CONSTRAINT unique_audience_demographic UNIQUE (
+        source_id,
+        date,
+        dimension,
+        bucket
+    )
+);
+
+CREATE TABLE audience_demographics_on_target (
+    id UUID PRIMARY KEY,
+    synced_at TIMESTAMP NOT NULL,
+    demographic_id UUID NOT NULL,
+    target_id UUID NOT NULL,
+    target_record_id TEXT NOT NULL,
+    CONSTRAINT fk_audience_demographics FOREIGN KEY (demographic_id) REFERENCES audience_demographics (id) ON DELETE CASCADE,
+    CONSTRAINT fk_target FOREIGN KEY (target_id) REFERENCES targets (id) ON DELETE CASCADE,
+    CONSTRAINT unique_audience_demographics_on_target UNIQUE (demographic_id, target_id)
+);
+
+-- +goose Down
+DROP TABLE audience_demographics_on_target;
+
+DROP TABLE audience_demographics;
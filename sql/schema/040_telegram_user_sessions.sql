@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE telegram_sessions (
+    id UUID PRIMARY KEY,
+    source_id UUID NOT NULL UNIQUE,
+    CONSTRAINT fk_source FOREIGN KEY (source_id) REFERENCES sources (id) ON DELETE CASCADE,
+    encrypted_session BYTEA NOT NULL,
+    nonce BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE posts_reactions_breakdown (
+    id UUID PRIMARY KEY,
+    synced_at TIMESTAMP NOT NULL,
+    post_id UUID NOT NULL,
+    CONSTRAINT fk_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE,
+    reaction TEXT NOT NULL,
+    count BIGINT NOT NULL,
+    CONSTRAINT unique_post_reaction UNIQUE (post_id, reaction)
+);
+
+ALTER TABLE sources_stats ADD COLUMN notifications_enabled_pct FLOAT;
+
+-- +goose Down
+ALTER TABLE sources_stats DROP COLUMN notifications_enabled_pct;
+
+DROP TABLE posts_reactions_breakdown;
+
+DROP TABLE telegram_sessions;
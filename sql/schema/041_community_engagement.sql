@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE sources_stats ADD COLUMN online_count BIGINT;
+
+ALTER TABLE posts_analytics_history ADD COLUMN comments BIGINT;
+
+-- +goose Down
+ALTER TABLE posts_analytics_history DROP COLUMN comments;
+
+ALTER TABLE sources_stats DROP COLUMN online_count;
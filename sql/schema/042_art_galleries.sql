@@ -0,0 +1,47 @@
+-- +goose Up
+-- Update network constraint to include art gallery networks
+ALTER TABLE sources DROP CONSTRAINT network_check;
+
+ALTER TABLE sources
+ADD CONSTRAINT network_check CHECK (
+    network IN (
+        'Instagram',
+        'Bluesky',
+        'Murrtube',
+        'BadPups',
+        'TikTok',
+        'Mastodon',
+        'Reddit',
+        'Telegram',
+        'Discord',
+        'YouTube',
+        'FurTrack',
+        'Google Analytics',
+        'FurAffinity',
+        'Weasyl',
+        'Inkbunny',
+        'DeviantArt',
+        'Pixiv'
+    )
+);
+
+-- +goose Down
+ALTER TABLE sources DROP CONSTRAINT network_check;
+
+ALTER TABLE sources
+ADD CONSTRAINT network_check CHECK (
+    network IN (
+        'Instagram',
+        'Bluesky',
+        'Murrtube',
+        'BadPups',
+        'TikTok',
+        'Mastodon',
+        'Reddit',
+        'Telegram',
+        'Discord',
+        'YouTube',
+        'FurTrack',
+        'Google Analytics'
+    )
+);
@@ -0,0 +1,80 @@
+-- +goose Up
+-- Update network constraint to include streaming networks
+ALTER TABLE sources DROP CONSTRAINT network_check;
+
+ALTER TABLE sources
+ADD CONSTRAINT network_check CHECK (
+    network IN (
+        'Instagram',
+        'Bluesky',
+        'Murrtube',
+        'BadPups',
+        'TikTok',
+        'Mastodon',
+        'Reddit',
+        'Telegram',
+        'Discord',
+        'YouTube',
+        'FurTrack',
+        'Google Analytics',
+        'FurAffinity',
+        'Weasyl',
+        'Inkbunny',
+        'DeviantArt',
+        'Pixiv',
+        'Twitch',
+        'Kick'
+    )
+);
+
+CREATE TABLE stream_viewer_samples (
+    id UUID PRIMARY KEY,
+    source_id UUID NOT NULL,
+    CONSTRAINT fk_source FOREIGN KEY (source_id) REFERENCES sources (id) ON DELETE CASCADE,
+    stream_id TEXT NOT NULL,
+    sampled_at TIMESTAMP NOT NULL,
+    viewers BIGINT NOT NULL
+);
+
+CREATE INDEX idx_stream_viewer_samples_stream ON stream_viewer_samples (source_id, stream_id);
+
+CREATE TABLE posts_stream_stats (
+    id UUID PRIMARY KEY,
+    post_id UUID NOT NULL UNIQUE,
+    CONSTRAINT fk_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE,
+    stream_id TEXT,
+    duration_seconds BIGINT,
+    peak_viewers BIGINT,
+    average_viewers FLOAT,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE posts_stream_stats;
+
+DROP TABLE stream_viewer_samples;
+
+ALTER TABLE sources DROP CONSTRAINT network_check;
+
+ALTER TABLE sources
+ADD CONSTRAINT network_check CHECK (
+    network IN (
+        'Instagram',
+        'Bluesky',
+        'Murrtube',
+        'BadPups',
+        'TikTok',
+        'Mastodon',
+        'Reddit',
+        'Telegram',
+        'Discord',
+        'YouTube',
+        'FurTrack',
+        'Google Analytics',
+        'FurAffinity',
+        'Weasyl',
+        'Inkbunny',
+        'DeviantArt',
+        'Pixiv'
+    )
+);
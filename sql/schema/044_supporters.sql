@@ -0,0 +1,82 @@
+-- +goose Up
+-- Update network constraint to include membership platforms
+ALTER TABLE sources DROP CONSTRAINT network_check;
+
+ALTER TABLE sources
+ADD CONSTRAINT network_check CHECK (
+    network IN (
+        'Instagram',
+        'Bluesky',
+        'Murrtube',
+        'BadPups',
+        'TikTok',
+        'Mastodon',
+        'Reddit',
+        'Telegram',
+        'Discord',
+        'YouTube',
+        'FurTrack',
+        'Google Analytics',
+        'FurAffinity',
+        'Weasyl',
+        'Inkbunny',
+        'DeviantArt',
+        'Pixiv',
+        'Twitch',
+        'Kick',
+        'Patreon',
+        'Ko-fi',
+        'SubscribeStar'
+    )
+);
+
+ALTER TABLE sources_stats ADD COLUMN supporters_count BIGINT;
+
+CREATE TABLE supporter_events (
+    id UUID PRIMARY KEY,
+    source_id UUID NOT NULL,
+    CONSTRAINT fk_source FOREIGN KEY (source_id) REFERENCES sources (id) ON DELETE CASCADE,
+    external_id TEXT NOT NULL,
+    received_at TIMESTAMP NOT NULL,
+    event_type TEXT NOT NULL,
+    supporter_key TEXT NOT NULL,
+    supporter_name TEXT,
+    amount FLOAT,
+    currency TEXT,
+    tier_name TEXT,
+    is_subscription BOOLEAN NOT NULL DEFAULT FALSE,
+    message TEXT,
+    CONSTRAINT unique_source_event UNIQUE (source_id, external_id)
+);
+
+-- +goose Down
+DROP TABLE supporter_events;
+
+ALTER TABLE sources_stats DROP COLUMN supporters_count;
+
+ALTER TABLE sources DROP CONSTRAINT network_check;
+
+ALTER TABLE sources
+ADD CONSTRAINT network_check CHECK (
+    network IN (
+        'Instagram',
+        'Bluesky',
+        'Murrtube',
+        'BadPups',
+        'TikTok',
+        'Mastodon',
+        'Reddit',
+        'Telegram',
+        'Discord',
+        'YouTube',
+        'FurTrack',
+        'Google Analytics',
+        'FurAffinity',
+        'Weasyl',
+        'Inkbunny',
+        'DeviantArt',
+        'Pixiv',
+        'Twitch',
+        'Kick'
+    )
+);
@@ -0,0 +1,79 @@
+-- +goose Up
+-- Update network constraint to include community platforms
+ALTER TABLE sources DROP CONSTRAINT network_check;
+
+ALTER TABLE sources
+ADD CONSTRAINT network_check CHECK (
+    network IN (
+        'Instagram',
+        'Bluesky',
+        'Murrtube',
+        'BadPups',
+        'TikTok',
+        'Mastodon',
+        'Reddit',
+        'Telegram',
+        'Discord',
+        'YouTube',
+        'FurTrack',
+        'Google Analytics',
+        'FurAffinity',
+        'Weasyl',
+        'Inkbunny',
+        'DeviantArt',
+        'Pixiv',
+        'Twitch',
+        'Kick',
+        'Patreon',
+        'Ko-fi',
+        'SubscribeStar',
+        'Lemmy'
+    )
+);
+
+ALTER TABLE posts_reactions_history ADD COLUMN comments BIGINT;
+
+ALTER TABLE posts_reactions_history ADD COLUMN upvote_ratio FLOAT;
+
+ALTER TABLE sources_stats ADD COLUMN karma BIGINT;
+
+ALTER TABLE sources_stats ADD COLUMN community_subscribers BIGINT;
+
+-- +goose Down
+ALTER TABLE sources_stats DROP COLUMN community_subscribers;
+
+ALTER TABLE sources_stats DROP COLUMN karma;
+
+ALTER TABLE posts_reactions_history DROP COLUMN upvote_ratio;
+
+ALTER TABLE posts_reactions_history DROP COLUMN comments;
+
+ALTER TABLE sources DROP CONSTRAINT network_check;
+
+ALTER TABLE sources
+ADD CONSTRAINT network_check CHECK (
+    network IN (
+        'Instagram',
+        'Bluesky',
+        'Murrtube',
+        'BadPups',
+        'TikTok',
+        'Mastodon',
+        'Reddit',
+        'Telegram',
+        'Discord',
+        'YouTube',
+        'FurTrack',
+        'Google Analytics',
+        'FurAffinity',
+        'Weasyl',
+        'Inkbunny',
+        'DeviantArt',
+        'Pixiv',
+        'Twitch',
+        'Kick',
+        'Patreon',
+        'Ko-fi',
+        'SubscribeStar'
+    )
+);
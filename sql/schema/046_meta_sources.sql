@@ -0,0 +1,66 @@
+-- +goose Up
+-- Update network constraint to include Threads and Facebook Pages
+ALTER TABLE sources DROP CONSTRAINT network_check;
+
+ALTER TABLE sources
+ADD CONSTRAINT network_check CHECK (
+    network IN (
+        'Instagram',
+        'Bluesky',
+        'Murrtube',
+        'BadPups',
+        'TikTok',
+        'Mastodon',
+        'Reddit',
+        'Telegram',
+        'Discord',
+        'YouTube',
+        'FurTrack',
+        'Google Analytics',
+        'FurAffinity',
+        'Weasyl',
+        'Inkbunny',
+        'DeviantArt',
+        'Pixiv',
+        'Twitch',
+        'Kick',
+        'Patreon',
+        'Ko-fi',
+        'SubscribeStar',
+        'Lemmy',
+        'Threads',
+        'Facebook'
+    )
+);
+
+-- +goose Down
+ALTER TABLE sources DROP CONSTRAINT network_check;
+
+ALTER TABLE sources
+ADD CONSTRAINT network_check CHECK (
+    network IN (
+        'Instagram',
+        'Bluesky',
+        'Murrtube',
+        'BadPups',
+        'TikTok',
+        'Mastodon',
+        'Reddit',
+        'Telegram',
+        'Discord',
+        'YouTube',
+        'FurTrack',
+        'Google Analytics',
+        'FurAffinity',
+        'Weasyl',
+        'Inkbunny',
+        'DeviantArt',
+        'Pixiv',
+        'Twitch',
+        'Kick',
+        'Patreon',
+        'Ko-fi',
+        'SubscribeStar',
+        'Lemmy'
+    )
+);
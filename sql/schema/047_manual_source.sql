@@ -0,0 +1,69 @@
+-- +goose Up
+-- Update network constraint to include manually entered sources
+ALTER TABLE sources DROP CONSTRAINT network_check;
+
+ALTER TABLE sources
+ADD CONSTRAINT network_check CHECK (
+    network IN (
+        'Instagram',
+        'Bluesky',
+        'Murrtube',
+        'BadPups',
+        'TikTok',
+        'Mastodon',
+        'Reddit',
+        'Telegram',
+        'Discord',
+        'YouTube',
+        'FurTrack',
+        'Google Analytics',
+        'FurAffinity',
+        'Weasyl',
+        'Inkbunny',
+        'DeviantArt',
+        'Pixiv',
+        'Twitch',
+        'Kick',
+        'Patreon',
+        'Ko-fi',
+        'SubscribeStar',
+        'Lemmy',
+        'Threads',
+        'Facebook',
+        'Manual'
+    )
+);
+
+-- +goose Down
+ALTER TABLE sources DROP CONSTRAINT network_check;
+
+ALTER TABLE sources
+ADD CONSTRAINT network_check CHECK (
+    network IN (
+        'Instagram',
+        'Bluesky',
+        'Murrtube',
+        'BadPups',
+        'TikTok',
+        'Mastodon',
+        'Reddit',
+        'Telegram',
+        'Discord',
+        'YouTube',
+        'FurTrack',
+        'Google Analytics',
+        'FurAffinity',
+        'Weasyl',
+        'Inkbunny',
+        'DeviantArt',
+        'Pixiv',
+        'Twitch',
+        'Kick',
+        'Patreon',
+        'Ko-fi',
+        'SubscribeStar',
+        'Lemmy',
+        'Threads',
+        'Facebook'
+    )
+);
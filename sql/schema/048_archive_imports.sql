@@ -0,0 +1,7 @@
+-- +goose Up
+-- Posts created from a platform data export, kept until a live sync sees them
+ALTER TABLE posts
+ADD COLUMN is_imported BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN is_imported;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Stable account ID on the network, e.g. the Bluesky DID behind a handle
+ALTER TABLE sources
+ADD COLUMN account_id TEXT;
+
+ALTER TABLE posts_reactions_history
+ADD COLUMN quotes BIGINT,
+ADD COLUMN bookmarks BIGINT;
+
+-- +goose Down
+ALTER TABLE posts_reactions_history
+DROP COLUMN bookmarks,
+DROP COLUMN quotes;
+
+ALTER TABLE sources DROP COLUMN account_id;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE source_username_history (
+    id UUID PRIMARY KEY,
+    source_id UUID NOT NULL,
+    CONSTRAINT fk_source FOREIGN KEY (source_id) REFERENCES sources (id) ON DELETE CASCADE,
+    old_user_name TEXT NOT NULL,
+    new_user_name TEXT NOT NULL,
+    changed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_source_username_history_source ON source_username_history (source_id);
+
+-- +goose Down
+DROP TABLE source_username_history;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Update target type constraint to include Google Sheets
+ALTER TABLE targets DROP CONSTRAINT type_check;
+
+ALTER TABLE targets
+ADD CONSTRAINT type_check CHECK (
+    target_type IN (
+        'NocoDB',
+        'Notion',
+        'CSV',
+        'Google Sheets',
+        'None'
+    )
+);
+
+-- +goose Down
+ALTER TABLE targets DROP CONSTRAINT type_check;
+
+ALTER TABLE targets
+ADD CONSTRAINT type_check CHECK (
+    target_type IN ('NocoDB', 'Notion', 'CSV', 'None')
+);
@@ -0,0 +1,31 @@
+-- +goose Up
+-- Update target type constraint to include Baserow, Grist and Teable
+ALTER TABLE targets DROP CONSTRAINT type_check;
+
+ALTER TABLE targets
+ADD CONSTRAINT type_check CHECK (
+    target_type IN (
+        'NocoDB',
+        'Notion',
+        'CSV',
+        'Google Sheets',
+        'Baserow',
+        'Grist',
+        'Teable',
+        'None'
+    )
+);
+
+-- +goose Down
+ALTER TABLE targets DROP CONSTRAINT type_check;
+
+ALTER TABLE targets
+ADD CONSTRAINT type_check CHECK (
+    target_type IN (
+        'NocoDB',
+        'Notion',
+        'CSV',
+        'Google Sheets',
+        'None'
+    )
+);
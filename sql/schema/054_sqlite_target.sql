@@ -0,0 +1,39 @@
+-- +goose Up
+-- Update target type constraint to include SQLite snapshots
+ALTER TABLE targets DROP CONSTRAINT type_check;
+
+ALTER TABLE targets
+ADD CONSTRAINT type_check CHECK (
+    target_type IN (
+        'NocoDB',
+        'Notion',
+        'CSV',
+        'Google Sheets',
+        'Baserow',
+        'Grist',
+        'Teable',
+        'PostgreSQL',
+        'MySQL',
+        'SQLite',
+        'None'
+    )
+);
+
+-- +goose Down
+ALTER TABLE targets DROP CONSTRAINT type_check;
+
+ALTER TABLE targets
+ADD CONSTRAINT type_check CHECK (
+    target_type IN (
+        'NocoDB',
+        'Notion',
+        'CSV',
+        'Google Sheets',
+        'Baserow',
+        'Grist',
+        'Teable',
+        'PostgreSQL',
+        'MySQL',
+        'None'
+    )
+);
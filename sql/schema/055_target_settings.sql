@@ -0,0 +1,7 @@
+-- +goose Up
+-- Per target options, e.g. which CSV outputs go into the export bundle
+ALTER TABLE targets
+ADD COLUMN settings JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- +goose Down
+ALTER TABLE targets DROP COLUMN settings;
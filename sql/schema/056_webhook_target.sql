@@ -0,0 +1,64 @@
+-- +goose Up
+-- Update target type constraint to include outbound webhooks
+ALTER TABLE targets DROP CONSTRAINT type_check;
+
+ALTER TABLE targets
+ADD CONSTRAINT type_check CHECK (
+    target_type IN (
+        'NocoDB',
+        'Notion',
+        'CSV',
+        'Google Sheets',
+        'Baserow',
+        'Grist',
+        'Teable',
+        'PostgreSQL',
+        'MySQL',
+        'SQLite',
+        'Webhook',
+        'None'
+    )
+);
+
+-- One row per event and webhook target, kept as the delivery log
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    target_id UUID NOT NULL,
+    CONSTRAINT fk_target FOREIGN KEY (target_id) REFERENCES targets (id) ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'Pending',
+    CONSTRAINT status_check CHECK (status IN ('Pending', 'Delivered', 'Failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_attempt_at TIMESTAMP,
+    response_status INTEGER,
+    last_error TEXT
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (target_id, status, next_attempt_at);
+
+CREATE INDEX idx_webhook_deliveries_created ON webhook_deliveries (target_id, created_at);
+
+-- +goose Down
+DROP TABLE webhook_deliveries;
+
+ALTER TABLE targets DROP CONSTRAINT type_check;
+
+ALTER TABLE targets
+ADD CONSTRAINT type_check CHECK (
+    target_type IN (
+        'NocoDB',
+        'Notion',
+        'CSV',
+        'Google Sheets',
+        'Baserow',
+        'Grist',
+        'Teable',
+        'PostgreSQL',
+        'MySQL',
+        'SQLite',
+        'None'
+    )
+);
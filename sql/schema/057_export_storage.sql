@@ -0,0 +1,6 @@
+-- +goose Up
+-- Object key of an export uploaded to the target's S3-compatible bucket
+ALTER TABLE exports ADD COLUMN storage_key TEXT;
+
+-- +goose Down
+ALTER TABLE exports DROP COLUMN storage_key;
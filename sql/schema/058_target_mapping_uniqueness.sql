@@ -0,0 +1,39 @@
+-- +goose Up
+-- Record IDs only have to be unique within one target. Targets that store
+-- RPSync's own IDs, or number their rows from 1, share values across targets.
+ALTER TABLE posts_on_target
+DROP CONSTRAINT posts_on_target_target_post_id_key;
+
+ALTER TABLE posts_on_target
+ADD CONSTRAINT unique_posts_on_target_target_post_id UNIQUE (target_id, target_post_id);
+
+ALTER TABLE sources_on_target
+DROP CONSTRAINT sources_on_target_target_source_id_key;
+
+ALTER TABLE sources_on_target
+ADD CONSTRAINT unique_sources_on_target_target_source_id UNIQUE (target_id, target_source_id);
+
+ALTER TABLE sources_stats_on_target
+DROP CONSTRAINT sources_stats_on_target_target_record_id_key;
+
+ALTER TABLE sources_stats_on_target
+ADD CONSTRAINT unique_sources_stats_on_target_target_record_id UNIQUE (target_id, target_record_id);
+
+-- +goose Down
+ALTER TABLE sources_stats_on_target
+DROP CONSTRAINT unique_sources_stats_on_target_target_record_id;
+
+ALTER TABLE sources_stats_on_target
+ADD CONSTRAINT sources_stats_on_target_target_record_id_key UNIQUE (target_record_id);
+
+ALTER TABLE sources_on_target
+DROP CONSTRAINT unique_sources_on_target_target_source_id;
+
+ALTER TABLE sources_on_target
+ADD CONSTRAINT sources_on_target_target_source_id_key UNIQUE (target_source_id);
+
+ALTER TABLE posts_on_target
+DROP CONSTRAINT unique_posts_on_target_target_post_id;
+
+ALTER TABLE posts_on_target
+ADD CONSTRAINT posts_on_target_target_post_id_key UNIQUE (target_post_id);
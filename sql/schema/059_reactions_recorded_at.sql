@@ -0,0 +1,12 @@
+-- +goose Up
+-- When a history row was last written, so targets can pick up backfilled days
+-- that are older than what they already loaded
+ALTER TABLE posts_reactions_history
+ADD COLUMN recorded_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+CREATE INDEX idx_posts_reactions_history_recorded_at ON posts_reactions_history (recorded_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_posts_reactions_history_recorded_at;
+
+ALTER TABLE posts_reactions_history DROP COLUMN recorded_at;
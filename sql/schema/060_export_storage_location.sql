@@ -0,0 +1,24 @@
+-- +goose Up
+-- Where an uploaded export lives, so it can still be served and removed after
+-- the target is pointed at another bucket or stops uploading
+ALTER TABLE exports
+ADD COLUMN storage_endpoint TEXT,
+ADD COLUMN storage_region TEXT,
+ADD COLUMN storage_bucket TEXT;
+
+UPDATE exports e
+SET
+    storage_endpoint = t.settings ->> 's3_endpoint',
+    storage_region = COALESCE(t.settings ->> 's3_region', ''),
+    storage_bucket = t.settings ->> 's3_bucket'
+FROM targets t
+WHERE
+    e.target_id = t.id
+    AND e.storage_key IS NOT NULL
+    AND t.settings ->> 's3_bucket' IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE exports
+DROP COLUMN storage_bucket,
+DROP COLUMN storage_region,
+DROP COLUMN storage_endpoint;
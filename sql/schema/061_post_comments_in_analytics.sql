@@ -0,0 +1,33 @@
+-- +goose Up
+-- Comment counts are kept with the other per-day post analytics only
+INSERT INTO
+    posts_analytics_history (id, date, post_id, comments)
+SELECT DISTINCT
+    ON (prh.post_id, date_trunc('day', prh.synced_at)) gen_random_uuid (),
+    date_trunc('day', prh.synced_at),
+    prh.post_id,
+    prh.comments
+FROM posts_reactions_history prh
+WHERE
+    prh.comments IS NOT NULL
+ORDER BY prh.post_id, date_trunc('day', prh.synced_at), prh.synced_at DESC
+ON CONFLICT (post_id, date) DO
+UPDATE
+SET
+    comments = COALESCE(
+        posts_analytics_history.comments,
+        EXCLUDED.comments
+    );
+
+ALTER TABLE posts_reactions_history DROP COLUMN comments;
+
+-- +goose Down
+ALTER TABLE posts_reactions_history ADD COLUMN comments BIGINT;
+
+UPDATE posts_reactions_history prh
+SET
+    comments = pah.comments
+FROM posts_analytics_history pah
+WHERE
+    pah.post_id = prh.post_id
+    AND pah.date = date_trunc('day', prh.synced_at);